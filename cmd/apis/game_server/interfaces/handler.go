@@ -28,6 +28,8 @@ func Run(port int, w *dataServerApi.DataServerApiService, IPWhitelist map[string
 		v1.GET("/production_matches", w.GetProdMatches)
 		v1.GET("/production_results", w.GetProdWinningOutcomes)
 		v1.GET("/production_live_scores", w.GetProdLiveScores)
+		v1.POST("/bet_builder/price", w.PriceBetBuilder)
+		v1.GET("/verify_draws", w.VerifyDraws)
 	}
 
 	v2 := Router.Group("/v2")
@@ -39,6 +41,12 @@ func Run(port int, w *dataServerApi.DataServerApiService, IPWhitelist map[string
 		v2.GET("/games", w.GetProdMatches)
 		v2.GET("/results", w.GetProdWinningOutcomes)
 		v2.GET("/scores", w.GetProdLiveScores)
+		v2.POST("/bet_builder/price", w.PriceBetBuilder)
+		v2.POST("/bet_builder/settle", w.SettleBetBuilder)
+		v2.GET("/category_inventory", w.CategoryInventory)
+		v2.GET("/verify_draws", w.VerifyDraws)
+		v2.GET("/goal_drift", w.GoalDrift)
+		v2.GET("/reuse_counters", w.ReuseCounters)
 	}
 
 	portStr := fmt.Sprintf(":%d", port)
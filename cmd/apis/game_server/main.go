@@ -22,12 +22,43 @@ var addConfigPathLocal = "/apps/go/magic_carpet/cmd/apis/game_server/"
 func main() {
 	InitConfig()
 
-	w, err := dataServerApi.NewDataServerApiService(
+	cfgs := []dataServerApi.DataServerApiConfiguration{
 		dataServerApi.WithMysqlLeaguesRepository(viper.GetString("mysql.live")),
 		dataServerApi.WithMysqlSeasonWeeksRepository(viper.GetString("mysql.live")),
+		dataServerApi.WithMysqlOddsProfilesRepository(viper.GetString("mysql.live")),
 		dataServerApi.WithRedisProdRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// Same match combinations are priced from the correct score matrix of the match.
+	if viper.GetBool("bet_builder.enabled") {
+		cfgs = append(cfgs, dataServerApi.WithBetBuilder(viper.GetFloat64("bet_builder.overround"),
+			viper.GetInt("bet_builder.max_legs"), viper.GetFloat64("bet_builder.max_odd")))
+	}
+
+	// Live count of the goal category sets every daemon draws matches from.
+	if viper.GetBool("category_inventory.enabled") {
+		cfgs = append(cfgs, dataServerApi.WithCategoryInventory())
+	}
+
+	// Seed commitments and draws of every scope, for players to verify.
+	if viper.GetBool("seed_commitments.enabled") {
+		cfgs = append(cfgs, dataServerApi.WithMysqlSeedCommitmentsRepository(viper.GetString("mysql.live")))
+	}
+
+	// Published results of a competition against its historical distribution.
+	if viper.GetBool("goal_drift.enabled") {
+		cfgs = append(cfgs, dataServerApi.WithGoalDrift(viper.GetString("mysql.live"),
+			viper.GetFloat64("goal_drift.goals_per_match_bound"), viper.GetFloat64("goal_drift.rate_bound"),
+			viper.GetFloat64("goal_drift.alpha"), viper.GetInt("goal_drift.min_matches")))
+	}
+
+	// Claims the reuse policy kept out of season weeks and instant rounds.
+	if viper.GetBool("reuse_policy.enabled") {
+		cfgs = append(cfgs, dataServerApi.WithMysqlReusePoliciesRepository(viper.GetString("mysql.live")))
+	}
+
+	w, err := dataServerApi.NewDataServerApiService(cfgs...)
 	if err != nil {
 		fmt.Printf("Unable to start data server api service ** %v", err)
 	}
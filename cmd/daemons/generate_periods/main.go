@@ -26,14 +26,21 @@ var inProgress2 bool
 func main() {
 	InitConfig()
 
-	pg, err := generatePeriod.NewGeneratePeriodService(
+	cfgs := []generatePeriod.GeneratePeriodConfiguration{
 		generatePeriod.WithMysqlSsnsRepository(viper.GetString("mySQL.live")),
 		generatePeriod.WithMysqlScheduledTimeRepository(viper.GetString("mySQL.live")),
 		generatePeriod.WithMysqlGoalPatternsRepository(viper.GetString("mySQL.live")),
 		generatePeriod.WithMysqlSnWkPtsRepository(viper.GetString("mySQL.live")),
 		generatePeriod.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// The seed goal patterns are drawn from is committed to and revealed after the season.
+	if viper.GetBool("seed_commitments.enabled") {
+		cfgs = append(cfgs, generatePeriod.WithMysqlSeedCommitmentsRepository(viper.GetString("mySQL.live")))
+	}
+
+	pg, err := generatePeriod.NewGeneratePeriodService(cfgs...)
 	if err != nil {
 		log.Printf("Unable to start generate period service ::: %s", err)
 	}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns"
 	"github.com/lukemakhanu/magic_carpet/internal/services/goalPattern"
 	"github.com/spf13/viper"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -26,7 +27,14 @@ var inProgress bool
 func main() {
 	InitConfig()
 
+	// Season length, strategy and retirement per competition, competitions 1 to 4 when not set.
+	var competitions []goalPatterns.Settings
+	if err := viper.UnmarshalKey("goal_patterns.competitions", &competitions); err != nil {
+		log.Printf("Err : %v failed to read goal pattern competitions", err)
+	}
+
 	pg, err := goalPattern.NewGoalPatternService(
+		goalPattern.WithCompetitions(competitions),
 		goalPattern.WithMysqlMrsRepository(viper.GetString("mySQL.live")),
 		goalPattern.WithMysqGoalPatternsRepository(viper.GetString("mySQL.live")),
 		goalPattern.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
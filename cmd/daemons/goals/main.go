@@ -13,6 +13,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels"
 	"github.com/lukemakhanu/magic_carpet/internal/services/goal"
 	"github.com/spf13/viper"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -30,13 +31,22 @@ func main() {
 	oddsSortedSet := viper.GetString("redis-sorted-set.odds")
 	projectID := viper.GetString("redis-sorted-set.projectID")
 
+	// Category sets are filled up to their target level, the default levels when none is set.
+	var ll []stockLevels.Level
+	err := viper.UnmarshalKey("stock_levels.levels", &ll)
+	if err != nil {
+		log.Printf("Err : %v unable to read stock levels", err)
+	}
+
 	pg, err := goal.NewGoalService(
 		goal.WithMysqlCheckMatchesRepository(viper.GetString("mySQL.live")),
 		goal.WithMysqlGoalsRepository(viper.GetString("mySQL.live")),
+		goal.WithMysqlInconsistentMatchesRepository(viper.GetString("mySQL.live")),
 		goal.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
 		goal.WithSlowRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		goal.WithStockLevels(ll),
 	)
 	if err != nil {
 		log.Printf(" **** Unable to start goal service **** : %s", err)
@@ -0,0 +1,150 @@
+// Package main runs the tavern and performs an Order
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels"
+	"github.com/lukemakhanu/magic_carpet/internal/services/inventoryMonitor"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/daemons/inventory_monitor/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/daemons/inventory_monitor/"
+
+var inProgress bool
+
+func main() {
+	InitConfig()
+
+	var ll []stockLevels.Level
+	err := viper.UnmarshalKey("stock_levels.levels", &ll)
+	if err != nil {
+		log.Printf("Err : %v unable to read stock levels", err)
+	}
+
+	playerIDs := []string{}
+	for _, p := range strings.Split(viper.GetString("stock_levels.players"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			playerIDs = append(playerIDs, p)
+		}
+	}
+
+	cfgs := []inventoryMonitor.InventoryMonitorConfiguration{
+		inventoryMonitor.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		inventoryMonitor.WithStockLevels(viper.GetString("stock_levels.sanitizedSet"), playerIDs, ll),
+		inventoryMonitor.WithConsumptionWindow(time.Duration(viper.GetInt("inventory_monitor.window_minutes"))*time.Minute,
+			viper.GetFloat64("inventory_monitor.depletion_hours")),
+	}
+
+	// Sets under their minimum or running out are posted to the ops webhook.
+	if viper.GetBool("webhook.enabled") {
+		cfgs = append(cfgs, inventoryMonitor.WithWebhook(viper.GetString("webhook.url"),
+			time.Duration(viper.GetInt("webhook.cooldown_minutes"))*time.Minute))
+	}
+
+	w, err := inventoryMonitor.NewInventoryMonitorService(cfgs...)
+	if err != nil {
+		log.Printf(" **** Unable to start inventory monitor service **** : %s", err)
+		return
+	}
+
+	ctx := context.Background()
+
+	router := gin.Default()
+	router.GET("/v1/inventory", w.GetStocks)
+	go func() {
+		portStr := fmt.Sprintf(":%d", viper.GetInt("inventory_monitor.port"))
+		log.Printf("Running on port ::: %s", portStr)
+		err := router.Run(portStr)
+		if err != nil {
+			log.Printf("Err : %v inventory endpoint stopped", err)
+		}
+	}()
+
+	ticker := time.NewTicker(time.Duration(viper.GetInt("inventory_monitor.interval_seconds")) * time.Second)
+	defer ticker.Stop()
+	go func() {
+		for {
+			select {
+			case t := <-ticker.C:
+				if !inProgress {
+					inProgress = true
+
+					Check(ctx, w)
+
+				} else {
+					log.Printf("**** Check in process **** %v.\n", t)
+				}
+			}
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	defer close(sig)
+	signal.Notify(sig, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
+
+	s := <-sig
+
+	fmt.Println("caught signal and exiting", s)
+}
+
+// Check : counts the category sets and alerts on the ones running dry
+func Check(ctx context.Context, w *inventoryMonitor.InventoryMonitorService) {
+
+	defer func() {
+		inProgress = false
+		log.Printf("** done calling Check ** ")
+	}()
+
+	err := w.Check(ctx)
+	if err != nil {
+		log.Printf("Err : %v failed to check inventory", err)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("inventory_monitor.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -31,6 +31,7 @@ func main() {
 		prepareMatch.WithMysqlCleanUpsRepository(viper.GetString("mySQL.live")),
 		prepareMatch.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		prepareMatch.WithReuseAfterDays(viper.GetInt("reuse_policy.global_days")),
 	)
 	if err != nil {
 		log.Printf(" **** Unable to start prepare match service ***** : %s", err)
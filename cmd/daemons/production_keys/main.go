@@ -13,6 +13,11 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/lukemakhanu/magic_carpet/internal/services/oddsProfile"
 	"github.com/lukemakhanu/magic_carpet/internal/services/productionKey"
 	"github.com/spf13/viper"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -30,16 +35,92 @@ func main() {
 	sanitizedKeysSet := viper.GetString("redis-sorted-set.sanitizedKeysSet")
 	minimumRequired := viper.GetInt("redis-sorted-set.minimumRequired")
 
-	pg, err := productionKey.NewProcessKeyService(
+	cfgs := []productionKey.ProcessKeyConfiguration{
 		productionKey.WithMysqlMatchesRepository(viper.GetString("mySQL.live")),
 		productionKey.WithMysqlSeasonWeeksRepository(viper.GetString("mySQL.live")),
 		productionKey.WithMysqlCheckMatchesRepository(viper.GetString("mySQL.live")),
 		productionKey.WithMysqlMrsRepository(viper.GetString("mySQL.live")),
 		productionKey.WithMysqlUsedMatchesRepository(viper.GetString("mySQL.live")),
 		productionKey.WithMysqlCleanUpsRepository(viper.GetString("mySQL.live")),
+		productionKey.WithMysqlWeekResultsRepository(viper.GetString("mySQL.live")),
 		productionKey.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// Without margins a flat factor is taken off every price.
+	var mm []margins.MarketMargin
+	err := viper.UnmarshalKey("margins", &mm)
+	if err != nil {
+		log.Printf("Err : %v unable to read margins", err)
+	}
+	if len(mm) > 0 {
+		cfgs = append(cfgs, productionKey.WithMarginEngine(mm))
+	}
+
+	// Markets of every match are checked before a season week is published.
+	if viper.GetBool("odds_validation.enabled") {
+		var rules oddsChecks.Rules
+		err := viper.UnmarshalKey("odds_validation.rules", &rules)
+		if err != nil {
+			log.Printf("Err : %v unable to read odds validation rules", err)
+		}
+		cfgs = append(cfgs, productionKey.WithOddsValidator(rules, viper.GetInt("odds_validation.max_swaps")))
+	}
+
+	// Provider winning outcomes are settled again before a season week is published.
+	if viper.GetBool("settlement.check") {
+		cfgs = append(cfgs, productionKey.WithSettlementEngine())
+	}
+
+	// Odds are also saved priced for every client with an odds profile.
+	if viper.GetBool("odds_profiles.enabled") {
+		op, err := oddsProfile.NewOddsProfileService(
+			oddsProfile.WithMysqlOddsProfilesRepository(viper.GetString("mySQL.live")),
+			oddsProfile.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+				viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		)
+		if err != nil {
+			log.Printf("Err : %v unable to start odds profiles", err)
+		} else {
+			cfgs = append(cfgs, productionKey.WithOddsProfileService(op))
+		}
+	}
+
+	// Markets priced from the correct scores, offered when the catalogue lists them.
+	if viper.GetBool("derived_markets.enabled") {
+		cfgs = append(cfgs, productionKey.WithDerivedMarkets(viper.GetStringSlice("derived_markets.codes")))
+	}
+
+	// Markets, their names and order come from the catalogue when it is enabled.
+	if viper.GetBool("market_catalogue.enabled") {
+		mc, err := marketCatalogue.NewMarketCatalogueService(
+			marketCatalogue.WithMysqlMarketCataloguesRepository(viper.GetString("mySQL.live")),
+			marketCatalogue.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+				viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+			marketCatalogue.WithCache(viper.GetString("market_catalogue.cache_key"), viper.GetDuration("market_catalogue.cache_ttl")),
+		)
+		if err != nil {
+			log.Printf("Err : %v unable to start market catalogue", err)
+		} else {
+			cfgs = append(cfgs, productionKey.WithMysqlLeaguesRepository(viper.GetString("mySQL.live")),
+				productionKey.WithMarketCatalogueService(mc, viper.GetString("market_catalogue.locale")))
+		}
+	}
+
+	// The seed matches are drawn from is committed to and revealed after the season week.
+	if viper.GetBool("seed_commitments.enabled") {
+		cfgs = append(cfgs, productionKey.WithMysqlSeedCommitmentsRepository(viper.GetString("mySQL.live")))
+	}
+
+	// Matches are only shown again as the reuse policy allows.
+	if viper.GetBool("reuse_policy.enabled") {
+		cfgs = append(cfgs, productionKey.WithMysqlReusePoliciesRepository(viper.GetString("mySQL.live"),
+			viper.GetInt("reuse_policy.global_days"), viper.GetInt("reuse_policy.player_days"),
+			viper.GetBool("reuse_policy.same_season"), viper.GetBool("reuse_policy.same_season_week"),
+			viper.GetInt("reuse_policy.max_blocked")))
+	}
+
+	pg, err := productionKey.NewProcessKeyService(cfgs...)
 	if err != nil {
 		log.Printf(" **** Unable to start production keys service ***** : %s", err)
 	}
@@ -66,6 +147,15 @@ func main() {
 		}
 	}()
 
+	// Keys of season weeks whose build stopped go back to their claim sets.
+	reaper := time.NewTicker(processRedis.ReapInterval)
+	defer reaper.Stop()
+	go func() {
+		for range reaper.C {
+			pg.ReapLeases(ctx)
+		}
+	}()
+
 	sig := make(chan os.Signal, 1)
 	defer close(sig)
 	signal.Notify(sig, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
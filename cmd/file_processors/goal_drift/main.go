@@ -0,0 +1,143 @@
+// Package main compares the results published for every competition with its historical
+// results in mrs and flags drift beyond the configured bounds. It exits with 2 when a
+// competition drifted.
+//
+//	goal_drift -competition 1,2 -from 2024-12-01 -to 2024-12-19
+//	goal_drift -competition 1 -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/services/goalDrift"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/goal_drift/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/goal_drift/"
+
+func main() {
+	competitions := flag.String("competition", "", "competition ids separated by commas, goal_drift.competitions when empty")
+	fromDay := flag.String("from", "", "first day (yyyy-mm-dd), 30 days ago when empty")
+	toDay := flag.String("to", "", "day after the last (yyyy-mm-dd), tomorrow when empty")
+	asJSON := flag.Bool("json", false, "print the reports as json")
+	flag.Parse()
+
+	InitConfig()
+
+	if *competitions == "" {
+		*competitions = viper.GetString("goal_drift.competitions")
+	}
+
+	competitionIDs := []string{}
+	for _, c := range strings.Split(*competitions, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			competitionIDs = append(competitionIDs, c)
+		}
+	}
+
+	if len(competitionIDs) == 0 {
+		fmt.Fprintf(os.Stderr, "Err : no competition set\n")
+		os.Exit(1)
+	}
+
+	gd, err := goalDrift.NewGoalDriftService(
+		goalDrift.WithMysqlSeasonWeeksRepository(viper.GetString("mysql.live")),
+		goalDrift.WithGoalDrift(viper.GetString("mysql.live"),
+			viper.GetFloat64("goal_drift.goals_per_match_bound"), viper.GetFloat64("goal_drift.rate_bound"),
+			viper.GetFloat64("goal_drift.alpha"), viper.GetInt("goal_drift.min_matches")),
+	)
+	if err != nil {
+		log.Fatalf("Unable to start goal drift service : %s", err)
+	}
+
+	reports, err := gd.Report(context.Background(), competitionIDs, *fromDay, *toDay)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	drifted := false
+	for _, r := range reports {
+		log.Printf("competition %s weeks %d matches %d drifted %v", r.CompetitionID, r.SeasonWeeks, r.Published.Matches, r.Drifted)
+		if r.Drifted {
+			drifted = true
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, r := range reports {
+
+			fmt.Printf("competition %s  %s to %s  weeks %d (missing %d)  matches %d published / %d historical\n",
+				r.CompetitionID, r.From, r.To, r.SeasonWeeks, r.MissingWeeks, r.Published.Matches, r.Historical.Matches)
+
+			if !r.Sufficient {
+				fmt.Printf("    fewer than %d matches, drift not flagged\n", r.Bounds.MinMatches)
+			}
+
+			fmt.Printf("    %-18s %10s %10s %10s %8s  %s\n", "METRIC", "PUBLISHED", "HISTORICAL", "DIFF", "BOUND", "DRIFTED")
+			for _, m := range r.Metrics {
+				fmt.Printf("    %-18s %10.4f %10.4f %+10.4f %8.4f  %v\n", m.Name, m.Published, m.Historical, m.Difference, m.Bound, m.Drifted)
+			}
+
+			fmt.Printf("    %-18s %10s %10s %10s %8s  %s\n", "TEST", "STATISTIC", "DF", "P VALUE", "ALPHA", "DRIFTED")
+			for _, t := range r.Tests {
+				fmt.Printf("    %-18s %10.4f %10d %10.6f %8.4f  %v\n", t.Name, t.Statistic, t.DegreesOfFreedom, t.PValue, r.Bounds.Alpha, t.Drifted)
+			}
+		}
+	}
+
+	if drifted {
+		os.Exit(2)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("goal_drift.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,178 @@
+// Package main builds the goal patterns of a competition and prints the goal curve of
+// every season before anything is saved. Patterns are only saved with -save.
+//
+//	goal_pattern_preview -competition 1
+//	goal_pattern_preview -competition 3 -strategy stratified -length 34 -weeks
+//	goal_pattern_preview -competition 1 -save
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns"
+	"github.com/lukemakhanu/magic_carpet/internal/services/goalPattern"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/goal_pattern_preview/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/goal_pattern_preview/"
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+func main() {
+	competitionID := flag.String("competition", "", "competition id")
+	strategy := flag.String("strategy", "", "shuffle, stratified or chronological, the configured strategy when empty")
+	length := flag.Int("length", 0, "rounds per season, the configured season length when 0")
+	weeks := flag.Bool("weeks", false, "list the weeks of every season")
+	asJSON := flag.Bool("json", false, "print the build as json")
+	save := flag.Bool("save", false, "save the seasons as active goal patterns")
+	flag.Parse()
+
+	InitConfig()
+
+	if *competitionID == "" {
+		fmt.Fprintf(os.Stderr, "Err : no competition set\n")
+		os.Exit(1)
+	}
+
+	// Season length, strategy and retirement per competition, competitions 1 to 4 when not set.
+	var competitions []goalPatterns.Settings
+	if err := viper.UnmarshalKey("goal_patterns.competitions", &competitions); err != nil {
+		log.Printf("Err : %v failed to read goal pattern competitions", err)
+	}
+
+	gp, err := goalPattern.NewGoalPatternService(
+		goalPattern.WithCompetitions(competitions),
+		goalPattern.WithMysqlMrsRepository(viper.GetString("mySQL.live")),
+		goalPattern.WithMysqGoalPatternsRepository(viper.GetString("mySQL.live")),
+	)
+	if err != nil {
+		log.Fatalf("Unable to start goal pattern service : %s", err)
+	}
+
+	st, err := gp.Settings(*competitionID)
+	if err != nil {
+		st = goalPatterns.Settings{CompetitionID: *competitionID, SeasonLength: goalPatterns.DefaultSeasonLength, Strategy: goalPatterns.Shuffle,
+			RetireDays: goalPatterns.DefaultRetireDays}
+	}
+
+	if *strategy != "" {
+		st.Strategy = *strategy
+	}
+
+	if *length > 0 {
+		st.SeasonLength = *length
+	}
+
+	s, err := goalPatterns.NewSettings(st.CompetitionID, st.SeasonLength, st.Strategy, st.RetireDays)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	b, err := gp.Preview(ctx, *s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("competition %s  strategy %s  season length %d  seasons %d  leftover %d  in use %d\n",
+			b.Settings.CompetitionID, b.Settings.Strategy, b.Settings.SeasonLength, len(b.Seasons), len(b.Leftover), b.InUse)
+
+		fmt.Printf("    %-8s %6s %5s %6s %5s  %s\n", "SEASON", "GOALS", "MIN", "AVG", "MAX", "CURVE")
+		for _, x := range b.Seasons {
+
+			curve := x.Curve()
+			total := curve[len(curve)-1]
+
+			lo, hi := x.Rounds[0].Goals, x.Rounds[0].Goals
+			for _, rd := range x.Rounds {
+				lo = min(lo, rd.Goals)
+				hi = max(hi, rd.Goals)
+			}
+
+			fmt.Printf("    %-8s %6d %5d %6.2f %5d  %s\n", x.SeasonID, total, lo, float64(total)/float64(len(x.Rounds)), hi, sparkline(x.Rounds, lo, hi))
+
+			if *weeks {
+				for i, rd := range x.Rounds {
+					fmt.Printf("        week %2d  round %-8d %s  goals %3d  cumulative %5d\n", i+1, rd.RoundNumberID, rd.StartTime, rd.Goals, curve[i])
+				}
+			}
+		}
+	}
+
+	if *save {
+		if err := gp.Save(ctx, b); err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "saved %d seasons\n", len(b.Seasons))
+	}
+}
+
+// sparkline : goals of every week of a season scaled between lo and hi.
+func sparkline(rr []goalPatterns.Round, lo, hi int) string {
+	var sb strings.Builder
+	for _, rd := range rr {
+		i := 0
+		if hi > lo {
+			i = (rd.Goals - lo) * (len(sparks) - 1) / (hi - lo)
+		}
+		sb.WriteRune(sparks[i])
+	}
+	return sb.String()
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("goal_patterns.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
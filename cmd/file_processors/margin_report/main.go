@@ -0,0 +1,161 @@
+// Package main prints the payout of every market once repriced by the margin engine,
+// for the margins configured and optionally for the markets of a provider odds file.
+//
+//	margin_report
+//	margin_report -file /ke/generator/odds/31114422_3_20.txt -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins/marginEngine"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/margin_report/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/margin_report/"
+
+func main() {
+	file := flag.String("file", "", "provider odds file to reprice")
+	asJSON := flag.Bool("json", false, "print the report as json")
+	flag.Parse()
+
+	InitConfig()
+
+	var mm []margins.MarketMargin
+	err := viper.UnmarshalKey("margins", &mm)
+	if err != nil {
+		log.Fatalf("Err : %v unable to read margins", err)
+	}
+
+	engine, err := marginEngine.New(mm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	if *file == "" {
+		PrintMargins(mm, *asJSON)
+		return
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	var o oddsFiles.RawOdds
+	err = json.Unmarshal(data, &o)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v failed to unmarshal %s\n", err, *file)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	priced := []margins.Priced{}
+	for _, x := range o.RawMarkets {
+
+		odds := []float64{}
+		for _, i := range x.RawOutcomes {
+			v, err := strconv.ParseFloat(i.OddValue, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Err : %v skipped market %s\n", err, x.SubTypeID)
+				odds = nil
+				break
+			}
+			odds = append(odds, v)
+		}
+		if odds == nil {
+			continue
+		}
+
+		p, err := engine.Reprice(ctx, x.SubTypeID, odds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			continue
+		}
+		priced = append(priced, p)
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(priced, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("Parent match %s\n\n", o.ParentMatchID)
+	fmt.Printf("%-10s %-13s %9s %9s %9s %8s  %s\n", "market", "method", "provider", "target", "book", "payout", "odds")
+	for _, p := range priced {
+		fmt.Printf("%-10s %-13s %9.4f %9.4f %9.4f %7.2f%%  %v\n", p.Code, p.Method, p.ProviderOverround,
+			p.TargetOverround, p.Overround, p.Payout*100, p.Odds)
+	}
+}
+
+// PrintMargins : target overround and payout of every configured market.
+func PrintMargins(mm []margins.MarketMargin, asJSON bool) {
+
+	sort.Slice(mm, func(i, j int) bool { return mm[i].Code < mm[j].Code })
+
+	if asJSON {
+		out, _ := json.MarshalIndent(mm, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("%-10s %-13s %9s %8s\n", "market", "method", "target", "payout")
+	for _, m := range mm {
+		if m.Method == margins.Keep {
+			fmt.Printf("%-10s %-13s %9s %8s\n", m.Code, m.Method, "provider", "provider")
+			continue
+		}
+		fmt.Printf("%-10s %-13s %9.4f %7.2f%%\n", m.Code, m.Method, m.Overround, 100/m.Overround)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("margin_report.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,239 @@
+// Package main lists and edits the market catalogue. Every change is saved in mysql and
+// the cached catalogue refreshed, the production key builders pick it up on their next week.
+//
+//	market_catalogue -list -client 2 -locale sw
+//	market_catalogue -code TG25 -client 2 -disable
+//	market_catalogue -code 1X2 -competition 3 -name "Match Result" -priority 5
+//	market_catalogue -code 1X2 -locale sw -name "Matokeo"
+//	market_catalogue -code GG -outcomes "GG,NG" -priority 40
+//	market_catalogue -refresh
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/market_catalogue/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/market_catalogue/"
+
+func main() {
+	list := flag.Bool("list", false, "print the markets offered to -competition / -client in -locale")
+	refresh := flag.Bool("refresh", false, "reload the cached catalogue from mysql")
+	asJSON := flag.Bool("json", false, "print the list as json")
+	code := flag.String("code", "", "market to change")
+	competitionID := flag.String("competition", "", "competition the change applies to")
+	clientID := flag.String("client", "", "client the change applies to")
+	locale := flag.String("locale", "", "locale of -name, or of the list")
+	name := flag.String("name", "", "market name")
+	outcomes := flag.String("outcomes", "", "outcome order, comma separated outcome ids")
+	priority := flag.Int("priority", 0, "display priority, lowest first")
+	enable := flag.Bool("enable", false, "offer the market")
+	disable := flag.Bool("disable", false, "stop offering the market")
+	inherit := flag.Bool("inherit", false, "drop the status of an override, the market status applies")
+	flag.Parse()
+
+	InitConfig()
+
+	mc, err := marketCatalogue.NewMarketCatalogueService(
+		marketCatalogue.WithMysqlMarketCataloguesRepository(viper.GetString("mySQL.live")),
+		marketCatalogue.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		marketCatalogue.WithCache(viper.GetString("market_catalogue.cache_key"), viper.GetDuration("market_catalogue.cache_ttl")),
+	)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+
+	status := ""
+	switch {
+	case *enable && *disable, *enable && *inherit, *disable && *inherit:
+		fail(fmt.Errorf("only one of -enable, -disable and -inherit can be set"))
+	case *enable:
+		status = marketCatalogues.Enabled
+	case *disable:
+		status = marketCatalogues.Disabled
+	case *inherit:
+		status = marketCatalogues.Inherit
+	}
+
+	switch {
+	case *refresh:
+		c, err := mc.Refresh(ctx)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("Catalogue refreshed : %d markets, %d names, %d overrides\n", len(c.Markets), len(c.Names), len(c.Overrides))
+		return
+
+	case *list:
+		sel, err := mc.Selection(ctx, *competitionID, *clientID, *locale)
+		if err != nil {
+			fail(err)
+		}
+		PrintMarkets(sel.Markets(), *asJSON)
+		return
+
+	case *code == "":
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := mc.Catalogue(ctx)
+	if err != nil {
+		fail(err)
+	}
+
+	switch {
+	case *competitionID != "" || *clientID != "":
+		err = SaveOverride(ctx, mc, c, *code, *competitionID, *clientID, status, *name, *priority)
+
+	case *locale != "":
+		if status != "" || *outcomes != "" || *priority != 0 {
+			fail(fmt.Errorf("-locale only renames a market, set -name alone"))
+		}
+		err = mc.SaveMarketName(ctx, *code, *locale, *name)
+
+	default:
+		if status == marketCatalogues.Inherit {
+			fail(fmt.Errorf("-inherit only applies to a competition or a client"))
+		}
+		err = SaveMarket(ctx, mc, c, *code, *name, *outcomes, *priority, status)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Printf("Market %s saved\n", *code)
+}
+
+// SaveMarket : adds a market or changes the fields that were set on an existing one.
+func SaveMarket(ctx context.Context, mc *marketCatalogue.MarketCatalogueService, c *marketCatalogues.Catalogue,
+	code, name, outcomes string, priority int, status string) error {
+
+	m := marketCatalogues.MarketCatalogues{Code: code, Status: marketCatalogues.Enabled}
+	for _, x := range c.Markets {
+		if x.Code == code {
+			m = x
+			break
+		}
+	}
+
+	if name != "" {
+		m.Name = name
+	}
+	if outcomes != "" {
+		m.OutcomeOrder = outcomes
+	}
+	if priority != 0 {
+		m.Priority = priority
+	}
+	if status != "" {
+		m.Status = status
+	}
+
+	return mc.SaveMarket(ctx, m.Code, m.Name, m.OutcomeOrder, m.Priority, m.Status)
+}
+
+// SaveOverride : adds an override or changes the fields that were set on an existing one.
+func SaveOverride(ctx context.Context, mc *marketCatalogue.MarketCatalogueService, c *marketCatalogues.Catalogue,
+	code, competitionID, clientID, status, name string, priority int) error {
+
+	known := false
+	for _, x := range c.Markets {
+		if x.Code == code {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("market %s is not in the catalogue", code)
+	}
+
+	o := marketCatalogues.MarketOverrides{Code: code, CompetitionID: competitionID, ClientID: clientID}
+	for _, x := range c.Overrides {
+		if x.Code == code && x.CompetitionID == competitionID && x.ClientID == clientID {
+			o = x
+			break
+		}
+	}
+
+	if status != "" {
+		o.Status = status
+	}
+	if name != "" {
+		o.Name = name
+	}
+	if priority != 0 {
+		o.Priority = priority
+	}
+
+	return mc.SaveMarketOverride(ctx, o.Code, o.CompetitionID, o.ClientID, o.Status, o.Name, o.Priority)
+}
+
+// PrintMarkets : markets offered, in display order.
+func PrintMarkets(dd []marketCatalogues.Display, asJSON bool) {
+
+	if asJSON {
+		out, _ := json.MarshalIndent(dd, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("%-8s %-8s %-40s %s\n", "priority", "market", "name", "outcomes")
+	for _, d := range dd {
+		fmt.Printf("%-8d %-8s %-40s %v\n", d.Priority, d.Code, d.Name, d.OutcomeOrder)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+	os.Exit(1)
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("market_catalogue.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,166 @@
+// Package main lists and edits the odds profiles of clients. Profiles are picked up by
+// the production key builders on the next season week they publish.
+//
+//	odds_profiles -list
+//	odds_profiles -client 2 -method proportional -margin 1.10 -max 500
+//	odds_profiles -client 3 -ladder "2:0.01,3:0.05,5:0.1,10:0.25,*:1" -markets "1X2,GG,TG25"
+//	odds_profiles -client 3 -inactive
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles/oddsProfilesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/services/oddsProfile"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/odds_profiles/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/odds_profiles/"
+
+func main() {
+	list := flag.Bool("list", false, "print every profile")
+	asJSON := flag.Bool("json", false, "print the list as json")
+	clientID := flag.String("client", "", "client the profile belongs to")
+	method := flag.String("method", "", "margin method : proportional, power, shin or keep")
+	margin := flag.Float64("margin", 0, "target overround, 1.10 pays out 90.9%")
+	minOdd := flag.Float64("min", 0, "lowest price offered")
+	maxOdd := flag.Float64("max", 0, "highest price offered, 0 for no cap")
+	ladder := flag.String("ladder", "", "rounding ladder, price:increment pairs, * for every price above")
+	markets := flag.String("markets", "", "comma separated market codes offered, every market when empty")
+	active := flag.Bool("active", false, "price odds for the client")
+	inactive := flag.Bool("inactive", false, "stop pricing odds for the client")
+	flag.Parse()
+
+	InitConfig()
+
+	conn := viper.GetString("mySQL.live")
+
+	repo, err := oddsProfilesMysql.New(conn)
+	if err != nil {
+		fail(err)
+	}
+
+	op, err := oddsProfile.NewOddsProfileService(oddsProfile.WithMysqlOddsProfilesRepository(conn))
+	if err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+
+	if *list {
+		pp, err := repo.GetProfiles(ctx)
+		if err != nil {
+			fail(err)
+		}
+		PrintProfiles(pp, *asJSON)
+		return
+	}
+
+	if *clientID == "" || (*active && *inactive) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Fields left out keep their saved value.
+	p := oddsProfiles.OddsProfiles{ClientID: *clientID, Status: oddsProfiles.Active}
+	pp, err := repo.GetProfileByClientID(ctx, *clientID)
+	if err != nil {
+		fail(err)
+	}
+	if len(pp) > 0 {
+		p = pp[0]
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "method":
+			p.Method = *method
+		case "margin":
+			p.Margin = *margin
+		case "min":
+			p.MinOdd = *minOdd
+		case "max":
+			p.MaxOdd = *maxOdd
+		case "ladder":
+			p.Ladder = *ladder
+		case "markets":
+			p.Markets = *markets
+		case "active":
+			p.Status = oddsProfiles.Active
+		case "inactive":
+			p.Status = oddsProfiles.Inactive
+		}
+	})
+
+	err = op.SaveProfile(ctx, p.ClientID, p.Method, p.Margin, p.MinOdd, p.MaxOdd, p.Ladder, p.Markets, p.Status)
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Printf("Odds profile of client %s saved\n", p.ClientID)
+}
+
+// PrintProfiles : every profile, by client.
+func PrintProfiles(pp []oddsProfiles.OddsProfiles, asJSON bool) {
+
+	if asJSON {
+		out, _ := json.MarshalIndent(pp, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("%-8s %-9s %-13s %-7s %-6s %-8s %-30s %s\n", "client", "status", "method", "margin", "min", "max", "ladder", "markets")
+	for _, p := range pp {
+		fmt.Printf("%-8s %-9s %-13s %-7.4f %-6.2f %-8.2f %-30s %s\n", p.ClientID, p.Status, p.Method, p.Margin,
+			p.MinOdd, p.MaxOdd, p.Ladder, p.Markets)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+	os.Exit(1)
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("odds_profiles.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,116 @@
+// Package main lists feed files moved into quarantine, per day and per country.
+//
+//	quarantine_report -from 2024-12-01 -to 2024-12-19 -country ke -list
+//	quarantine_report -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/services/quarantineReport"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/quarantine_report/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/quarantine_report/"
+
+func main() {
+	fromDay := flag.String("from", "", "first day (yyyy-mm-dd), empty for no bound")
+	toDay := flag.String("to", "", "last day (yyyy-mm-dd), empty for no bound")
+	country := flag.String("country", "", "country code, empty for all")
+	list := flag.Bool("list", false, "list every quarantined file")
+	asJSON := flag.Bool("json", false, "print the report as json")
+	flag.Parse()
+
+	InitConfig()
+
+	qr, err := quarantineReport.NewQuarantineReportService(
+		quarantineReport.WithQuarantineDirectoryRepository(viper.GetString("quarantine_report.quarantine_directory")),
+	)
+	if err != nil {
+		log.Fatalf("Unable to start quarantine report service : %s", err)
+	}
+
+	reports, err := qr.Report(context.Background(), *fromDay, *toDay, *country)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("%-12s %-8s %6s  %s\n", "DAY", "COUNTRY", "FILES", "REASONS")
+	for _, r := range reports {
+
+		codes := []string{}
+		for c := range r.Codes {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+
+		reasons := ""
+		for _, c := range codes {
+			reasons += fmt.Sprintf("%s=%d ", c, r.Codes[c])
+		}
+
+		fmt.Printf("%-12s %-8s %6d  %s\n", r.Day, r.Country, r.Total, reasons)
+
+		if *list {
+			for _, f := range r.Files {
+				fmt.Printf("    %s  %s/%s  [%s] %s=%q %s\n", f.QuarantinedAt, f.Directory, f.Rejection.FileName,
+					f.Rejection.Code, f.Rejection.Field, f.Rejection.Value, f.Rejection.Detail)
+			}
+		}
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("quarantine_report.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,165 @@
+// Package main replays stored odds, winning outcome and live score files of a country into a
+// separate redis database (or key prefix) and reports how they differ from what is live.
+//
+//	replay -country ke -from 2024-12-01 -to 2024-12-19
+//	replay -country tz -from 2024-12-01 -to 2024-12-01 -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/lukemakhanu/magic_carpet/internal/services/productionKey"
+	"github.com/lukemakhanu/magic_carpet/internal/services/replay"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/replay/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/replay/"
+
+func main() {
+	today := time.Now().Format("2006-01-02")
+	country := flag.String("country", "", "country code e.g ke, tz")
+	fromDate := flag.String("from", today, "first day (yyyy-mm-dd)")
+	toDate := flag.String("to", today, "last day (yyyy-mm-dd)")
+	asJSON := flag.Bool("json", false, "print the report as json")
+	flag.Parse()
+
+	if *country == "" {
+		fmt.Fprintln(os.Stderr, "country not set")
+		os.Exit(2)
+	}
+
+	InitConfig()
+
+	sameDatabase := viper.GetString("redis.live") == viper.GetString("replay_redis.live") &&
+		viper.GetInt("redis.dbNum") == viper.GetInt("replay_redis.dbNum")
+
+	cfgs := []replay.ReplayConfiguration{
+		replay.WithMysqlWinningOutcomesRepository(viper.GetString("mySQL.live")),
+		replay.WithLiveRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		replay.WithReplayRedisRepository(viper.GetString("mySQL.live"), viper.GetString("replay_redis.live"),
+			viper.GetInt("replay_redis.dbNum"), viper.GetInt("replay_redis.maxIdle"), viper.GetInt("replay_redis.maxActive"),
+			viper.GetDuration("replay_redis.duration"), viper.GetString("replay_redis.keyPrefix")),
+	}
+
+	if viper.GetString("blob_storage.driver") == "s3" {
+		cfgs = append(cfgs, replay.WithS3BlobRepository(viper.GetString("blob_storage.endpoint"),
+			viper.GetString("blob_storage.region"), viper.GetString("blob_storage.bucket"),
+			viper.GetString("blob_storage.access_key"), viper.GetString("blob_storage.secret_key"),
+			viper.GetBool("blob_storage.use_ssl")))
+	}
+
+	// Matches are priced the way production keys are. The flat factor used without margins
+	// is drawn at random, prices are only compared when margins are set.
+	var mm []margins.MarketMargin
+	err := viper.UnmarshalKey("margins", &mm)
+	if err != nil {
+		log.Printf("Err : %v unable to read margins", err)
+	}
+	if len(mm) > 0 {
+		pcfgs := []productionKey.ProcessKeyConfiguration{productionKey.WithMarginEngine(mm)}
+
+		if viper.GetBool("derived_markets.enabled") {
+			pcfgs = append(pcfgs, productionKey.WithDerivedMarkets(viper.GetStringSlice("derived_markets.codes")))
+		}
+
+		if viper.GetBool("market_catalogue.enabled") {
+			mc, err := marketCatalogue.NewMarketCatalogueService(
+				marketCatalogue.WithMysqlMarketCataloguesRepository(viper.GetString("mySQL.live")),
+			)
+			if err != nil {
+				log.Fatalf("Unable to start market catalogue : %s", err)
+			}
+			pcfgs = append(pcfgs, productionKey.WithMarketCatalogueService(mc, viper.GetString("market_catalogue.locale")))
+		}
+
+		pk, err := productionKey.NewProcessKeyService(pcfgs...)
+		if err != nil {
+			log.Fatalf("Unable to start production key service : %s", err)
+		}
+		cfgs = append(cfgs, replay.WithProductionKeyService(pk))
+	}
+
+	rs, err := replay.NewReplayService(cfgs...)
+	if err != nil {
+		log.Fatalf("Unable to start replay service : %s", err)
+	}
+
+	sets := replay.ReplaySets{
+		Odds:      viper.GetString("redis-sorted-set.odds"),
+		Wo:        viper.GetString("redis-sorted-set.winningOutcome"),
+		Ls:        viper.GetString("redis-sorted-set.liveScore"),
+		Sanitized: viper.GetString("redis-sorted-set.sanitizedSet"),
+	}
+
+	report, err := rs.Replay(context.Background(), *country, *fromDate, *toDate, sets, sameDatabase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("country %s | %s to %s | prefix %q\n", report.Country, report.FromDate, report.ToDate, report.KeyPrefix)
+	fmt.Printf("wo files %d | matches %d | identical %d | changed %d | not live %d | refused %d\n",
+		report.WoFiles, report.Matches, report.Identical, report.Changed, report.NotLive, report.Refused)
+
+	for _, d := range report.Diffs {
+		fmt.Printf("%-12s %-10s live=%s\n%-23s replay=%s\n", d.ParentMatchID, d.Field, d.Live, "", d.Replay)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("replay.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
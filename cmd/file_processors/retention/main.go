@@ -0,0 +1,161 @@
+// Package main moves processed feed files into dated archive directories and ages the
+// rows of the file tracking tables, following the per table policies of the config.
+//
+//	retention -dry-run -once
+//	retention
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/retentions"
+	"github.com/lukemakhanu/magic_carpet/internal/services/retention"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/retention/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/retention/"
+
+var inProgress bool
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be archived or purged, change nothing")
+	once := flag.Bool("once", false, "run the policies once and exit")
+	flag.Parse()
+
+	InitConfig()
+
+	var policies []retentions.Policy
+	err := viper.UnmarshalKey("retention.policies", &policies)
+	if err != nil {
+		log.Fatalf("Err : %v unable to read retention policies", err)
+	}
+
+	cfgs := []retention.RetentionConfiguration{
+		retention.WithMysqlRetentionsRepository(viper.GetString("mySQL.live")),
+		retention.WithPolicies(policies),
+		retention.WithDryRun(*dryRun || viper.GetBool("retention.dry_run")),
+	}
+
+	// Feed files dropped in a bucket are archived in the bucket.
+	if viper.GetString("blob_storage.driver") == "s3" {
+		cfgs = append(cfgs, retention.WithS3ArchiveRepository(viper.GetString("blob_storage.endpoint"),
+			viper.GetString("blob_storage.region"), viper.GetString("blob_storage.bucket"),
+			viper.GetString("blob_storage.access_key"), viper.GetString("blob_storage.secret_key"),
+			viper.GetBool("blob_storage.use_ssl"), viper.GetString("retention.archive_directory"), viper.GetBool("retention.compress")))
+	} else {
+		cfgs = append(cfgs, retention.WithArchiveDirectoryRepository(viper.GetString("retention.archive_directory"), viper.GetBool("retention.compress")))
+	}
+
+	rs, err := retention.NewRetentionService(cfgs...)
+	if err != nil {
+		log.Fatalf("Unable to start retention service : %s", err)
+	}
+
+	ctx := context.Background()
+
+	if *once {
+		reports, err := rs.Run(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		}
+
+		data, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(data))
+
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	interval := viper.GetDuration("retention.interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	go func() {
+		RunRetention(ctx, rs)
+		for {
+			select {
+			case t := <-ticker.C:
+				if !inProgress {
+					RunRetention(ctx, rs)
+				} else {
+					log.Printf("**** Retention in process **** %v.\n", t)
+				}
+			}
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	defer close(sig)
+	signal.Notify(sig, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
+
+	s := <-sig
+
+	fmt.Println("caught signal and exiting", s)
+}
+
+// RunRetention : applies every policy once
+func RunRetention(ctx context.Context, rs *retention.RetentionService) {
+
+	inProgress = true
+	defer func() {
+		inProgress = false
+		log.Printf("** done calling Retention ** ")
+	}()
+
+	_, err := rs.Run(ctx)
+	if err != nil {
+		log.Printf("Err : %v failed to apply retention", err)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("retention.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
@@ -0,0 +1,125 @@
+// Package main runs the statistical test battery against a selection rng and prints a
+// signed report for the test lab. Daemons with seed_commitments enabled select with the
+// seed commitment draws, the others with crypto/rand, so a report is needed for each
+// source in use. The source tested is named in the report.
+//
+//	rng_report -keygen
+//	rng_report -source crypto/rand -draws 1000000 -buckets 100 -alpha 0.01 -out report.json
+//	rng_report -source seed_commitments -draws 1000000 -buckets 100 -alpha 0.01 -out report.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
+	"github.com/lukemakhanu/magic_carpet/internal/services/rngReport"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/file_processors/rng_report/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/rng_report/"
+
+func main() {
+	draws := flag.Int("draws", 1000000, "64 bit words drawn for each test")
+	buckets := flag.Int("buckets", 100, "buckets of the chi square test")
+	alpha := flag.Float64("alpha", 0.01, "significance level, a test passes when its p value is at least alpha")
+	source := flag.String("source", cryptoRand.Name, "rng tested, crypto/rand or seed_commitments")
+	out := flag.String("out", "", "file the signed report is written to, empty for stdout")
+	keygen := flag.Bool("keygen", false, "print a new signing key and its public key")
+	flag.Parse()
+
+	if *keygen {
+		seed, publicKey, err := rngReport.GenerateKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("signing key : %s\npublic key  : %s\n", seed, publicKey)
+		return
+	}
+
+	InitConfig()
+
+	rr, err := rngReport.NewRngReportService(
+		rngReport.WithSource(*source),
+		rngReport.WithSigningKey(viper.GetString("rng_report.signing_key_file")),
+	)
+	if err != nil {
+		log.Fatalf("Unable to start rng report service : %s", err)
+	}
+
+	sr, err := rr.Run(context.Background(), *draws, *buckets, *alpha)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := json.MarshalIndent(sr, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+		os.Exit(1)
+	}
+
+	log.Printf("source %s draws %d passed %v", sr.Report.Source, sr.Report.Draws, sr.Report.Passed)
+
+	if *out == "" {
+		fmt.Println(string(data))
+	} else {
+		err = os.WriteFile(*out, data, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Err : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%-12s %10s %14s %10s  %s\n", "TEST", "SAMPLE", "STATISTIC", "P VALUE", "PASSED")
+		for _, t := range sr.Report.Tests {
+			fmt.Printf("%-12s %10d %14.4f %10.6f  %v\n", t.Name, t.Sample, t.Statistic, t.PValue, t.Passed)
+		}
+	}
+
+	if !sr.Report.Passed {
+		os.Exit(2)
+	}
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("rng_report.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
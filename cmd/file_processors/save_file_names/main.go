@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"net/http"
 
 	"fmt"
 	"log"
@@ -11,8 +12,9 @@ import (
 	"syscall"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/lukemakhanu/magic_carpet/internal/services/saveFile"
+	"github.com/lukemakhanu/magic_carpet/internal/services/ingestion"
 	"github.com/spf13/viper"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -23,65 +25,50 @@ var addConfigPathLocal = "/apps/go/magic_carpet/cmd/file_processors/save_file_na
 func main() {
 	InitConfig()
 
-	// Live Score
-	liveScoreService, err := saveFile.NewSaveFileService(
-		saveFile.WithMysqlLiveScoreFilesRepository(viper.GetString("mySQL.live")),
-		saveFile.WithDirectoryReaderRepository(viper.GetString("ke.ls_directory"), viper.GetString("save_file_names.combination")),
-	)
+	var countries []ingestion.CountryFeed
+	err := viper.UnmarshalKey("countries", &countries)
 	if err != nil {
-		log.Printf("Unable to start save data service (live scores) : %s", err)
+		log.Fatalf("Err : %v unable to read countries", err)
 	}
 
-	// Winning Outcome
-
-	winningOutcome, err := saveFile.NewSaveFileService(
-		saveFile.WithMysqlWoFilesRepository(viper.GetString("mySQL.live")),
-		saveFile.WithDirectoryReaderRepository(viper.GetString("ke.wo_directory"), viper.GetString("save_file_names.combination")),
-	)
-	if err != nil {
-		log.Printf("Unable to start save file data service (winning outcomes) :: %s", err)
+	cfgs := []ingestion.IngestionConfiguration{
+		ingestion.WithMysqlRepository(viper.GetString("mySQL.live")),
+		ingestion.WithFileNameValidation(viper.GetString("save_file_names.combination"),
+			viper.GetString("save_file_names.quarantine_directory"),
+			viper.GetString("save_file_names.project_ids"), viper.GetString("save_file_names.competition_ids")),
+		ingestion.WithMode(viper.GetString("save_file_names.mode"), viper.GetDuration("save_file_names.poll_interval"),
+			viper.GetDuration("save_file_names.max_backoff")),
+		ingestion.WithCountries(countries),
 	}
 
-	// Odds
+	// The provider drops the feed files in a bucket, several hosts can ingest it.
+	if viper.GetString("blob_storage.driver") == "s3" {
+		cfgs = append(cfgs, ingestion.WithS3BlobRepository(viper.GetString("blob_storage.endpoint"),
+			viper.GetString("blob_storage.region"), viper.GetString("blob_storage.bucket"),
+			viper.GetString("blob_storage.access_key"), viper.GetString("blob_storage.secret_key"),
+			viper.GetBool("blob_storage.use_ssl")))
+	}
 
-	odds, err := saveFile.NewSaveFileService(
-		saveFile.WithMysqlOddsFilesRepository(viper.GetString("mySQL.live")),
-		saveFile.WithDirectoryReaderRepository(viper.GetString("ke.odds_directory"), viper.GetString("save_file_names.combination")),
-	)
+	is, err := ingestion.NewIngestionService(cfgs...)
 	if err != nil {
-		log.Printf("Unable to start file data service (Odds) : %s", err)
+		log.Fatalf("Unable to start ingestion service : %s", err)
 	}
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	log.Printf("** About to start APP ***")
 
-	// 1. Kenya
-
-	go func() {
-		err := liveScoreService.SaveLiveScoreFiles(ctx, viper.GetString("ke.ls_directory"), viper.GetString("ke.country"))
-		if err != nil {
-			log.Printf("Err : %v unable to save ls files", err)
-		}
-	}()
+	is.Start(ctx)
 
-	go func() {
-		err := winningOutcome.SaveWinningOutcomesFiles(ctx, viper.GetString("ke.wo_directory"), viper.GetString("ke.country"))
-		if err != nil {
-			log.Printf("Err : %v unable to save wo files", err)
-		}
-	}()
-
-	go func() {
-		err := odds.SaveFiles(ctx, viper.GetString("ke.odds_directory"), viper.GetString("ke.country"))
-		if err != nil {
-			log.Printf("Err : %v unable to save odds files...", err)
-		}
-	}()
-
-	// 2. Tanzania
+	interval := viper.GetDuration("save_file_names.counters_interval")
+	if interval > 0 {
+		go is.LogCounters(ctx, interval)
+	}
 
-	// 3. Ghana
+	if port := viper.GetInt("save_file_names.counters_port"); port > 0 {
+		go ServeCounters(port, is)
+	}
 
 	sig := make(chan os.Signal, 1)
 	defer close(sig)
@@ -92,6 +79,22 @@ func main() {
 	fmt.Println("caught signal and exiting:::", s)
 }
 
+// ServeCounters : GET /counters returns the ingestion counters of every country.
+func ServeCounters(port int, is *ingestion.IngestionService) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	router.GET("/counters", func(c *gin.Context) {
+		c.JSON(http.StatusOK, is.Counters())
+	})
+
+	err := router.Run(fmt.Sprintf(":%d", port))
+	if err != nil {
+		log.Printf("Err : %v counters endpoint stopped", err)
+	}
+}
+
 func InitConfig() {
 	configUtils(addConfigPathLive, addConfigPathLocal)
 	logUtils(viper.GetString("save_file_names.logs"), viper.GetInt("log_setting.MaxSize"),
@@ -32,13 +32,35 @@ func main() {
 	liveScoreSortedSet := viper.GetString("redis-sorted-set.liveScore")
 	oddsSortedSet := viper.GetString("redis-sorted-set.odds")
 
-	pg, err := saveFileRedis.NewFileProcessorService(
+	cfgs := []saveFileRedis.FileProcessorConfiguration{
 		saveFileRedis.WithMysqlLiveScoreRepository(viper.GetString("mySQL.live")),
 		saveFileRedis.WithMysqlWinningOutcomesRepository(viper.GetString("mySQL.live")),
 		saveFileRedis.WithMysqlRawOddsRepository(viper.GetString("mySQL.live")),
+		saveFileRedis.WithMysqlFileRevisionsRepository(viper.GetString("mySQL.live")),
+		saveFileRedis.WithMysqlSeasonWeeksRepository(viper.GetString("mySQL.live")),
 		saveFileRedis.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// Matches a revision changes are categorised again by the goals daemon.
+	if viper.GetString("redis-sorted-set.sanitized") != "" {
+		cfgs = append(cfgs, saveFileRedis.WithSanitizedSets(oddsSortedSet, viper.GetString("redis-sorted-set.sanitized")))
+	}
+
+	// Season weeks taken back are drawn again from a new seed.
+	if viper.GetBool("seed_commitments.enabled") {
+		cfgs = append(cfgs, saveFileRedis.WithMysqlSeedCommitmentsRepository(viper.GetString("mySQL.live")))
+	}
+
+	// Feed files dropped in a bucket instead of the local disk.
+	if viper.GetString("blob_storage.driver") == "s3" {
+		cfgs = append(cfgs, saveFileRedis.WithS3BlobRepository(viper.GetString("blob_storage.endpoint"),
+			viper.GetString("blob_storage.region"), viper.GetString("blob_storage.bucket"),
+			viper.GetString("blob_storage.access_key"), viper.GetString("blob_storage.secret_key"),
+			viper.GetBool("blob_storage.use_ssl")))
+	}
+
+	pg, err := saveFileRedis.NewFileProcessorService(cfgs...)
 	if err != nil {
 		log.Printf("Unable to start save file service ::: %s", err)
 	}
@@ -58,7 +80,7 @@ func main() {
 			case t := <-ticker.C:
 				if !inProgress {
 					inProgress = true
-					SaveWinningOutcomes(ctx, matchChan, pg)
+					SaveWinningOutcomes(ctx, matchChan, pg, oddsSortedSet, liveScoreSortedSet)
 				} else {
 					log.Printf("in process.. %v.\n", t)
 				}
@@ -117,7 +139,7 @@ func main() {
 	fmt.Println("caught signal and exiting:::", s)
 }
 
-func SaveWinningOutcomes(ctx context.Context, matchChan chan saveFileRedis.Job, sm *saveFileRedis.FileProcessorService) {
+func SaveWinningOutcomes(ctx context.Context, matchChan chan saveFileRedis.Job, sm *saveFileRedis.FileProcessorService, oddsSortedSet, liveScoreSortedSet string) {
 
 	defer func() {
 		inProgress = false
@@ -149,6 +171,45 @@ func SaveWinningOutcomes(ctx context.Context, matchChan chan saveFileRedis.Job,
 
 	}
 
+	// Files re-delivered with a new content. A changed wo file is already back to
+	// pending above, odds and live scores overwrite their keys here. A match whose key
+	// changes is dropped from the sanitized sets and categorised again, season weeks
+	// published from it that have not started are taken back and built again. A revision
+	// is only marked processed once its keys are saved, a failed one is retried.
+	revisions, err := sm.PendingRevisions(ctx, status)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	}
+
+	for _, r := range revisions {
+
+		log.Printf("Revision %d of %s file %s [%s]", r.Revision, r.FileType, r.ExtID, r.Country)
+
+		if r.FileType == "odds" {
+
+			err := sm.ReturnOdds(ctx, oddsSortedSet, r.ExtID, "", r.Country, matchChan)
+			if err != nil {
+				log.Printf("Err : %v failed to save revision %s", err, r.FileRevisionID)
+				continue
+			}
+
+		} else if r.FileType == "ls" {
+
+			err := sm.ReturnRawLs(ctx, liveScoreSortedSet, r.ExtID, r.Country, matchChan)
+			if err != nil {
+				log.Printf("Err : %v failed to save revision %s", err, r.FileRevisionID)
+				continue
+			}
+
+		}
+
+		_, err := sm.UpdateRevisionStatus(ctx, "processed", r.FileRevisionID)
+		if err != nil {
+			log.Printf("Err : %v", err)
+		}
+
+	}
+
 }
 
 func InitConfig() {
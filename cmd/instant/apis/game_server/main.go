@@ -14,6 +14,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/services/instantGameServer"
 	instantRedisServer "github.com/lukemakhanu/magic_carpet/internal/services/instantRedis"
 
@@ -56,7 +57,7 @@ func main() {
 		log.Printf("err : %v on loading all matches", err)
 	}
 
-	ms, err := instantGameServer.NewInstantGameServerService(
+	cfgs := []instantGameServer.InstantGameServerConfiguration{
 		instantGameServer.WithSharedHttpConfRepository(),
 		instantGameServer.WithMysqlPlayersRepository(mysqlLive),
 		instantGameServer.WithMysqlMatchesRequestsRepository(mysqlLive),
@@ -64,11 +65,30 @@ func main() {
 		instantGameServer.WithAvailableMatches(availableMatches),
 		instantGameServer.WithRedisResultsRepository(redisLive, viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// Matches are only shown to a player again as the reuse policy allows.
+	if viper.GetBool("reuse_policy.enabled") {
+		cfgs = append(cfgs, instantGameServer.WithMysqlReusePoliciesRepository(mysqlLive,
+			viper.GetInt("reuse_policy.global_days"), viper.GetInt("reuse_policy.player_days"),
+			viper.GetBool("reuse_policy.same_season"), viper.GetBool("reuse_policy.same_season_week"),
+			viper.GetInt("reuse_policy.max_blocked")))
+	}
+
+	ms, err := instantGameServer.NewInstantGameServerService(cfgs...)
 	if err != nil {
 		panic(err)
 	}
 
+	// Keys of rounds whose request never finished go back to the players claim sets.
+	reaper := time.NewTicker(processRedis.ReapInterval)
+	defer reaper.Stop()
+	go func() {
+		for range reaper.C {
+			ms.ReapLeases(ctx)
+		}
+	}()
+
 	// Start Api here
 	Run(viper.GetInt("instant_game_server.port"), ms)
 
@@ -13,6 +13,9 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/lukemakhanu/magic_carpet/internal/services/oddsProfile"
 	"github.com/lukemakhanu/magic_carpet/internal/services/productionInstantKey"
 	"github.com/spf13/viper"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -30,13 +33,78 @@ func main() {
 	liveScoreSortedSet := viper.GetString("redis-sorted-set.liveScore")
 	oddsSortedSet := viper.GetString("redis-sorted-set.odds")
 
-	pg, err := productionInstantKey.NewProcessInstantKeyService(
+	cfgs := []productionInstantKey.ProcessInstantKeyConfiguration{
 		productionInstantKey.WithMysqlMatchesRepository(viper.GetString("mySQL.live")),
 		productionInstantKey.WithMysqlSeasonWeeksRepository(viper.GetString("mySQL.live")),
 		productionInstantKey.WithMysqlCheckMatchesRepository(viper.GetString("mySQL.live")),
 		productionInstantKey.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
 			viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
-	)
+	}
+
+	// Without margins oddsFactor is taken off every price.
+	var mm []margins.MarketMargin
+	err := viper.UnmarshalKey("margins", &mm)
+	if err != nil {
+		log.Printf("Err : %v unable to read margins", err)
+	}
+	if len(mm) > 0 {
+		cfgs = append(cfgs, productionInstantKey.WithMarginEngine(mm))
+	}
+
+	// Provider winning outcomes are settled again before a season week is published.
+	if viper.GetBool("settlement.check") {
+		cfgs = append(cfgs, productionInstantKey.WithSettlementEngine())
+	}
+
+	// Odds are also saved priced for every client with an odds profile.
+	if viper.GetBool("odds_profiles.enabled") {
+		op, err := oddsProfile.NewOddsProfileService(
+			oddsProfile.WithMysqlOddsProfilesRepository(viper.GetString("mySQL.live")),
+			oddsProfile.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+				viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+		)
+		if err != nil {
+			log.Printf("Err : %v unable to start odds profiles", err)
+		} else {
+			cfgs = append(cfgs, productionInstantKey.WithOddsProfileService(op))
+		}
+	}
+
+	// Markets priced from the correct scores, offered when the catalogue lists them.
+	if viper.GetBool("derived_markets.enabled") {
+		cfgs = append(cfgs, productionInstantKey.WithDerivedMarkets(viper.GetStringSlice("derived_markets.codes")))
+	}
+
+	// Markets, their names and order come from the catalogue when it is enabled.
+	if viper.GetBool("market_catalogue.enabled") {
+		mc, err := marketCatalogue.NewMarketCatalogueService(
+			marketCatalogue.WithMysqlMarketCataloguesRepository(viper.GetString("mySQL.live")),
+			marketCatalogue.WithRedisRepository(viper.GetString("redis.live"), viper.GetInt("redis.dbNum"),
+				viper.GetInt("redis.maxIdle"), viper.GetInt("redis.maxActive"), viper.GetDuration("redis.duration")),
+			marketCatalogue.WithCache(viper.GetString("market_catalogue.cache_key"), viper.GetDuration("market_catalogue.cache_ttl")),
+		)
+		if err != nil {
+			log.Printf("Err : %v unable to start market catalogue", err)
+		} else {
+			cfgs = append(cfgs, productionInstantKey.WithMysqlLeaguesRepository(viper.GetString("mySQL.live")),
+				productionInstantKey.WithMarketCatalogueService(mc, viper.GetString("market_catalogue.locale")))
+		}
+	}
+
+	// The seed matches are drawn from is committed to and revealed after the season week.
+	if viper.GetBool("seed_commitments.enabled") {
+		cfgs = append(cfgs, productionInstantKey.WithMysqlSeedCommitmentsRepository(viper.GetString("mySQL.live")))
+	}
+
+	// Matches are only shown again as the reuse policy allows.
+	if viper.GetBool("reuse_policy.enabled") {
+		cfgs = append(cfgs, productionInstantKey.WithMysqlReusePoliciesRepository(viper.GetString("mySQL.live"),
+			viper.GetInt("reuse_policy.global_days"), viper.GetInt("reuse_policy.player_days"),
+			viper.GetBool("reuse_policy.same_season"), viper.GetBool("reuse_policy.same_season_week"),
+			viper.GetInt("reuse_policy.max_blocked")))
+	}
+
+	pg, err := productionInstantKey.NewProcessInstantKeyService(cfgs...)
 	if err != nil {
 		log.Printf(" * Unable to start production instant keys service * : %s", err)
 	}
@@ -0,0 +1,126 @@
+// Package main converts the World Cup tournament xml feed into odds, winning
+// outcome and live score files picked up by save_file_names.
+package main
+
+import (
+	"context"
+	"time"
+
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/services/wcFeed"
+	"github.com/spf13/viper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var addConfigPathLive = "/apps/go/magic_carpet/cmd/wc/wc_feed/"
+var addConfigPathLocal = "/apps/go/magic_carpet/cmd/wc/wc_feed/"
+
+var inProgress bool
+
+func main() {
+	InitConfig()
+
+	wf, err := wcFeed.NewWcFeedService(
+		wcFeed.WithXmlFeedRepository(viper.GetString("wc.feed_file"), viper.GetString("wc.project_id"),
+			viper.GetString("wc.competition_id")),
+	)
+	if err != nil {
+		log.Fatalf(" **** Unable to start world cup feed service **** : %s", err)
+	}
+
+	ctx := context.Background()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	go func() {
+		for {
+			select {
+			case t := <-ticker.C:
+				if !inProgress {
+					inProgress = true
+
+					WcFeed(ctx, wf)
+
+				} else {
+					log.Printf("**** WcFeed in process **** %v.\n", t)
+				}
+			}
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	defer close(sig)
+	signal.Notify(sig, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
+
+	s := <-sig
+
+	fmt.Println("caught signal and exiting", s)
+}
+
+// WcFeed : used to save world cup odds, winning outcome and live score files
+func WcFeed(ctx context.Context, wf *wcFeed.WcFeedService) {
+
+	defer func() {
+		inProgress = false
+		log.Printf("** done calling wcFeed ** ")
+	}()
+
+	saved, err := wf.SaveOddsFiles(ctx, viper.GetString("wc.odds_directory"))
+	if err != nil {
+		log.Printf("Err : %v failed to save world cup odds files", err)
+	}
+	log.Printf("World cup odds files saved : %d", saved)
+
+	saved, err = wf.SaveWinningOutcomeFiles(ctx, viper.GetString("wc.wo_directory"))
+	if err != nil {
+		log.Printf("Err : %v failed to save world cup winning outcome files", err)
+	}
+	log.Printf("World cup winning outcome files saved : %d", saved)
+
+	saved, err = wf.SaveLiveScoreFiles(ctx, viper.GetString("wc.ls_directory"))
+	if err != nil {
+		log.Printf("Err : %v failed to save world cup live score files", err)
+	}
+	log.Printf("World cup live score files saved : %d", saved)
+}
+
+func InitConfig() {
+	configUtils(addConfigPathLive, addConfigPathLocal)
+	logUtils(viper.GetString("wc.logs"), viper.GetInt("log_setting.MaxSize"),
+		viper.GetInt("log_setting.MaxBackups"), viper.GetInt("log_setting.MaxAge"),
+		viper.GetBool("log_setting.Compress"))
+}
+
+func logUtils(logDirectory string, maxSize int, maxBackups int, maxAge int, compress bool) {
+	log.SetOutput(&lumberjack.Logger{
+		Filename:   logDirectory,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+	})
+}
+
+func configUtils(addConfigPathLive string, addConfigPathLocal string) {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	viper.SetDefault("host", "localhost")
+	viper.SetConfigName("config")
+	viper.AddConfigPath(addConfigPathLive)
+	viper.AddConfigPath(addConfigPathLocal)
+	viper.AddConfigPath(".")
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.Printf("Error : %v", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s", e.Name)
+	})
+}
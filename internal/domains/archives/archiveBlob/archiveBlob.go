@@ -0,0 +1,108 @@
+package archiveBlob
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/archives"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+)
+
+var _ archives.ArchivesRepository = (*ArchiveBlobConfigs)(nil)
+
+// ArchiveBlobConfigs : files are kept in the blob store the feed is read from, as
+// <archiveDir>/<yyyy-mm-dd>/<country>/<file type>/<file name>[.gz]
+type ArchiveBlobConfigs struct {
+	blobs      blobs.BlobsRepository
+	archiveDir string
+	compress   bool
+}
+
+// New initializes a new archive in a blob store.
+func New(b blobs.BlobsRepository, archiveDir string, compress bool) (*ArchiveBlobConfigs, error) {
+
+	if b == nil {
+		return nil, fmt.Errorf("blob store not set")
+	}
+
+	if archiveDir == "" {
+		return nil, fmt.Errorf("archiveDir not set")
+	}
+
+	c := &ArchiveBlobConfigs{
+		blobs:      b,
+		archiveDir: archiveDir,
+		compress:   compress,
+	}
+
+	return c, nil
+}
+
+// Target : archive directory and file name for a file.
+func (s *ArchiveBlobConfigs) Target(day, country, fileType, fileName string) (string, string) {
+	dir := path.Join(s.archiveDir, day, country, fileType)
+	if s.compress {
+		return dir, fileName + ".gz"
+	}
+	return dir, fileName
+}
+
+// Archive : a stored object only becomes visible once complete, the copy is put in place directly.
+func (s *ArchiveBlobConfigs) Archive(ctx context.Context, day, country, fileType, directory, fileName string) (string, string, error) {
+
+	dir, name := s.Target(day, country, fileType, fileName)
+
+	src, err := s.blobs.Open(ctx, directory, fileName)
+	if err != nil {
+		return "", "", err
+	}
+	defer src.Close()
+
+	data, err := s.copy(src)
+	if err != nil {
+		return "", "", fmt.Errorf("Err : %v failed to archive %s/%s", err, directory, fileName)
+	}
+
+	err = s.blobs.Put(ctx, dir, name, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return dir, name, nil
+}
+
+// Remove : deletes a file.
+func (s *ArchiveBlobConfigs) Remove(ctx context.Context, directory, fileName string) error {
+	err := s.blobs.Remove(ctx, directory, fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ArchiveBlobConfigs) copy(src io.Reader) ([]byte, error) {
+
+	if !s.compress {
+		return io.ReadAll(src)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := io.Copy(zw, src)
+	if err != nil {
+		return nil, err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
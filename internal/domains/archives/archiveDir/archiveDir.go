@@ -0,0 +1,117 @@
+package archiveDir
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/archives"
+)
+
+var _ archives.ArchivesRepository = (*ArchiveDirConfigs)(nil)
+
+// ArchiveDirConfigs : files are kept as <archiveDir>/<yyyy-mm-dd>/<country>/<file type>/<file name>[.gz]
+type ArchiveDirConfigs struct {
+	archiveDir string
+	compress   bool
+}
+
+// New initializes a new archive directory.
+func New(archiveDir string, compress bool) (*ArchiveDirConfigs, error) {
+
+	if archiveDir == "" {
+		return nil, fmt.Errorf("archiveDir not set")
+	}
+
+	c := &ArchiveDirConfigs{
+		archiveDir: archiveDir,
+		compress:   compress,
+	}
+
+	return c, nil
+}
+
+// Target : archive directory and file name for a file.
+func (s *ArchiveDirConfigs) Target(day, country, fileType, fileName string) (string, string) {
+	dir := filepath.Join(s.archiveDir, day, country, fileType)
+	if s.compress {
+		return dir, fileName + ".gz"
+	}
+	return dir, fileName
+}
+
+// Archive : writes the copy under a hidden name first then renames it into place.
+func (s *ArchiveDirConfigs) Archive(ctx context.Context, day, country, fileType, directory, fileName string) (string, string, error) {
+
+	dir, name := s.Target(day, country, fileType, fileName)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", "", fmt.Errorf("Err : %v failed to create %s", err, dir)
+	}
+
+	src, err := os.Open(filepath.Join(directory, fileName))
+	if err != nil {
+		return "", "", fmt.Errorf("Err : %v failed to open %s/%s", err, directory, fileName)
+	}
+	defer src.Close()
+
+	tmpPath := filepath.Join(dir, "."+name)
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return "", "", fmt.Errorf("Err : %v failed to create %s", err, tmpPath)
+	}
+
+	err = s.copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", "", fmt.Errorf("Err : %v failed to archive %s/%s", err, directory, fileName)
+	}
+
+	err = os.Rename(tmpPath, filepath.Join(dir, name))
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", "", fmt.Errorf("Err : %v failed to rename %s", err, tmpPath)
+	}
+
+	return dir, name, nil
+}
+
+// Remove : deletes a file.
+func (s *ArchiveDirConfigs) Remove(ctx context.Context, directory, fileName string) error {
+	err := os.Remove(filepath.Join(directory, fileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Err : %v failed to remove %s/%s", err, directory, fileName)
+	}
+	return nil
+}
+
+func (s *ArchiveDirConfigs) copy(dst *os.File, src io.Reader) error {
+
+	if !s.compress {
+		_, err := io.Copy(dst, src)
+		if err != nil {
+			return err
+		}
+		return dst.Sync()
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err := io.Copy(zw, src)
+	if err != nil {
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	return dst.Sync()
+}
@@ -0,0 +1,14 @@
+package archives
+
+import "context"
+
+// ArchivesRepository : moves processed feed files out of the live directories.
+type ArchivesRepository interface {
+	// Target : where Archive would put the file, nothing is touched.
+	Target(day, country, fileType, fileName string) (string, string)
+	// Archive : copies the file to its target and returns the new directory and name.
+	// The source is left in place, Remove it once the new location is recorded.
+	Archive(ctx context.Context, day, country, fileType, directory, fileName string) (string, string, error)
+	// Remove : deletes a file, a missing file is not an error.
+	Remove(ctx context.Context, directory, fileName string) error
+}
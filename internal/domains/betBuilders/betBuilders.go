@@ -0,0 +1,47 @@
+package betBuilders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewSelections : legs of a combination, every outcome appears once.
+func NewSelections(ss []Selection, maxLegs int) ([]Selection, error) {
+
+	if len(ss) < 2 {
+		return nil, fmt.Errorf("a combination needs at least 2 selections")
+	}
+
+	if maxLegs > 0 && len(ss) > maxLegs {
+		return nil, fmt.Errorf("a combination has at most %d selections", maxLegs)
+	}
+
+	seen := make(map[string]bool)
+	legs := []Selection{}
+
+	for _, s := range ss {
+
+		s.Code = strings.ToUpper(strings.TrimSpace(s.Code))
+		s.OutcomeID = strings.TrimSpace(s.OutcomeID)
+
+		if s.Code == "" {
+			return nil, fmt.Errorf("code not set")
+		}
+
+		if s.OutcomeID == "" {
+			return nil, fmt.Errorf("outcome of %s not set", s.Code)
+		}
+
+		// Outcomes of one market on different lines can be combined, selections that
+		// can not all win are rejected when priced.
+		key := s.Code + "|" + strings.ToUpper(s.OutcomeID)
+		if seen[key] {
+			return nil, fmt.Errorf("outcome %s of %s selected twice", s.OutcomeID, s.Code)
+		}
+		seen[key] = true
+
+		legs = append(legs, s)
+	}
+
+	return legs, nil
+}
@@ -0,0 +1,13 @@
+package betBuilders
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+)
+
+// BetBuildersRepository : prices and settles combinations of outcomes of one match.
+type BetBuildersRepository interface {
+	Price(ctx context.Context, matchID string, mkts []oddsFiles.FinalMarkets, ss []Selection) (Quote, error)
+	Settle(ctx context.Context, matchID string, ss []Selection, fs oddsFiles.FinalScores, lsc []oddsFiles.FinalLiveScores) (Settlement, error)
+}
@@ -0,0 +1,429 @@
+package scoreMatrix
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/betBuilders"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements/settlementEngine"
+)
+
+var _ betBuilders.BetBuildersRepository = (*ScoreMatrixConfigs)(nil)
+
+// unsupported : markets settled on the goal timeline, the score matrix does not know
+// when goals were scored.
+var unsupported = map[string]bool{
+	"FTS": true,
+	"TFG": true,
+}
+
+// fitted : markets whose prices the matrix is fitted to, full time scores, half time
+// scores and half time / full time results.
+var fitted = []string{"CS", "HS", "DR"}
+
+// fitRounds : rounds of iterative proportional fitting, the marginals agree to well
+// under a tenth of a percent long before.
+const fitRounds = 50
+
+// minProbability : a combination less likely than this is taken as contradictory.
+const minProbability = 1e-6
+
+// maxHalfGoals : goals a team may score in the second half.
+const maxHalfGoals = 10
+
+// ScoreMatrixConfigs : bet builder margin and limits.
+type ScoreMatrixConfigs struct {
+	overround float64
+	maxLegs   int
+	maxOdd    float64
+	engine    settlements.SettlementsRepository
+}
+
+// state : half time and full time score of the match.
+type state struct {
+	match settlements.Match
+	p     float64
+}
+
+// New initializes the bet builder. overround is the margin of a combination, maxLegs
+// and maxOdd are not enforced when 0.
+func New(overround float64, maxLegs int, maxOdd float64) (*ScoreMatrixConfigs, error) {
+
+	if overround < 1 || overround > 2 {
+		return nil, fmt.Errorf("overround %.4f must be between 1 and 2", overround)
+	}
+
+	if maxLegs < 0 {
+		return nil, fmt.Errorf("maxLegs must not be negative")
+	}
+
+	if maxOdd != 0 && maxOdd <= 1.01 {
+		return nil, fmt.Errorf("maxOdd %.2f must be above 1.01", maxOdd)
+	}
+
+	engine, err := settlementEngine.New()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScoreMatrixConfigs{
+		overround: overround,
+		maxLegs:   maxLegs,
+		maxOdd:    maxOdd,
+		engine:    engine,
+	}, nil
+}
+
+// Price : chance that every selection wins, read from the joint half time / full time
+// score distribution of the match, so correlated legs are priced together rather than
+// multiplied. Selections that can not all win are rejected.
+func (s *ScoreMatrixConfigs) Price(ctx context.Context, matchID string, mkts []oddsFiles.FinalMarkets, ss []betBuilders.Selection) (betBuilders.Quote, error) {
+
+	q := betBuilders.Quote{MatchID: matchID, Overround: s.overround}
+
+	legs, err := betBuilders.NewSelections(ss, s.maxLegs)
+	if err != nil {
+		return q, err
+	}
+	q.Selections = legs
+
+	offered := make(map[string]oddsFiles.FinalMarkets)
+	for _, m := range mkts {
+		offered[m.Code] = m
+	}
+
+	for n, l := range legs {
+
+		if unsupported[l.Code] {
+			return q, fmt.Errorf("market %s can not be combined", l.Code)
+		}
+
+		m, ok := offered[l.Code]
+		if !ok {
+			return q, fmt.Errorf("market %s not offered on match %s", l.Code, matchID)
+		}
+
+		found := false
+		for _, o := range m.FinalOutcomes {
+			if strings.EqualFold(o.OutcomeID, l.OutcomeID) {
+				legs[n].OutcomeID = o.OutcomeID
+				found = true
+			}
+		}
+		if !found {
+			return q, fmt.Errorf("outcome %s of %s not offered on match %s", l.OutcomeID, l.Code, matchID)
+		}
+	}
+
+	states, err := s.matrix(offered)
+	if err != nil {
+		return q, err
+	}
+
+	p := 0.0
+	for _, st := range states {
+
+		all := true
+		for _, l := range legs {
+			won, known := s.engine.Wins(st.match, l.Code, l.OutcomeID)
+			if !known {
+				return q, fmt.Errorf("outcome %s of %s can not be priced", l.OutcomeID, l.Code)
+			}
+			if !won {
+				all = false
+				break
+			}
+		}
+
+		if all {
+			p += st.p
+		}
+	}
+
+	if p < minProbability {
+		return q, fmt.Errorf("selections contradict each other")
+	}
+
+	q.Probability = math.Round(p*1e6) / 1e6
+	q.FairOdd = floor2(1 / p)
+	q.Odd = math.Max(1.01, floor2(1/(p*s.overround)))
+	if s.maxOdd > 0 {
+		q.Odd = math.Min(q.Odd, s.maxOdd)
+	}
+
+	return q, nil
+}
+
+// Settle : a leg wins when the match winning outcomes list it. Markets without winning
+// outcomes are settled from the final score and the live scores, a leg neither can
+// settle is void.
+func (s *ScoreMatrixConfigs) Settle(ctx context.Context, matchID string, ss []betBuilders.Selection, fs oddsFiles.FinalScores, lsc []oddsFiles.FinalLiveScores) (betBuilders.Settlement, error) {
+
+	st := betBuilders.Settlement{MatchID: matchID}
+
+	legs, err := betBuilders.NewSelections(ss, 0)
+	if err != nil {
+		return st, err
+	}
+
+	winners := make(map[string]map[string]bool)
+	for _, w := range fs.FinalWinningOutcomes {
+		if winners[w.SubTypeID] == nil {
+			winners[w.SubTypeID] = make(map[string]bool)
+		}
+		winners[w.SubTypeID][strings.ToUpper(w.OutcomeID)] = true
+	}
+
+	m, err := settlements.NewMatch(fs.HomeScore, fs.AwayScore, lsc)
+	if err != nil {
+		m = nil
+	}
+
+	st.Result = betBuilders.Won
+	for _, l := range legs {
+
+		leg := betBuilders.Leg{Selection: l, Result: betBuilders.Void}
+
+		if ww, ok := winners[l.Code]; ok {
+			leg.Result = betBuilders.Lost
+			if ww[strings.ToUpper(l.OutcomeID)] {
+				leg.Result = betBuilders.Won
+			}
+		} else if m != nil {
+			if won, known := s.engine.Wins(*m, l.Code, l.OutcomeID); known {
+				leg.Result = betBuilders.Lost
+				if won {
+					leg.Result = betBuilders.Won
+				}
+			}
+		}
+
+		switch {
+		case leg.Result == betBuilders.Lost:
+			st.Result = betBuilders.Lost
+		case leg.Result == betBuilders.Void && st.Result == betBuilders.Won:
+			st.Result = betBuilders.Void
+		}
+
+		st.Legs = append(st.Legs, leg)
+	}
+
+	return st, nil
+}
+
+// matrix : joint distribution of the half time and full time scores. The second half of
+// every half time score is spread as two Poisson, one per team, then the whole matrix is
+// fitted to the correct score, half time score and half time / full time prices.
+func (s *ScoreMatrixConfigs) matrix(offered map[string]oddsFiles.FinalMarkets) ([]state, error) {
+
+	ft := scoreGrid(offered["CS"].FinalOutcomes)
+	if len(ft) == 0 {
+		return nil, fmt.Errorf("match has no correct scores to price combinations from")
+	}
+
+	ftHome, ftAway := means(ft)
+
+	ht := scoreGrid(offered["HS"].FinalOutcomes)
+	if len(ht) == 0 {
+		// Without half time scores, a little under half the goals come before the break.
+		ht = poissonGrid(ftHome*0.45, ftAway*0.45)
+	}
+
+	htHome, htAway := means(ht)
+	lambdaHome := math.Max(ftHome-htHome, 0.05)
+	lambdaAway := math.Max(ftAway-htAway, 0.05)
+
+	states := []state{}
+	for h, ph := range ht {
+		for f := range ft {
+
+			home, away := f[0]-h[0], f[1]-h[1]
+			if home < 0 || away < 0 || home > maxHalfGoals || away > maxHalfGoals {
+				continue
+			}
+
+			states = append(states, state{
+				match: settlements.Match{
+					HomeScore:     f[0],
+					AwayScore:     f[1],
+					Timeline:      true,
+					HalfHomeScore: h[0],
+					HalfAwayScore: h[1],
+				},
+				p: ph * poisson(lambdaHome, home) * poisson(lambdaAway, away),
+			})
+		}
+	}
+
+	if len(states) == 0 {
+		return nil, fmt.Errorf("half time and correct scores do not agree")
+	}
+
+	// States won by each fitted outcome, and the fair chance of the outcome.
+	type constraint struct {
+		members []int
+		target  float64
+	}
+
+	constraints := []constraint{}
+	for _, code := range fitted {
+
+		m, ok := offered[code]
+		if !ok {
+			continue
+		}
+
+		cc := []constraint{}
+		total := 0.0
+		for _, o := range m.FinalOutcomes {
+			if o.OddValue <= 1 {
+				continue
+			}
+
+			// An outcome that can not be read would leave the matrix unfitted to its
+			// market and misprice every combination, so the match is not priced.
+			c := constraint{target: 1 / o.OddValue}
+			for i, st := range states {
+				won, known := s.engine.Wins(st.match, code, o.OutcomeID)
+				if !known {
+					return nil, fmt.Errorf("outcome %s of %s can not be read, the matrix can not be fitted", o.OutcomeID, code)
+				}
+				if won {
+					c.members = append(c.members, i)
+				}
+			}
+
+			if len(c.members) > 0 {
+				cc = append(cc, c)
+				total += c.target
+			}
+		}
+
+		for _, c := range cc {
+			c.target = c.target / total
+			constraints = append(constraints, c)
+		}
+	}
+
+	for r := 0; r < fitRounds; r++ {
+		for _, c := range constraints {
+			current := 0.0
+			for _, i := range c.members {
+				current += states[i].p
+			}
+			if current <= 0 {
+				continue
+			}
+			for _, i := range c.members {
+				states[i].p *= c.target / current
+			}
+		}
+	}
+
+	total := 0.0
+	for _, st := range states {
+		total += st.p
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("score matrix is empty")
+	}
+	for i := range states {
+		states[i].p /= total
+	}
+
+	return states, nil
+}
+
+// scoreGrid : score prices to probabilities adding up to 1, any other score is left out.
+func scoreGrid(outcomes []oddsFiles.FinalOutcomes) map[[2]int]float64 {
+
+	g := make(map[[2]int]float64)
+	total := 0.0
+
+	for _, o := range outcomes {
+		h, a, ok := correctScore(o.OutcomeID)
+		if !ok {
+			h, a, ok = correctScore(o.OutcomeName)
+		}
+		if !ok || o.OddValue <= 1 {
+			continue
+		}
+
+		g[[2]int{h, a}] += 1 / o.OddValue
+		total += 1 / o.OddValue
+	}
+
+	for x, q := range g {
+		g[x] = q / total
+	}
+
+	return g
+}
+
+func poissonGrid(lambdaHome, lambdaAway float64) map[[2]int]float64 {
+	g := make(map[[2]int]float64)
+	total := 0.0
+	for h := 0; h <= maxHalfGoals; h++ {
+		for a := 0; a <= maxHalfGoals; a++ {
+			p := poisson(lambdaHome, h) * poisson(lambdaAway, a)
+			g[[2]int{h, a}] = p
+			total += p
+		}
+	}
+	for x, q := range g {
+		g[x] = q / total
+	}
+	return g
+}
+
+func means(g map[[2]int]float64) (float64, float64) {
+	home, away := 0.0, 0.0
+	for x, q := range g {
+		home += float64(x[0]) * q
+		away += float64(x[1]) * q
+	}
+	return home, away
+}
+
+func poisson(lambda float64, k int) float64 {
+	p := math.Exp(-lambda)
+	for i := 1; i <= k; i++ {
+		p *= lambda / float64(i)
+	}
+	return p
+}
+
+func floor2(v float64) float64 {
+	return math.Floor(v*100+1e-9) / 100
+}
+
+// correctScore : reads 2-1 or 2:1
+func correctScore(outcome string) (int, int, bool) {
+	outcome = strings.TrimSpace(outcome)
+	sep := "-"
+	if strings.Contains(outcome, ":") {
+		sep = ":"
+	}
+
+	parts := strings.Split(outcome, sep)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	h, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || h < 0 {
+		return 0, 0, false
+	}
+
+	a, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || a < 0 {
+		return 0, 0, false
+	}
+
+	return h, a, true
+}
@@ -0,0 +1,56 @@
+package betBuilders
+
+// Outcomes of a bet builder once settled. A combination is void when one of its legs
+// could not be settled and none lost.
+const (
+	Won  = "won"
+	Lost = "lost"
+	Void = "void"
+)
+
+// Selection : one leg of a combination, an outcome of a market of the match.
+type Selection struct {
+	Code      string `json:"code"`
+	OutcomeID string `json:"outcome_id"`
+}
+
+// Quote : price of a combination. Probability is the chance every leg wins once the
+// margin of the match markets is taken out, Odd carries the bet builder margin.
+type Quote struct {
+	MatchID     string      `json:"match_id"`
+	Selections  []Selection `json:"selections"`
+	Probability float64     `json:"probability"`
+	FairOdd     float64     `json:"fair_odd"`
+	Odd         float64     `json:"odd"`
+	Overround   float64     `json:"overround"`
+}
+
+// Leg : a settled selection.
+type Leg struct {
+	Selection
+	Result string `json:"result"`
+}
+
+// Settlement : a settled combination.
+type Settlement struct {
+	MatchID string `json:"match_id"`
+	Result  string `json:"result"`
+	Legs    []Leg  `json:"legs"`
+}
+
+// Request : a combination sent to the bet builder API. ClientID prices it from the odds
+// of the client odds profile.
+type Request struct {
+	SeasonWeekID string      `json:"season_week_id"`
+	MatchID      string      `json:"match_id"`
+	ClientID     string      `json:"client_id"`
+	Selections   []Selection `json:"selections"`
+}
+
+// BetBuilderAPI : bet builder api
+type BetBuilderAPI struct {
+	StatusCode        string      `json:"status_code"`
+	StatusDescription string      `json:"status_description"`
+	Quote             *Quote      `json:"quote,omitempty"`
+	Settlement        *Settlement `json:"settlement,omitempty"`
+}
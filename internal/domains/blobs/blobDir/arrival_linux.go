@@ -0,0 +1,24 @@
+//go:build linux
+
+package blobDir
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// ArrivalTime : the later of the modification and the change time of a file. A file
+// moved into a directory keeps its mtime but gets a new ctime.
+func ArrivalTime(info os.FileInfo) time.Time {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+
+	ctime := time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec))
+	if ctime.After(info.ModTime()) {
+		return ctime
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package blobDir
+
+import (
+	"os"
+	"time"
+)
+
+// ArrivalTime : the modification time, the change time is only read on linux.
+func ArrivalTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -0,0 +1,105 @@
+package blobDir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+)
+
+var _ blobs.BlobsRepository = (*BlobDirConfigs)(nil)
+
+// BlobDirConfigs : feed files on the local disk, directories are used as they are.
+// ModTime of a listed file is its ArrivalTime.
+type BlobDirConfigs struct{}
+
+// New initializes a local file system store.
+func New() (*BlobDirConfigs, error) {
+	return &BlobDirConfigs{}, nil
+}
+
+// List : files of directory, sub directories are skipped.
+func (s *BlobDirConfigs) List(ctx context.Context, directory string) ([]blobs.Blob, error) {
+	bb := []blobs.Blob{}
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return bb, fmt.Errorf("Err : %v failed to read directory %s", err, directory)
+	}
+
+	for _, e := range entries {
+
+		if e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			// Removed since it was listed
+			continue
+		}
+
+		bb = append(bb, blobs.Blob{Name: e.Name(), Size: info.Size(), ModTime: ArrivalTime(info)})
+	}
+
+	return bb, nil
+}
+
+// Stat : size and arrival time of a file.
+func (s *BlobDirConfigs) Stat(ctx context.Context, directory, name string) (blobs.Blob, error) {
+	info, err := os.Stat(filepath.Join(directory, name))
+	if err != nil {
+		return blobs.Blob{}, fmt.Errorf("Err : %v failed to stat %s/%s", err, directory, name)
+	}
+
+	if info.IsDir() {
+		return blobs.Blob{}, fmt.Errorf("%s/%s is a directory", directory, name)
+	}
+
+	return blobs.Blob{Name: name, Size: info.Size(), ModTime: ArrivalTime(info)}, nil
+}
+
+// Open : opens a file for reading, the caller closes it.
+func (s *BlobDirConfigs) Open(ctx context.Context, directory, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(directory, name))
+	if err != nil {
+		return nil, fmt.Errorf("Err : %v failed to open %s/%s", err, directory, name)
+	}
+	return f, nil
+}
+
+// Put : writes under a hidden name first then renames it into place, watchers skip hidden files.
+func (s *BlobDirConfigs) Put(ctx context.Context, directory, name string, data []byte) error {
+
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to create %s", err, directory)
+	}
+
+	tmp := filepath.Join(directory, "."+name+".tmp")
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Err : %v failed to write %s/%s", err, directory, name)
+	}
+
+	err = os.Rename(tmp, filepath.Join(directory, name))
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Err : %v failed to rename %s/%s", err, directory, name)
+	}
+
+	return nil
+}
+
+// Remove : deletes a file.
+func (s *BlobDirConfigs) Remove(ctx context.Context, directory, name string) error {
+	err := os.Remove(filepath.Join(directory, name))
+	if err != nil {
+		return fmt.Errorf("Err : %w failed to remove %s/%s", err, directory, name)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package blobS3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+)
+
+var _ blobs.BlobsRepository = (*BlobS3Configs)(nil)
+
+// BlobS3Configs : feed files in a bucket of an S3 compatible store (AWS, MinIO).
+// Buckets are addressed path style (<endpoint>/<bucket>/<key>), the key of a file is
+// its directory and name without the leading slash.
+type BlobS3Configs struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	scheme    string
+	client    *http.Client
+}
+
+// listBucketResult : ListObjectsV2 response, only what we read.
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// New initializes a new bucket store. endpoint is host[:port] e.g 127.0.0.1:9000 for MinIO.
+func New(endpoint, region, bucket, accessKey, secretKey string, useSSL bool) (*BlobS3Configs, error) {
+
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint not set")
+	}
+
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket not set")
+	}
+
+	if accessKey == "" {
+		return nil, fmt.Errorf("accessKey not set")
+	}
+
+	if secretKey == "" {
+		return nil, fmt.Errorf("secretKey not set")
+	}
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	scheme := "http"
+	if useSSL {
+		scheme = "https"
+	}
+
+	c := &BlobS3Configs{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		scheme:    scheme,
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}
+
+	return c, nil
+}
+
+// List : objects directly under directory, deeper keys are left out.
+func (s *BlobS3Configs) List(ctx context.Context, directory string) ([]blobs.Blob, error) {
+	bb := []blobs.Blob{}
+
+	prefix := keyPrefix(directory)
+	token := ""
+
+	for {
+
+		q := url.Values{}
+		q.Set("list-type", "2")
+		q.Set("prefix", prefix)
+		q.Set("delimiter", "/")
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", q, nil)
+		if err != nil {
+			return bb, fmt.Errorf("Err : %v failed to list %s", err, directory)
+		}
+
+		var lr listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&lr)
+		resp.Body.Close()
+		if err != nil {
+			return bb, fmt.Errorf("Err : %v failed to decode listing of %s", err, directory)
+		}
+
+		for _, c := range lr.Contents {
+
+			name := strings.TrimPrefix(c.Key, prefix)
+			if name == "" {
+				// Directory marker
+				continue
+			}
+
+			bb = append(bb, blobs.Blob{Name: name, Size: c.Size, ModTime: c.LastModified})
+		}
+
+		if !lr.IsTruncated || lr.NextContinuationToken == "" {
+			return bb, nil
+		}
+		token = lr.NextContinuationToken
+	}
+}
+
+// Stat : size and modification time of an object.
+func (s *BlobS3Configs) Stat(ctx context.Context, directory, name string) (blobs.Blob, error) {
+
+	resp, err := s.do(ctx, http.MethodHead, objectKey(directory, name), nil, nil)
+	if err != nil {
+		return blobs.Blob{}, fmt.Errorf("Err : %w failed to stat %s/%s", err, directory, name)
+	}
+	resp.Body.Close()
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return blobs.Blob{Name: name, Size: size, ModTime: modTime}, nil
+}
+
+// Open : streams an object, the caller closes it.
+func (s *BlobS3Configs) Open(ctx context.Context, directory, name string) (io.ReadCloser, error) {
+
+	resp, err := s.do(ctx, http.MethodGet, objectKey(directory, name), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Err : %w failed to open %s/%s", err, directory, name)
+	}
+
+	return resp.Body, nil
+}
+
+// Put : uploads an object, it only becomes visible once complete.
+func (s *BlobS3Configs) Put(ctx context.Context, directory, name string, data []byte) error {
+
+	resp, err := s.do(ctx, http.MethodPut, objectKey(directory, name), nil, data)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to put %s/%s", err, directory, name)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Remove : deletes an object.
+func (s *BlobS3Configs) Remove(ctx context.Context, directory, name string) error {
+
+	resp, err := s.do(ctx, http.MethodDelete, objectKey(directory, name), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Err : %w failed to remove %s/%s", err, directory, name)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// do : sends a signed request for key (the bucket itself when empty). A status other
+// than 2xx is returned as an error, a missing object wraps fs.ErrNotExist.
+func (s *BlobS3Configs) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+
+	p := "/" + s.bucket
+	if key != "" {
+		p += "/" + key
+	}
+
+	u := &url.URL{
+		Scheme:   s.scheme,
+		Host:     s.endpoint,
+		Path:     p,
+		RawPath:  uriEncode(p, false),
+		RawQuery: canonicalQuery(query),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(sum[:]), time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s %s returned %d : %w", method, p, resp.StatusCode, fs.ErrNotExist)
+	}
+
+	return nil, fmt.Errorf("%s %s returned %d : %s", method, p, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+// sign : AWS signature version 4. Host and every header already set are signed.
+func (s *BlobS3Configs) sign(req *http.Request, payloadHash string, now time.Time) {
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", day, s.region)
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(crHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery : parameters sorted by name, names and values uri encoded.
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(query))
+	for k := range query {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pairs := []string{}
+	for _, k := range names {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode : encodes every byte but the unreserved characters, slashes are kept
+// unless encodeSlash is set.
+func uriEncode(v string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// keyPrefix : /tz/generator/odds -> tz/generator/odds/
+func keyPrefix(directory string) string {
+	p := strings.Trim(path.Clean("/"+directory), "/")
+	if p == "" {
+		return ""
+	}
+	return p + "/"
+}
+
+// objectKey : /tz/generator/odds + 1_2_3.txt -> tz/generator/odds/1_2_3.txt
+func objectKey(directory, name string) string {
+	return keyPrefix(directory) + name
+}
@@ -0,0 +1,93 @@
+//go:build integration
+
+package blobS3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMinIO : runs against a MinIO server, the bucket must exist.
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	mc mb local/feeds
+//	S3_ENDPOINT=127.0.0.1:9000 S3_BUCKET=feeds S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin \
+//	    go test -tags integration ./internal/domains/blobs/blobS3/
+func TestMinIO(t *testing.T) {
+
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT not set")
+	}
+
+	s, err := New(endpoint, os.Getenv("S3_REGION"), os.Getenv("S3_BUCKET"),
+		os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"), os.Getenv("S3_USE_SSL") == "true")
+	if err != nil {
+		t.Fatalf("New : %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Spaces and reserved characters exercise the canonical uri and query.
+	directory := fmt.Sprintf("/it/%d/odds dir", time.Now().UnixNano())
+	name := "1_2_3 +$.txt"
+	data := []byte(`{"parent_match_id":"1"}`)
+
+	err = s.Put(ctx, directory, name, data)
+	if err != nil {
+		t.Fatalf("Put : %v", err)
+	}
+	defer s.Remove(ctx, directory, name)
+
+	// A deeper key is not listed under directory.
+	err = s.Put(ctx, directory+"/nested", name, data)
+	if err != nil {
+		t.Fatalf("Put nested : %v", err)
+	}
+	defer s.Remove(ctx, directory+"/nested", name)
+
+	bb, err := s.List(ctx, directory)
+	if err != nil {
+		t.Fatalf("List : %v", err)
+	}
+	if len(bb) != 1 || bb[0].Name != name || bb[0].Size != int64(len(data)) {
+		t.Fatalf("List = %+v, want only %s of %d bytes", bb, name, len(data))
+	}
+
+	b, err := s.Stat(ctx, directory, name)
+	if err != nil {
+		t.Fatalf("Stat : %v", err)
+	}
+	if b.Size != int64(len(data)) || b.ModTime.IsZero() {
+		t.Fatalf("Stat = %+v", b)
+	}
+
+	rc, err := s.Open(ctx, directory, name)
+	if err != nil {
+		t.Fatalf("Open : %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("ReadAll : %v", err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("Open read %s, want %s", got, data)
+	}
+
+	err = s.Remove(ctx, directory, name)
+	if err != nil {
+		t.Fatalf("Remove : %v", err)
+	}
+
+	_, err = s.Stat(ctx, directory, name)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat after Remove = %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,17 @@
+package blobs
+
+import (
+	"context"
+	"io"
+)
+
+// BlobsRepository : where feed files are read from. directory is the directory of the
+// feed as configured (e.g /tz/generator/winning_outcomes), an object store uses it as
+// the key prefix so that the paths saved in mysql are the same whatever the store.
+type BlobsRepository interface {
+	List(ctx context.Context, directory string) ([]Blob, error)
+	Stat(ctx context.Context, directory, name string) (Blob, error)
+	Open(ctx context.Context, directory, name string) (io.ReadCloser, error)
+	Put(ctx context.Context, directory, name string, data []byte) error
+	Remove(ctx context.Context, directory, name string) error
+}
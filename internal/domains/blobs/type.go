@@ -0,0 +1,10 @@
+package blobs
+
+import "time"
+
+// Blob : a feed file, sub directories are never listed.
+type Blob struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
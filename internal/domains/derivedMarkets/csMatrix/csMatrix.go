@@ -0,0 +1,506 @@
+package csMatrix
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements/settlementEngine"
+)
+
+var _ derivedMarkets.DerivedMarketsRepository = (*CsMatrixConfigs)(nil)
+
+// minProbability : fair probabilities are floored so that no fair price goes above 500.
+const minProbability = 0.002
+
+// minOdd : lowest price ever offered.
+const minOdd = 1.01
+
+// defaultOverround : margin of the derived markets when the match has no complete 1X2
+// to take it from and no margin engine is set.
+const defaultOverround = 1.08
+
+// maxSecondHalfGoals : second half goals summed over when pricing the highest scoring half.
+const maxSecondHalfGoals = 15
+
+type score struct{ home, away int }
+
+// grid : fair probability of every correct score, at full time and, when HS is quoted, at half time.
+type grid struct {
+	ft map[score]float64
+	ht map[score]float64
+}
+
+type outcome struct{ id, name string }
+
+// derivation : outcomes of a derived market and their fair probabilities.
+type derivation struct {
+	outcomes []outcome
+	halfTime bool
+	probs    func(g grid) []float64
+}
+
+// CsMatrixConfigs : markets derived, in the order they are returned.
+type CsMatrixConfigs struct {
+	codes       []string
+	derivations map[string]derivation
+	engine      settlements.SettlementsRepository
+}
+
+// New initializes the correct score derivation for codes, every derived market when none is given.
+func New(codes ...string) (*CsMatrixConfigs, error) {
+
+	all := derivations()
+
+	if len(codes) == 0 {
+		codes = append(codes, order...)
+	}
+
+	c := &CsMatrixConfigs{
+		derivations: make(map[string]derivation),
+	}
+
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+
+		d, ok := all[code]
+		if !ok {
+			return nil, fmt.Errorf("market %s can not be derived", code)
+		}
+
+		if _, ok := c.derivations[code]; ok {
+			return nil, fmt.Errorf("market %s set twice", code)
+		}
+
+		c.derivations[code] = d
+		c.codes = append(c.codes, code)
+	}
+
+	engine, err := settlementEngine.New(c.codes...)
+	if err != nil {
+		return nil, err
+	}
+	c.engine = engine
+
+	return c, nil
+}
+
+// Derive : prices the derived markets the match does not already have. The correct score
+// prices are taken back to fair probabilities, the margin is then put in by the margin
+// engine or, without one, proportionally at the overround of the 1X2.
+func (s *CsMatrixConfigs) Derive(ctx context.Context, mkts []oddsFiles.FinalMarkets, engine margins.MarginsRepository) ([]oddsFiles.FinalMarkets, error) {
+
+	derived := []oddsFiles.FinalMarkets{}
+
+	quoted := make(map[string]bool)
+	var cs, hs, mr []oddsFiles.FinalOutcomes
+	for _, m := range mkts {
+		quoted[m.Code] = true
+		switch m.Code {
+		case "CS":
+			cs = m.FinalOutcomes
+		case "HS":
+			hs = m.FinalOutcomes
+		case "1X2":
+			mr = m.FinalOutcomes
+		}
+	}
+
+	g := grid{
+		ft: scoreGrid(cs),
+		ht: scoreGrid(hs),
+	}
+
+	if len(g.ft) == 0 {
+		return derived, fmt.Errorf("no correct scores to derive markets from")
+	}
+
+	overround := defaultOverround
+	if b := book(mr); len(mr) == 3 && b > 1 {
+		overround = b
+	}
+
+	for _, code := range s.codes {
+
+		if quoted[code] {
+			continue
+		}
+
+		d := s.derivations[code]
+		if d.halfTime && len(g.ht) == 0 {
+			continue
+		}
+
+		probs := d.probs(g)
+		fair := make([]float64, len(probs))
+		for i, p := range probs {
+			fair[i] = 1 / math.Max(p, minProbability)
+		}
+
+		odds, err := price(ctx, engine, code, fair, overround)
+		if err != nil {
+			log.Printf("Err : %v skipped derived market %s", err, code)
+			continue
+		}
+
+		mkt := oddsFiles.FinalMarkets{
+			Name: code,
+			Code: code,
+		}
+
+		for i, o := range d.outcomes {
+			mkt.FinalOutcomes = append(mkt.FinalOutcomes, oddsFiles.FinalOutcomes{
+				OutcomeID:    o.id,
+				OutcomeName:  o.name,
+				OddValue:     odds[i],
+				OutcomeAlias: o.id,
+			})
+		}
+
+		derived = append(derived, mkt)
+	}
+
+	return derived, nil
+}
+
+// Settle : winning outcomes of the derived markets, in the winning outcome structure the
+// provider markets use. Every offered outcome is settled on its own so that banded outcomes
+// like 6+ are matched.
+func (s *CsMatrixConfigs) Settle(ctx context.Context, m settlements.Match) []oddsFiles.FinalWinningOutcomes {
+
+	wos := []oddsFiles.FinalWinningOutcomes{}
+	for _, code := range s.codes {
+		for _, o := range s.derivations[code].outcomes {
+
+			won, known := s.engine.Wins(m, code, o.id)
+			if !known || !won {
+				continue
+			}
+
+			wos = append(wos, oddsFiles.FinalWinningOutcomes{
+				SubTypeID:   code,
+				OutcomeID:   o.id,
+				OutcomeName: o.name,
+			})
+		}
+	}
+
+	return wos
+}
+
+// price : fair odds to offered odds.
+func price(ctx context.Context, engine margins.MarginsRepository, code string, fair []float64, overround float64) ([]float64, error) {
+
+	if engine != nil {
+		p, err := engine.Reprice(ctx, code, fair)
+		if err != nil {
+			return nil, err
+		}
+		return p.Odds, nil
+	}
+
+	total := 0.0
+	for _, o := range fair {
+		total += 1 / o
+	}
+
+	odds := make([]float64, len(fair))
+	for i, o := range fair {
+		q := (1 / o) / total
+		odds[i] = math.Max(minOdd, math.Floor(100/(q*overround)+1e-9)/100)
+	}
+
+	return odds, nil
+}
+
+// order : display order of the derived markets.
+var order = []string{
+	derivedMarkets.AsianHandicapM15,
+	derivedMarkets.AsianHandicapM05,
+	derivedMarkets.AsianHandicapP05,
+	derivedMarkets.AsianHandicapP15,
+	derivedMarkets.WinningMargin,
+	derivedMarkets.ExactGoals,
+	derivedMarkets.HomeOverUnder05,
+	derivedMarkets.HomeOverUnder15,
+	derivedMarkets.HomeOverUnder25,
+	derivedMarkets.AwayOverUnder05,
+	derivedMarkets.AwayOverUnder15,
+	derivedMarkets.AwayOverUnder25,
+	derivedMarkets.HomeCleanSheet,
+	derivedMarkets.AwayCleanSheet,
+	derivedMarkets.HomeWinToNil,
+	derivedMarkets.AwayWinToNil,
+	derivedMarkets.HighestHalf,
+}
+
+// derivations : every market that can be priced from the correct scores.
+func derivations() map[string]derivation {
+
+	handicap := []outcome{{"1", "Home"}, {"2", "Away"}}
+	overUnder := []outcome{{settlements.Over, "Over"}, {settlements.Under, "Under"}}
+	yesNo := []outcome{{"YES", "Yes"}, {"NO", "No"}}
+
+	return map[string]derivation{
+		derivedMarkets.AsianHandicapM15: {outcomes: handicap, probs: asianHandicap(-15)},
+		derivedMarkets.AsianHandicapM05: {outcomes: handicap, probs: asianHandicap(-5)},
+		derivedMarkets.AsianHandicapP05: {outcomes: handicap, probs: asianHandicap(5)},
+		derivedMarkets.AsianHandicapP15: {outcomes: handicap, probs: asianHandicap(15)},
+		derivedMarkets.WinningMargin: {
+			outcomes: []outcome{{"H1", "Home by 1"}, {"H2", "Home by 2"}, {"H3+", "Home by 3+"}, {"X", "Draw"},
+				{"A1", "Away by 1"}, {"A2", "Away by 2"}, {"A3+", "Away by 3+"}},
+			probs: winningMargin,
+		},
+		derivedMarkets.ExactGoals: {
+			outcomes: []outcome{{"0", "0"}, {"1", "1"}, {"2", "2"}, {"3", "3"}, {"4", "4"}, {"5", "5"}, {"6+", "6+"}},
+			probs:    exactGoals,
+		},
+		derivedMarkets.HomeOverUnder05: {outcomes: overUnder, probs: teamOver(true, 0)},
+		derivedMarkets.HomeOverUnder15: {outcomes: overUnder, probs: teamOver(true, 1)},
+		derivedMarkets.HomeOverUnder25: {outcomes: overUnder, probs: teamOver(true, 2)},
+		derivedMarkets.AwayOverUnder05: {outcomes: overUnder, probs: teamOver(false, 0)},
+		derivedMarkets.AwayOverUnder15: {outcomes: overUnder, probs: teamOver(false, 1)},
+		derivedMarkets.AwayOverUnder25: {outcomes: overUnder, probs: teamOver(false, 2)},
+		derivedMarkets.HomeCleanSheet:  {outcomes: yesNo, probs: yesOrNo(func(x score) bool { return x.away == 0 })},
+		derivedMarkets.AwayCleanSheet:  {outcomes: yesNo, probs: yesOrNo(func(x score) bool { return x.home == 0 })},
+		derivedMarkets.HomeWinToNil:    {outcomes: yesNo, probs: yesOrNo(func(x score) bool { return x.home > 0 && x.away == 0 })},
+		derivedMarkets.AwayWinToNil:    {outcomes: yesNo, probs: yesOrNo(func(x score) bool { return x.away > 0 && x.home == 0 })},
+		derivedMarkets.HighestHalf: {
+			outcomes: []outcome{{"1H", "1st Half"}, {"2H", "2nd Half"}, {"X", "Equal"}},
+			halfTime: true,
+			probs:    highestHalf,
+		},
+	}
+}
+
+// asianHandicap : half goal line given to the home team, -15 is -1.5.
+func asianHandicap(line int) func(g grid) []float64 {
+	return func(g grid) []float64 {
+		home := where(g.ft, func(x score) bool { return x.home*10+line > x.away*10 })
+		return []float64{home, 1 - home}
+	}
+}
+
+func winningMargin(g grid) []float64 {
+	p := make([]float64, 7)
+	for x, q := range g.ft {
+		d := x.home - x.away
+		switch {
+		case d >= 3:
+			p[2] += q
+		case d > 0:
+			p[d-1] += q
+		case d == 0:
+			p[3] += q
+		case d <= -3:
+			p[6] += q
+		default:
+			p[3-d] += q
+		}
+	}
+	return p
+}
+
+func exactGoals(g grid) []float64 {
+	p := make([]float64, 7)
+	for x, q := range g.ft {
+		n := x.home + x.away
+		if n > 6 {
+			n = 6
+		}
+		p[n] += q
+	}
+	return p
+}
+
+// teamOver : over line.5 goals for the home or the away team.
+func teamOver(home bool, line int) func(g grid) []float64 {
+	return func(g grid) []float64 {
+		over := where(g.ft, func(x score) bool {
+			if home {
+				return x.home > line
+			}
+			return x.away > line
+		})
+		return []float64{over, 1 - over}
+	}
+}
+
+func yesOrNo(yes func(x score) bool) func(g grid) []float64 {
+	return func(g grid) []float64 {
+		p := where(g.ft, yes)
+		return []float64{p, 1 - p}
+	}
+}
+
+// highestHalf : first half goals follow the half time scores, second half goals a Poisson
+// of the goals expected at full time less the ones expected at half time.
+func highestHalf(g grid) []float64 {
+
+	first := make(map[int]float64)
+	firstMean := 0.0
+	for x, q := range g.ht {
+		first[x.home+x.away] += q
+		firstMean += float64(x.home+x.away) * q
+	}
+
+	fullMean := 0.0
+	for x, q := range g.ft {
+		fullMean += float64(x.home+x.away) * q
+	}
+
+	lambda := math.Max(fullMean-firstMean, 0.05)
+
+	p := make([]float64, 3)
+	for n, q := range first {
+		second := math.Exp(-lambda)
+		for k := 0; k <= maxSecondHalfGoals; k++ {
+			if k > 0 {
+				second *= lambda / float64(k)
+			}
+			switch {
+			case n > k:
+				p[0] += q * second
+			case k > n:
+				p[1] += q * second
+			default:
+				p[2] += q * second
+			}
+		}
+	}
+	return p
+}
+
+func where(ft map[score]float64, match func(x score) bool) float64 {
+	p := 0.0
+	for x, q := range ft {
+		if match(x) {
+			p += q
+		}
+	}
+	return p
+}
+
+// scoreGrid : correct score prices to probabilities adding up to 1. Outcomes of any other
+// score keep their share under the unlisted score of their result with the fewest goals,
+// so that it is not spread over the listed scores.
+func scoreGrid(outcomes []oddsFiles.FinalOutcomes) map[score]float64 {
+
+	g := make(map[score]float64)
+	others := make(map[string]float64)
+	total := 0.0
+
+	for _, o := range outcomes {
+		if o.OddValue <= 1 {
+			continue
+		}
+
+		h, a, ok := correctScore(o.OutcomeID)
+		if !ok {
+			h, a, ok = correctScore(o.OutcomeName)
+		}
+
+		if ok {
+			g[score{h, a}] += 1 / o.OddValue
+		} else {
+			others[otherResult(o.OutcomeName)] += 1 / o.OddValue
+		}
+		total += 1 / o.OddValue
+	}
+
+	if len(g) == 0 {
+		return g
+	}
+
+	listed := make(map[score]bool)
+	for x := range g {
+		listed[x] = true
+	}
+
+	for r, q := range others {
+		x := otherScore(listed, r)
+		g[x] += q
+	}
+
+	for x, q := range g {
+		g[x] = q / total
+	}
+
+	return g
+}
+
+// otherResult : result an any other score outcome stands for, 1, X or 2, empty for any result.
+func otherResult(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "home"):
+		return "1"
+	case strings.Contains(name, "away"):
+		return "2"
+	case strings.Contains(name, "draw"):
+		return "X"
+	}
+	return ""
+}
+
+// otherScore : the unlisted score of result with the fewest goals, the home side first.
+func otherScore(listed map[score]bool, result string) score {
+	for n := 0; ; n++ {
+		for h := n; h >= 0; h-- {
+			x := score{h, n - h}
+			if listed[x] {
+				continue
+			}
+			switch {
+			case result == "1" && x.home <= x.away,
+				result == "2" && x.away <= x.home,
+				result == "X" && x.home != x.away:
+				continue
+			}
+			return x
+		}
+	}
+}
+
+func book(outcomes []oddsFiles.FinalOutcomes) float64 {
+	b := 0.0
+	for _, o := range outcomes {
+		if o.OddValue > 0 {
+			b += 1 / o.OddValue
+		}
+	}
+	return b
+}
+
+// correctScore : reads 2-1 or 2:1
+func correctScore(outcome string) (int, int, bool) {
+	outcome = strings.TrimSpace(outcome)
+	sep := "-"
+	if strings.Contains(outcome, ":") {
+		sep = ":"
+	}
+
+	parts := strings.Split(outcome, sep)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	h, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || h < 0 {
+		return 0, 0, false
+	}
+
+	a, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || a < 0 {
+		return 0, 0, false
+	}
+
+	return h, a, true
+}
@@ -0,0 +1,16 @@
+package derivedMarkets
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+)
+
+// DerivedMarketsRepository : prices markets the feed does not carry from the ones it does,
+// and settles them.
+type DerivedMarketsRepository interface {
+	Derive(ctx context.Context, mkts []oddsFiles.FinalMarkets, engine margins.MarginsRepository) ([]oddsFiles.FinalMarkets, error)
+	Settle(ctx context.Context, m settlements.Match) []oddsFiles.FinalWinningOutcomes
+}
@@ -0,0 +1,22 @@
+package derivedMarkets
+
+// Codes of the markets priced from the correct score matrix.
+const (
+	AsianHandicapM15 = "AHM15"
+	AsianHandicapM05 = "AHM05"
+	AsianHandicapP05 = "AHP05"
+	AsianHandicapP15 = "AHP15"
+	WinningMargin    = "WM"
+	ExactGoals       = "EG"
+	HomeOverUnder05  = "T1OU05"
+	HomeOverUnder15  = "T1OU15"
+	HomeOverUnder25  = "T1OU25"
+	AwayOverUnder05  = "T2OU05"
+	AwayOverUnder15  = "T2OU15"
+	AwayOverUnder25  = "T2OU25"
+	HomeCleanSheet   = "CSH"
+	AwayCleanSheet   = "CSA"
+	HomeWinToNil     = "WTNH"
+	AwayWinToNil     = "WTNA"
+	HighestHalf      = "HSH"
+)
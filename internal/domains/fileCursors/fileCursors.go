@@ -0,0 +1,33 @@
+package fileCursors
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewFileCursor instantiate fileCursors
+func NewFileCursor(cursorName, lastFileName string, lastModTime int64) (*FileCursors, error) {
+
+	if cursorName == "" {
+		return &FileCursors{}, fmt.Errorf("cursorName not set")
+	}
+
+	if lastFileName == "" {
+		return &FileCursors{}, fmt.Errorf("lastFileName not set")
+	}
+
+	if lastModTime <= 0 {
+		return &FileCursors{}, fmt.Errorf("lastModTime not set")
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &FileCursors{
+		CursorName:   cursorName,
+		LastModTime:  lastModTime,
+		LastFileName: lastFileName,
+		Created:      created,
+		Modified:     modified,
+	}, nil
+}
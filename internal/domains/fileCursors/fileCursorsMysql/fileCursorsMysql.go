@@ -0,0 +1,99 @@
+package fileCursorsMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileCursors"
+)
+
+var _ fileCursors.FileCursorsRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save : moves the cursor forward, it never goes back to an older file.
+func (mr *MysqlRepository) Save(ctx context.Context, t fileCursors.FileCursors) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT file_cursors SET cursor_name=?,last_mod_time=?,last_file_name=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE \n"+
+		"last_file_name=if(values(last_mod_time) >= last_mod_time,values(last_file_name),last_file_name), \n"+
+		"last_mod_time=greatest(last_mod_time,values(last_mod_time)),modified=now()",
+		t.CursorName, t.LastModTime, t.LastFileName)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save file cursor : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last file cursor ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// Rewind : moves the cursor back to lastModTime when it is past it, so that the next scan
+// hands over the file that could not be saved again.
+func (mr *MysqlRepository) Rewind(ctx context.Context, cursorName string, lastModTime int64) (int64, error) {
+	var rs int64
+	result, err := mr.db.Exec("update file_cursors set last_file_name='',last_mod_time=?,modified=now() \n"+
+		"where cursor_name=? and last_mod_time > ?", lastModTime, cursorName, lastModTime)
+	if err != nil {
+		return rs, fmt.Errorf("unable to rewind file cursor %s : %v", cursorName, err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetCursor : returns the cursor saved under cursorName
+func (r *MysqlRepository) GetCursor(ctx context.Context, cursorName string) ([]fileCursors.FileCursors, error) {
+	var gc []fileCursors.FileCursors
+
+	statement := fmt.Sprintf("select file_cursor_id,cursor_name,last_mod_time,last_file_name,created,modified \n"+
+		"from file_cursors where cursor_name = '%s'", cursorName)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g fileCursors.FileCursors
+		err := raws.Scan(&g.FileCursorID, &g.CursorName, &g.LastModTime, &g.LastFileName, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
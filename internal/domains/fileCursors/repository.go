@@ -0,0 +1,10 @@
+package fileCursors
+
+import "context"
+
+// FileCursorsRepository : keeps track of how far a feed directory has been read.
+type FileCursorsRepository interface {
+	Save(ctx context.Context, t FileCursors) (int, error)
+	GetCursor(ctx context.Context, cursorName string) ([]FileCursors, error)
+	Rewind(ctx context.Context, cursorName string, lastModTime int64) (int64, error)
+}
@@ -0,0 +1,20 @@
+package fileCursors
+
+// CREATE TABLE `file_cursors` (
+// 	`file_cursor_id` bigint(20) NOT NULL AUTO_INCREMENT,
+// 	`cursor_name` varchar(100) NOT NULL,
+// 	`last_mod_time` bigint(20) NOT NULL DEFAULT '0',
+// 	`last_file_name` varchar(300) NOT NULL,
+// 	`created` datetime NOT NULL,
+// 	`modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+// FileCursors : last file picked from a feed directory. LastModTime is the
+// file modification time in unix nano seconds.
+type FileCursors struct {
+	FileCursorID string
+	CursorName   string
+	LastModTime  int64
+	LastFileName string
+	Created      string
+	Modified     string
+}
@@ -0,0 +1,8 @@
+package fileNames
+
+// FileNameParser : validates the file names of one feed type (odds, wo, ls or teams).
+// Parse returns a *Rejection when the name does not follow the grammar.
+type FileNameParser interface {
+	FileType() string
+	Parse(name string) (FileName, error)
+}
@@ -0,0 +1,178 @@
+package nameParser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames"
+)
+
+var _ fileNames.FileNameParser = (*NameParser)(nil)
+
+type NameParser struct {
+	fileType       string
+	projectIDs     []string
+	competitionIDs []string
+}
+
+// New initializes a file name parser for fileType. projectIDs and competitionIDs
+// are comma separated lists of accepted ids, leave empty to accept any number.
+func New(fileType, projectIDs, competitionIDs string) (*NameParser, error) {
+
+	switch fileType {
+	case "odds", "wo", "ls", "teams":
+	default:
+		return nil, fmt.Errorf("unknown file type %s", fileType)
+	}
+
+	c := &NameParser{
+		fileType:       fileType,
+		projectIDs:     splitList(projectIDs),
+		competitionIDs: splitList(competitionIDs),
+	}
+
+	return c, nil
+}
+
+// Parsers : returns a parser for every feed type.
+func Parsers(projectIDs, competitionIDs string) (map[string]fileNames.FileNameParser, error) {
+	parsers := make(map[string]fileNames.FileNameParser)
+	for _, t := range []string{"odds", "wo", "ls", "teams"} {
+		p, err := New(t, projectIDs, competitionIDs)
+		if err != nil {
+			return nil, err
+		}
+		parsers[t] = p
+	}
+	return parsers, nil
+}
+
+// FileType : returns the feed type handled by this parser
+func (s *NameParser) FileType() string {
+	return s.fileType
+}
+
+// Parse : validates name against the grammar of the feed type.
+func (s *NameParser) Parse(name string) (fileNames.FileName, error) {
+
+	fn := fileNames.FileName{
+		FileType: s.fileType,
+		Name:     name,
+	}
+
+	if !strings.HasSuffix(name, ".txt") {
+		return fn, s.reject(name, fileNames.BadExtension, "extension", name, "expected .txt")
+	}
+
+	data := strings.Split(strings.TrimSuffix(name, ".txt"), "_")
+
+	if s.fileType == "wo" {
+		if len(data) != 4 || data[0] != "wo" {
+			return fn, s.reject(name, fileNames.BadFormat, "name", name, "expected wo_<extID>_<projectID>_<competitionID>.txt")
+		}
+		data = data[1:]
+	}
+
+	if len(data) != 3 {
+		if s.fileType == "teams" {
+			return fn, s.reject(name, fileNames.BadFormat, "name", name, "expected <seasonID>_<competitionID>_<count>.txt")
+		}
+		return fn, s.reject(name, fileNames.BadFormat, "name", name, "expected <extID>_<projectID>_<competitionID>.txt")
+	}
+
+	if s.fileType == "teams" {
+
+		if !isID(data[0]) {
+			return fn, s.reject(name, fileNames.BadSeasonID, "season_id", data[0], "not a positive number")
+		}
+
+		if !isID(data[1]) {
+			return fn, s.reject(name, fileNames.BadCompetitionID, "competition_id", data[1], "not a positive number")
+		}
+
+		if !contains(s.competitionIDs, data[1]) {
+			return fn, s.reject(name, fileNames.UnknownCompetitionID, "competition_id", data[1], "competition not configured")
+		}
+
+		if !isID(data[2]) {
+			return fn, s.reject(name, fileNames.BadCount, "count", data[2], "not a positive number")
+		}
+
+		fn.SeasonID = data[0]
+		fn.CompetitionID = data[1]
+		fn.Count = data[2]
+
+		return fn, nil
+	}
+
+	if !isID(data[0]) {
+		return fn, s.reject(name, fileNames.BadExtID, "ext_id", data[0], "not a positive number")
+	}
+
+	if !isID(data[1]) {
+		return fn, s.reject(name, fileNames.BadProjectID, "project_id", data[1], "not a positive number")
+	}
+
+	if !contains(s.projectIDs, data[1]) {
+		return fn, s.reject(name, fileNames.UnknownProjectID, "project_id", data[1], "project not configured")
+	}
+
+	if !isID(data[2]) {
+		return fn, s.reject(name, fileNames.BadCompetitionID, "competition_id", data[2], "not a positive number")
+	}
+
+	if !contains(s.competitionIDs, data[2]) {
+		return fn, s.reject(name, fileNames.UnknownCompetitionID, "competition_id", data[2], "competition not configured")
+	}
+
+	fn.ExtID = data[0]
+	fn.ProjectID = data[1]
+	fn.CompetitionID = data[2]
+
+	return fn, nil
+}
+
+func (s *NameParser) reject(name, code, field, value, detail string) *fileNames.Rejection {
+	return &fileNames.Rejection{
+		FileName: name,
+		FileType: s.fileType,
+		Code:     code,
+		Field:    field,
+		Value:    value,
+		Detail:   detail,
+	}
+}
+
+// isID : true for positive integers without sign or leading zero.
+func isID(v string) bool {
+	if v == "" || v[0] == '0' {
+		return false
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	return err == nil && n > 0
+}
+
+// contains : an empty list accepts everything
+func contains(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(v string) []string {
+	list := []string{}
+	for _, x := range strings.Split(v, ",") {
+		x = strings.TrimSpace(x)
+		if x != "" {
+			list = append(list, x)
+		}
+	}
+	return list
+}
@@ -0,0 +1,45 @@
+package fileNames
+
+import "fmt"
+
+// FileName : parts of a feed file name.
+// odds / ls : <extID>_<projectID>_<competitionID>.txt
+// wo        : wo_<extID>_<projectID>_<competitionID>.txt
+// teams     : <seasonID>_<competitionID>_<count>.txt
+type FileName struct {
+	FileType      string
+	Name          string
+	ExtID         string
+	ProjectID     string
+	CompetitionID string
+	SeasonID      string
+	Count         string
+}
+
+// Rejection : why a file name was refused. Code is stable and meant to be
+// read by machines, Detail is for humans.
+type Rejection struct {
+	FileName string `json:"file_name"`
+	FileType string `json:"file_type"`
+	Code     string `json:"code"`
+	Field    string `json:"field"`
+	Value    string `json:"value"`
+	Detail   string `json:"detail"`
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("%s rejected [%s] %s=%q : %s", r.FileName, r.Code, r.Field, r.Value, r.Detail)
+}
+
+// Rejection codes
+const (
+	BadFormat            = "bad_format"
+	BadExtension         = "bad_extension"
+	BadExtID             = "bad_ext_id"
+	BadProjectID         = "bad_project_id"
+	BadCompetitionID     = "bad_competition_id"
+	BadSeasonID          = "bad_season_id"
+	BadCount             = "bad_count"
+	UnknownProjectID     = "unknown_project_id"
+	UnknownCompetitionID = "unknown_competition_id"
+)
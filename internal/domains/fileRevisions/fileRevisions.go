@@ -0,0 +1,45 @@
+package fileRevisions
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewFileRevision instantiate fileRevisions
+func NewFileRevision(fileType, country, extID, sha256 string, fileSize int64, revision int) (*FileRevisions, error) {
+
+	if fileType == "" {
+		return &FileRevisions{}, fmt.Errorf("fileType not set")
+	}
+
+	if country == "" {
+		return &FileRevisions{}, fmt.Errorf("country not set")
+	}
+
+	if extID == "" {
+		return &FileRevisions{}, fmt.Errorf("extID not set")
+	}
+
+	if len(sha256) != 64 {
+		return &FileRevisions{}, fmt.Errorf("sha256 not set")
+	}
+
+	if revision < 2 {
+		return &FileRevisions{}, fmt.Errorf("revision not set")
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &FileRevisions{
+		FileType: fileType,
+		Country:  country,
+		ExtID:    extID,
+		Revision: revision,
+		Sha256:   sha256,
+		FileSize: fileSize,
+		Status:   "pending",
+		Created:  created,
+		Modified: modified,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package fileRevisionsMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileRevisions"
+)
+
+var _ fileRevisions.FileRevisionsRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save :
+func (mr *MysqlRepository) Save(ctx context.Context, t fileRevisions.FileRevisions) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT file_revisions SET file_type=?,country=?,ext_id=?,revision=?,sha256=?, \n"+
+		"file_size=?,status=?,created=now(),modified=now() ON DUPLICATE KEY UPDATE modified=now()",
+		t.FileType, t.Country, t.ExtID, t.Revision, t.Sha256, t.FileSize, t.Status)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save file revision : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last file revision ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// GetPendingRevisions : oldest revisions first
+func (r *MysqlRepository) GetPendingRevisions(ctx context.Context, status string) ([]fileRevisions.FileRevisions, error) {
+	var gc []fileRevisions.FileRevisions
+	statement := fmt.Sprintf("select file_revision_id,file_type,country,ext_id,revision,sha256,file_size,status,\n"+
+		"created,modified from file_revisions where status='%s' order by file_revision_id limit 50", status)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g fileRevisions.FileRevisions
+		err := raws.Scan(&g.FileRevisionID, &g.FileType, &g.Country, &g.ExtID, &g.Revision, &g.Sha256,
+			&g.FileSize, &g.Status, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// UpdateRevisionStatus :
+func (mr *MysqlRepository) UpdateRevisionStatus(ctx context.Context, status, fileRevisionID string) (int64, error) {
+	var rs int64
+	result, err := mr.db.Exec("update file_revisions set status=?,modified=now() where file_revision_id = ? ",
+		status, fileRevisionID)
+	if err != nil {
+		return rs, fmt.Errorf("unable to update file revision : %v", err)
+	}
+	return result.RowsAffected()
+}
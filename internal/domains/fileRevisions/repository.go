@@ -0,0 +1,10 @@
+package fileRevisions
+
+import "context"
+
+// FileRevisionsRepository : changed feed files waiting to be pushed again to redis.
+type FileRevisionsRepository interface {
+	Save(ctx context.Context, t FileRevisions) (int, error)
+	GetPendingRevisions(ctx context.Context, status string) ([]FileRevisions, error)
+	UpdateRevisionStatus(ctx context.Context, status, fileRevisionID string) (int64, error)
+}
@@ -0,0 +1,28 @@
+package fileRevisions
+
+// CREATE TABLE `file_revisions` (
+// 	`file_revision_id` bigint(20) NOT NULL AUTO_INCREMENT,
+// 	`file_type` enum('odds','wo','ls') NOT NULL,
+// 	`country` varchar(50) NOT NULL,
+// 	`ext_id` varchar(30) NOT NULL,
+// 	`revision` int(11) NOT NULL,
+// 	`sha256` char(64) NOT NULL,
+// 	`file_size` bigint(20) NOT NULL,
+// 	`status` enum('pending','processed') NOT NULL,
+// 	`created` datetime NOT NULL,
+// 	`modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+// FileRevisions : a feed file re-delivered with a different content. ExtID is the
+// parent match id for odds and the round id for wo and ls.
+type FileRevisions struct {
+	FileRevisionID string
+	FileType       string
+	Country        string
+	ExtID          string
+	Revision       int
+	Sha256         string
+	FileSize       int64
+	Status         string
+	Created        string
+	Modified       string
+}
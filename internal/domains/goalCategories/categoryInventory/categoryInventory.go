@@ -0,0 +1,50 @@
+package categoryInventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
+)
+
+var _ goalCategories.GoalCategoriesRepository = (*CategoryInventoryConfigs)(nil)
+
+// CategoryInventoryConfigs : redis the category sets are saved in.
+type CategoryInventoryConfigs struct {
+	redisConn processRedis.RunRedis
+}
+
+// New initializes the inventory over an open redis connection.
+func New(redisConn processRedis.RunRedis) (*CategoryInventoryConfigs, error) {
+	if redisConn == nil {
+		return nil, fmt.Errorf("redisConn not set")
+	}
+
+	return &CategoryInventoryConfigs{redisConn: redisConn}, nil
+}
+
+// Inventory : zcard of every category set of every consumer.
+func (s *CategoryInventoryConfigs) Inventory(ctx context.Context, cc []goalCategories.Consumer) ([]goalCategories.Inventory, error) {
+
+	ii := []goalCategories.Inventory{}
+	for _, c := range cc {
+		for _, category := range c.Categories {
+
+			set := goalCategories.Key(c.Prefix, category)
+			count, err := s.redisConn.SortedSetLen(ctx, set)
+			if err != nil {
+				return ii, fmt.Errorf("err : %v failed to get zcard for key %s ", err, set)
+			}
+
+			ii = append(ii, goalCategories.Inventory{
+				Consumer: c.Name,
+				Category: category,
+				Set:      set,
+				Count:    count,
+			})
+		}
+	}
+
+	return ii, nil
+}
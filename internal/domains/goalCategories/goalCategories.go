@@ -0,0 +1,189 @@
+package goalCategories
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Category : the narrowest category of a score, the one rounds are drawn from. No goals
+// is 0, one goal is 1_h or 1_a, up to 6 goals it is the total, whether both teams scored
+// and the result, like 3_gg_h. Matches of MaxGoals and more are 7.
+func Category(home, away int) string {
+
+	total := home + away
+
+	switch {
+	case total == 0:
+		return "0"
+	case total == 1:
+		return fmt.Sprintf("%d_%s", total, result(home, away))
+	case total >= MaxGoals:
+		return strconv.Itoa(MaxGoals)
+	}
+
+	return fmt.Sprintf("%d_%s_%s", total, goals(home, away), result(home, away))
+}
+
+// Categories : every category a match with this score is filed under, over or under
+// 2.5 goals first, then from the total goals down to Category.
+func Categories(home, away int) []string {
+
+	total := home + away
+
+	cc := []string{Under25}
+	if total > 2 {
+		cc = []string{Over25}
+	}
+
+	switch {
+	case total == 0:
+		cc = append(cc, "0", Under15)
+	case total == 1:
+		cc = append(cc, "1", Under15, Category(home, away))
+	case total >= MaxGoals:
+		cc = append(cc, Category(home, away))
+	default:
+		cc = append(cc, strconv.Itoa(total),
+			fmt.Sprintf("%d_%s", total, goals(home, away)),
+			Category(home, away))
+	}
+
+	return cc
+}
+
+// Key : sorted set of a category of set, set itself when category is empty.
+func Key(set, category string) string {
+	if category == "" {
+		return set
+	}
+	return fmt.Sprintf("%s_%s", set, category)
+}
+
+// Keys : set and every category set of set a match with this score is saved in.
+func Keys(set string, home, away int) []string {
+	kk := []string{set}
+	for _, c := range Categories(home, away) {
+		kk = append(kk, Key(set, c))
+	}
+	return kk
+}
+
+// Sets : set and all its category sets, the sets a used match is dropped from.
+func Sets(set string) []string {
+	ss := []string{set}
+	for _, c := range All() {
+		ss = append(ss, Key(set, c))
+	}
+	return ss
+}
+
+// ClaimKey : cleaned up copy of a category set production keys draws rounds from.
+func ClaimKey(key string) string {
+	return fmt.Sprintf("%s_%s", claimPrefix, key)
+}
+
+// PlayerClaimKey : cleaned up copy of a category set the rounds of a player are drawn from.
+func PlayerClaimKey(playerID, key string) string {
+	return fmt.Sprintf("%s_%s_%s", claimPrefix, playerID, key)
+}
+
+// All : every category a match can be filed under.
+func All() []string {
+	cc := []string{}
+	seen := make(map[string]bool)
+	for _, s := range scores() {
+		for _, c := range Categories(s.Home, s.Away) {
+			if !seen[c] {
+				seen[c] = true
+				cc = append(cc, c)
+			}
+		}
+	}
+	return cc
+}
+
+// Finals : every category rounds are drawn from.
+func Finals() []string {
+	cc := []string{}
+	seen := make(map[string]bool)
+	for _, s := range scores() {
+		c := Category(s.Home, s.Away)
+		if !seen[c] {
+			seen[c] = true
+			cc = append(cc, c)
+		}
+	}
+	return cc
+}
+
+// Consumers : the sets of set every daemon reads, and the CL_ sets of each player.
+func Consumers(set string, playerIDs ...string) []Consumer {
+
+	cc := []Consumer{
+		{Name: Sanitized, Prefix: set, Categories: append([]string{""}, All()...)},
+		{Name: Production, Prefix: ClaimKey(set), Categories: Finals()},
+	}
+
+	for _, p := range playerIDs {
+		cc = append(cc, Consumer{Name: Instant, Prefix: PlayerClaimKey(p, set), Categories: Finals()})
+	}
+
+	return cc
+}
+
+// ParseRawScores : scores of a goal distribution, saved as
+// 52824375#1#2**52824376#1#1**52824377#2#1. Entries not made of three parts are skipped.
+func ParseRawScores(rawScores string) ([]Score, error) {
+
+	ss := []Score{}
+	for _, cc := range strings.Split(rawScores, "**") {
+
+		goals := strings.Split(cc, "#")
+		if len(goals) != 3 {
+			continue
+		}
+
+		home, err := strconv.Atoi(goals[1])
+		if err != nil {
+			return ss, fmt.Errorf("err : %v failed to convert hsc to int", err)
+		}
+
+		away, err := strconv.Atoi(goals[2])
+		if err != nil {
+			return ss, fmt.Errorf("err : %v failed to convert asc to int", err)
+		}
+
+		ss = append(ss, Score{Home: home, Away: away})
+	}
+
+	return ss, nil
+}
+
+// scores : one score of every category, fewer goals first and the home side first.
+func scores() []Score {
+	ss := []Score{}
+	for total := 0; total <= MaxGoals; total++ {
+		for home := total; home >= 0; home-- {
+			ss = append(ss, Score{Home: home, Away: total - home})
+		}
+	}
+	return ss
+}
+
+func goals(home, away int) string {
+	if home > 0 && away > 0 {
+		return GoalGoal
+	}
+	return NoGoal
+}
+
+func result(home, away int) string {
+	switch {
+	case home > away:
+		return Home
+	case away > home:
+		return Away
+	}
+	return Draw
+}
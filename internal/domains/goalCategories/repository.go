@@ -0,0 +1,8 @@
+package goalCategories
+
+import "context"
+
+// GoalCategoriesRepository : live count of the category sets.
+type GoalCategoriesRepository interface {
+	Inventory(ctx context.Context, cc []Consumer) ([]Inventory, error)
+}
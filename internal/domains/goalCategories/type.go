@@ -0,0 +1,61 @@
+package goalCategories
+
+// DefaultSet : sorted set every sanitized match is saved in, the category sets are named after it.
+const DefaultSet = "SANITIZED_ODDS"
+
+// Suffixes the category sets are named with.
+const (
+	Over25   = "TGO25"
+	Under25  = "TGU25"
+	Under15  = "TGU15"
+	GoalGoal = "gg"
+	NoGoal   = "ng"
+	Home     = "h"
+	Away     = "a"
+	Draw     = "d"
+)
+
+// MaxGoals : matches with this many goals or more share one category.
+const MaxGoals = 7
+
+// claimPrefix : cleaned up copies of the categories rounds are drawn from.
+const claimPrefix = "CL"
+
+// Consumers of the category sets.
+const (
+	// Sanitized : sets the goals, prepare keys and prepare instant keys daemons fill.
+	Sanitized = "sanitized"
+	// Production : CL_ sets production keys draws rounds from.
+	Production = "production"
+	// Instant : CL_<player>_ sets the instant game server draws a player's rounds from.
+	Instant = "instant"
+)
+
+// Score : final score of a match.
+type Score struct {
+	Home int
+	Away int
+}
+
+// Consumer : a daemon reading category sets, the key its sets start with and the
+// categories it reads.
+type Consumer struct {
+	Name       string
+	Prefix     string
+	Categories []string
+}
+
+// Inventory : live count of a category set.
+type Inventory struct {
+	Consumer string `json:"consumer"`
+	Category string `json:"category"`
+	Set      string `json:"set"`
+	Count    int    `json:"count"`
+}
+
+// InventoryAPI : category inventory api
+type InventoryAPI struct {
+	StatusCode        string      `json:"status_code"`
+	StatusDescription string      `json:"status_description"`
+	Inventory         []Inventory `json:"inventory"`
+}
@@ -0,0 +1,107 @@
+package driftReport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalDrifts"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults"
+)
+
+var _ goalDrifts.GoalDriftsRepository = (*DriftReportConfigs)(nil)
+
+// DriftReportConfigs : where published and historical results are read from.
+type DriftReportConfigs struct {
+	weekResults weekResults.WeekResultsRepository
+	mrsMysql    mrs.MrsRepository
+	seasonWeeks seasonWeeks.SeasonWeeksRepository
+	bounds      goalDrifts.Bounds
+}
+
+// New initializes the report over the results saved for every published season week,
+// the mrs history and the season weeks.
+func New(wr weekResults.WeekResultsRepository, mrsMysql mrs.MrsRepository, sw seasonWeeks.SeasonWeeksRepository, bounds goalDrifts.Bounds) (*DriftReportConfigs, error) {
+	if wr == nil {
+		return nil, fmt.Errorf("weekResults not set")
+	}
+
+	if mrsMysql == nil {
+		return nil, fmt.Errorf("mrsMysql not set")
+	}
+
+	if sw == nil {
+		return nil, fmt.Errorf("seasonWeeks not set")
+	}
+
+	return &DriftReportConfigs{
+		weekResults: wr,
+		mrsMysql:    mrsMysql,
+		seasonWeeks: sw,
+		bounds:      bounds,
+	}, nil
+}
+
+// Report : season weeks of competitionID published from from up to to, against every
+// result of the competition in mrs.
+func (s *DriftReportConfigs) Report(ctx context.Context, competitionID, from, to string) (goalDrifts.Report, error) {
+
+	rp := goalDrifts.Report{
+		CompetitionID: competitionID,
+		From:          from,
+		To:            to,
+		Bounds:        s.bounds,
+		Published:     goalDrifts.NewDistribution(),
+		Historical:    goalDrifts.NewDistribution(),
+		CreatedAt:     time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	history, err := s.mrsMysql.RawScores(ctx, competitionID)
+	if err != nil {
+		return rp, fmt.Errorf("err : %v failed to read mrs of competition %s", err, competitionID)
+	}
+
+	for _, h := range history {
+		scores, err := goalCategories.ParseRawScores(h.RawScores)
+		if err != nil {
+			log.Printf("Err : %v failed to parse raw scores of round %d", err, h.RoundNumberID)
+			continue
+		}
+		for _, sc := range scores {
+			rp.Historical.Add(sc.Home, sc.Away)
+		}
+	}
+
+	weeks, err := s.seasonWeeks.PublishedSsnWeeks(ctx, competitionID, from, to)
+	if err != nil {
+		return rp, fmt.Errorf("err : %v failed to read season weeks of competition %s", err, competitionID)
+	}
+
+	for _, w := range weeks {
+
+		rp.SeasonWeeks++
+
+		results, err := s.weekResults.GetSeasonWeekResults(ctx, w.SeasonWeekID)
+		if err != nil {
+			return rp, fmt.Errorf("err : %v failed to read results of season week %s", err, w.SeasonWeekID)
+		}
+
+		if len(results) == 0 {
+			log.Printf("Err : no results saved for season week %s", w.SeasonWeekID)
+			rp.MissingWeeks++
+			continue
+		}
+
+		for _, m := range results {
+			rp.Published.Add(m.HomeScore, m.AwayScore)
+		}
+	}
+
+	goalDrifts.Evaluate(&rp)
+
+	return rp, nil
+}
@@ -0,0 +1,267 @@
+package goalDrifts
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+)
+
+// NewBounds : validates the drift tolerated.
+func NewBounds(goalsPerMatch, rate, alpha float64, minMatches int) (*Bounds, error) {
+
+	if goalsPerMatch <= 0 {
+		return nil, fmt.Errorf("goals per match bound must be above 0, %v set", goalsPerMatch)
+	}
+
+	if rate <= 0 || rate >= 1 {
+		return nil, fmt.Errorf("rate bound %v must be between 0 and 1", rate)
+	}
+
+	if alpha <= 0 || alpha >= 1 {
+		return nil, fmt.Errorf("alpha %v must be between 0 and 1", alpha)
+	}
+
+	if minMatches < 1 {
+		return nil, fmt.Errorf("at least 1 match is needed, %d set", minMatches)
+	}
+
+	return &Bounds{
+		GoalsPerMatch: goalsPerMatch,
+		Rate:          rate,
+		Alpha:         alpha,
+		MinMatches:    minMatches,
+	}, nil
+}
+
+// DefaultDays : days reported on when from is not set.
+const DefaultDays = 30
+
+// Window : validates the days reported on, yyyy-mm-dd. from defaults to DefaultDays ago
+// and to, which is excluded, to tomorrow.
+func Window(from, to string) (string, string, error) {
+
+	now := time.Now()
+
+	if to == "" {
+		to = now.AddDate(0, 0, 1).Format("2006-01-02")
+	}
+
+	if from == "" {
+		from = now.AddDate(0, 0, -DefaultDays).Format("2006-01-02")
+	}
+
+	fromDay, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return from, to, fmt.Errorf("from %q is not a yyyy-mm-dd date", from)
+	}
+
+	toDay, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return from, to, fmt.Errorf("to %q is not a yyyy-mm-dd date", to)
+	}
+
+	if !fromDay.Before(toDay) {
+		return from, to, fmt.Errorf("from %s must be before to %s", from, to)
+	}
+
+	return from, to, nil
+}
+
+// NewDistribution : empty sample.
+func NewDistribution() Distribution {
+	return Distribution{GoalCounts: make([]int, MaxGoals+1)}
+}
+
+// Add : counts the final score of one match.
+func (d *Distribution) Add(home, away int) {
+
+	total := home + away
+
+	d.Matches++
+	d.Goals += total
+	d.GoalCounts[min(total, MaxGoals)]++
+
+	switch {
+	case home > away:
+		d.Home++
+	case away > home:
+		d.Away++
+	default:
+		d.Draw++
+	}
+
+	if home > 0 && away > 0 {
+		d.GoalGoal++
+	}
+
+	if total > 2 {
+		d.Over25++
+	}
+}
+
+// rate : share of the matches of d that count is.
+func (d Distribution) rate(count int) float64 {
+	if d.Matches == 0 {
+		return 0
+	}
+	return float64(count) / float64(d.Matches)
+}
+
+// Evaluate : sets the metrics and tests of the published sample of rp against its
+// historical one. Drift is only flagged once both have at least MinMatches matches.
+func Evaluate(rp *Report) {
+
+	published, historical, b := rp.Published, rp.Historical, rp.Bounds
+
+	rp.Metrics = []Metric{
+		metric(GoalsPerMatch, published.rate(published.Goals), historical.rate(historical.Goals), b.GoalsPerMatch),
+		metric(HomeRate, published.rate(published.Home), historical.rate(historical.Home), b.Rate),
+		metric(DrawRate, published.rate(published.Draw), historical.rate(historical.Draw), b.Rate),
+		metric(AwayRate, published.rate(published.Away), historical.rate(historical.Away), b.Rate),
+		metric(GoalGoalRate, published.rate(published.GoalGoal), historical.rate(historical.GoalGoal), b.Rate),
+		metric(Over25Rate, published.rate(published.Over25), historical.rate(historical.Over25), b.Rate),
+	}
+
+	rp.Tests = []Test{
+		chiSquare(GoalsChiSquare, published.GoalCounts, historical.GoalCounts),
+		chiSquare(ResultChiSquare, []int{published.Home, published.Draw, published.Away},
+			[]int{historical.Home, historical.Draw, historical.Away}),
+		kolmogorovSmirnov(GoalsKS, published.GoalCounts, historical.GoalCounts),
+	}
+
+	rp.Sufficient = published.Matches >= b.MinMatches && historical.Matches >= b.MinMatches
+	rp.Drifted = false
+	if !rp.Sufficient {
+		return
+	}
+
+	for i := range rp.Metrics {
+		rp.Metrics[i].Drifted = math.Abs(rp.Metrics[i].Difference) > rp.Metrics[i].Bound
+		if rp.Metrics[i].Drifted {
+			rp.Drifted = true
+		}
+	}
+
+	for i := range rp.Tests {
+		rp.Tests[i].Drifted = rp.Tests[i].PValue < b.Alpha
+		if rp.Tests[i].Drifted {
+			rp.Drifted = true
+		}
+	}
+}
+
+func metric(name string, published, historical, bound float64) Metric {
+	return Metric{
+		Name:       name,
+		Published:  published,
+		Historical: historical,
+		Difference: published - historical,
+		Bound:      bound,
+	}
+}
+
+// chiSquare : chi square test of homogeneity of two samples counted in the same cells.
+// Neighbouring cells are pooled until every expected count is at least 5.
+func chiSquare(name string, a, b []int) Test {
+
+	totalA, totalB := sum(a), sum(b)
+	total := totalA + totalB
+
+	t := Test{Name: name, PValue: 1}
+	if totalA == 0 || totalB == 0 {
+		return t
+	}
+
+	smallest := float64(min(totalA, totalB))
+
+	pooledA, pooledB := []int{}, []int{}
+	cellA, cellB := 0, 0
+	for i := range a {
+		cellA += a[i]
+		cellB += b[i]
+		if float64(cellA+cellB)*smallest/float64(total) >= 5 {
+			pooledA = append(pooledA, cellA)
+			pooledB = append(pooledB, cellB)
+			cellA, cellB = 0, 0
+		}
+	}
+
+	// What is left is too small for a cell of its own.
+	if cellA+cellB > 0 && len(pooledA) > 0 {
+		pooledA[len(pooledA)-1] += cellA
+		pooledB[len(pooledB)-1] += cellB
+	}
+
+	if len(pooledA) < 2 {
+		return t
+	}
+
+	for i := range pooledA {
+		column := float64(pooledA[i] + pooledB[i])
+		expectedA := column * float64(totalA) / float64(total)
+		expectedB := column * float64(totalB) / float64(total)
+		t.Statistic += math.Pow(float64(pooledA[i])-expectedA, 2)/expectedA +
+			math.Pow(float64(pooledB[i])-expectedB, 2)/expectedB
+	}
+
+	t.DegreesOfFreedom = len(pooledA) - 1
+	t.PValue = rngs.ChiSquarePValue(t.Statistic, t.DegreesOfFreedom)
+
+	return t
+}
+
+// kolmogorovSmirnov : two sample Kolmogorov Smirnov test over the cumulative goal counts.
+// Goals are discrete so the asymptotic p value is conservative.
+func kolmogorovSmirnov(name string, a, b []int) Test {
+
+	totalA, totalB := sum(a), sum(b)
+
+	t := Test{Name: name, PValue: 1}
+	if totalA == 0 || totalB == 0 {
+		return t
+	}
+
+	cumulativeA, cumulativeB := 0, 0
+	for i := range a {
+		cumulativeA += a[i]
+		cumulativeB += b[i]
+		d := math.Abs(float64(cumulativeA)/float64(totalA) - float64(cumulativeB)/float64(totalB))
+		t.Statistic = math.Max(t.Statistic, d)
+	}
+
+	n := math.Sqrt(float64(totalA) * float64(totalB) / float64(totalA+totalB))
+	t.PValue = kolmogorovQ((n + 0.12 + 0.11/n) * t.Statistic)
+
+	return t
+}
+
+// kolmogorovQ : probability of the Kolmogorov distribution exceeding lambda.
+func kolmogorovQ(lambda float64) float64 {
+
+	if lambda < 1e-3 {
+		return 1
+	}
+
+	q := 0.0
+	sign := 1.0
+	for j := 1; j <= 100; j++ {
+		term := sign * 2 * math.Exp(-2*float64(j*j)*lambda*lambda)
+		q += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+
+	return math.Min(math.Max(q, 0), 1)
+}
+
+func sum(cc []int) int {
+	total := 0
+	for _, c := range cc {
+		total += c
+	}
+	return total
+}
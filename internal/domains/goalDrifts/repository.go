@@ -0,0 +1,8 @@
+package goalDrifts
+
+import "context"
+
+// GoalDriftsRepository : compares the results published for a competition with its history.
+type GoalDriftsRepository interface {
+	Report(ctx context.Context, competitionID, from, to string) (Report, error)
+}
@@ -0,0 +1,88 @@
+package goalDrifts
+
+// Metrics compared between published and historical results.
+const (
+	GoalsPerMatch = "goals_per_match"
+	HomeRate      = "home_rate"
+	DrawRate      = "draw_rate"
+	AwayRate      = "away_rate"
+	GoalGoalRate  = "gg_rate"
+	Over25Rate    = "over_2_5_rate"
+)
+
+// Tests run between published and historical results.
+const (
+	GoalsChiSquare  = "goals_chi_square"
+	ResultChiSquare = "result_chi_square"
+	GoalsKS         = "goals_ks"
+)
+
+// MaxGoals : matches with more goals are counted in the last cell of GoalCounts.
+const MaxGoals = 6
+
+// Distribution : results of a sample of matches. GoalCounts holds the number of matches
+// with 0 up to MaxGoals goals.
+type Distribution struct {
+	Matches    int   `json:"matches"`
+	Goals      int   `json:"goals"`
+	Home       int   `json:"home"`
+	Draw       int   `json:"draw"`
+	Away       int   `json:"away"`
+	GoalGoal   int   `json:"gg"`
+	Over25     int   `json:"over_2_5"`
+	GoalCounts []int `json:"goal_counts"`
+}
+
+// Bounds : drift tolerated before a report is flagged. GoalsPerMatch and Rate are the
+// largest absolute differences allowed, a test drifts when its p value is below Alpha.
+// Nothing is flagged while either sample has fewer than MinMatches matches.
+type Bounds struct {
+	GoalsPerMatch float64 `json:"goals_per_match"`
+	Rate          float64 `json:"rate"`
+	Alpha         float64 `json:"alpha"`
+	MinMatches    int     `json:"min_matches"`
+}
+
+// Metric : one metric of both samples.
+type Metric struct {
+	Name       string  `json:"name"`
+	Published  float64 `json:"published"`
+	Historical float64 `json:"historical"`
+	Difference float64 `json:"difference"`
+	Bound      float64 `json:"bound"`
+	Drifted    bool    `json:"drifted"`
+}
+
+// Test : one test of the published sample against the historical one.
+type Test struct {
+	Name             string  `json:"name"`
+	Statistic        float64 `json:"statistic"`
+	DegreesOfFreedom int     `json:"degrees_of_freedom,omitempty"`
+	PValue           float64 `json:"p_value"`
+	Drifted          bool    `json:"drifted"`
+}
+
+// Report : published season weeks of a competition against its historical results.
+// MissingWeeks are published weeks whose winning outcomes could not be read.
+type Report struct {
+	CompetitionID string       `json:"competition_id"`
+	From          string       `json:"from"`
+	To            string       `json:"to"`
+	SeasonWeeks   int          `json:"season_weeks"`
+	MissingWeeks  int          `json:"missing_weeks"`
+	Bounds        Bounds       `json:"bounds"`
+	Published     Distribution `json:"published"`
+	Historical    Distribution `json:"historical"`
+	Metrics       []Metric     `json:"metrics"`
+	Tests         []Test       `json:"tests"`
+	Sufficient    bool         `json:"sufficient"`
+	Drifted       bool         `json:"drifted"`
+	CreatedAt     string       `json:"created_at"`
+}
+
+// DriftAPI : drift report api
+type DriftAPI struct {
+	StatusCode        string `json:"status_code"`
+	StatusDescription string `json:"status_description"`
+	Report            Report `json:"report"`
+}
@@ -2,11 +2,16 @@ package goalPatterns
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
 )
 
 // NewGoalPatterns instantiate goalPatterns
-func NewGoalPatterns(seasonID, roundNumberID, competitionID string) (*GoalPatterns, error) {
+func NewGoalPatterns(seasonID, roundNumberID, competitionID, strategy string) (*GoalPatterns, error) {
 
 	if seasonID == "" {
 		return &GoalPatterns{}, fmt.Errorf("seasonID not set")
@@ -20,6 +25,10 @@ func NewGoalPatterns(seasonID, roundNumberID, competitionID string) (*GoalPatter
 		return &GoalPatterns{}, fmt.Errorf("competitionID alias not set")
 	}
 
+	if !validStrategy(strategy) {
+		return &GoalPatterns{}, fmt.Errorf("strategy %q not supported", strategy)
+	}
+
 	created := time.Now().Format("2006-01-02 15:04:05")
 	modified := time.Now().Format("2006-01-02 15:04:05")
 
@@ -27,7 +36,229 @@ func NewGoalPatterns(seasonID, roundNumberID, competitionID string) (*GoalPatter
 		SeasonID:      seasonID,
 		RoundNumberID: roundNumberID,
 		CompetitionID: competitionID,
+		Strategy:      strategy,
+		Status:        Active,
 		Created:       created,
 		Modified:      modified,
 	}, nil
 }
+
+// NewSettings : validates how the patterns of a competition are built.
+func NewSettings(competitionID string, seasonLength int, strategy string, retireDays int) (*Settings, error) {
+
+	if competitionID == "" {
+		return nil, fmt.Errorf("competitionID not set")
+	}
+
+	if seasonLength < 2 {
+		return nil, fmt.Errorf("season length of competition %s must be at least 2, %d set", competitionID, seasonLength)
+	}
+
+	if !validStrategy(strategy) {
+		return nil, fmt.Errorf("strategy %q of competition %s not supported", strategy, competitionID)
+	}
+
+	if retireDays < 0 {
+		return nil, fmt.Errorf("retire days of competition %s must not be negative, %d set", competitionID, retireDays)
+	}
+
+	return &Settings{
+		CompetitionID: competitionID,
+		SeasonLength:  seasonLength,
+		Strategy:      strategy,
+		RetireDays:    retireDays,
+	}, nil
+}
+
+// DefaultSettings : 38 round seasons of shuffled rounds for competitions 1 to 4, retired
+// after DefaultRetireDays.
+func DefaultSettings() []Settings {
+	ss := []Settings{}
+	for _, c := range []string{"1", "2", "3", "4"} {
+		ss = append(ss, Settings{CompetitionID: c, SeasonLength: DefaultSeasonLength, Strategy: Shuffle, RetireDays: DefaultRetireDays})
+	}
+	return ss
+}
+
+func validStrategy(strategy string) bool {
+	return strategy == Shuffle || strategy == Stratified || strategy == Chronological
+}
+
+// InUse : rounds of the active patterns of pp.
+func InUse(pp []GoalPatterns) (map[int]bool, error) {
+	used := make(map[int]bool)
+	for _, p := range pp {
+
+		if p.Status != "" && p.Status != Active {
+			continue
+		}
+
+		for _, id := range strings.Split(p.RoundNumberID, ",") {
+			rn, err := strconv.Atoi(strings.TrimSpace(id))
+			if err != nil {
+				return used, fmt.Errorf("round %q of pattern %s not valid", id, p.GoalPatternID)
+			}
+			used[rn] = true
+		}
+	}
+	return used, nil
+}
+
+// Fits : whether patterns gg can still be saved next to the patterns pp of their
+// competition. None of their rounds may be in an active pattern and their seasons must come
+// after every season of pp, a build previewed before other patterns were saved does not fit.
+func Fits(pp, gg []GoalPatterns) error {
+
+	used, err := InUse(pp)
+	if err != nil {
+		return err
+	}
+
+	lastSeasonID := 0
+	for _, p := range pp {
+		id, err := strconv.Atoi(p.SeasonID)
+		if err == nil && id > lastSeasonID {
+			lastSeasonID = id
+		}
+	}
+
+	for _, g := range gg {
+
+		id, err := strconv.Atoi(g.SeasonID)
+		if err != nil || id <= lastSeasonID {
+			return fmt.Errorf("season %s is already saved, last season is %d", g.SeasonID, lastSeasonID)
+		}
+
+		for _, x := range strings.Split(g.RoundNumberID, ",") {
+			rn, err := strconv.Atoi(strings.TrimSpace(x))
+			if err != nil {
+				return fmt.Errorf("round %q of season %s not valid", x, g.SeasonID)
+			}
+			if used[rn] {
+				return fmt.Errorf("round %d of season %s is in an active pattern", rn, g.SeasonID)
+			}
+			used[rn] = true
+		}
+	}
+
+	return nil
+}
+
+// BuildSeasons : puts the rounds not in use into seasons of st.SeasonLength rounds with
+// st.Strategy. Seasons are numbered from firstSeasonID, every round is in one season at
+// most and rounds that do not fill a season are left over.
+func BuildSeasons(rounds []Round, used map[int]bool, st Settings, firstSeasonID int, r rngs.RngsRepository) (Build, error) {
+
+	b := Build{Settings: st}
+
+	seen := make(map[int]bool)
+	free := []Round{}
+	for _, rd := range rounds {
+		if seen[rd.RoundNumberID] {
+			continue
+		}
+		seen[rd.RoundNumberID] = true
+
+		if used[rd.RoundNumberID] {
+			b.InUse++
+			continue
+		}
+		free = append(free, rd)
+	}
+
+	count := len(free) / st.SeasonLength
+
+	var ss [][]Round
+	switch st.Strategy {
+	case Shuffle:
+		shuffle(free, r)
+		ss, b.Leftover = chunk(free, st.SeasonLength, count)
+	case Chronological:
+		sort.SliceStable(free, func(i, j int) bool {
+			if free[i].StartTime != free[j].StartTime {
+				return free[i].StartTime < free[j].StartTime
+			}
+			return free[i].RoundNumberID < free[j].RoundNumberID
+		})
+		ss, b.Leftover = chunk(free, st.SeasonLength, count)
+	case Stratified:
+		ss, b.Leftover = stratify(free, st.SeasonLength, count, r)
+	default:
+		return b, fmt.Errorf("strategy %q not supported", st.Strategy)
+	}
+
+	for i, rr := range ss {
+		b.Seasons = append(b.Seasons, Season{
+			SeasonID: fmt.Sprintf("%d", firstSeasonID+i),
+			Rounds:   rr,
+		})
+	}
+
+	return b, nil
+}
+
+// RoundNumberIDs : rounds of a season the way patterns save them, 1,2,3.
+func (s Season) RoundNumberIDs() string {
+	ids := []string{}
+	for _, rd := range s.Rounds {
+		ids = append(ids, fmt.Sprintf("%d", rd.RoundNumberID))
+	}
+	return strings.Join(ids, ",")
+}
+
+// Curve : goals scored by the end of every week of a season.
+func (s Season) Curve() []int {
+	cc := []int{}
+	total := 0
+	for _, rd := range s.Rounds {
+		total += rd.Goals
+		cc = append(cc, total)
+	}
+	return cc
+}
+
+// shuffle : Fisher–Yates shuffle of rr with r.
+func shuffle(rr []Round, r rngs.RngsRepository) {
+	for i := len(rr) - 1; i > 0; i-- {
+		j := r.IntN(i + 1)
+		rr[i], rr[j] = rr[j], rr[i]
+	}
+}
+
+// chunk : the first count seasons of length rounds of rr, and what is left.
+func chunk(rr []Round, length, count int) ([][]Round, []Round) {
+	ss := [][]Round{}
+	for i := 0; i < count; i++ {
+		ss = append(ss, rr[i*length:(i+1)*length])
+	}
+	return ss, rr[count*length:]
+}
+
+// stratify : rounds sorted by goals are cut into length strata of count rounds, every
+// season takes one round of each stratum so that all of them score alike. The weeks of a
+// season are shuffled so that goals do not rise through it.
+func stratify(rr []Round, length, count int, r rngs.RngsRepository) ([][]Round, []Round) {
+
+	// Which rounds are left over is drawn too.
+	shuffle(rr, r)
+	picked, leftover := rr[:count*length], rr[count*length:]
+
+	sort.SliceStable(picked, func(i, j int) bool {
+		return picked[i].Goals < picked[j].Goals
+	})
+
+	ss := make([][]Round, count)
+	for k := 0; k < length; k++ {
+		stratum := picked[k*count : (k+1)*count]
+		shuffle(stratum, r)
+		for i := range ss {
+			ss[i] = append(ss[i], stratum[i])
+		}
+	}
+
+	for i := range ss {
+		shuffle(ss[i], r)
+	}
+
+	return ss, leftover
+}
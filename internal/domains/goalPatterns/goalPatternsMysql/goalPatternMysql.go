@@ -38,9 +38,9 @@ func New(connectionString string) (*MysqlRepository, error) {
 // Save :
 func (mr *MysqlRepository) Save(ctx context.Context, t goalPatterns.GoalPatterns) (int, error) {
 	var d int
-	rs, err := mr.db.Exec("INSERT goal_patterns SET season_id=?,round_number_id=?,competition_id=?, \n"+
+	rs, err := mr.db.Exec("INSERT goal_patterns SET season_id=?,round_number_id=?,competition_id=?,strategy=?,status=?, \n"+
 		"created=now(),modified=now() ON DUPLICATE KEY UPDATE modified=now()",
-		t.SeasonID, t.RoundNumberID, t.CompetitionID)
+		t.SeasonID, t.RoundNumberID, t.CompetitionID, t.Strategy, t.Status)
 
 	if err != nil {
 		return d, fmt.Errorf("unable to save goal_patterns : %v", err)
@@ -54,12 +54,83 @@ func (mr *MysqlRepository) Save(ctx context.Context, t goalPatterns.GoalPatterns
 	return int(lastInsertedID), nil
 }
 
-// GoalDistributions :
+// SaveSeasons : saves the patterns of a build of a competition in one transaction. The
+// patterns of the competition are locked while the build is checked against them, a build
+// that no longer fits them is not saved.
+func (mr *MysqlRepository) SaveSeasons(ctx context.Context, competitionID string, gg []goalPatterns.GoalPatterns) error {
+
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction : %v", err)
+	}
+
+	raws, err := tx.QueryContext(ctx, "select goal_pattern_id,season_id,round_number_id,competition_id,strategy,status \n"+
+		"from goal_patterns where competition_id=? for update", competitionID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("unable to lock goal_patterns of competition %s : %v", competitionID, err)
+	}
+
+	var pp []goalPatterns.GoalPatterns
+	for raws.Next() {
+		var g goalPatterns.GoalPatterns
+		err := raws.Scan(&g.GoalPatternID, &g.SeasonID, &g.RoundNumberID, &g.CompetitionID, &g.Strategy, &g.Status)
+		if err != nil {
+			raws.Close()
+			tx.Rollback()
+			return err
+		}
+		pp = append(pp, g)
+	}
+	raws.Close()
+
+	if err := raws.Err(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = goalPatterns.Fits(pp, gg)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("build of competition %s not saved, preview it again : %v", competitionID, err)
+	}
+
+	for _, t := range gg {
+		_, err = tx.ExecContext(ctx, "INSERT goal_patterns SET season_id=?,round_number_id=?,competition_id=?,strategy=?,status=?, \n"+
+			"created=now(),modified=now()", t.SeasonID, t.RoundNumberID, t.CompetitionID, t.Strategy, t.Status)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("unable to save goal_patterns of season %s : %v", t.SeasonID, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit goal_patterns of competition %s : %v", competitionID, err)
+	}
+
+	return nil
+}
+
+// Retire : retires the active patterns of a competition saved more than days ago, their
+// rounds are free to be built into new seasons.
+func (mr *MysqlRepository) Retire(ctx context.Context, competitionID string, days int) (int64, error) {
+	rs, err := mr.db.ExecContext(ctx, "update goal_patterns set status=?,modified=now() \n"+
+		"where competition_id=? and status=? and created < now() - interval ? day",
+		goalPatterns.Retired, competitionID, goalPatterns.Active, days)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retire goal_patterns of competition %s : %v", competitionID, err)
+	}
+
+	return rs.RowsAffected()
+}
+
+// GoalDistributions : active patterns of a competition.
 func (r *MysqlRepository) GoalDistributions(ctx context.Context, competitionID string) ([]goalPatterns.GoalPatterns, error) {
 	var gc []goalPatterns.GoalPatterns
 
-	statement := fmt.Sprintf("select goal_pattern_id,season_id,round_number_id,competition_id from goal_patterns \n"+
-		"where competition_id = '%s'   ", competitionID)
+	statement := fmt.Sprintf("select goal_pattern_id,season_id,round_number_id,competition_id,strategy,status from goal_patterns \n"+
+		"where competition_id = '%s' and status = '%s' ", competitionID, goalPatterns.Active)
 	raws, err := r.db.Query(statement)
 	if err != nil {
 		return nil, err
@@ -67,7 +138,7 @@ func (r *MysqlRepository) GoalDistributions(ctx context.Context, competitionID s
 
 	for raws.Next() {
 		var g goalPatterns.GoalPatterns
-		err := raws.Scan(&g.GoalPatternID, &g.SeasonID, &g.RoundNumberID, &g.CompetitionID)
+		err := raws.Scan(&g.GoalPatternID, &g.SeasonID, &g.RoundNumberID, &g.CompetitionID, &g.Strategy, &g.Status)
 		if err != nil {
 			return nil, err
 		}
@@ -81,3 +152,15 @@ func (r *MysqlRepository) GoalDistributions(ctx context.Context, competitionID s
 
 	return gc, nil
 }
+
+// LastSeasonID : highest season of the patterns of a competition, 0 when none is saved.
+func (r *MysqlRepository) LastSeasonID(ctx context.Context, competitionID string) (int, error) {
+	var d int
+	err := r.db.QueryRowContext(ctx, "select coalesce(max(cast(season_id as unsigned)),0) from goal_patterns \n"+
+		"where competition_id = ? ", competitionID).Scan(&d)
+	if err != nil {
+		return d, fmt.Errorf("unable to return last season of goal_patterns : %v", err)
+	}
+
+	return d, nil
+}
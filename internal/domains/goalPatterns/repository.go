@@ -5,5 +5,8 @@ import "context"
 // GoalPatternsRepository
 type GoalPatternsRepository interface {
 	Save(ctx context.Context, t GoalPatterns) (int, error)
+	SaveSeasons(ctx context.Context, competitionID string, gg []GoalPatterns) error
+	Retire(ctx context.Context, competitionID string, days int) (int64, error)
 	GoalDistributions(ctx context.Context, competitionID string) ([]GoalPatterns, error)
+	LastSeasonID(ctx context.Context, competitionID string) (int, error)
 }
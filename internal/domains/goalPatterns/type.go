@@ -1,10 +1,62 @@
 package goalPatterns
 
+// Strategies rounds are put into seasons with.
+const (
+	Shuffle       = "shuffle"
+	Stratified    = "stratified"
+	Chronological = "chronological"
+)
+
+// Statuses of a pattern, only active patterns are drawn by the period generators.
+const (
+	Active  = "active"
+	Retired = "retired"
+)
+
+// DefaultSeasonLength : rounds of a season of a 20 team league.
+const DefaultSeasonLength = 38
+
+// DefaultRetireDays : days a pattern stays active before its rounds are built into new seasons.
+const DefaultRetireDays = 30
+
 type GoalPatterns struct {
 	GoalPatternID string
 	SeasonID      string
 	RoundNumberID string
 	CompetitionID string
+	Strategy      string
+	Status        string
 	Created       string
 	Modified      string
 }
+
+// Settings : how the patterns of a competition are built. Patterns are retired RetireDays
+// after they were saved so that their rounds are built into new seasons, never when 0.
+type Settings struct {
+	CompetitionID string `mapstructure:"competition_id" json:"competition_id"`
+	SeasonLength  int    `mapstructure:"season_length" json:"season_length"`
+	Strategy      string `mapstructure:"strategy" json:"strategy"`
+	RetireDays    int    `mapstructure:"retire_days" json:"retire_days"`
+}
+
+// Round : a historical round and the goals scored in it.
+type Round struct {
+	RoundNumberID int    `json:"round_number_id"`
+	StartTime     string `json:"start_time"`
+	Goals         int    `json:"goals"`
+}
+
+// Season : rounds of one pattern in the order their weeks are played.
+type Season struct {
+	SeasonID string  `json:"season_id"`
+	Rounds   []Round `json:"rounds"`
+}
+
+// Build : seasons built for a competition. Leftover rounds wait for enough rounds to
+// fill a season, InUse rounds are in active patterns already and were left out.
+type Build struct {
+	Settings Settings `json:"settings"`
+	Seasons  []Season `json:"seasons"`
+	Leftover []Round  `json:"leftover"`
+	InUse    int      `json:"in_use"`
+}
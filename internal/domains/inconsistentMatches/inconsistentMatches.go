@@ -0,0 +1,52 @@
+package inconsistentMatches
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks"
+)
+
+// NewInconsistentMatch instantiate inconsistentMatches
+func NewInconsistentMatch(parentMatchID, country string, issues []matchChecks.Issue) (*InconsistentMatches, error) {
+
+	if parentMatchID == "" {
+		return &InconsistentMatches{}, fmt.Errorf("parentMatchID not set")
+	}
+
+	if country == "" {
+		return &InconsistentMatches{}, fmt.Errorf("country not set")
+	}
+
+	if len(issues) == 0 {
+		return &InconsistentMatches{}, fmt.Errorf("issues not set")
+	}
+
+	m := make(map[string]bool)
+	codes := []string{}
+	for _, x := range issues {
+		if !m[x.Code] {
+			m[x.Code] = true
+			codes = append(codes, x.Code)
+		}
+	}
+
+	reasons, err := json.Marshal(issues)
+	if err != nil {
+		return &InconsistentMatches{}, fmt.Errorf("err : %v failed to marshal issues", err)
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &InconsistentMatches{
+		ParentMatchID: parentMatchID,
+		Country:       country,
+		Codes:         strings.Join(codes, ","),
+		Reasons:       string(reasons),
+		Created:       created,
+		Modified:      modified,
+	}, nil
+}
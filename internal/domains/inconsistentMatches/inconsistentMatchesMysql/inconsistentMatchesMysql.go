@@ -0,0 +1,84 @@
+package inconsistentMatchesMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/inconsistentMatches"
+)
+
+var _ inconsistentMatches.InconsistentMatchesRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save : a match checked again keeps a single row with the latest reasons.
+func (mr *MysqlRepository) Save(ctx context.Context, t inconsistentMatches.InconsistentMatches) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT inconsistent_matches SET parent_match_id=?,country=?,codes=?,reasons=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE codes=values(codes),reasons=values(reasons),modified=now()",
+		t.ParentMatchID, t.Country, t.Codes, t.Reasons)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save inconsistent match : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last inconsistent match ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// GetInconsistentMatches : matches refused between the given dates
+func (r *MysqlRepository) GetInconsistentMatches(ctx context.Context, fromDate, toDate string) ([]inconsistentMatches.InconsistentMatches, error) {
+	var gc []inconsistentMatches.InconsistentMatches
+	statement := fmt.Sprintf("select inconsistent_match_id,parent_match_id,country,codes,reasons,created,modified \n"+
+		"from inconsistent_matches where modified between '%s' and '%s' order by inconsistent_match_id", fromDate, toDate)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g inconsistentMatches.InconsistentMatches
+		err := raws.Scan(&g.InconsistentMatchID, &g.ParentMatchID, &g.Country, &g.Codes, &g.Reasons,
+			&g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
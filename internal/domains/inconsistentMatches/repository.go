@@ -0,0 +1,9 @@
+package inconsistentMatches
+
+import "context"
+
+// InconsistentMatchesRepository : matches refused by the cross file check.
+type InconsistentMatchesRepository interface {
+	Save(ctx context.Context, t InconsistentMatches) (int, error)
+	GetInconsistentMatches(ctx context.Context, fromDate, toDate string) ([]InconsistentMatches, error)
+}
@@ -0,0 +1,22 @@
+package inconsistentMatches
+
+// CREATE TABLE `inconsistent_matches` (
+// 	`inconsistent_match_id` bigint(20) NOT NULL AUTO_INCREMENT,
+// 	`parent_match_id` varchar(30) NOT NULL,
+// 	`country` varchar(10) NOT NULL,
+// 	`codes` varchar(300) NOT NULL,
+// 	`reasons` text NOT NULL,
+// 	`created` datetime NOT NULL,
+// 	`modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+// InconsistentMatches : a match held back from publishing because its odds, winning
+// outcome and live score files disagree. Reasons is the json list of issues.
+type InconsistentMatches struct {
+	InconsistentMatchID string
+	ParentMatchID       string
+	Country             string
+	Codes               string
+	Reasons             string
+	Created             string
+	Modified            string
+}
@@ -107,6 +107,34 @@ func (r *MysqlRepository) GetLeagueByID(ctx context.Context, leagueID string) ([
 	return gc, nil
 }
 
+// GetLeagueBySeasonWeekID : league a season week belongs to.
+func (r *MysqlRepository) GetLeagueBySeasonWeekID(ctx context.Context, seasonWeekID string) ([]leagues.Leagues, error) {
+	var gc []leagues.Leagues
+	statement := fmt.Sprintf("select l.league_id,l.client_id,l.league,l.league_abbrv,l.created,l.modified from leagues as l \n"+
+		"inner join sn_wks as w on w.league_id = l.league_id where w.season_week_id = '%s' ", seasonWeekID)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g leagues.Leagues
+		err := raws.Scan(&g.LeagueID, &g.ClientID, &g.League, &g.LeagueAbbrv, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
 func (r *MysqlRepository) GetLeagueByClientID(ctx context.Context, clientID string) ([]leagues.Leagues, error) {
 	var gc []leagues.Leagues
 	statement := fmt.Sprintf("select league_id,client_id,league,league_abbrv,created,modified from leagues where client_id = '%s' ",
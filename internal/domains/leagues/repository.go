@@ -8,6 +8,7 @@ type LeaguesRepository interface {
 	GetLeagues(ctx context.Context) ([]Leagues, error)
 	GetLeagueByID(ctx context.Context, leagueID string) ([]Leagues, error)
 	GetLeagueByClientID(ctx context.Context, clientID string) ([]Leagues, error)
+	GetLeagueBySeasonWeekID(ctx context.Context, seasonWeekID string) ([]Leagues, error)
 	UpdateLeague(ctx context.Context, clientID, league, leagueAbbrv, leagueID string) (int64, error)
 
 	GetMatchDetails(ctx context.Context, leagueAbbr string) ([]MatchDetails, error)
@@ -6,7 +6,7 @@ import (
 )
 
 // NewLiveScoreFile instantiate
-func NewLiveScoreFile(lsFileName, lsDir, country, extID, projectID, competitionID string) (*LiveScoreFiles, error) {
+func NewLiveScoreFile(lsFileName, lsDir, country, extID, projectID, competitionID, sha256 string, fileSize int64) (*LiveScoreFiles, error) {
 
 	if lsFileName == "" {
 		return &LiveScoreFiles{}, fmt.Errorf("lsFileName not set")
@@ -32,6 +32,10 @@ func NewLiveScoreFile(lsFileName, lsDir, country, extID, projectID, competitionI
 		return &LiveScoreFiles{}, fmt.Errorf("competitionID not set")
 	}
 
+	if len(sha256) != 64 {
+		return &LiveScoreFiles{}, fmt.Errorf("sha256 not set")
+	}
+
 	created := time.Now().Format("2006-01-02 15:04:05")
 	modified := time.Now().Format("2006-01-02 15:04:05")
 
@@ -42,6 +46,9 @@ func NewLiveScoreFile(lsFileName, lsDir, country, extID, projectID, competitionI
 		ProjectID:     projectID,
 		CompetitionID: competitionID,
 		Country:       country,
+		Sha256:        sha256,
+		FileSize:      fileSize,
+		Revision:      1,
 		Created:       created,
 		Modified:      modified,
 	}, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/liveScoreFiles"
@@ -35,12 +36,12 @@ func New(connectionString string) (*MysqlRepository, error) {
 	}, nil
 }
 
-// Save :
+// Save : 0 is returned when the ext id of the country is already saved
 func (mr *MysqlRepository) Save(ctx context.Context, t liveScoreFiles.LiveScoreFiles) (int, error) {
 	var d int
-	rs, err := mr.db.Exec("INSERT live_scores_files SET ls_file_name=?,ls_dir=?,country=?,ext_id=?, \n"+
-		"project_id=?,competition_id=?,created=now(),modified=now() ON DUPLICATE KEY UPDATE modified=now()",
-		t.LsFileName, t.LsDir, t.Country, t.ExtID, t.ProjectID, t.CompetitionID)
+	rs, err := mr.db.Exec("INSERT IGNORE live_scores_files SET ls_file_name=?,ls_dir=?,country=?,ext_id=?, \n"+
+		"project_id=?,competition_id=?,sha256=?,file_size=?,revision=?,created=now(),modified=now()",
+		t.LsFileName, t.LsDir, t.Country, t.ExtID, t.ProjectID, t.CompetitionID, t.Sha256, t.FileSize, t.Revision)
 
 	if err != nil {
 		return d, fmt.Errorf("unable to save live score files : %v", err)
@@ -117,3 +118,85 @@ func (mr *MysqlRepository) UpdateLsFile(ctx context.Context, lsFileName, lsDir,
 	}
 	return result.RowsAffected()
 }
+
+// GetLsFileByExtID : returns the live score file saved for a round
+func (r *MysqlRepository) GetLsFileByExtID(ctx context.Context, extID, country string) ([]liveScoreFiles.LiveScoreFiles, error) {
+	var gc []liveScoreFiles.LiveScoreFiles
+	statement := fmt.Sprintf("select live_score_file_id,ls_file_name,ls_dir,country,ext_id,project_id,competition_id,\n"+
+		"sha256,file_size,revision,created,modified from live_scores_files where ext_id='%s' and country='%s'",
+		extID, country)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g liveScoreFiles.LiveScoreFiles
+		err := raws.Scan(&g.LiveScoreFileID, &g.LsFileName, &g.LsDir, &g.Country, &g.ExtID, &g.ProjectID, &g.CompetitionID,
+			&g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// GetLsFilesByExtIDs : live score files saved for any of extIDs, in one query
+func (r *MysqlRepository) GetLsFilesByExtIDs(ctx context.Context, extIDs []string, country string) ([]liveScoreFiles.LiveScoreFiles, error) {
+	var gc []liveScoreFiles.LiveScoreFiles
+
+	if len(extIDs) == 0 {
+		return gc, nil
+	}
+
+	args := []interface{}{country}
+	for _, id := range extIDs {
+		args = append(args, id)
+	}
+
+	statement := fmt.Sprintf("select live_score_file_id,ls_file_name,ls_dir,country,ext_id,project_id,competition_id,\n"+
+		"sha256,file_size,revision,created,modified from live_scores_files where country=? and ext_id in (%s)",
+		strings.TrimSuffix(strings.Repeat("?,", len(extIDs)), ","))
+
+	raws, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g liveScoreFiles.LiveScoreFiles
+		err := raws.Scan(&g.LiveScoreFileID, &g.LsFileName, &g.LsDir, &g.Country, &g.ExtID, &g.ProjectID, &g.CompetitionID,
+			&g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+// UpdateLsRevision : saves the content of a re-delivered live score file, only while the
+// row still holds previousSha256
+func (mr *MysqlRepository) UpdateLsRevision(ctx context.Context, lsFileName, lsDir, sha256 string, fileSize int64, revision int, lsFileID, previousSha256 string) (int64, error) {
+	var rs int64
+	result, err := mr.db.Exec("update live_scores_files set ls_file_name=?,ls_dir=?,sha256=?,file_size=?,revision=?,modified=now() \n"+
+		"where live_score_file_id = ? and sha256 = ? ", lsFileName, lsDir, sha256, fileSize, revision, lsFileID, previousSha256)
+	if err != nil {
+		return rs, fmt.Errorf("unable to update live score file revision : %v", err)
+	}
+	return result.RowsAffected()
+}
@@ -8,4 +8,8 @@ type LiveScoreFilesRepository interface {
 	GetLsFiles(ctx context.Context) ([]LiveScoreFiles, error)
 	GetLsFileByID(ctx context.Context, lsFileID string) ([]LiveScoreFiles, error)
 	UpdateLsFile(ctx context.Context, lsFileName, lsDir, country, lsExtID, lsFileID string) (int64, error)
+
+	GetLsFileByExtID(ctx context.Context, extID, country string) ([]LiveScoreFiles, error)
+	GetLsFilesByExtIDs(ctx context.Context, extIDs []string, country string) ([]LiveScoreFiles, error)
+	UpdateLsRevision(ctx context.Context, lsFileName, lsDir, sha256 string, fileSize int64, revision int, lsFileID, previousSha256 string) (int64, error)
 }
@@ -22,6 +22,9 @@ type LiveScoreFiles struct {
 	ExtID           string
 	ProjectID       string
 	CompetitionID   string
+	Sha256          string
+	FileSize        int64
+	Revision        int
 	Created         string
 	Modified        string
 }
@@ -28,6 +28,8 @@ type FileInfo struct {
 	ProjectID     string
 	CompetitionID string
 	LsFileName    string
+	ModTime       time.Time
+	Size          int64
 }
 
 type LsData struct {
@@ -0,0 +1,264 @@
+package marginEngine
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+)
+
+var _ margins.MarginsRepository = (*MarginEngineConfigs)(nil)
+
+// bisections : iterations when solving for the power or the Shin insider share,
+// far more than float64 needs.
+const bisections = 100
+
+// minOdd : lowest price ever offered.
+const minOdd = 1.01
+
+// MarginEngineConfigs : margins keyed by market code, margins.DefaultMarket for the rest.
+type MarginEngineConfigs struct {
+	margins map[string]margins.MarketMargin
+}
+
+// New initializes the margin engine. Markets without a margin, when no default is
+// set, keep the provider prices.
+func New(mm []margins.MarketMargin) (*MarginEngineConfigs, error) {
+
+	if len(mm) == 0 {
+		return nil, fmt.Errorf("margins not set")
+	}
+
+	c := &MarginEngineConfigs{
+		margins: make(map[string]margins.MarketMargin),
+	}
+
+	for _, m := range mm {
+		nm, err := margins.NewMarketMargin(m.Code, m.Method, m.Overround)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := c.margins[nm.Code]; ok {
+			return nil, fmt.Errorf("margin of %s set twice", nm.Code)
+		}
+		c.margins[nm.Code] = *nm
+	}
+
+	return c, nil
+}
+
+// Margin : margin applied to a market.
+func (s *MarginEngineConfigs) Margin(code string) margins.MarketMargin {
+	if m, ok := s.margins[code]; ok {
+		return m
+	}
+	if m, ok := s.margins[margins.DefaultMarket]; ok {
+		m.Code = code
+		return m
+	}
+	return margins.MarketMargin{Code: code, Method: margins.Keep}
+}
+
+// Reprice : converts the provider odds to probabilities, takes the provider margin out
+// then applies the target overround with the method of the market. Odds are floored to
+// 2 decimals so the payout never goes above the target.
+func (s *MarginEngineConfigs) Reprice(ctx context.Context, code string, odds []float64) (margins.Priced, error) {
+
+	m := s.Margin(code)
+
+	p := margins.Priced{
+		Code:            code,
+		Method:          m.Method,
+		TargetOverround: m.Overround,
+	}
+
+	implied := make([]float64, len(odds))
+	for i, o := range odds {
+		if o <= 1 || math.IsNaN(o) || math.IsInf(o, 0) {
+			return p, fmt.Errorf("odd %v of %s is not a valid price", o, code)
+		}
+		implied[i] = 1 / o
+	}
+	p.ProviderOverround = round4(sum(implied))
+
+	if m.Method == margins.Keep || len(odds) < 2 {
+		p.Method = margins.Keep
+		p.Odds = make([]float64, len(odds))
+		for i, o := range odds {
+			p.Odds[i] = floor2(o)
+		}
+		p.Overround, p.Payout = book(p.Odds)
+		return p, nil
+	}
+
+	var fair, target []float64
+	var err error
+
+	switch m.Method {
+	case margins.Power:
+		fair = powerFair(implied)
+		target, err = powerTarget(fair, m.Overround)
+	case margins.Shin:
+		fair = shinFair(implied)
+		target, err = shinTarget(fair, m.Overround)
+	default:
+		fair = proportionalFair(implied)
+		target = proportionalTarget(fair, m.Overround)
+	}
+	if err != nil {
+		return p, fmt.Errorf("Err : %v failed to reprice %s", err, code)
+	}
+
+	p.Odds = make([]float64, len(target))
+	for i, t := range target {
+		p.Odds[i] = math.Max(minOdd, floor2(1/t))
+	}
+	p.Overround, p.Payout = book(p.Odds)
+
+	return p, nil
+}
+
+// proportionalFair : p / booksum
+func proportionalFair(implied []float64) []float64 {
+	b := sum(implied)
+	fair := make([]float64, len(implied))
+	for i, x := range implied {
+		fair[i] = x / b
+	}
+	return fair
+}
+
+// proportionalTarget : q * overround
+func proportionalTarget(fair []float64, overround float64) []float64 {
+	target := make([]float64, len(fair))
+	for i, q := range fair {
+		target[i] = q * overround
+	}
+	return target
+}
+
+// powerFair : q = p^k with k such that the q add up to 1.
+func powerFair(implied []float64) []float64 {
+	k := solve(0.01, 100, func(k float64) float64 {
+		return sumPow(implied, k) - 1
+	})
+	return pow(implied, k)
+}
+
+// powerTarget : r = q^k with k in (0, 1] such that the r add up to the overround.
+func powerTarget(fair []float64, overround float64) ([]float64, error) {
+	if overround >= float64(len(fair)) {
+		return nil, fmt.Errorf("overround %.4f too high for %d outcomes", overround, len(fair))
+	}
+	k := solve(1e-6, 1, func(k float64) float64 {
+		return sumPow(fair, k) - overround
+	})
+	return pow(fair, k), nil
+}
+
+// shinFair : removes the margin with Shin's model, z is the share of insider money
+// such that the fair probabilities add up to 1. A book under 1 has no insiders.
+func shinFair(implied []float64) []float64 {
+	b := sum(implied)
+	if b <= 1 {
+		return proportionalFair(implied)
+	}
+
+	fairAt := func(z float64) []float64 {
+		fair := make([]float64, len(implied))
+		for i, x := range implied {
+			fair[i] = (math.Sqrt(z*z+4*(1-z)*x*x/b) - z) / (2 * (1 - z))
+		}
+		return fair
+	}
+
+	z := solve(0, 0.999, func(z float64) float64 {
+		return sum(fairAt(z)) - 1
+	})
+	return fairAt(z)
+}
+
+// shinTarget : Shin's bookmaker prices r = sqrt(z q + (1-z) q^2) * S, S the sum of the
+// square roots, with z such that the book adds up to the overround.
+func shinTarget(fair []float64, overround float64) ([]float64, error) {
+
+	roots := func(z float64) ([]float64, float64) {
+		r := make([]float64, len(fair))
+		for i, q := range fair {
+			r[i] = math.Sqrt(z*q + (1-z)*q*q)
+		}
+		return r, sum(r)
+	}
+
+	_, maxS := roots(1)
+	if overround > maxS*maxS {
+		return nil, fmt.Errorf("overround %.4f too high for the shin model, at most %.4f", overround, maxS*maxS)
+	}
+
+	z := solve(0, 1, func(z float64) float64 {
+		_, s := roots(z)
+		return s*s - overround
+	})
+
+	r, s := roots(z)
+	for i := range r {
+		r[i] *= s
+	}
+	return r, nil
+}
+
+// solve : root of a monotonic f between lo and hi by bisection.
+func solve(lo, hi float64, f func(float64) float64) float64 {
+	rising := f(hi) > f(lo)
+	for i := 0; i < bisections; i++ {
+		mid := (lo + hi) / 2
+		if (f(mid) > 0) == rising {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// book : overround and payout of rounded odds.
+func book(odds []float64) (float64, float64) {
+	b := 0.0
+	for _, o := range odds {
+		b += 1 / o
+	}
+	if b == 0 {
+		return 0, 0
+	}
+	return round4(b), round4(1 / b)
+}
+
+func sum(x []float64) float64 {
+	t := 0.0
+	for _, v := range x {
+		t += v
+	}
+	return t
+}
+
+func sumPow(x []float64, k float64) float64 {
+	return sum(pow(x, k))
+}
+
+func pow(x []float64, k float64) []float64 {
+	r := make([]float64, len(x))
+	for i, v := range x {
+		r[i] = math.Pow(v, k)
+	}
+	return r
+}
+
+func floor2(x float64) float64 {
+	return math.Floor(x*100+1e-9) / 100
+}
+
+func round4(x float64) float64 {
+	return math.Round(x*10000) / 10000
+}
@@ -0,0 +1,28 @@
+package margins
+
+import "fmt"
+
+// NewMarketMargin : validates the margin of a market. The overround must be at least 1,
+// below that the market pays out more than it takes in.
+func NewMarketMargin(code, method string, overround float64) (*MarketMargin, error) {
+
+	if code == "" {
+		return nil, fmt.Errorf("code not set")
+	}
+
+	switch method {
+	case Proportional, Power, Shin:
+		if overround < 1 || overround > 2 {
+			return nil, fmt.Errorf("overround %.4f of %s must be between 1 and 2", overround, code)
+		}
+	case Keep:
+	default:
+		return nil, fmt.Errorf("method %q of %s not supported", method, code)
+	}
+
+	return &MarketMargin{
+		Code:      code,
+		Method:    method,
+		Overround: overround,
+	}, nil
+}
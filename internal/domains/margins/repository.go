@@ -0,0 +1,9 @@
+package margins
+
+import "context"
+
+// MarginsRepository : reprices the outcomes of a market to its target overround.
+type MarginsRepository interface {
+	Reprice(ctx context.Context, code string, odds []float64) (Priced, error)
+	Margin(code string) MarketMargin
+}
@@ -0,0 +1,35 @@
+package margins
+
+// Methods used to take the provider margin out of a market and to put ours in.
+const (
+	// Proportional : every probability is scaled by the same factor.
+	Proportional = "proportional"
+	// Power : probabilities are raised to the same power, long shots carry more of the margin.
+	Power = "power"
+	// Shin : margin follows Shin's insider trading model, long shots carry more of the margin.
+	Shin = "shin"
+	// Keep : provider prices are kept, for markets whose outcomes overlap (e.g Multi-Goals).
+	Keep = "keep"
+)
+
+// DefaultMarket : code of the margin used by markets without their own.
+const DefaultMarket = "*"
+
+// MarketMargin : target overround of a market, 1.06 pays out 1/1.06 = 94.34%.
+type MarketMargin struct {
+	Code      string  `mapstructure:"code" json:"code"`
+	Method    string  `mapstructure:"method" json:"method"`
+	Overround float64 `mapstructure:"overround" json:"overround"`
+}
+
+// Priced : a market once repriced. Overround and Payout are worked out from the
+// rounded odds, they are what a customer actually gets.
+type Priced struct {
+	Code              string    `json:"code"`
+	Method            string    `json:"method"`
+	Odds              []float64 `json:"odds"`
+	ProviderOverround float64   `json:"provider_overround"`
+	TargetOverround   float64   `json:"target_overround"`
+	Overround         float64   `json:"overround"`
+	Payout            float64   `json:"payout"`
+}
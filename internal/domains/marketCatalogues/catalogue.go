@@ -0,0 +1,183 @@
+package marketCatalogues
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultMarkets : markets offered before the catalogue was kept in mysql, in display order.
+var defaultMarkets = []struct{ code, name string }{
+	{"CS", "Correct Score (FT)"},
+	{"HS", "Half Time Score"},
+	{"1X2", "Match Result"},
+	{"H1X2", "Half Time Result"},
+	{"DC", "Double Chance"},
+	{"DCH", "Double Chance (HT)"},
+	{"TG15", "Over/Under 1.5"},
+	{"TG25", "Over/Under 2.5"},
+	{"TG35", "Over/Under 3.5"},
+	{"HX1", "Handicap -1"},
+	{"HX2", "Handicap -2"},
+	{"DR", "Half Time / Full Time"},
+	{"TG", "Total Goals"},
+	{"GG", "Goal:Goal FT"},
+	{"HGG", "Goal:Goal HT"},
+	{"1X2OU15", "1X2 and Over/Under 1.5"},
+	{"1X2OU25", "1X2 and Over/Under 2.5"},
+	{"1X2OU35", "1X2 and Over/Under 3.5"},
+	{"1X2OU45", "1X2 and Over/Under 4.5"},
+	{"1X2OU55", "1X2 and Over/Under 5.5"},
+	{"1X2G", "1X2 and Goal/No Goal"},
+	{"T1OU15", "Team 1 Over/Under 1.5"},
+	{"T2OU15", "Team 2 Over/Under 1.5"},
+	{"T1G", "Team 1 Goal/No Goal"},
+	{"T2G", "Team 2 Goal/No Goal"},
+	{"TGOE", "Total Goals Odd/Even"},
+	{"TFG", "Time of First Goal"},
+	{"FTS", "First Team to Score"},
+	{"MG", "Multi-Goals"},
+	{"AHM15", "Asian Handicap -1.5"},
+	{"AHM05", "Asian Handicap -0.5"},
+	{"AHP05", "Asian Handicap +0.5"},
+	{"AHP15", "Asian Handicap +1.5"},
+	{"WM", "Winning Margin"},
+	{"EG", "Exact Goals"},
+	{"T1OU05", "Team 1 Over/Under 0.5"},
+	{"T1OU25", "Team 1 Over/Under 2.5"},
+	{"T2OU05", "Team 2 Over/Under 0.5"},
+	{"T2OU25", "Team 2 Over/Under 2.5"},
+	{"CSH", "Home Clean Sheet"},
+	{"CSA", "Away Clean Sheet"},
+	{"WTNH", "Home Win to Nil"},
+	{"WTNA", "Away Win to Nil"},
+	{"HSH", "Highest Scoring Half"},
+}
+
+// DefaultCatalogue : the markets seeded by the migration, used when no catalogue could be loaded.
+func DefaultCatalogue() *Catalogue {
+	c := &Catalogue{}
+	for i, x := range defaultMarkets {
+		c.Markets = append(c.Markets, MarketCatalogues{
+			Code:     x.code,
+			Name:     x.name,
+			Priority: (i + 1) * 10,
+			Status:   Enabled,
+		})
+	}
+	return c
+}
+
+// Selection : markets offered to a competition and a client, keyed by code.
+type Selection struct {
+	markets map[string]Display
+}
+
+// Select : resolves every market for a competition, a client and a locale. Overrides are
+// applied from the least to the most specific : competition, client, then both. Names
+// fall back from the client override to the locale name then to the market name.
+func (c *Catalogue) Select(competitionID, clientID, locale string) *Selection {
+
+	locale = strings.ToLower(locale)
+
+	names := make(map[string]string)
+	for _, n := range c.Names {
+		if n.Locale == locale {
+			names[n.Code] = n.Name
+		}
+	}
+
+	overrides := make(map[string][]MarketOverrides)
+	for _, o := range c.Overrides {
+		if o.CompetitionID != "" && o.CompetitionID != competitionID {
+			continue
+		}
+		if o.ClientID != "" && o.ClientID != clientID {
+			continue
+		}
+		overrides[o.Code] = append(overrides[o.Code], o)
+	}
+
+	sel := &Selection{markets: make(map[string]Display)}
+
+	for _, m := range c.Markets {
+
+		status := m.Status
+		d := Display{
+			Code:     m.Code,
+			Name:     m.Name,
+			Priority: m.Priority,
+		}
+		if n, ok := names[m.Code]; ok {
+			d.Name = n
+		}
+		if m.OutcomeOrder != "" {
+			d.OutcomeOrder = strings.Split(m.OutcomeOrder, ",")
+		}
+
+		oo := overrides[m.Code]
+		sort.SliceStable(oo, func(i, j int) bool {
+			return specificity(oo[i]) < specificity(oo[j])
+		})
+
+		for _, o := range oo {
+			if o.Status != Inherit && o.Status != "" {
+				status = o.Status
+			}
+			if o.Name != "" {
+				d.Name = o.Name
+			}
+			if o.Priority != 0 {
+				d.Priority = o.Priority
+			}
+		}
+
+		if status == Enabled {
+			sel.markets[m.Code] = d
+		}
+	}
+
+	return sel
+}
+
+// specificity : competition only, client only, both.
+func specificity(o MarketOverrides) int {
+	n := 0
+	if o.CompetitionID != "" {
+		n++
+	}
+	if o.ClientID != "" {
+		n += 2
+	}
+	return n
+}
+
+// Market : the market when offered.
+func (s *Selection) Market(code string) (Display, bool) {
+	d, ok := s.markets[code]
+	return d, ok
+}
+
+// Markets : every market offered, by priority then code.
+func (s *Selection) Markets() []Display {
+	dd := []Display{}
+	for _, d := range s.markets {
+		dd = append(dd, d)
+	}
+	sort.Slice(dd, func(i, j int) bool {
+		if dd[i].Priority != dd[j].Priority {
+			return dd[i].Priority < dd[j].Priority
+		}
+		return dd[i].Code < dd[j].Code
+	})
+	return dd
+}
+
+// OutcomeRank : position of an outcome in the display order, outcomes not listed come last.
+func (d Display) OutcomeRank(outcomeID string) int {
+	for i, x := range d.OutcomeOrder {
+		if x == outcomeID {
+			return i
+		}
+	}
+	return len(d.OutcomeOrder)
+}
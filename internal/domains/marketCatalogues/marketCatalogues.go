@@ -0,0 +1,109 @@
+package marketCatalogues
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NewMarketCatalogue instantiate a market
+func NewMarketCatalogue(code, name, outcomeOrder string, priority int, status string) (*MarketCatalogues, error) {
+
+	if code == "" {
+		return &MarketCatalogues{}, fmt.Errorf("code not set")
+	}
+
+	if name == "" {
+		return &MarketCatalogues{}, fmt.Errorf("name not set")
+	}
+
+	if status != Enabled && status != Disabled {
+		return &MarketCatalogues{}, fmt.Errorf("status %q not supported", status)
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &MarketCatalogues{
+		Code:         code,
+		Name:         name,
+		OutcomeOrder: cleanList(outcomeOrder),
+		Priority:     priority,
+		Status:       status,
+		Created:      created,
+		Modified:     modified,
+	}, nil
+}
+
+// NewMarketName instantiate the name of a market in a locale
+func NewMarketName(code, locale, name string) (*MarketNames, error) {
+
+	if code == "" {
+		return &MarketNames{}, fmt.Errorf("code not set")
+	}
+
+	if locale == "" {
+		return &MarketNames{}, fmt.Errorf("locale not set")
+	}
+
+	if name == "" {
+		return &MarketNames{}, fmt.Errorf("name not set")
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &MarketNames{
+		Code:     code,
+		Locale:   strings.ToLower(locale),
+		Name:     name,
+		Created:  created,
+		Modified: modified,
+	}, nil
+}
+
+// NewMarketOverride instantiate an override, at least a competition or a client must be set.
+func NewMarketOverride(code, competitionID, clientID, status, name string, priority int) (*MarketOverrides, error) {
+
+	if code == "" {
+		return &MarketOverrides{}, fmt.Errorf("code not set")
+	}
+
+	if competitionID == "" && clientID == "" {
+		return &MarketOverrides{}, fmt.Errorf("competitionID or clientID not set")
+	}
+
+	if status == "" {
+		status = Inherit
+	}
+
+	if status != Enabled && status != Disabled && status != Inherit {
+		return &MarketOverrides{}, fmt.Errorf("status %q not supported", status)
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &MarketOverrides{
+		Code:          code,
+		CompetitionID: competitionID,
+		ClientID:      clientID,
+		Status:        status,
+		Name:          name,
+		Priority:      priority,
+		Created:       created,
+		Modified:      modified,
+	}, nil
+}
+
+// cleanList : "1, X ,2" -> "1,X,2"
+func cleanList(list string) string {
+	items := []string{}
+	for _, x := range strings.Split(list, ",") {
+		x = strings.TrimSpace(x)
+		if x != "" {
+			items = append(items, x)
+		}
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,180 @@
+package marketCataloguesMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
+)
+
+var _ marketCatalogues.MarketCataloguesRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// SaveMarket : adds a market or updates the one with the same code.
+func (mr *MysqlRepository) SaveMarket(ctx context.Context, t marketCatalogues.MarketCatalogues) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT market_catalogues SET code=?,name=?,outcome_order=?,priority=?,status=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE name=values(name),outcome_order=values(outcome_order), \n"+
+		"priority=values(priority),status=values(status),modified=now()",
+		t.Code, t.Name, t.OutcomeOrder, t.Priority, t.Status)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save market : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last market ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// SaveMarketName : adds or replaces the name of a market in a locale.
+func (mr *MysqlRepository) SaveMarketName(ctx context.Context, t marketCatalogues.MarketNames) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT market_catalogue_names SET code=?,locale=?,name=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE name=values(name),modified=now()",
+		t.Code, t.Locale, t.Name)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save market name : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last market name ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// SaveMarketOverride : adds or replaces the override of a market for a competition / client.
+func (mr *MysqlRepository) SaveMarketOverride(ctx context.Context, t marketCatalogues.MarketOverrides) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT market_catalogue_overrides SET code=?,competition_id=?,client_id=?,status=?,name=?,priority=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE status=values(status),name=values(name), \n"+
+		"priority=values(priority),modified=now()",
+		t.Code, t.CompetitionID, t.ClientID, t.Status, t.Name, t.Priority)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save market override : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last market override ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// GetMarkets : every market, by priority
+func (r *MysqlRepository) GetMarkets(ctx context.Context) ([]marketCatalogues.MarketCatalogues, error) {
+	var gc []marketCatalogues.MarketCatalogues
+	statement := fmt.Sprintf("select market_catalogue_id,code,name,outcome_order,priority,status,created,modified \n" +
+		"from market_catalogues order by priority,code")
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g marketCatalogues.MarketCatalogues
+		err := raws.Scan(&g.MarketCatalogueID, &g.Code, &g.Name, &g.OutcomeOrder, &g.Priority, &g.Status,
+			&g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// GetMarketNames : names of every market in every locale
+func (r *MysqlRepository) GetMarketNames(ctx context.Context) ([]marketCatalogues.MarketNames, error) {
+	var gc []marketCatalogues.MarketNames
+	statement := fmt.Sprintf("select code,locale,name,created,modified from market_catalogue_names order by code,locale")
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g marketCatalogues.MarketNames
+		err := raws.Scan(&g.Code, &g.Locale, &g.Name, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// GetMarketOverrides : every competition / client override
+func (r *MysqlRepository) GetMarketOverrides(ctx context.Context) ([]marketCatalogues.MarketOverrides, error) {
+	var gc []marketCatalogues.MarketOverrides
+	statement := fmt.Sprintf("select code,competition_id,client_id,status,name,priority,created,modified \n" +
+		"from market_catalogue_overrides order by code,competition_id,client_id")
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g marketCatalogues.MarketOverrides
+		err := raws.Scan(&g.Code, &g.CompetitionID, &g.ClientID, &g.Status, &g.Name, &g.Priority,
+			&g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
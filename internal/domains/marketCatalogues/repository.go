@@ -0,0 +1,13 @@
+package marketCatalogues
+
+import "context"
+
+// MarketCataloguesRepository : markets we offer, their names and per competition / client overrides.
+type MarketCataloguesRepository interface {
+	SaveMarket(ctx context.Context, t MarketCatalogues) (int, error)
+	SaveMarketName(ctx context.Context, t MarketNames) (int, error)
+	SaveMarketOverride(ctx context.Context, t MarketOverrides) (int, error)
+	GetMarkets(ctx context.Context) ([]MarketCatalogues, error)
+	GetMarketNames(ctx context.Context) ([]MarketNames, error)
+	GetMarketOverrides(ctx context.Context) ([]MarketOverrides, error)
+}
@@ -0,0 +1,75 @@
+package marketCatalogues
+
+// Override statuses, inherit keeps what the less specific rows say.
+const (
+	Enabled  = "enabled"
+	Disabled = "disabled"
+	Inherit  = "inherit"
+)
+
+// MarketCatalogues : a market we may offer. OutcomeOrder is a comma separated list of
+// outcome ids, outcomes left out keep the provider order after the listed ones.
+// A lower priority is displayed first.
+type MarketCatalogues struct {
+	MarketCatalogueID string `json:"market_catalogue_id"`
+	Code              string `json:"code"`
+	Name              string `json:"name"`
+	OutcomeOrder      string `json:"outcome_order"`
+	Priority          int    `json:"priority"`
+	Status            string `json:"status"`
+	Created           string `json:"created"`
+	Modified          string `json:"modified"`
+}
+
+// MarketNames : display name of a market in a locale.
+type MarketNames struct {
+	Code     string `json:"code"`
+	Locale   string `json:"locale"`
+	Name     string `json:"name"`
+	Created  string `json:"created"`
+	Modified string `json:"modified"`
+}
+
+// MarketOverrides : enables, disables or renames a market for a competition, a client
+// or both, an empty id matches any. An empty name and a zero priority are inherited,
+// the name applies to every locale of the client.
+type MarketOverrides struct {
+	Code          string `json:"code"`
+	CompetitionID string `json:"competition_id"`
+	ClientID      string `json:"client_id"`
+	Status        string `json:"status"`
+	Name          string `json:"name"`
+	Priority      int    `json:"priority"`
+	Created       string `json:"created"`
+	Modified      string `json:"modified"`
+}
+
+// Catalogue : every market with its names and overrides, what is cached in redis.
+type Catalogue struct {
+	Markets   []MarketCatalogues `json:"markets"`
+	Names     []MarketNames      `json:"names"`
+	Overrides []MarketOverrides  `json:"overrides"`
+}
+
+// Display : a market as offered to a competition and a client.
+type Display struct {
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	Priority     int      `json:"priority"`
+	OutcomeOrder []string `json:"outcome_order"`
+}
+
+/*
+CREATE TABLE `market_catalogues` (
+  `market_catalogue_id` int(11) NOT NULL AUTO_INCREMENT,
+  `code` varchar(20) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `outcome_order` varchar(500) NOT NULL DEFAULT '',
+  `priority` int(11) NOT NULL DEFAULT '100',
+  `status` enum('enabled','disabled') NOT NULL DEFAULT 'enabled',
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`market_catalogue_id`),
+  UNIQUE KEY `code` (`code`)
+);
+*/
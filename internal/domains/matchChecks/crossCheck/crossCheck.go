@@ -0,0 +1,204 @@
+package crossCheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements/settlementEngine"
+)
+
+var _ matchChecks.MatchChecksRepository = (*CrossCheck)(nil)
+
+type CrossCheck struct {
+	parentMatchID string
+	oddsPayload   string
+	woPayload     string
+	lsPayload     string
+}
+
+// New initializes a cross check for one parent match. lsPayload may be empty
+// for goalless matches.
+func New(parentMatchID, oddsPayload, woPayload, lsPayload string) (*CrossCheck, error) {
+
+	if parentMatchID == "" {
+		return nil, fmt.Errorf("parentMatchID not set")
+	}
+
+	if oddsPayload == "" {
+		return nil, fmt.Errorf("oddsPayload not set")
+	}
+
+	if woPayload == "" {
+		return nil, fmt.Errorf("woPayload not set")
+	}
+
+	c := &CrossCheck{
+		parentMatchID: parentMatchID,
+		oddsPayload:   oddsPayload,
+		woPayload:     woPayload,
+		lsPayload:     lsPayload,
+	}
+
+	return c, nil
+}
+
+// Check : returns every disagreement found between the three files.
+func (s *CrossCheck) Check(ctx context.Context) ([]matchChecks.Issue, error) {
+
+	issues := []matchChecks.Issue{}
+
+	var o oddsFiles.RawOdds
+	err := json.Unmarshal([]byte(s.oddsPayload), &o)
+	if err != nil {
+		return issues, fmt.Errorf("Err : %v failed to unmarshal odds of %s", err, s.parentMatchID)
+	}
+
+	var wo oddsFiles.RawWinningOutcomes
+	err = json.Unmarshal([]byte(s.woPayload), &wo)
+	if err != nil {
+		return issues, fmt.Errorf("Err : %v failed to unmarshal winning outcome of %s", err, s.parentMatchID)
+	}
+
+	var ls oddsFiles.RawLS
+	if s.lsPayload != "" {
+		err = json.Unmarshal([]byte(s.lsPayload), &ls)
+		if err != nil {
+			return issues, fmt.Errorf("Err : %v failed to unmarshal live score of %s", err, s.parentMatchID)
+		}
+	}
+
+	issues = append(issues, s.checkParents(o, wo, ls)...)
+
+	if len(o.RawMarkets) == 0 {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.NoMarkets,
+			Detail: "odds file has no markets"})
+	}
+
+	hScore, errH := strconv.Atoi(wo.HomeScore)
+	aScore, errA := strconv.Atoi(wo.AwayScore)
+	if errH != nil || errA != nil || hScore < 0 || aScore < 0 {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.BadFinalScore,
+			Detail: fmt.Sprintf("final score %q-%q is not a valid score", wo.HomeScore, wo.AwayScore)})
+		return issues, nil
+	}
+
+	issues = append(issues, checkLiveScores(ls, hScore, aScore)...)
+	woIssues, err := checkWinningOutcomes(ctx, wo, ls)
+	if err != nil {
+		return issues, fmt.Errorf("Err : %v failed to settle %s", err, s.parentMatchID)
+	}
+	issues = append(issues, woIssues...)
+
+	return issues, nil
+}
+
+// checkParents : all three files must describe the same parent match.
+func (s *CrossCheck) checkParents(o oddsFiles.RawOdds, wo oddsFiles.RawWinningOutcomes, ls oddsFiles.RawLS) []matchChecks.Issue {
+	issues := []matchChecks.Issue{}
+
+	if o.ParentMatchID != s.parentMatchID {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.ParentMismatch,
+			Detail: fmt.Sprintf("odds file is for %s", o.ParentMatchID)})
+	}
+
+	for _, x := range wo.RawWOs {
+		if x.ParentMatchID != s.parentMatchID {
+			issues = append(issues, matchChecks.Issue{Code: matchChecks.ParentMismatch,
+				Detail: fmt.Sprintf("winning outcome %s/%s is for %s", x.SubTypeID, x.OutcomeID, x.ParentMatchID)})
+			break
+		}
+	}
+
+	if ls.ParentMatchID != "" && ls.ParentMatchID != s.parentMatchID {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.ParentMismatch,
+			Detail: fmt.Sprintf("live score file is for %s", ls.ParentMatchID)})
+	}
+
+	return issues
+}
+
+// checkLiveScores : every entry adds exactly one goal, minutes never go back and
+// the last entry is the final score.
+func checkLiveScores(ls oddsFiles.RawLS, hScore, aScore int) []matchChecks.Issue {
+	issues := []matchChecks.Issue{}
+
+	if len(ls.Goals) == 0 {
+		if hScore+aScore > 0 {
+			issues = append(issues, matchChecks.Issue{Code: matchChecks.MissingLiveScores,
+				Detail: fmt.Sprintf("final score %d-%d has no live scores", hScore, aScore)})
+		}
+		return issues
+	}
+
+	prevHome, prevAway, prevMinute := 0, 0, 0
+	for i, x := range ls.Goals {
+
+		if (x.HomeScore-prevHome)+(x.AwayScore-prevAway) != 1 || x.HomeScore < prevHome || x.AwayScore < prevAway {
+			issues = append(issues, matchChecks.Issue{Code: matchChecks.BadGoalSequence,
+				Detail: fmt.Sprintf("entry %d goes from %d-%d to %d-%d", i, prevHome, prevAway, x.HomeScore, x.AwayScore)})
+		}
+
+		minute, ok := settlements.MinuteScored(x.MinuteScored)
+		if ok {
+			if minute < prevMinute {
+				issues = append(issues, matchChecks.Issue{Code: matchChecks.BadMinuteSequence,
+					Detail: fmt.Sprintf("entry %d scored at %s after minute %d", i, x.MinuteScored, prevMinute)})
+			}
+			prevMinute = minute
+		}
+
+		prevHome, prevAway = x.HomeScore, x.AwayScore
+	}
+
+	if prevHome != hScore || prevAway != aScore {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.LiveScoreMismatch,
+			Detail: fmt.Sprintf("last live score %d-%d, final score %d-%d", prevHome, prevAway, hScore, aScore)})
+	}
+
+	return issues
+}
+
+// checkWinningOutcomes : every winning outcome must agree with the result the settlement
+// engine works out from the final score and the live scores.
+func checkWinningOutcomes(ctx context.Context, wo oddsFiles.RawWinningOutcomes, ls oddsFiles.RawLS) ([]matchChecks.Issue, error) {
+	issues := []matchChecks.Issue{}
+
+	liveScores := []oddsFiles.FinalLiveScores{}
+	for _, x := range ls.Goals {
+		liveScores = append(liveScores, oddsFiles.FinalLiveScores{
+			HomeScore:    x.HomeScore,
+			AwayScore:    x.AwayScore,
+			MinuteScored: x.MinuteScored,
+		})
+	}
+
+	m, err := settlements.NewMatch(wo.HomeScore, wo.AwayScore, liveScores)
+	if err != nil {
+		return issues, err
+	}
+
+	engine, err := settlementEngine.New()
+	if err != nil {
+		return issues, err
+	}
+
+	for _, d := range engine.Compare(ctx, *m, wo.RawWOs, nil) {
+
+		code := matchChecks.SettlementWrong
+		switch d.SubTypeID {
+		case "CS":
+			code = matchChecks.CorrectScoreWrong
+		case "1X2":
+			code = matchChecks.MatchResultWrong
+		}
+
+		issues = append(issues, matchChecks.Issue{Code: code, Detail: d.Detail})
+	}
+
+	return issues, nil
+}
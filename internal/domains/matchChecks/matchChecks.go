@@ -0,0 +1,9 @@
+package matchChecks
+
+import "context"
+
+// MatchChecksRepository : cross checks the files saved in redis for one parent match.
+// An empty list means the match can be published.
+type MatchChecksRepository interface {
+	Check(ctx context.Context) ([]Issue, error)
+}
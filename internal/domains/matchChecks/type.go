@@ -0,0 +1,28 @@
+package matchChecks
+
+import "fmt"
+
+// Issue codes returned by the cross check.
+const (
+	ParentMismatch    = "parent_mismatch"
+	NoMarkets         = "no_markets"
+	BadFinalScore     = "bad_final_score"
+	MissingLiveScores = "missing_live_scores"
+	BadGoalSequence   = "bad_goal_sequence"
+	BadMinuteSequence = "bad_minute_sequence"
+	LiveScoreMismatch = "live_score_mismatch"
+	CorrectScoreWrong = "correct_score_mismatch"
+	MatchResultWrong  = "match_result_mismatch"
+	SettlementWrong   = "settlement_mismatch"
+)
+
+// Issue : a single disagreement between the odds, winning outcome and live score
+// files of one parent match.
+type Issue struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func (i Issue) Error() string {
+	return fmt.Sprintf("%s : %s", i.Code, i.Detail)
+}
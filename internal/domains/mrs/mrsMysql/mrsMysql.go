@@ -110,3 +110,32 @@ func (r *MysqlRepository) GoalDistribution(ctx context.Context, roundNumberID, c
 
 	return gc, nil
 }
+
+// RawScores : every round of a competition with its raw scores, the historical results
+// published weeks are drawn from.
+func (r *MysqlRepository) RawScores(ctx context.Context, competitionID string) ([]mrs.Mrs, error) {
+	var gc []mrs.Mrs
+	statement := fmt.Sprintf("select mr_id,round_number_id,competition_id,start_time,total_goals,goal_count,raw_scores \n" +
+		" from mrs where competition_id = ? ")
+
+	raws, err := r.db.Query(statement, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g mrs.Mrs
+		err := raws.Scan(&g.MrID, &g.RoundNumberID, &g.CompetitionID, &g.StartTime, &g.TotalGoals, &g.GoalCount, &g.RawScores)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
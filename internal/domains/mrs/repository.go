@@ -7,4 +7,5 @@ type MrsRepository interface {
 	Save(context.Context, Mrs) (int, error)
 	GoalPatterns(ctx context.Context, competitionID string) ([]Mrs, error)
 	GoalDistribution(ctx context.Context, roundNumberID, competitionID string) ([]Mrs, error)
+	RawScores(ctx context.Context, competitionID string) ([]Mrs, error)
 }
@@ -0,0 +1,54 @@
+package oddsChecks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewRules : fills in the defaults and checks the bounds make sense.
+func NewRules(r Rules) (*Rules, error) {
+
+	if r.MinOdd == 0 {
+		r.MinOdd = 1.01
+	}
+
+	if r.MaxOdd == 0 {
+		r.MaxOdd = 1000
+	}
+
+	if r.MinOverround == 0 {
+		r.MinOverround = 1
+	}
+
+	if r.MaxOverround == 0 {
+		r.MaxOverround = 1.4
+	}
+
+	if r.Tolerance == 0 {
+		r.Tolerance = 0.1
+	}
+
+	if r.MinOdd < 1 || r.MaxOdd <= r.MinOdd {
+		return nil, fmt.Errorf("odds bounds %.2f - %.2f not valid", r.MinOdd, r.MaxOdd)
+	}
+
+	if r.MinOverround <= 0 || r.MaxOverround <= r.MinOverround {
+		return nil, fmt.Errorf("overround bounds %.4f - %.4f not valid", r.MinOverround, r.MaxOverround)
+	}
+
+	if r.Tolerance < 0 || r.Tolerance >= 1 {
+		return nil, fmt.Errorf("tolerance %.4f must be between 0 and 1", r.Tolerance)
+	}
+
+	// Market codes are upper case, config readers may hand them over lower cased.
+	outcomes := make(map[string]int)
+	for code, n := range r.Outcomes {
+		if n < 1 {
+			return nil, fmt.Errorf("outcomes of %s must be at least 1", code)
+		}
+		outcomes[strings.ToUpper(code)] = n
+	}
+	r.Outcomes = outcomes
+
+	return &r, nil
+}
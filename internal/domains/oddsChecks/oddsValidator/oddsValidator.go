@@ -0,0 +1,277 @@
+package oddsValidator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+)
+
+var _ oddsChecks.OddsChecksRepository = (*OddsValidatorConfigs)(nil)
+
+// outcomes : outcomes of the markets whose outcome count never changes. Markets left out
+// (CS, HS, TG, TFG, MG) only need one outcome.
+var outcomes = map[string]int{
+	"1X2":     3,
+	"H1X2":    3,
+	"HX1":     3,
+	"HX2":     3,
+	"DC":      3,
+	"DCH":     3,
+	"FTS":     3,
+	"DR":      9,
+	"TG15":    2,
+	"TG25":    2,
+	"TG35":    2,
+	"GG":      2,
+	"HGG":     2,
+	"TGOE":    2,
+	"T1OU15":  2,
+	"T2OU15":  2,
+	"T1G":     2,
+	"T2G":     2,
+	"1X2G":    6,
+	"1X2OU15": 6,
+	"1X2OU25": 6,
+	"1X2OU35": 6,
+	"1X2OU45": 6,
+	"1X2OU55": 6,
+	"AHM15":   2,
+	"AHM05":   2,
+	"AHP05":   2,
+	"AHP15":   2,
+	"WM":      7,
+	"EG":      7,
+	"T1OU05":  2,
+	"T1OU25":  2,
+	"T2OU05":  2,
+	"T2OU25":  2,
+	"CSH":     2,
+	"CSA":     2,
+	"WTNH":    2,
+	"WTNA":    2,
+	"HSH":     3,
+}
+
+// overlapping : markets where one result wins several outcomes, their book is not an overround.
+var overlapping = map[string]bool{
+	"DC":  true,
+	"DCH": true,
+	"MG":  true,
+}
+
+// minScores : correct scores needed before CS is compared with 1X2.
+const minScores = 6
+
+// OddsValidatorConfigs : rules the markets of a match are checked against.
+type OddsValidatorConfigs struct {
+	rules    oddsChecks.Rules
+	outcomes map[string]int
+}
+
+// New initializes the odds validator.
+func New(r oddsChecks.Rules) (*OddsValidatorConfigs, error) {
+
+	rules, err := oddsChecks.NewRules(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &OddsValidatorConfigs{
+		rules:    *rules,
+		outcomes: make(map[string]int),
+	}
+
+	for code, n := range outcomes {
+		c.outcomes[code] = n
+	}
+	for code, n := range rules.Outcomes {
+		c.outcomes[code] = n
+	}
+
+	return c, nil
+}
+
+// Validate : every issue found on the markets of one match.
+func (s *OddsValidatorConfigs) Validate(ctx context.Context, mkts []oddsFiles.FinalMarkets) []matchChecks.Issue {
+
+	issues := []matchChecks.Issue{}
+
+	if len(mkts) == 0 {
+		issues = append(issues, matchChecks.Issue{Code: matchChecks.NoMarkets,
+			Detail: "match has no markets"})
+		return issues
+	}
+
+	for _, m := range mkts {
+		issues = append(issues, s.checkMarket(m)...)
+	}
+
+	issues = append(issues, s.checkCoherence(mkts)...)
+
+	return issues
+}
+
+// checkMarket : outcome completeness, prices and overround of one market.
+func (s *OddsValidatorConfigs) checkMarket(m oddsFiles.FinalMarkets) []matchChecks.Issue {
+
+	issues := []matchChecks.Issue{}
+
+	expected, complete := s.outcomes[m.Code]
+	if !complete {
+		expected = 1
+	}
+
+	if len(m.FinalOutcomes) < expected {
+		issues = append(issues, matchChecks.Issue{Code: oddsChecks.MissingOutcomes,
+			Detail: fmt.Sprintf("%s has %d outcomes, %d expected", m.Code, len(m.FinalOutcomes), expected)})
+	}
+
+	seen := make(map[string]bool)
+	book := 0.0
+	priced := true
+
+	for _, o := range m.FinalOutcomes {
+
+		if seen[o.OutcomeID] {
+			issues = append(issues, matchChecks.Issue{Code: oddsChecks.DuplicateOutcome,
+				Detail: fmt.Sprintf("%s outcome %s quoted twice", m.Code, o.OutcomeID)})
+		}
+		seen[o.OutcomeID] = true
+
+		switch {
+		case math.IsNaN(o.OddValue) || o.OddValue < s.rules.MinOdd:
+			issues = append(issues, matchChecks.Issue{Code: oddsChecks.PriceTooLow,
+				Detail: fmt.Sprintf("%s outcome %s priced %.2f, at least %.2f", m.Code, o.OutcomeID, o.OddValue, s.rules.MinOdd)})
+			priced = false
+			continue
+		case o.OddValue > s.rules.MaxOdd:
+			issues = append(issues, matchChecks.Issue{Code: oddsChecks.PriceTooHigh,
+				Detail: fmt.Sprintf("%s outcome %s priced %.2f, at most %.2f", m.Code, o.OutcomeID, o.OddValue, s.rules.MaxOdd)})
+		}
+
+		book += 1 / o.OddValue
+	}
+
+	// The book of a market only adds up to its overround once every outcome is quoted.
+	if !complete || overlapping[m.Code] || !priced || len(m.FinalOutcomes) != expected {
+		return issues
+	}
+
+	if book < s.rules.MinOverround {
+		issues = append(issues, matchChecks.Issue{Code: oddsChecks.OverroundTooLow,
+			Detail: fmt.Sprintf("%s overround %.4f, at least %.4f", m.Code, book, s.rules.MinOverround)})
+	}
+
+	if book > s.rules.MaxOverround {
+		issues = append(issues, matchChecks.Issue{Code: oddsChecks.OverroundTooHigh,
+			Detail: fmt.Sprintf("%s overround %.4f, at most %.4f", m.Code, book, s.rules.MaxOverround)})
+	}
+
+	return issues
+}
+
+// checkCoherence : the 1X2 probabilities implied by the correct score prices must be close
+// to the quoted 1X2 ones. Both are taken without their margin.
+func (s *OddsValidatorConfigs) checkCoherence(mkts []oddsFiles.FinalMarkets) []matchChecks.Issue {
+
+	issues := []matchChecks.Issue{}
+
+	quoted := make(map[string]float64)
+	implied := make(map[string]float64)
+	scores := 0
+
+	for _, m := range mkts {
+		switch m.Code {
+		case "1X2":
+			for _, o := range m.FinalOutcomes {
+				if o.OddValue > 1 {
+					quoted[strings.ToUpper(strings.TrimSpace(o.OutcomeID))] += 1 / o.OddValue
+				}
+			}
+
+		case "CS":
+			for _, o := range m.FinalOutcomes {
+				h, a, ok := correctScore(o.OutcomeID)
+				if !ok {
+					h, a, ok = correctScore(o.OutcomeName)
+				}
+				if !ok || o.OddValue <= 1 {
+					// Any other score
+					continue
+				}
+
+				r := "X"
+				if h > a {
+					r = "1"
+				} else if h < a {
+					r = "2"
+				}
+				implied[r] += 1 / o.OddValue
+				scores++
+			}
+		}
+	}
+
+	if len(quoted) != 3 || scores < minScores {
+		return issues
+	}
+
+	normalise(quoted)
+	normalise(implied)
+
+	for _, r := range []string{"1", "X", "2"} {
+		gap := math.Abs(quoted[r] - implied[r])
+		if gap > s.rules.Tolerance {
+			issues = append(issues, matchChecks.Issue{Code: oddsChecks.IncoherentPrices,
+				Detail: fmt.Sprintf("1X2 outcome %s at %.1f%%, correct scores imply %.1f%%", r, quoted[r]*100, implied[r]*100)})
+		}
+	}
+
+	return issues
+}
+
+// normalise : probabilities scaled to add up to 1.
+func normalise(p map[string]float64) {
+	t := 0.0
+	for _, v := range p {
+		t += v
+	}
+	if t == 0 {
+		return
+	}
+	for k, v := range p {
+		p[k] = v / t
+	}
+}
+
+// correctScore : reads 2-1 or 2:1
+func correctScore(outcome string) (int, int, bool) {
+	outcome = strings.TrimSpace(outcome)
+	sep := "-"
+	if strings.Contains(outcome, ":") {
+		sep = ":"
+	}
+
+	parts := strings.Split(outcome, sep)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	h, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	a, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return h, a, true
+}
@@ -0,0 +1,14 @@
+package oddsChecks
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+)
+
+// OddsChecksRepository : checks the markets of one match once formulated. An empty list
+// means the match can be published.
+type OddsChecksRepository interface {
+	Validate(ctx context.Context, mkts []oddsFiles.FinalMarkets) []matchChecks.Issue
+}
@@ -0,0 +1,27 @@
+package oddsChecks
+
+// Issue codes returned by the odds validator.
+const (
+	MissingOutcomes  = "missing_outcomes"
+	DuplicateOutcome = "duplicate_outcome"
+	PriceTooLow      = "price_too_low"
+	PriceTooHigh     = "price_too_high"
+	OverroundTooLow  = "overround_too_low"
+	OverroundTooHigh = "overround_too_high"
+	IncoherentPrices = "incoherent_prices"
+)
+
+// Rules : bounds a match must respect before it is published. Zero values take the defaults.
+type Rules struct {
+	MinOdd       float64 `mapstructure:"min_odd" json:"min_odd"`
+	MaxOdd       float64 `mapstructure:"max_odd" json:"max_odd"`
+	MinOverround float64 `mapstructure:"min_overround" json:"min_overround"`
+	MaxOverround float64 `mapstructure:"max_overround" json:"max_overround"`
+
+	// Tolerance : largest gap allowed between a quoted 1X2 probability and the one
+	// implied by the correct score prices.
+	Tolerance float64 `mapstructure:"tolerance" json:"tolerance"`
+
+	// Outcomes : outcomes expected per market code, on top of the built in ones.
+	Outcomes map[string]int `mapstructure:"outcomes" json:"outcomes"`
+}
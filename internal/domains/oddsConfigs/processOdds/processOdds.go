@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand/v2"
+	"sort"
 	"strconv"
 
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsConfigs"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
 )
 
 var _ oddsConfigs.OddsConfigsRepository = (*OddsConfigs)(nil)
@@ -20,6 +26,10 @@ type OddsConfigs struct {
 	woPayload   string
 	lsPayload   string
 	oddsFactor  float64
+	margins     margins.MarginsRepository
+	markets     *marketCatalogues.Selection
+	derived     derivedMarkets.DerivedMarketsRepository
+	rng         rngs.RngsRepository
 }
 
 // New initializes a new instance of odds.
@@ -42,16 +52,61 @@ func New(oddsPayload, woPayload, lsPayload string, oddsFactor float64) (*OddsCon
 		return nil, fmt.Errorf("oddsFactor not set")
 	}
 
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	c := &OddsConfigs{
+		rng:         rng,
 		oddsPayload: oddsPayload,
 		woPayload:   woPayload,
 		lsPayload:   lsPayload,
 		oddsFactor:  oddsFactor,
+		markets:     marketCatalogues.DefaultCatalogue().Select("", "", ""),
+	}
+
+	return c, nil
+}
+
+// NewWithMargins initializes odds repriced by the margin engine instead of
+// taking a flat factor off every price.
+func NewWithMargins(oddsPayload, woPayload, lsPayload string, engine margins.MarginsRepository) (*OddsConfigs, error) {
+
+	if engine == nil {
+		return nil, fmt.Errorf("engine not set")
+	}
+
+	// The factor is not used once the engine is set.
+	c, err := New(oddsPayload, woPayload, lsPayload, 0.01)
+	if err != nil {
+		return nil, err
 	}
 
+	c.margins = engine
+
 	return c, nil
 }
 
+// SetMarkets : markets offered, their names and order. The built in catalogue is used until set.
+func (s *OddsConfigs) SetMarkets(markets *marketCatalogues.Selection) {
+	if markets != nil {
+		s.markets = markets
+	}
+}
+
+// SetRng : source the flat factor is drawn from, crypto/rand until set.
+func (s *OddsConfigs) SetRng(rng rngs.RngsRepository) {
+	if rng != nil {
+		s.rng = rng
+	}
+}
+
+// SetDerivedMarkets : markets priced from the correct scores, offered when the catalogue lists them.
+func (s *OddsConfigs) SetDerivedMarkets(derived derivedMarkets.DerivedMarketsRepository) {
+	s.derived = derived
+}
+
 // FormulateOdds : rewrites odds the right way
 func (s *OddsConfigs) FormulateOdds(ctx context.Context) ([]oddsFiles.FinalMarkets, oddsFiles.FinalScores, []oddsFiles.FinalLiveScores, error) {
 
@@ -71,15 +126,28 @@ func (s *OddsConfigs) FormulateOdds(ctx context.Context) ([]oddsFiles.FinalMarke
 	for _, x := range o.RawMarkets {
 		//log.Printf("name:%s, subTypeID:%s", x.Name, x.SubTypeID)
 
-		updatedName, status := renameMarketName(x.SubTypeID)
+		display, offered := s.markets.Market(x.SubTypeID)
+
+		if offered {
 
-		if status == "1" {
+			x.RawOutcomes = orderOutcomes(display, x.RawOutcomes)
 
 			mkt := oddsFiles.FinalMarkets{
-				Name: updatedName,
+				Name: display.Name,
 				Code: x.SubTypeID,
 			}
 
+			if s.margins != nil {
+				outcomes, err := s.repriceMarket(ctx, x)
+				if err != nil {
+					log.Printf("Err : %v skipped market %s", err, x.SubTypeID)
+					continue
+				}
+				mkt.FinalOutcomes = outcomes
+				mkts = append(mkts, mkt)
+				continue
+			}
+
 			for _, i := range x.RawOutcomes {
 				//log.Printf("outcomeID: %s, outcomeName: %s, outcomeAlias: %s, oddValue: %s",
 				//	i.OutcomeID, i.OutcomeName, i.OutcomeAlias, i.OddValue)
@@ -112,6 +180,8 @@ func (s *OddsConfigs) FormulateOdds(ctx context.Context) ([]oddsFiles.FinalMarke
 
 	}
 
+	derived := s.deriveMarkets(ctx, o.RawMarkets)
+
 	// Process Winning Outcomes
 
 	var wo oddsFiles.RawWinningOutcomes
@@ -125,9 +195,9 @@ func (s *OddsConfigs) FormulateOdds(ctx context.Context) ([]oddsFiles.FinalMarke
 
 	for _, w := range wo.RawWOs {
 
-		_, status := renameMarketName(w.SubTypeID)
+		_, offered := s.markets.Market(w.SubTypeID)
 
-		if status == "1" {
+		if offered {
 
 			fWo := oddsFiles.FinalWinningOutcomes{
 				SubTypeID:   w.SubTypeID,
@@ -166,6 +236,13 @@ func (s *OddsConfigs) FormulateOdds(ctx context.Context) ([]oddsFiles.FinalMarke
 
 	}
 
+	// Derived markets are only published once the result settles them.
+	derived, derivedWos := s.settleDerived(ctx, derived, fs, lsc)
+	fs.FinalWinningOutcomes = append(fs.FinalWinningOutcomes, derivedWos...)
+
+	mkts = append(mkts, derived...)
+	s.orderMarkets(mkts)
+
 	return mkts, fs, lsc, nil
 }
 
@@ -188,12 +265,14 @@ func (s *OddsConfigs) FormulateOdds2(ctx context.Context) ([]oddsFiles.FinalMark
 	for _, x := range o.RawMarkets {
 		//log.Printf("name:%s, subTypeID:%s", x.Name, x.SubTypeID)
 
-		updatedName, status := renameMarketName(x.SubTypeID)
+		display, offered := s.markets.Market(x.SubTypeID)
+
+		if offered {
 
-		if status == "1" {
+			x.RawOutcomes = orderOutcomes(display, x.RawOutcomes)
 
 			mkt := oddsFiles.FinalMarkets{
-				Name: updatedName,
+				Name: display.Name,
 				Code: x.SubTypeID,
 			}
 
@@ -209,7 +288,7 @@ func (s *OddsConfigs) FormulateOdds2(ctx context.Context) ([]oddsFiles.FinalMark
 
 					goals := s.OddsFactor(ctx)
 					max := len(goals)
-					selectedRations := rand.IntN(max)
+					selectedRations := s.rng.IntN(max)
 
 					//selectedRations := s.NewRandomIndexes(ctx, max)
 
@@ -246,6 +325,8 @@ func (s *OddsConfigs) FormulateOdds2(ctx context.Context) ([]oddsFiles.FinalMark
 
 	}
 
+	derived := s.deriveMarkets(ctx, o.RawMarkets)
+
 	// Process Winning Outcomes
 
 	var wo oddsFiles.RawWinningOutcomes
@@ -259,9 +340,9 @@ func (s *OddsConfigs) FormulateOdds2(ctx context.Context) ([]oddsFiles.FinalMark
 
 	for _, w := range wo.RawWOs {
 
-		_, status := renameMarketName(w.SubTypeID)
+		_, offered := s.markets.Market(w.SubTypeID)
 
-		if status == "1" {
+		if offered {
 
 			fWo := oddsFiles.FinalWinningOutcomes{
 				SubTypeID:   w.SubTypeID,
@@ -300,9 +381,47 @@ func (s *OddsConfigs) FormulateOdds2(ctx context.Context) ([]oddsFiles.FinalMark
 
 	}
 
+	// Derived markets are only published once the result settles them.
+	derived, derivedWos := s.settleDerived(ctx, derived, fs, lsc)
+	fs.FinalWinningOutcomes = append(fs.FinalWinningOutcomes, derivedWos...)
+
+	mkts = append(mkts, derived...)
+	s.orderMarkets(mkts)
+
 	return mkts, fs, lsc, nil
 }
 
+// repriceMarket : reprices all the outcomes of a market together, an outcome without
+// a valid price would leave the others mispriced so the market is dropped.
+func (s *OddsConfigs) repriceMarket(ctx context.Context, x oddsFiles.RawMarkets) ([]oddsFiles.FinalOutcomes, error) {
+
+	odds := make([]float64, len(x.RawOutcomes))
+	for n, i := range x.RawOutcomes {
+		oddVl, err := strconv.ParseFloat(i.OddValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Err : %v failed to convert %s of %s", err, i.OddValue, i.OutcomeID)
+		}
+		odds[n] = oddVl
+	}
+
+	p, err := s.margins.Reprice(ctx, x.SubTypeID, odds)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := []oddsFiles.FinalOutcomes{}
+	for n, i := range x.RawOutcomes {
+		outcomes = append(outcomes, oddsFiles.FinalOutcomes{
+			OutcomeID:    i.OutcomeID,
+			OutcomeName:  i.OutcomeName,
+			OddValue:     p.Odds[n],
+			OutcomeAlias: i.OutcomeAlias,
+		})
+	}
+
+	return outcomes, nil
+}
+
 // NewRandomIndexes : used to create new randomization.
 func (s *OddsConfigs) NewRandomIndexes(ctx context.Context, max int) map[int]int {
 	//min := 1
@@ -312,7 +431,7 @@ func (s *OddsConfigs) NewRandomIndexes(ctx context.Context, max int) map[int]int
 		// rand.Seed(time.Now().UnixNano())
 		// val := rand.Intn(max-min+1) + min
 
-		val := rand.IntN(max)
+		val := s.rng.IntN(max)
 		m[val] = val
 	}
 
@@ -334,68 +453,109 @@ func (s *OddsConfigs) OddsFactor(ctx context.Context) []float64 {
 	return data
 }
 
-func renameMarketName(subTypeID string) (string, string) {
-
-	if subTypeID == "CS" {
-		return "Correct Score (FT)", "1"
-	} else if subTypeID == "HS" {
-		return "Half Time Score", "1"
-	} else if subTypeID == "1X2" {
-		return "Match Result", "1"
-	} else if subTypeID == "H1X2" {
-		return "Half Time Result", "1"
-	} else if subTypeID == "DC" {
-		return "Double Chance", "1"
-	} else if subTypeID == "DCH" {
-		return "Double Chance (HT)", "1"
-	} else if subTypeID == "TG15" {
-		return "Over/Under 1.5", "1"
-	} else if subTypeID == "TG25" {
-		return "Over/Under 2.5", "1"
-	} else if subTypeID == "TG35" {
-		return "Over/Under 3.5", "1"
-	} else if subTypeID == "HX1" {
-		return "Handicap -1", "1"
-	} else if subTypeID == "HX2" {
-		return "Handicap -2", "1"
-	} else if subTypeID == "DR" {
-		return "Half Time / Full Time", "1"
-	} else if subTypeID == "TG" {
-		return "Total Goals", "1"
-	} else if subTypeID == "GG" {
-		return "Goal:Goal FT", "1"
-	} else if subTypeID == "HGG" {
-		return "Goal:Goal HT", "1"
-	} else if subTypeID == "1X2OU15" {
-		return "1X2 and Over/Under 1.5", "1"
-	} else if subTypeID == "1X2OU25" {
-		return "1X2 and Over/Under 2.5", "1"
-	} else if subTypeID == "1X2OU35" {
-		return "1X2 and Over/Under 3.5", "1"
-	} else if subTypeID == "1X2OU45" {
-		return "1X2 and Over/Under 4.5", "1"
-	} else if subTypeID == "1X2OU55" {
-		return "1X2 and Over/Under 5.5", "1"
-	} else if subTypeID == "1X2G" {
-		return "1X2 and Goal/No Goal", "1"
-	} else if subTypeID == "T1OU15" {
-		return "Team 1 Over/Under 1.5", "1"
-	} else if subTypeID == "T2OU15" {
-		return "Team 2 Over/Under 1.5", "1"
-	} else if subTypeID == "T1G" {
-		return "Team 1 Goal/No Goal", "1"
-	} else if subTypeID == "T2G" {
-		return "Team 2 Goal/No Goal", "1"
-	} else if subTypeID == "TGOE" {
-		return "Total Goals Odd/Even", "1"
-	} else if subTypeID == "TFG" {
-		return "Time of First Goal", "1"
-	} else if subTypeID == "FTS" {
-		return "First Team to Score", "1"
-	} else if subTypeID == "MG" {
-		return "Multi-Goals", "1"
-	} else {
-		return "0", "0"
+// orderMarkets : markets by display priority, the provider order is kept between equals.
+func (s *OddsConfigs) orderMarkets(mkts []oddsFiles.FinalMarkets) {
+	sort.SliceStable(mkts, func(i, j int) bool {
+		a, _ := s.markets.Market(mkts[i].Code)
+		b, _ := s.markets.Market(mkts[j].Code)
+		return a.Priority < b.Priority
+	})
+}
+
+// deriveMarkets : derived markets the catalogue offers, priced from the provider odds before
+// any margin or odds factor was applied.
+func (s *OddsConfigs) deriveMarkets(ctx context.Context, raw []oddsFiles.RawMarkets) []oddsFiles.FinalMarkets {
+
+	mkts := []oddsFiles.FinalMarkets{}
+	if s.derived == nil {
+		return mkts
+	}
+
+	quoted := []oddsFiles.FinalMarkets{}
+	for _, x := range raw {
+		m := oddsFiles.FinalMarkets{Name: x.Name, Code: x.SubTypeID}
+		for _, i := range x.RawOutcomes {
+			oddVl, err := strconv.ParseFloat(i.OddValue, 64)
+			if err != nil {
+				continue
+			}
+			m.FinalOutcomes = append(m.FinalOutcomes, oddsFiles.FinalOutcomes{
+				OutcomeID:    i.OutcomeID,
+				OutcomeName:  i.OutcomeName,
+				OddValue:     oddVl,
+				OutcomeAlias: i.OutcomeAlias,
+			})
+		}
+		quoted = append(quoted, m)
+	}
+
+	dd, err := s.derived.Derive(ctx, quoted, s.margins)
+	if err != nil {
+		log.Printf("Err : %v no derived markets", err)
+		return mkts
+	}
+
+	for _, d := range dd {
+		display, offered := s.markets.Market(d.Code)
+		if !offered {
+			continue
+		}
+		d.Name = display.Name
+		mkts = append(mkts, d)
+	}
+
+	return mkts
+}
+
+// settleDerived : derived markets the result settles and their winning outcomes. A market
+// no outcome of which could be settled, like HSH when the goal times are not known, is left out.
+func (s *OddsConfigs) settleDerived(ctx context.Context, derived []oddsFiles.FinalMarkets, fs oddsFiles.FinalScores, lsc []oddsFiles.FinalLiveScores) ([]oddsFiles.FinalMarkets, []oddsFiles.FinalWinningOutcomes) {
+
+	settled := []oddsFiles.FinalMarkets{}
+	wos := []oddsFiles.FinalWinningOutcomes{}
+	if s.derived == nil || len(derived) == 0 {
+		return settled, wos
+	}
+
+	m, err := settlements.NewMatch(fs.HomeScore, fs.AwayScore, lsc)
+	if err != nil {
+		log.Printf("Err : %v derived markets not settled", err)
+		return settled, wos
+	}
+
+	offered := make(map[string]bool)
+	for _, d := range derived {
+		offered[d.Code] = true
+	}
+
+	won := make(map[string]bool)
+	for _, w := range s.derived.Settle(ctx, *m) {
+		if offered[w.SubTypeID] {
+			wos = append(wos, w)
+			won[w.SubTypeID] = true
+		}
+	}
+
+	for _, d := range derived {
+		if !won[d.Code] {
+			log.Printf("Derived market %s left out, the result does not settle it", d.Code)
+			continue
+		}
+		settled = append(settled, d)
+	}
+
+	return settled, wos
+}
+
+// orderOutcomes : outcomes in the display order of the market, unlisted ones keep the provider order.
+func orderOutcomes(display marketCatalogues.Display, outcomes []oddsFiles.RawOutcomes) []oddsFiles.RawOutcomes {
+	if len(display.OutcomeOrder) == 0 {
+		return outcomes
 	}
 
+	ordered := append([]oddsFiles.RawOutcomes{}, outcomes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return display.OutcomeRank(ordered[i].OutcomeID) < display.OutcomeRank(ordered[j].OutcomeID)
+	})
+	return ordered
 }
@@ -6,7 +6,7 @@ import (
 )
 
 // NewOddsFile instantiate oddsFile Struct
-func NewOddsFile(oddsFileName, fileDirectory, country, parentID, competitionID, matchID string) (*OddsFiles, error) {
+func NewOddsFile(oddsFileName, fileDirectory, country, parentID, competitionID, matchID, sha256 string, fileSize int64) (*OddsFiles, error) {
 
 	if oddsFileName == "" {
 		return &OddsFiles{}, fmt.Errorf("oddsFileName not set")
@@ -32,6 +32,10 @@ func NewOddsFile(oddsFileName, fileDirectory, country, parentID, competitionID,
 		return &OddsFiles{}, fmt.Errorf("matchID not set")
 	}
 
+	if len(sha256) != 64 {
+		return &OddsFiles{}, fmt.Errorf("sha256 not set")
+	}
+
 	created := time.Now().Format("2006-01-02 15:04:05")
 	modified := time.Now().Format("2006-01-02 15:04:05")
 
@@ -42,7 +46,16 @@ func NewOddsFile(oddsFileName, fileDirectory, country, parentID, competitionID,
 		ParentID:      parentID,
 		CompetitionID: competitionID,
 		MatchID:       matchID,
+		Sha256:        sha256,
+		FileSize:      fileSize,
+		Revision:      1,
 		Created:       created,
 		Modified:      modified,
 	}, nil
 }
+
+// BuiltWeeksKey : key the season weeks published from the match of oddsKey (keO:31475634)
+// are listed under.
+func BuiltWeeksKey(oddsKey string) string {
+	return fmt.Sprintf("%s_%s", "pr_built", oddsKey)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
@@ -54,12 +55,13 @@ func (mr *MysqlRepository) Save(ctx context.Context, t oddsFiles.OddsFiles) (int
 	return int(lastInsertedID), nil
 }
 
-// SaveOdd :
+// SaveOdd : 0 is returned when the parent id of the country is already saved
 func (mr *MysqlRepository) SaveOdd(ctx context.Context, t oddsFiles.OddsFiles) (int, error) {
 	var d int
-	rs, err := mr.db.Exec("INSERT o_files SET odds_file_name=?,file_directory=?,country=?, \n"+
-		"parent_id=?,competition_id=?,match_id=?,created=now(),modified=now() ON DUPLICATE KEY UPDATE modified=now()",
-		t.OddsFileName, t.FileDirectory, t.Country, t.ParentID, t.CompetitionID, t.MatchID)
+	rs, err := mr.db.Exec("INSERT IGNORE o_files SET odds_file_name=?,file_directory=?,country=?, \n"+
+		"parent_id=?,competition_id=?,match_id=?,sha256=?,file_size=?,revision=?, \n"+
+		"created=now(),modified=now()",
+		t.OddsFileName, t.FileDirectory, t.Country, t.ParentID, t.CompetitionID, t.MatchID, t.Sha256, t.FileSize, t.Revision)
 
 	if err != nil {
 		return d, fmt.Errorf("unable to save leagues : %v", err)
@@ -240,7 +242,7 @@ func (r *MysqlRepository) GetReadyMatchCount(ctx context.Context) ([]oddsFiles.M
 func (r *MysqlRepository) GetAllOddsParentID(ctx context.Context, parentID, countryCode string) ([]oddsFiles.OddsFiles, error) {
 	var gc []oddsFiles.OddsFiles
 	statement := fmt.Sprintf("select odds_file_id,odds_file_name,file_directory,country,parent_id,competition_id,match_id,\n"+
-		"created,modified from o_files where parent_id = '%s' and country = '%s' ",
+		"sha256,file_size,revision,created,modified from o_files where parent_id = '%s' and country = '%s' ",
 		parentID, countryCode)
 
 	raws, err := r.db.Query(statement)
@@ -250,7 +252,7 @@ func (r *MysqlRepository) GetAllOddsParentID(ctx context.Context, parentID, coun
 
 	for raws.Next() {
 		var g oddsFiles.OddsFiles
-		err := raws.Scan(&g.OddsFileID, &g.OddsFileName, &g.FileDirectory, &g.Country, &g.ParentID, &g.CompetitionID, &g.MatchID, &g.Created, &g.Modified)
+		err := raws.Scan(&g.OddsFileID, &g.OddsFileName, &g.FileDirectory, &g.Country, &g.ParentID, &g.CompetitionID, &g.MatchID, &g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
 		if err != nil {
 			return nil, err
 		}
@@ -264,3 +266,54 @@ func (r *MysqlRepository) GetAllOddsParentID(ctx context.Context, parentID, coun
 
 	return gc, nil
 }
+
+// GetOddsFilesByParentIDs : odds files saved for any of parentIDs, in one query
+func (r *MysqlRepository) GetOddsFilesByParentIDs(ctx context.Context, parentIDs []string, countryCode string) ([]oddsFiles.OddsFiles, error) {
+	var gc []oddsFiles.OddsFiles
+
+	if len(parentIDs) == 0 {
+		return gc, nil
+	}
+
+	args := []interface{}{countryCode}
+	for _, id := range parentIDs {
+		args = append(args, id)
+	}
+
+	statement := fmt.Sprintf("select odds_file_id,odds_file_name,file_directory,country,parent_id,competition_id,match_id,\n"+
+		"sha256,file_size,revision,created,modified from o_files where country = ? and parent_id in (%s) ",
+		strings.TrimSuffix(strings.Repeat("?,", len(parentIDs)), ","))
+
+	raws, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g oddsFiles.OddsFiles
+		err := raws.Scan(&g.OddsFileID, &g.OddsFileName, &g.FileDirectory, &g.Country, &g.ParentID, &g.CompetitionID, &g.MatchID, &g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+// UpdateOddRevision : saves the content of a re-delivered odds file, only while the row
+// still holds previousSha256
+func (mr *MysqlRepository) UpdateOddRevision(ctx context.Context, oddsFileName, fileDirectory, sha256 string, fileSize int64, revision int, oddsFileID, previousSha256 string) (int64, error) {
+	var rs int64
+	result, err := mr.db.Exec("update o_files set odds_file_name=?,file_directory=?,sha256=?,file_size=?,revision=?,modified=now() \n"+
+		"where odds_file_id = ? and sha256 = ? ", oddsFileName, fileDirectory, sha256, fileSize, revision, oddsFileID, previousSha256)
+	if err != nil {
+		return rs, fmt.Errorf("unable to update odds file revision : %v", err)
+	}
+	return result.RowsAffected()
+}
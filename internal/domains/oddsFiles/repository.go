@@ -15,4 +15,6 @@ type OddsFilesRepository interface {
 	GetReadyMatchCount(ctx context.Context) ([]MatchDet, error)
 
 	GetAllOddsParentID(ctx context.Context, parentID, countryCode string) ([]OddsFiles, error)
+	GetOddsFilesByParentIDs(ctx context.Context, parentIDs []string, countryCode string) ([]OddsFiles, error)
+	UpdateOddRevision(ctx context.Context, oddsFileName, fileDirectory, sha256 string, fileSize int64, revision int, oddsFileID, previousSha256 string) (int64, error)
 }
@@ -33,6 +33,9 @@ type OddsFiles struct {
 	ParentID      string
 	CompetitionID string
 	MatchID       string
+	Sha256        string
+	FileSize      int64
+	Revision      int
 	Created       string
 	Modified      string
 }
@@ -82,19 +85,38 @@ type FinalOutcomes struct {
 	OutcomeName  string  `json:"outcome_name"`
 	OddValue     float64 `json:"odd_value"`
 	OutcomeAlias string  `json:"outcome_alias"`
+	DisplayOdd   string  `json:"display_odd,omitempty"`
 }
 
 // RawOdds : used to parse raw winning outcomes
+// Tournament fields are only filled by tournament feeds (World Cup) and are
+// left out of league payloads.
 type RawOdds struct {
 	ParentMatchID string       `json:"parent_match_id"`
 	ProjectID     string       `json:"project_id"`
 	MatchID       string       `json:"match_id"`
+	TournamentID  string       `json:"tournament_id,omitempty"`
+	RoundID       string       `json:"round_id,omitempty"`
+	RoundName     string       `json:"round_name,omitempty"`
+	RoundNumber   string       `json:"round_number,omitempty"`
+	EventNumber   string       `json:"event_number,omitempty"`
+	StartTime     string       `json:"start_time,omitempty"`
+	EndTime       string       `json:"end_time,omitempty"`
+	HomeTeamID    string       `json:"home_team_id,omitempty"`
+	HomeTeam      string       `json:"home_team,omitempty"`
+	HomeAlias     string       `json:"home_alias,omitempty"`
+	AwayTeamID    string       `json:"away_team_id,omitempty"`
+	AwayTeam      string       `json:"away_team,omitempty"`
+	AwayAlias     string       `json:"away_alias,omitempty"`
 	RawMarkets    []RawMarkets `json:"markets"`
 }
 
 type RawMarkets struct {
 	Name        string        `json:"name"`
 	SubTypeID   string        `json:"sub_type_id"`
+	Handicap    string        `json:"handicap,omitempty"`
+	Split       string        `json:"split,omitempty"`
+	AppliedTo   string        `json:"applied_to,omitempty"`
 	RawOutcomes []RawOutcomes `json:"outcomes"`
 }
 
@@ -169,6 +191,11 @@ type FinalLiveScores struct {
 // RawWinningOutcomes : raw winning outcomes
 type RawWinningOutcomes struct {
 	RoundNumberID string   `json:"round_number_id"`
+	TournamentID  string   `json:"tournament_id,omitempty"`
+	RoundName     string   `json:"round_name,omitempty"`
+	EventNumber   string   `json:"event_number,omitempty"`
+	HomeTeamID    string   `json:"home_team_id,omitempty"`
+	AwayTeamID    string   `json:"away_team_id,omitempty"`
 	HomeScore     string   `json:"home_score"`
 	AwayScore     string   `json:"away_score"`
 	RawWOs        []RawWOs `json:"winning_outcomes"`
@@ -198,6 +225,7 @@ type Goals struct {
 type MatchAPI struct {
 	StatusCode        string       `json:"status_code"`
 	StatusDescription string       `json:"status_description"`
+	OddsFormat        string       `json:"odds_format,omitempty"`
 	MatchDetails      MatchDetails `json:"match_details"`
 }
 
@@ -241,3 +269,12 @@ type FinalPeriod struct {
 	EndTime      string         `json:"end_time"`
 	FinalMatches []FinalMatches `json:"matches"`
 }
+
+// BuiltWeek : a season week published from a source match with the keys it was published
+// under, so that a revision of the match can take the week back before it starts.
+type BuiltWeek struct {
+	SeasonID     string   `json:"season_id"`
+	SeasonWeekID string   `json:"season_week_id"`
+	StartTime    string   `json:"start_time"`
+	Keys         []string `json:"keys"`
+}
@@ -0,0 +1,131 @@
+package oddsConverter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFormats"
+)
+
+var _ oddsFormats.OddsFormatsRepository = (*OddsConverterConfigs)(nil)
+
+type fraction struct {
+	numerator   int
+	denominator int
+	decimal     float64
+}
+
+// ladder : fractional prices quoted by UK bookmakers. Decimal prices between two steps
+// are shown at the lower one so that the fraction never pays more than the decimal.
+var ladder = newLadder([][2]int{
+	{1, 100}, {1, 80}, {1, 66}, {1, 50}, {1, 40}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14},
+	{1, 12}, {1, 10}, {1, 9}, {1, 8}, {2, 15}, {1, 7}, {1, 6}, {2, 11}, {1, 5}, {2, 9},
+	{1, 4}, {2, 7}, {3, 10}, {1, 3}, {4, 11}, {2, 5}, {4, 9}, {1, 2}, {8, 15}, {4, 7},
+	{8, 13}, {4, 6}, {8, 11}, {4, 5}, {5, 6}, {10, 11}, {1, 1}, {21, 20}, {11, 10}, {6, 5},
+	{5, 4}, {11, 8}, {6, 4}, {13, 8}, {7, 4}, {15, 8}, {2, 1}, {85, 40}, {9, 4}, {5, 2},
+	{11, 4}, {3, 1}, {10, 3}, {7, 2}, {4, 1}, {9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2},
+	{7, 1}, {15, 2}, {8, 1}, {17, 2}, {9, 1}, {10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1},
+	{18, 1}, {20, 1}, {22, 1}, {25, 1}, {28, 1}, {33, 1}, {40, 1}, {50, 1}, {66, 1}, {80, 1},
+	{100, 1}, {125, 1}, {150, 1}, {200, 1}, {250, 1}, {300, 1}, {400, 1}, {500, 1}, {750, 1}, {1000, 1},
+})
+
+// newLadder : steps in price order. Their decimal is floored to two places like every
+// stored price, 13/8 is stored as 2.62 and must read back as 13/8. Of the steps stored
+// at the same price the first listed is kept.
+func newLadder(steps [][2]int) []fraction {
+	ff := []fraction{}
+	seen := make(map[float64]bool)
+	for _, x := range steps {
+		d := floor2(1 + float64(x[0])/float64(x[1]))
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		ff = append(ff, fraction{
+			numerator:   x[0],
+			denominator: x[1],
+			decimal:     d,
+		})
+	}
+	sort.SliceStable(ff, func(i, j int) bool {
+		return ff[i].decimal < ff[j].decimal
+	})
+	return ff
+}
+
+// OddsConverterConfigs : format decimal prices are converted to.
+type OddsConverterConfigs struct {
+	format string
+}
+
+// New initializes a converter to format, decimal when format is empty.
+func New(format string) (*OddsConverterConfigs, error) {
+	f, err := oddsFormats.NewFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OddsConverterConfigs{format: f}, nil
+}
+
+// Format : name of the format prices are converted to.
+func (s *OddsConverterConfigs) Format() string {
+	return s.format
+}
+
+// Convert : decimal price in the display format, empty when the price has no meaning
+// in that format.
+func (s *OddsConverterConfigs) Convert(decimal float64) string {
+
+	if math.IsNaN(decimal) || decimal <= 1 {
+		return ""
+	}
+
+	switch s.format {
+	case oddsFormats.Fractional:
+		return fractional(decimal)
+
+	case oddsFormats.American:
+		if decimal >= 2 {
+			return fmt.Sprintf("+%d", int(math.Round((decimal-1)*100)))
+		}
+		return fmt.Sprintf("-%d", int(math.Round(100/(decimal-1))))
+
+	case oddsFormats.HongKong:
+		return fmt.Sprintf("%.2f", decimal-1)
+
+	case oddsFormats.Malay:
+		if decimal <= 2 {
+			return fmt.Sprintf("%.2f", decimal-1)
+		}
+		// Long prices would round to -0.00
+		return fmt.Sprintf("-%.2f", math.Max(1/(decimal-1), 0.01))
+	}
+
+	return fmt.Sprintf("%.2f", decimal)
+}
+
+// fractional : highest step of the ladder not above decimal. Prices past the ladder are
+// shown in whole units, prices below it as the longest 1/n not above them.
+func fractional(decimal float64) string {
+
+	top := ladder[len(ladder)-1]
+	if decimal > top.decimal {
+		return fmt.Sprintf("%d/1", int(math.Floor(decimal-1)))
+	}
+
+	n := sort.Search(len(ladder), func(i int) bool {
+		return ladder[i].decimal > decimal+1e-9
+	})
+	if n == 0 {
+		return fmt.Sprintf("1/%d", int(math.Ceil(1/(decimal-1)-1e-9)))
+	}
+	n--
+
+	return fmt.Sprintf("%d/%d", ladder[n].numerator, ladder[n].denominator)
+}
+
+func floor2(v float64) float64 {
+	return math.Floor(v*100+1e-9) / 100
+}
@@ -0,0 +1,15 @@
+package oddsFormats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewFormat : format named by a client, decimal when none is named.
+func NewFormat(format string) (string, error) {
+	f, ok := aliases[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return "", fmt.Errorf("odds format %s not supported", format)
+	}
+	return f, nil
+}
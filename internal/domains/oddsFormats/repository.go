@@ -0,0 +1,7 @@
+package oddsFormats
+
+// OddsFormatsRepository : converts decimal prices to the display format of a client.
+type OddsFormatsRepository interface {
+	Format() string
+	Convert(decimal float64) string
+}
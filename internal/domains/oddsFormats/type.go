@@ -0,0 +1,30 @@
+package oddsFormats
+
+// Formats prices can be displayed in. Decimal is the format odds are stored in.
+const (
+	Decimal    = "decimal"
+	Fractional = "fractional"
+	American   = "american"
+	HongKong   = "hongkong"
+	Malay      = "malay"
+)
+
+// aliases : other names clients send for a format.
+var aliases = map[string]string{
+	"":           Decimal,
+	"decimal":    Decimal,
+	"eu":         Decimal,
+	"european":   Decimal,
+	"fractional": Fractional,
+	"fraction":   Fractional,
+	"uk":         Fractional,
+	"american":   American,
+	"us":         American,
+	"moneyline":  American,
+	"hongkong":   HongKong,
+	"hong_kong":  HongKong,
+	"hk":         HongKong,
+	"malay":      Malay,
+	"malaysian":  Malay,
+	"my":         Malay,
+}
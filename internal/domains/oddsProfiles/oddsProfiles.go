@@ -0,0 +1,131 @@
+package oddsProfiles
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+)
+
+// NewOddsProfile instantiate the odds profile of a client
+func NewOddsProfile(clientID, method string, margin, minOdd, maxOdd float64, ladder, markets, status string) (*OddsProfiles, error) {
+
+	if clientID == "" {
+		return &OddsProfiles{}, fmt.Errorf("clientID not set")
+	}
+
+	if method != "" {
+		_, err := margins.NewMarketMargin(margins.DefaultMarket, method, margin)
+		if err != nil {
+			return &OddsProfiles{}, err
+		}
+	}
+
+	if minOdd == 0 {
+		minOdd = 1.01
+	}
+
+	if minOdd < 1.01 {
+		return &OddsProfiles{}, fmt.Errorf("minOdd %.2f must be at least 1.01", minOdd)
+	}
+
+	if maxOdd != 0 && maxOdd <= minOdd {
+		return &OddsProfiles{}, fmt.Errorf("maxOdd %.2f must be above minOdd %.2f", maxOdd, minOdd)
+	}
+
+	if _, err := ParseLadder(ladder); err != nil {
+		return &OddsProfiles{}, err
+	}
+
+	if status != Active && status != Inactive {
+		return &OddsProfiles{}, fmt.Errorf("status %q not supported", status)
+	}
+
+	codes := []string{}
+	for _, c := range strings.Split(markets, ",") {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			codes = append(codes, c)
+		}
+	}
+
+	created := time.Now().Format("2006-01-02 15:04:05")
+	modified := time.Now().Format("2006-01-02 15:04:05")
+
+	return &OddsProfiles{
+		ClientID: clientID,
+		Method:   method,
+		Margin:   margin,
+		MinOdd:   minOdd,
+		MaxOdd:   maxOdd,
+		Ladder:   strings.ReplaceAll(ladder, " ", ""),
+		Markets:  strings.Join(codes, ","),
+		Status:   status,
+		Created:  created,
+		Modified: modified,
+	}, nil
+}
+
+// ParseLadder : reads "2:0.01,3:0.02,5:0.05,10:0.1,20:0.5,*:1", prices up to 2 are rounded
+// down to 0.01, up to 3 to 0.02 and so on, * covers every price above the last step.
+// An empty ladder rounds every price down to 0.01.
+func ParseLadder(ladder string) ([]Step, error) {
+
+	ss := []Step{}
+	if strings.TrimSpace(ladder) == "" {
+		return ss, nil
+	}
+
+	for _, x := range strings.Split(ladder, ",") {
+
+		parts := strings.Split(strings.TrimSpace(x), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("ladder step %q must be price:increment", x)
+		}
+
+		s := Step{UpTo: -1}
+		if strings.TrimSpace(parts[0]) != "*" {
+			upTo, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+			if err != nil || upTo <= 1 {
+				return nil, fmt.Errorf("ladder step %q has no valid price", x)
+			}
+			s.UpTo = upTo
+		}
+
+		increment, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || increment < 0.01 {
+			return nil, fmt.Errorf("ladder step %q increment must be at least 0.01", x)
+		}
+		s.Increment = increment
+
+		ss = append(ss, s)
+	}
+
+	// * goes last
+	sort.SliceStable(ss, func(i, j int) bool {
+		if ss[i].UpTo < 0 || ss[j].UpTo < 0 {
+			return ss[j].UpTo < 0 && ss[i].UpTo >= 0
+		}
+		return ss[i].UpTo < ss[j].UpTo
+	})
+
+	return ss, nil
+}
+
+// MarketCodes : markets offered by the profile, every market when empty.
+func (p OddsProfiles) MarketCodes() map[string]bool {
+	mm := make(map[string]bool)
+	for _, c := range strings.Split(p.Markets, ",") {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			mm[c] = true
+		}
+	}
+	return mm
+}
+
+// OddsKey : redis key of the odds of a season week priced for a client.
+func OddsKey(apiDate, seasonWeekID, clientID string) string {
+	return fmt.Sprintf("%s_%s_%s_%s", "pr_odds", apiDate, seasonWeekID, clientID)
+}
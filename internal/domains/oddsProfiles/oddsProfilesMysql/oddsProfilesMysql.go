@@ -0,0 +1,104 @@
+package oddsProfilesMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles"
+)
+
+var _ oddsProfiles.OddsProfilesRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save : adds the profile of a client or replaces it.
+func (mr *MysqlRepository) Save(ctx context.Context, t oddsProfiles.OddsProfiles) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT odds_profiles SET client_id=?,method=?,margin=?,min_odd=?,max_odd=?,ladder=?,markets=?,status=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE method=values(method),margin=values(margin), \n"+
+		"min_odd=values(min_odd),max_odd=values(max_odd),ladder=values(ladder),markets=values(markets), \n"+
+		"status=values(status),modified=now()",
+		t.ClientID, t.Method, t.Margin, t.MinOdd, t.MaxOdd, t.Ladder, t.Markets, t.Status)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save odds profile : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last odds profile ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// GetProfiles : every profile, active or not
+func (r *MysqlRepository) GetProfiles(ctx context.Context) ([]oddsProfiles.OddsProfiles, error) {
+	statement := fmt.Sprintf("select odds_profile_id,client_id,method,margin,min_odd,max_odd,ladder,markets,status,created,modified \n" +
+		"from odds_profiles order by client_id")
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scan(raws)
+}
+
+// GetProfileByClientID : profile of a client
+func (r *MysqlRepository) GetProfileByClientID(ctx context.Context, clientID string) ([]oddsProfiles.OddsProfiles, error) {
+	statement := fmt.Sprintf("select odds_profile_id,client_id,method,margin,min_odd,max_odd,ladder,markets,status,created,modified \n" +
+		"from odds_profiles where client_id=?")
+
+	raws, err := r.db.Query(statement, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scan(raws)
+}
+
+func (r *MysqlRepository) scan(raws *sql.Rows) ([]oddsProfiles.OddsProfiles, error) {
+	defer raws.Close()
+
+	var gc []oddsProfiles.OddsProfiles
+	for raws.Next() {
+		var g oddsProfiles.OddsProfiles
+		err := raws.Scan(&g.OddsProfileID, &g.ClientID, &g.Method, &g.Margin, &g.MinOdd, &g.MaxOdd, &g.Ladder,
+			&g.Markets, &g.Status, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err := raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
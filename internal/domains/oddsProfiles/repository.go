@@ -0,0 +1,10 @@
+package oddsProfiles
+
+import "context"
+
+// OddsProfilesRepository contains methods that implements oddsProfiles struct
+type OddsProfilesRepository interface {
+	Save(ctx context.Context, t OddsProfiles) (int, error)
+	GetProfiles(ctx context.Context) ([]OddsProfiles, error)
+	GetProfileByClientID(ctx context.Context, clientID string) ([]OddsProfiles, error)
+}
@@ -0,0 +1,31 @@
+package oddsProfiles
+
+// Profile statuses, only active profiles are priced.
+const (
+	Active   = "active"
+	Inactive = "inactive"
+)
+
+// OddsProfiles : how the prices of a client differ from the published ones. Margin is the
+// target overround put back with Method once the published margin is taken out, an empty
+// Method keeps the published prices. Ladder rounds prices down, see ParseLadder. Markets
+// is a comma separated list of the market codes offered, every market when empty.
+type OddsProfiles struct {
+	OddsProfileID string  `json:"odds_profile_id"`
+	ClientID      string  `json:"client_id"`
+	Method        string  `json:"method"`
+	Margin        float64 `json:"margin"`
+	MinOdd        float64 `json:"min_odd"`
+	MaxOdd        float64 `json:"max_odd"`
+	Ladder        string  `json:"ladder"`
+	Markets       string  `json:"markets"`
+	Status        string  `json:"status"`
+	Created       string  `json:"created"`
+	Modified      string  `json:"modified"`
+}
+
+// Step : prices up to UpTo are rounded down to a multiple of Increment.
+type Step struct {
+	UpTo      float64 `json:"up_to"`
+	Increment float64 `json:"increment"`
+}
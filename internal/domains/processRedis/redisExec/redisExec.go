@@ -199,6 +199,21 @@ func (mr *RedisConfigs) ZRem(ctx context.Context, nameOfSet string, val string)
 	return response, nil
 }
 
+// IsZMember : true when val is in the sorted set
+func (mr *RedisConfigs) IsZMember(ctx context.Context, set, val string) (bool, error) {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("ZSCORE", set, val))
+	if err == redis.ErrNil {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("Failed to get score of %s in set %s | err : %w", val, set, err)
+	}
+
+	return true, nil
+}
+
 func (mr *RedisConfigs) HGet(ctx context.Context, key, field string) (string, error) {
 	conn := mr.r.Get()
 	defer conn.Close()
@@ -263,3 +278,273 @@ func (mr *RedisConfigs) SortedSetLen(ctx context.Context, key string) (int, erro
 
 	return len, nil
 }
+
+// reapLeases : members whose lease has run out go back to the set with their score.
+const reapLeases = `
+local now = redis.call('TIME')
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ms)
+for _, m in ipairs(expired) do
+	local score = redis.call('HGET', KEYS[3], m)
+	if score then
+		redis.call('ZADD', KEYS[1], score, m)
+	end
+	redis.call('ZREM', KEYS[2], m)
+	redis.call('HDEL', KEYS[3], m)
+end
+`
+
+// leasedSets : set of the sorted sets members are leased from, read by ZReap.
+const leasedSets = "leased_sets"
+
+// claimScript : KEYS set, leases, lease scores, leased sets. ARGV fetched, pick, lease in
+// milliseconds. Returns the member then the members it was picked from, in order.
+var claimScript = redis.NewScript(4, `
+redis.replicate_commands()
+`+reapLeases+`
+local n = redis.call('ZCARD', KEYS[1])
+local fetched = tonumber(ARGV[1])
+if fetched > 0 and fetched < n then
+	n = fetched
+end
+if n == 0 then
+	return false
+end
+local candidates = redis.call('ZRANGE', KEYS[1], 0, n - 1)
+local idx = math.floor(tonumber(ARGV[2]) * n)
+if idx >= n then
+	idx = n - 1
+end
+local member = candidates[idx + 1]
+local score = redis.call('ZSCORE', KEYS[1], member)
+redis.call('ZREM', KEYS[1], member)
+local lease = tonumber(ARGV[3])
+if lease > 0 then
+	redis.call('ZADD', KEYS[2], ms + lease, member)
+	redis.call('HSET', KEYS[3], member, score)
+	redis.call('SADD', KEYS[4], KEYS[1])
+end
+table.insert(candidates, 1, member)
+return candidates
+`)
+
+// claimFromScript : claimScript with the candidates read from ARGV pool members still in
+// set, in pool order, rather than from the head of set. ARGV fetched, pick, lease in
+// milliseconds then the pool.
+var claimFromScript = redis.NewScript(4, `
+redis.replicate_commands()
+`+reapLeases+`
+local fetched = tonumber(ARGV[1])
+local candidates = {}
+for i = 4, #ARGV do
+	if fetched > 0 and #candidates >= fetched then
+		break
+	end
+	if redis.call('ZSCORE', KEYS[1], ARGV[i]) then
+		table.insert(candidates, ARGV[i])
+	end
+end
+local n = #candidates
+if n == 0 then
+	return false
+end
+local idx = math.floor(tonumber(ARGV[2]) * n)
+if idx >= n then
+	idx = n - 1
+end
+local member = candidates[idx + 1]
+local score = redis.call('ZSCORE', KEYS[1], member)
+redis.call('ZREM', KEYS[1], member)
+local lease = tonumber(ARGV[3])
+if lease > 0 then
+	redis.call('ZADD', KEYS[2], ms + lease, member)
+	redis.call('HSET', KEYS[3], member, score)
+	redis.call('SADD', KEYS[4], KEYS[1])
+end
+table.insert(candidates, 1, member)
+return candidates
+`)
+
+// releaseScript : KEYS set, leases, lease scores. ARGV member.
+var releaseScript = redis.NewScript(3, `
+local score = redis.call('HGET', KEYS[3], ARGV[1])
+if score then
+	redis.call('ZADD', KEYS[1], score, ARGV[1])
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+// commitScript : KEYS leases, lease scores. ARGV member. 0 when the member holds no lease.
+var commitScript = redis.NewScript(2, `
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+return 1
+`)
+
+// commitAllScript : KEYS leases then lease scores of every member. ARGV members. Returns
+// the position of the first member holding no lease, nothing is committed then, 0 once
+// every lease is committed.
+var commitAllScript = `
+for i = 1, #ARGV do
+	if not redis.call('ZSCORE', KEYS[2 * i - 1], ARGV[i]) then
+		return i
+	end
+end
+for i = 1, #ARGV do
+	redis.call('ZREM', KEYS[2 * i - 1], ARGV[i])
+	redis.call('HDEL', KEYS[2 * i], ARGV[i])
+end
+return 0
+`
+
+// reapScript : KEYS set, leases, lease scores, leased sets. Returns the members put back,
+// set is no longer listed once it holds no lease.
+var reapScript = redis.NewScript(4, `
+redis.replicate_commands()
+`+reapLeases+`
+if redis.call('ZCARD', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[4], KEYS[1])
+end
+return #expired
+`)
+
+// leaseKeys : sorted set of the leased members of set by expiry, and the hash of their scores.
+func leaseKeys(set string) (string, string) {
+	return fmt.Sprintf("%s:leases", set), fmt.Sprintf("%s:lease_scores", set)
+}
+
+// ZClaim : picks and removes a member of set in one script so that two callers never
+// get the same member.
+func (mr *RedisConfigs) ZClaim(ctx context.Context, set string, fetched int, pick float64, lease time.Duration) (string, []string, error) {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	if pick < 0 || pick >= 1 {
+		return "", nil, fmt.Errorf("pick %f must be in [0, 1)", pick)
+	}
+
+	leases, scores := leaseKeys(set)
+	claimed, err := redis.Strings(claimScript.Do(conn, set, leases, scores, leasedSets, fetched, pick, lease.Milliseconds()))
+	if err == redis.ErrNil || (err == nil && len(claimed) == 0) {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("Failed to claim a member of set %s | err : %w", set, err)
+	}
+
+	return claimed[0], claimed[1:], nil
+}
+
+// ZClaimFrom : ZClaim over the members of pool still in set, so that the candidates of a
+// pick are fixed before it.
+func (mr *RedisConfigs) ZClaimFrom(ctx context.Context, set string, pool []string, fetched int, pick float64, lease time.Duration) (string, []string, error) {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	if pick < 0 || pick >= 1 {
+		return "", nil, fmt.Errorf("pick %f must be in [0, 1)", pick)
+	}
+
+	leases, scores := leaseKeys(set)
+	args := redis.Args{}.Add(set, leases, scores, leasedSets, fetched, pick, lease.Milliseconds()).AddFlat(pool)
+	claimed, err := redis.Strings(claimFromScript.Do(conn, args...))
+	if err == redis.ErrNil || (err == nil && len(claimed) == 0) {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("Failed to claim a member of set %s | err : %w", set, err)
+	}
+
+	return claimed[0], claimed[1:], nil
+}
+
+// ZCommit : drops the lease of a claimed member, it stays out of set. A lease that ran out
+// and was handed back to set can not be committed.
+func (mr *RedisConfigs) ZCommit(ctx context.Context, set, member string) error {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	leases, scores := leaseKeys(set)
+	held, err := redis.Int(commitScript.Do(conn, leases, scores, member))
+	if err != nil {
+		return fmt.Errorf("Failed to commit %s claimed from set %s | err : %w", member, set, err)
+	}
+
+	if held == 0 {
+		return fmt.Errorf("lease of %s claimed from set %s has run out", member, set)
+	}
+
+	return nil
+}
+
+// ZRelease : puts a claimed member back in set with the score it had.
+func (mr *RedisConfigs) ZRelease(ctx context.Context, set, member string) error {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	leases, scores := leaseKeys(set)
+	_, err := releaseScript.Do(conn, set, leases, scores, member)
+	if err != nil {
+		return fmt.Errorf("Failed to release %s back into set %s | err : %w", member, set, err)
+	}
+
+	return nil
+}
+
+// ZCommitAll : drops the lease of every member in one script, nothing is committed when
+// the lease of one of them ran out.
+func (mr *RedisConfigs) ZCommitAll(ctx context.Context, leases []processRedis.Lease) error {
+	if len(leases) == 0 {
+		return nil
+	}
+
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	args := []interface{}{}
+	for _, l := range leases {
+		k, sc := leaseKeys(l.Set)
+		args = append(args, k, sc)
+	}
+	for _, l := range leases {
+		args = append(args, l.Member)
+	}
+
+	missing, err := redis.Int(redis.NewScript(2*len(leases), commitAllScript).Do(conn, args...))
+	if err != nil {
+		return fmt.Errorf("Failed to commit %d claimed members | err : %w", len(leases), err)
+	}
+
+	if missing > 0 {
+		l := leases[missing-1]
+		return fmt.Errorf("lease of %s claimed from set %s has run out, none committed", l.Member, l.Set)
+	}
+
+	return nil
+}
+
+// ZReap : puts the members whose lease ran out back in the sets they were claimed from,
+// claims only reap the set they draw from.
+func (mr *RedisConfigs) ZReap(ctx context.Context) (int, error) {
+	conn := mr.r.Get()
+	defer conn.Close()
+
+	sets, err := redis.Strings(conn.Do("SMEMBERS", leasedSets))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read the leased sets | err : %w", err)
+	}
+
+	reaped := 0
+	for _, set := range sets {
+		leases, scores := leaseKeys(set)
+		n, err := redis.Int(reapScript.Do(conn, set, leases, scores, leasedSets))
+		if err != nil {
+			return reaped, fmt.Errorf("Failed to reap the leases of set %s | err : %w", set, err)
+		}
+		reaped += n
+	}
+
+	return reaped, nil
+}
@@ -1,6 +1,21 @@
 package processRedis
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// DefaultLease : how long a claimed member is held before it goes back to its set.
+const DefaultLease = 2 * time.Minute
+
+// ReapInterval : how often daemons put members whose lease ran out back in their sets.
+const ReapInterval = 30 * time.Second
+
+// Lease : a member claimed from set.
+type Lease struct {
+	Set    string
+	Member string
+}
 
 // RunRedis is implemented to save feed.
 type RunRedis interface {
@@ -15,8 +30,27 @@ type RunRedis interface {
 	GetZRangeWithLimit(ctx context.Context, set string, fetched int) ([]string, error)
 	ZRevRange(ctx context.Context, set string) ([]string, error)
 	ZRem(ctx context.Context, nameOfSet string, val string) (interface{}, error)
+	IsZMember(ctx context.Context, set, val string) (bool, error)
 	Delete(ctx context.Context, key string) (interface{}, error)
 	SortedSetLen(ctx context.Context, key string) (int, error)
 
 	GetZRevRangeWithLimit(ctx context.Context, set string, fetched int) ([]string, error)
+
+	// ZClaim removes one of the first fetched members of set in a single step, pick in
+	// [0, 1) choosing which, and returns it with the members it was picked from in order.
+	// The member is leased, it goes back to set with its score unless committed before
+	// lease runs out. Empty when set has no member.
+	ZClaim(ctx context.Context, set string, fetched int, pick float64, lease time.Duration) (string, []string, error)
+	// ZClaimFrom is ZClaim with the candidates taken from the members of pool still in
+	// set, in pool order.
+	ZClaimFrom(ctx context.Context, set string, pool []string, fetched int, pick float64, lease time.Duration) (string, []string, error)
+	// ZCommit keeps a claimed member out of set for good, it fails once the lease ran out.
+	ZCommit(ctx context.Context, set, member string) error
+	// ZRelease puts a claimed member back in set with its score.
+	ZRelease(ctx context.Context, set, member string) error
+	// ZCommitAll commits every lease or, when one of them ran out, none.
+	ZCommitAll(ctx context.Context, leases []Lease) error
+	// ZReap puts the members whose lease ran out back in every set they were claimed
+	// from and returns how many went back.
+	ZReap(ctx context.Context) (int, error)
 }
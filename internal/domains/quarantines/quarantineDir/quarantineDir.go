@@ -0,0 +1,199 @@
+package quarantineDir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/quarantines"
+)
+
+var _ quarantines.QuarantinesRepository = (*QuarantineDirConfigs)(nil)
+
+const reasonSuffix = ".reason.json"
+
+// QuarantineDirConfigs : files are kept as <quarantineDir>/<yyyy-mm-dd>/<country>/<file name>
+type QuarantineDirConfigs struct {
+	quarantineDir string
+	// store holds the feed files when they are not on the local disk.
+	store blobs.BlobsRepository
+}
+
+// New initializes a new quarantine directory.
+func New(quarantineDir string) (*QuarantineDirConfigs, error) {
+
+	if quarantineDir == "" {
+		return nil, fmt.Errorf("quarantineDir not set")
+	}
+
+	c := &QuarantineDirConfigs{
+		quarantineDir: quarantineDir,
+	}
+
+	return c, nil
+}
+
+// NewWithBlobs initializes a quarantine directory for feed files kept in store, rejected
+// files are copied to the local quarantine then removed from the store. Files on the
+// local disk are still renamed.
+func NewWithBlobs(quarantineDir string, store blobs.BlobsRepository) (*QuarantineDirConfigs, error) {
+
+	c, err := New(quarantineDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, local := store.(*blobDir.BlobDirConfigs); !local {
+		c.store = store
+	}
+
+	return c, nil
+}
+
+// Quarantine : moves the rejected file and writes the reason next to it.
+func (s *QuarantineDirConfigs) Quarantine(ctx context.Context, country, directory string, r fileNames.Rejection) error {
+
+	now := time.Now()
+	day := now.Format("2006-01-02")
+
+	dir := filepath.Join(s.quarantineDir, day, country)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to create %s", err, dir)
+	}
+
+	q := quarantines.Quarantined{
+		Day:           day,
+		Country:       country,
+		Directory:     directory,
+		QuarantinedAt: now.Format("2006-01-02 15:04:05"),
+		Rejection:     r,
+	}
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to marshal reason for %s", err, r.FileName)
+	}
+
+	// Reason first, a file is never in quarantine without one.
+	err = os.WriteFile(filepath.Join(dir, r.FileName+reasonSuffix), data, 0644)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to write reason for %s", err, r.FileName)
+	}
+
+	if s.store != nil {
+		err = s.moveBlob(ctx, directory, dir, r.FileName)
+	} else {
+		err = os.Rename(filepath.Join(directory, r.FileName), filepath.Join(dir, r.FileName))
+	}
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to move %s into quarantine", err, r.FileName)
+	}
+
+	log.Printf("Quarantined %s/%s [%s] %s", directory, r.FileName, r.Code, r.Detail)
+
+	return nil
+}
+
+// moveBlob : copies a file out of the store then removes it from there.
+func (s *QuarantineDirConfigs) moveBlob(ctx context.Context, directory, dir, fileName string) error {
+
+	src, err := s.store.Open(ctx, directory, fileName)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, fileName), data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Remove(ctx, directory, fileName)
+}
+
+// QuarantinedFiles : returns quarantined files between fromDay and toDay (yyyy-mm-dd), both included.
+// Empty days mean no bound.
+func (s *QuarantineDirConfigs) QuarantinedFiles(ctx context.Context, fromDay, toDay string) ([]quarantines.Quarantined, error) {
+
+	qf := []quarantines.Quarantined{}
+
+	days, err := os.ReadDir(s.quarantineDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return qf, nil
+		}
+		return qf, fmt.Errorf("Err : %v failed to read %s", err, s.quarantineDir)
+	}
+
+	for _, d := range days {
+
+		if !d.IsDir() {
+			continue
+		}
+
+		if (fromDay != "" && d.Name() < fromDay) || (toDay != "" && d.Name() > toDay) {
+			continue
+		}
+
+		countries, err := os.ReadDir(filepath.Join(s.quarantineDir, d.Name()))
+		if err != nil {
+			return qf, fmt.Errorf("Err : %v failed to read %s", err, d.Name())
+		}
+
+		for _, c := range countries {
+
+			if !c.IsDir() {
+				continue
+			}
+
+			dir := filepath.Join(s.quarantineDir, d.Name(), c.Name())
+			files, err := os.ReadDir(dir)
+			if err != nil {
+				return qf, fmt.Errorf("Err : %v failed to read %s", err, dir)
+			}
+
+			for _, f := range files {
+
+				if !strings.HasSuffix(f.Name(), reasonSuffix) {
+					continue
+				}
+
+				data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+				if err != nil {
+					return qf, fmt.Errorf("Err : %v failed to read %s", err, f.Name())
+				}
+
+				var q quarantines.Quarantined
+				err = json.Unmarshal(data, &q)
+				if err != nil {
+					log.Printf("Err : %v failed to decode %s", err, f.Name())
+					continue
+				}
+
+				qf = append(qf, q)
+			}
+		}
+	}
+
+	sort.Slice(qf, func(i, j int) bool {
+		return qf[i].QuarantinedAt < qf[j].QuarantinedAt
+	})
+
+	return qf, nil
+}
@@ -0,0 +1,13 @@
+package quarantines
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames"
+)
+
+// QuarantinesRepository : keeps rejected feed files out of the pipeline.
+type QuarantinesRepository interface {
+	Quarantine(ctx context.Context, country, directory string, r fileNames.Rejection) error
+	QuarantinedFiles(ctx context.Context, fromDay, toDay string) ([]Quarantined, error)
+}
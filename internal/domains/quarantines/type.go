@@ -0,0 +1,22 @@
+package quarantines
+
+import "github.com/lukemakhanu/magic_carpet/internal/domains/fileNames"
+
+// Quarantined : a feed file moved out of its directory because its name was rejected.
+// It is saved next to the file as <file name>.reason.json
+type Quarantined struct {
+	Day           string              `json:"day"`
+	Country       string              `json:"country"`
+	Directory     string              `json:"directory"`
+	QuarantinedAt string              `json:"quarantined_at"`
+	Rejection     fileNames.Rejection `json:"rejection"`
+}
+
+// QuarantineReport : quarantined files for a day and a country.
+type QuarantineReport struct {
+	Day     string         `json:"day"`
+	Country string         `json:"country"`
+	Total   int            `json:"total"`
+	Codes   map[string]int `json:"codes"`
+	Files   []Quarantined  `json:"files"`
+}
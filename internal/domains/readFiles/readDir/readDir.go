@@ -2,12 +2,17 @@ package readDir
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames/nameParser"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/quarantines"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/readFiles"
 )
 
@@ -16,11 +21,43 @@ var _ readFiles.DirectoryReader = (*ReadDirectoryConfigs)(nil)
 type ReadDirectoryConfigs struct {
 	directory    string
 	combinations string
+	parsers      map[string]fileNames.FileNameParser
+	quarantine   quarantines.QuarantinesRepository
+	country      string
+	store        blobs.BlobsRepository
 }
 
 // New initializes a new instance of Match Client.
+// Badly named files are only logged, use NewWithQuarantine to move them away.
 func New(directory string, combinations string) (*ReadDirectoryConfigs, error) {
 
+	parsers, err := nameParser.Parsers("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithQuarantine(directory, combinations, "", parsers, nil)
+}
+
+// NewWithQuarantine initializes a directory reader that validates file names with
+// parsers and moves rejected files into quarantine.
+func NewWithQuarantine(directory, combinations, country string, parsers map[string]fileNames.FileNameParser, quarantine quarantines.QuarantinesRepository) (*ReadDirectoryConfigs, error) {
+
+	store, err := blobDir.New()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithBlobs(store, directory, combinations, country, parsers, quarantine)
+}
+
+// NewWithBlobs initializes a reader that lists directory in store instead of the local disk.
+func NewWithBlobs(store blobs.BlobsRepository, directory, combinations, country string, parsers map[string]fileNames.FileNameParser, quarantine quarantines.QuarantinesRepository) (*ReadDirectoryConfigs, error) {
+
+	if store == nil {
+		return nil, fmt.Errorf("store not set")
+	}
+
 	if directory == "" {
 		return nil, fmt.Errorf("directory not set")
 	}
@@ -29,9 +66,21 @@ func New(directory string, combinations string) (*ReadDirectoryConfigs, error) {
 		return nil, fmt.Errorf("combinations not set")
 	}
 
+	if len(parsers) == 0 {
+		return nil, fmt.Errorf("parsers not set")
+	}
+
+	if quarantine != nil && country == "" {
+		return nil, fmt.Errorf("country not set")
+	}
+
 	c := &ReadDirectoryConfigs{
 		directory:    directory,
 		combinations: combinations,
+		parsers:      parsers,
+		quarantine:   quarantine,
+		country:      country,
+		store:        store,
 	}
 
 	return c, nil
@@ -40,12 +89,7 @@ func New(directory string, combinations string) (*ReadDirectoryConfigs, error) {
 // GetMatch : returns match and odds from third party provider
 func (s *ReadDirectoryConfigs) ReadDirectory(ctx context.Context) ([]string, error) {
 	fileList := []string{}
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
@@ -55,9 +99,9 @@ func (s *ReadDirectoryConfigs) ReadDirectory(ctx context.Context) ([]string, err
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir()) // 47461300_4_24.txt
+		log.Println(v.Name) // 47461300_4_24.txt
 
-		data := strings.Split(v.Name(), "_")
+		data := strings.Split(v.Name, "_")
 		if len(data) == 3 {
 
 			chr := data[0]
@@ -66,13 +110,13 @@ func (s *ReadDirectoryConfigs) ReadDirectory(ctx context.Context) ([]string, err
 			log.Println("lastCharacter ", lastCharacter, "allowed", allowed)
 
 			if contains(allowed, lastCharacter) == true {
-				fileList = append(fileList, v.Name())
+				fileList = append(fileList, v.Name)
 			} else {
-				log.Println("Skip ", v.Name(), "allowed", allowed)
+				log.Println("Skip ", v.Name, "allowed", allowed)
 			}
 
 		} else {
-			log.Printf("Wrong data format : %s", v.Name())
+			log.Printf("Wrong data format : %s", v.Name)
 		}
 
 	}
@@ -83,12 +127,7 @@ func (s *ReadDirectoryConfigs) ReadDirectory(ctx context.Context) ([]string, err
 // AllFiles : returns all files in a directory
 func (s *ReadDirectoryConfigs) AllFiles(ctx context.Context) ([]lsFiles.FileInfo, error) {
 	fileList := []lsFiles.FileInfo{}
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
@@ -98,34 +137,16 @@ func (s *ReadDirectoryConfigs) AllFiles(ctx context.Context) ([]lsFiles.FileInfo
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir()) // 47461300_4_24.txt
-
-		data := strings.Split(v.Name(), "_")
-		if len(data) == 3 {
-
-			extID := data[0]
-			projectID := data[1]
-			//competitionID := data[2]
-
-			dat := strings.Split(data[2], ".")
-			if len(dat) == 2 {
-
-				rr := lsFiles.FileInfo{
-					ExtID:         extID,
-					ProjectID:     projectID,
-					CompetitionID: dat[0],
-					LsFileName:    v.Name(),
-				}
-				fileList = append(fileList, rr)
-
-			}
+		log.Println(v.Name) // 47461300_4_24.txt
 
-		} else {
-			log.Printf("Wrong data format : %s", v.Name())
+		fn, ok := s.parse(ctx, "odds", v.Name)
+		if ok {
+			rr := fileInfo(fn)
+			rr.ModTime = v.ModTime
+			rr.Size = v.Size
+			fileList = append(fileList, rr)
 		}
 
-		//fileList = append(fileList, v.Name())
-
 	}
 
 	return fileList, nil
@@ -135,24 +156,19 @@ func (s *ReadDirectoryConfigs) AllFiles(ctx context.Context) ([]lsFiles.FileInfo
 func (s *ReadDirectoryConfigs) SameRoundLiveScores(ctx context.Context, fileNames []string) ([]string, error) {
 	fileList := []string{}
 
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir())
+		log.Println(v.Name)
 
-		if contains(fileNames, v.Name()) == true {
-			fileList = append(fileList, v.Name())
+		if contains(fileNames, v.Name) == true {
+			fileList = append(fileList, v.Name)
 		} else {
-			log.Println("Skip ", v.Name())
+			log.Println("Skip ", v.Name)
 		}
 
 	}
@@ -171,12 +187,7 @@ func contains(s []string, str string) bool {
 
 func (s *ReadDirectoryConfigs) ReadLiveScoreDirectory(ctx context.Context) ([]lsFiles.FileInfo, error) {
 	fileList := []lsFiles.FileInfo{}
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
@@ -186,30 +197,14 @@ func (s *ReadDirectoryConfigs) ReadLiveScoreDirectory(ctx context.Context) ([]ls
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir()) // 31114422_3_20.txt
-
-		data := strings.Split(v.Name(), "_")
-		if len(data) == 3 {
-
-			extID := data[0]
-			projectID := data[1]
-			//competitionID := data[2]
-
-			dat := strings.Split(data[2], ".")
-			if len(dat) == 2 {
-
-				rr := lsFiles.FileInfo{
-					ExtID:         extID,
-					ProjectID:     projectID,
-					CompetitionID: dat[0],
-					LsFileName:    v.Name(),
-				}
-				fileList = append(fileList, rr)
+		log.Println(v.Name) // 31114422_3_20.txt
 
-			}
-
-		} else {
-			log.Printf("Wrong data format : %s", v.Name())
+		fn, ok := s.parse(ctx, "ls", v.Name)
+		if ok {
+			rr := fileInfo(fn)
+			rr.ModTime = v.ModTime
+			rr.Size = v.Size
+			fileList = append(fileList, rr)
 		}
 
 	}
@@ -219,12 +214,7 @@ func (s *ReadDirectoryConfigs) ReadLiveScoreDirectory(ctx context.Context) ([]ls
 
 func (s *ReadDirectoryConfigs) ReadWinningOutcomeDirectory(ctx context.Context) ([]lsFiles.FileInfo, error) {
 	fileList := []lsFiles.FileInfo{}
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
@@ -234,30 +224,14 @@ func (s *ReadDirectoryConfigs) ReadWinningOutcomeDirectory(ctx context.Context)
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir()) // wo_31114422_3_20.txt
-
-		data := strings.Split(v.Name(), "_")
-		if len(data) == 4 {
-
-			extID := data[1]
-			projectID := data[2]
-			//competitionID := data[3]
+		log.Println(v.Name) // wo_31114422_3_20.txt
 
-			dat := strings.Split(data[3], ".")
-			if len(dat) == 2 {
-
-				rr := lsFiles.FileInfo{
-					ExtID:         extID,
-					ProjectID:     projectID,
-					CompetitionID: dat[0],
-					LsFileName:    v.Name(),
-				}
-
-				fileList = append(fileList, rr)
-			}
-
-		} else {
-			log.Printf("Wrong data format : %s", v.Name())
+		fn, ok := s.parse(ctx, "wo", v.Name)
+		if ok {
+			rr := fileInfo(fn)
+			rr.ModTime = v.ModTime
+			rr.Size = v.Size
+			fileList = append(fileList, rr)
 		}
 
 	}
@@ -268,12 +242,7 @@ func (s *ReadDirectoryConfigs) ReadWinningOutcomeDirectory(ctx context.Context)
 // ReadTeamDirectory : used to read team directory
 func (s *ReadDirectoryConfigs) ReadTeamDirectory(ctx context.Context) ([]lsFiles.FileInfoTeams, error) {
 	fileList := []lsFiles.FileInfoTeams{}
-	f, err := os.Open(s.directory)
-	if err != nil {
-		return fileList, fmt.Errorf("Err : %v failed to read directory %s", err, s.directory)
-	}
-
-	files, err := f.Readdir(0)
+	files, err := s.store.List(ctx, s.directory)
 	if err != nil {
 		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
 	}
@@ -283,32 +252,68 @@ func (s *ReadDirectoryConfigs) ReadTeamDirectory(ctx context.Context) ([]lsFiles
 
 	for _, v := range files {
 
-		log.Println(v.Name(), v.IsDir()) // 27196_2_380.txt
+		log.Println(v.Name) // 27196_2_380.txt
 
-		data := strings.Split(v.Name(), "_")
-		if len(data) == 3 {
+		fn, ok := s.parse(ctx, "teams", v.Name)
+		if ok {
+			rr := lsFiles.FileInfoTeams{
+				SeasonID:      fn.SeasonID,
+				CompetitionID: fn.CompetitionID,
+				Count:         fn.Count,
+				Name:          fn.Name,
+			}
+			fileList = append(fileList, rr)
+		}
 
-			seasonID := data[0]
-			competitionID := data[1]
+	}
 
-			dat := strings.Split(data[2], ".")
-			if len(dat) == 2 {
+	return fileList, nil
+}
 
-				rr := lsFiles.FileInfoTeams{
-					SeasonID:      seasonID,
-					CompetitionID: competitionID,
-					Count:         dat[0],
-					Name:          v.Name(),
-				}
+// parse : validates name, rejected files are moved into quarantine when one is set.
+// Hidden files are uploads still in progress and are left alone.
+func (s *ReadDirectoryConfigs) parse(ctx context.Context, fileType, name string) (fileNames.FileName, bool) {
 
-				fileList = append(fileList, rr)
-			}
+	if strings.HasPrefix(name, ".") {
+		return fileNames.FileName{}, false
+	}
 
-		} else {
-			log.Printf("Wrong data format : %s", v.Name())
-		}
+	p, ok := s.parsers[fileType]
+	if !ok {
+		log.Printf("No file name parser for %s : %s", fileType, name)
+		return fileNames.FileName{}, false
+	}
 
+	fn, err := p.Parse(name)
+	if err == nil {
+		return fn, true
 	}
 
-	return fileList, nil
+	var r *fileNames.Rejection
+	if !errors.As(err, &r) {
+		log.Printf("Err : %v failed to parse %s", err, name)
+		return fn, false
+	}
+
+	if s.quarantine == nil {
+		log.Printf("Wrong data format : %v", r)
+		return fn, false
+	}
+
+	err = s.quarantine.Quarantine(ctx, s.country, s.directory, *r)
+	if err != nil {
+		log.Printf("Err : %v failed to quarantine %s", err, name)
+	}
+
+	return fn, false
+}
+
+// fileInfo : odds, wo and ls file names as returned to the services.
+func fileInfo(fn fileNames.FileName) lsFiles.FileInfo {
+	return lsFiles.FileInfo{
+		ExtID:         fn.ExtID,
+		ProjectID:     fn.ProjectID,
+		CompetitionID: fn.CompetitionID,
+		LsFileName:    fn.Name,
+	}
 }
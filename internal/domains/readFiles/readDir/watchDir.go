@@ -0,0 +1,227 @@
+package readDir
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/readFiles"
+)
+
+var _ readFiles.DirectoryWatcher = (*ReadDirectoryConfigs)(nil)
+
+const settleTime = time.Second
+
+// pollTime : how often a store without events is listed.
+const pollTime = 2 * time.Second
+
+// pollLookBack : files modified this long before the poller started are still handed over,
+// the startup scan may have listed the directory before the poller did.
+const pollLookBack = time.Minute
+
+// ScanDirectory : returns files of fileType that arrived at or after since, oldest first.
+// Used on startup to catch up with files that landed while the watcher was down, a zero
+// since returns every file.
+func (s *ReadDirectoryConfigs) ScanDirectory(ctx context.Context, fileType string, since time.Time) ([]lsFiles.FileInfo, error) {
+	fileList := []lsFiles.FileInfo{}
+	files, err := s.store.List(ctx, s.directory)
+	if err != nil {
+		return fileList, fmt.Errorf("Err : %v failed to read files %s", err, s.directory)
+	}
+
+	for _, v := range files {
+
+		if v.ModTime.Before(since) {
+			continue
+		}
+
+		fn, ok := s.parse(ctx, fileType, v.Name)
+		if !ok {
+			continue
+		}
+
+		rr := fileInfo(fn)
+		rr.ModTime = v.ModTime
+		rr.Size = v.Size
+		fileList = append(fileList, rr)
+	}
+
+	sort.Slice(fileList, func(i, j int) bool {
+		return fileList[i].ModTime.Before(fileList[j].ModTime)
+	})
+
+	return fileList, nil
+}
+
+// WatchDirectory : pushes every new file of fileType into files until ctx is done.
+// Only the local disk sends events, other stores are listed every pollTime.
+func (s *ReadDirectoryConfigs) WatchDirectory(ctx context.Context, fileType string, files chan<- lsFiles.FileInfo) error {
+
+	if _, ok := s.store.(*blobDir.BlobDirConfigs); !ok {
+		return s.pollDirectory(ctx, fileType, files)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to create watcher", err)
+	}
+	defer watcher.Close()
+
+	err = watcher.Add(s.directory)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to watch directory %s", err, s.directory)
+	}
+
+	log.Printf("Watching %s for %s files", s.directory, fileType)
+
+	// A file is only handed over once nothing was written to it for settleTime,
+	// a file still being written would otherwise be hashed half way.
+	pending := make(map[string]time.Time)
+	settle := time.NewTicker(settleTime / 2)
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("watcher closed for %s", s.directory)
+			}
+
+			// Files moved into the directory also arrive as Create.
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+				pending[event.Name] = time.Now()
+			}
+
+		case now := <-settle.C:
+			settled := []lsFiles.FileInfo{}
+			for name, last := range pending {
+
+				if now.Sub(last) < settleTime {
+					continue
+				}
+				delete(pending, name)
+
+				st, err := os.Stat(name)
+				if err != nil {
+					log.Printf("Err : %v failed to stat %s", err, name)
+					continue
+				}
+
+				if st.IsDir() {
+					continue
+				}
+
+				fn, ok := s.parse(ctx, fileType, filepath.Base(name))
+				if !ok {
+					continue
+				}
+
+				rr := fileInfo(fn)
+				rr.ModTime = blobDir.ArrivalTime(st)
+				rr.Size = st.Size()
+				settled = append(settled, rr)
+			}
+
+			err := sendFiles(ctx, settled, files)
+			if err != nil {
+				return err
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("watcher closed for %s", s.directory)
+			}
+			// Overflow means events were dropped, the caller has to rescan.
+			return fmt.Errorf("Err : %v watching %s", err, s.directory)
+		}
+	}
+}
+
+// pollDirectory : lists the directory every pollTime and pushes files that are new or
+// changed since the previous listing. Objects only show up once fully uploaded so there
+// is nothing to settle.
+func (s *ReadDirectoryConfigs) pollDirectory(ctx context.Context, fileType string, files chan<- lsFiles.FileInfo) error {
+
+	log.Printf("Polling %s for %s files", s.directory, fileType)
+
+	since := time.Now().Add(-pollLookBack)
+	seen := make(map[string]time.Time)
+
+	ticker := time.NewTicker(pollTime)
+	defer ticker.Stop()
+
+	for {
+
+		listed, err := s.store.List(ctx, s.directory)
+		if err != nil {
+			return fmt.Errorf("Err : %v polling %s", err, s.directory)
+		}
+
+		// Only names still listed are remembered, removed files are forgotten.
+		current := make(map[string]time.Time, len(listed))
+		changed := []lsFiles.FileInfo{}
+		for _, v := range listed {
+
+			current[v.Name] = v.ModTime
+			last, ok := seen[v.Name]
+			if ok && last.Equal(v.ModTime) {
+				continue
+			}
+
+			if v.ModTime.Before(since) {
+				continue
+			}
+
+			fn, ok := s.parse(ctx, fileType, v.Name)
+			if !ok {
+				continue
+			}
+
+			rr := fileInfo(fn)
+			rr.ModTime = v.ModTime
+			rr.Size = v.Size
+			changed = append(changed, rr)
+		}
+		seen = current
+
+		err = sendFiles(ctx, changed, files)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendFiles : hands ff over oldest first, the cursor of the reader moves with every file
+// it saves so that a newer file must never be saved before an older one.
+func sendFiles(ctx context.Context, ff []lsFiles.FileInfo, files chan<- lsFiles.FileInfo) error {
+
+	sort.Slice(ff, func(i, j int) bool {
+		return ff[i].ModTime.Before(ff[j].ModTime)
+	})
+
+	for _, f := range ff {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case files <- f:
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package readFiles
 
 import (
 	"context"
+	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
 )
@@ -15,3 +16,10 @@ type DirectoryReader interface {
 	ReadWinningOutcomeDirectory(ctx context.Context) ([]lsFiles.FileInfo, error)
 	ReadTeamDirectory(ctx context.Context) ([]lsFiles.FileInfoTeams, error)
 }
+
+// DirectoryWatcher is implemented to pick up feed files as they land.
+// fileType is one of odds, wo or ls.
+type DirectoryWatcher interface {
+	ScanDirectory(ctx context.Context, fileType string, since time.Time) ([]lsFiles.FileInfo, error)
+	WatchDirectory(ctx context.Context, fileType string, files chan<- lsFiles.FileInfo) error
+}
@@ -0,0 +1,15 @@
+package retentions
+
+import "context"
+
+// RetentionsRepository : ageing of the file tracking tables. Only files of rounds whose
+// winning outcomes are processed are ever returned.
+type RetentionsRepository interface {
+	CountAgedFiles(ctx context.Context, table string, days int, archiveDirectory string) (int, error)
+	CountAgedRows(ctx context.Context, table string, days int) (int, error)
+	AgedFiles(ctx context.Context, table string, days int, archiveDirectory string, limit int) ([]TrackedFile, error)
+	MoveFile(ctx context.Context, table, id, fileName, directory string) (int64, error)
+	AgedRows(ctx context.Context, table string, days int, archiveDirectory string, limit int) ([]string, error)
+	ArchiveRows(ctx context.Context, table string, ids []string) (int64, error)
+	PurgeRows(ctx context.Context, table string, ids []string) (int64, error)
+}
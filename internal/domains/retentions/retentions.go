@@ -0,0 +1,40 @@
+package retentions
+
+import "fmt"
+
+// NewPolicy instantiate a retention policy
+func NewPolicy(table string, fileDays, rowDays int, rowAction string) (*Policy, error) {
+
+	if _, ok := FileTypes[table]; !ok {
+		return &Policy{}, fmt.Errorf("table %s not supported", table)
+	}
+
+	if fileDays < 0 || rowDays < 0 {
+		return &Policy{}, fmt.Errorf("days must not be negative")
+	}
+
+	if rowAction == "" {
+		rowAction = RowKeep
+	}
+
+	if rowAction != RowKeep && rowAction != RowArchive && rowAction != RowPurge {
+		return &Policy{}, fmt.Errorf("row action %s not supported", rowAction)
+	}
+
+	// A row pointing at a live file must not disappear before the file is archived.
+	if rowAction != RowKeep && (rowDays == 0 || (fileDays > 0 && rowDays < fileDays)) {
+		return &Policy{}, fmt.Errorf("row days of %s must be set and not below file days", table)
+	}
+
+	// A re-delivered file whose row is gone would be saved again as a new file.
+	if rowAction != RowKeep && rowDays < DedupDays {
+		return &Policy{}, fmt.Errorf("row days of %s must be at least %d, re-delivered files are recognised by their row", table, DedupDays)
+	}
+
+	return &Policy{
+		Table:     table,
+		FileDays:  fileDays,
+		RowDays:   rowDays,
+		RowAction: rowAction,
+	}, nil
+}
@@ -0,0 +1,268 @@
+package retentionsMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/retentions"
+)
+
+var _ retentions.RetentionsRepository = (*MysqlRepository)(nil)
+
+// columns : names used by each file tracking table.
+type columns struct {
+	id    string
+	name  string
+	dir   string
+	where string
+}
+
+// Odds and live score files are only aged once the winning outcomes of their round are
+// processed, odds are linked to their round through match_odds.
+var tables = map[string]columns{
+	"o_files": {id: "odds_file_id", name: "odds_file_name", dir: "file_directory",
+		where: "exists (select 1 from match_odds mo inner join winning_outcome_files w on w.ext_id = mo.round_id and w.country = mo.country \n" +
+			"where mo.parent_id = t.parent_id and mo.country = t.country and w.status='processed')"},
+	"winning_outcome_files": {id: "wo_file_id", name: "wo_file_name", dir: "wo_dir", where: "t.status='processed'"},
+	"live_scores_files": {id: "live_score_file_id", name: "ls_file_name", dir: "ls_dir",
+		where: "exists (select 1 from winning_outcome_files w where w.ext_id = t.ext_id and w.country = t.country and w.status='processed')"},
+}
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+func tableColumns(table string) (columns, error) {
+	c, ok := tables[table]
+	if !ok {
+		return c, fmt.Errorf("table %s not supported", table)
+	}
+	return c, nil
+}
+
+// agedWhere : rows of t created more than days ago whose round is processed.
+func agedWhere(c columns, days int) string {
+	where := fmt.Sprintf("t.created < date(now()) - interval %d day", days)
+	if c.where != "" {
+		where += " and " + c.where
+	}
+	return where
+}
+
+// CountAgedFiles : number of rows older than days whose file is not yet in the archive
+func (r *MysqlRepository) CountAgedFiles(ctx context.Context, table string, days int, archiveDirectory string) (int, error) {
+	var count int
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return count, err
+	}
+
+	statement := fmt.Sprintf("select count(*) from %s t where %s and t.%s not like '%s%%'", table, agedWhere(c, days), c.dir, archiveDirectory)
+	err = r.db.QueryRow(statement).Scan(&count)
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// CountAgedRows : number of rows older than days
+func (r *MysqlRepository) CountAgedRows(ctx context.Context, table string, days int) (int, error) {
+	var count int
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return count, err
+	}
+
+	statement := fmt.Sprintf("select count(*) from %s t where %s", table, agedWhere(c, days))
+	err = r.db.QueryRow(statement).Scan(&count)
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// AgedFiles : rows older than days whose file is not yet in the archive
+func (r *MysqlRepository) AgedFiles(ctx context.Context, table string, days int, archiveDirectory string, limit int) ([]retentions.TrackedFile, error) {
+	var gc []retentions.TrackedFile
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := fmt.Sprintf("select t.%s,t.%s,t.%s,t.country,t.created from %s t where %s and t.%s not like '%s%%' \n"+
+		"order by t.%s limit %d", c.id, c.name, c.dir, table, agedWhere(c, days), c.dir, archiveDirectory, c.id, limit)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g retentions.TrackedFile
+		err := raws.Scan(&g.ID, &g.FileName, &g.Directory, &g.Country, &g.Created)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// MoveFile : points a row at the archived file
+func (mr *MysqlRepository) MoveFile(ctx context.Context, table, id, fileName, directory string) (int64, error) {
+	var rs int64
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return rs, err
+	}
+
+	statement := fmt.Sprintf("update %s set %s=?,%s=?,modified=now() where %s = ? ", table, c.name, c.dir, c.id)
+	result, err := mr.db.Exec(statement, fileName, directory, id)
+	if err != nil {
+		return rs, fmt.Errorf("unable to move file of %s %s : %v", table, id, err)
+	}
+	return result.RowsAffected()
+}
+
+// AgedRows : ids of rows older than days, only those whose file is in archiveDirectory
+// when it is set
+func (r *MysqlRepository) AgedRows(ctx context.Context, table string, days int, archiveDirectory string, limit int) ([]string, error) {
+	var gc []string
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+
+	where := agedWhere(c, days)
+	if archiveDirectory != "" {
+		where += fmt.Sprintf(" and t.%s like '%s%%'", c.dir, archiveDirectory)
+	}
+
+	statement := fmt.Sprintf("select t.%s from %s t where %s order by t.%s limit %d", c.id, table, where, c.id, limit)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g string
+		err := raws.Scan(&g)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// ArchiveRows : copies rows into <table>_archive and deletes them in one transaction
+func (mr *MysqlRepository) ArchiveRows(ctx context.Context, table string, ids []string) (int64, error) {
+	var rs int64
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return rs, err
+	}
+
+	if len(ids) == 0 {
+		return rs, nil
+	}
+
+	in, args := inClause(ids)
+
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return rs, fmt.Errorf("unable to start transaction : %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("insert ignore into %s_archive select * from %s where %s in (%s)", table, table, c.id, in), args...)
+	if err != nil {
+		tx.Rollback()
+		return rs, fmt.Errorf("unable to archive rows of %s : %v", table, err)
+	}
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf("delete from %s where %s in (%s)", table, c.id, in), args...)
+	if err != nil {
+		tx.Rollback()
+		return rs, fmt.Errorf("unable to delete archived rows of %s : %v", table, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return rs, fmt.Errorf("unable to commit archived rows of %s : %v", table, err)
+	}
+
+	return result.RowsAffected()
+}
+
+// PurgeRows : deletes rows
+func (mr *MysqlRepository) PurgeRows(ctx context.Context, table string, ids []string) (int64, error) {
+	var rs int64
+
+	c, err := tableColumns(table)
+	if err != nil {
+		return rs, err
+	}
+
+	if len(ids) == 0 {
+		return rs, nil
+	}
+
+	in, args := inClause(ids)
+	result, err := mr.db.Exec(fmt.Sprintf("delete from %s where %s in (%s)", table, c.id, in), args...)
+	if err != nil {
+		return rs, fmt.Errorf("unable to purge rows of %s : %v", table, err)
+	}
+	return result.RowsAffected()
+}
+
+func inClause(ids []string) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","), args
+}
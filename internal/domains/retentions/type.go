@@ -0,0 +1,38 @@
+package retentions
+
+// Row actions once a row is older than RowDays.
+const (
+	RowKeep    = "keep"
+	RowArchive = "archive"
+	RowPurge   = "purge"
+)
+
+// DedupDays : a re-delivered feed file is only recognised by the sha256 of its row, rows
+// are not moved out of a file tracking table sooner.
+const DedupDays = 30
+
+// FileTypes : file tracking tables handled by the retention and the file type they hold.
+var FileTypes = map[string]string{
+	"o_files":               "odds",
+	"winning_outcome_files": "wo",
+	"live_scores_files":     "ls",
+}
+
+// Policy : how long the files and the rows of a file tracking table are kept. Files older
+// than FileDays are moved to the archive, rows older than RowDays are moved into
+// <table>_archive, purged or kept. Zero days leaves files or rows alone.
+type Policy struct {
+	Table     string `mapstructure:"table" json:"table"`
+	FileDays  int    `mapstructure:"file_days" json:"file_days"`
+	RowDays   int    `mapstructure:"row_days" json:"row_days"`
+	RowAction string `mapstructure:"row_action" json:"row_action"`
+}
+
+// TrackedFile : a row of a file tracking table.
+type TrackedFile struct {
+	ID        string
+	FileName  string
+	Directory string
+	Country   string
+	Created   string
+}
@@ -0,0 +1,14 @@
+package reusePolicies
+
+import "context"
+
+// ReusePoliciesRepository : enforces the reuse policy on the keys claimed from the CL_ sets.
+type ReusePoliciesRepository interface {
+	// Check returns the policy that keeps key from sc and counts it, empty when allowed.
+	Check(ctx context.Context, sc Scope, key string) (string, error)
+	// Use records keys as shown in sc, or none of them when the policy keeps one from sc.
+	Use(ctx context.Context, sc Scope, keys []string) error
+	// Release drops the usages Use recorded for keys in sc.
+	Release(ctx context.Context, sc Scope, keys []string) error
+	Counters(ctx context.Context, from, to string) ([]Counter, error)
+}
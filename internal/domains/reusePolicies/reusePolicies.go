@@ -0,0 +1,83 @@
+package reusePolicies
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NewPolicy : validates the reuse policy.
+func NewPolicy(globalDays, playerDays int, season, seasonWeek bool) (*Policy, error) {
+
+	if globalDays < 0 {
+		return nil, fmt.Errorf("global days can not be negative, %d set", globalDays)
+	}
+
+	if playerDays < 0 {
+		return nil, fmt.Errorf("player days can not be negative, %d set", playerDays)
+	}
+
+	return &Policy{
+		GlobalDays: globalDays,
+		PlayerDays: playerDays,
+		Season:     season,
+		SeasonWeek: seasonWeek,
+	}, nil
+}
+
+// SourceMatch : country and parent match id of a key, keO:31475634 for production and
+// keIO:31475634 for instant.
+func SourceMatch(key string) (string, string, error) {
+	for _, sep := range []string{"IO:", "O:"} {
+		parts := strings.Split(key, sep)
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1], nil
+		}
+	}
+	return "", "", fmt.Errorf("key %s is not a match key", key)
+}
+
+// Horizon : how far back usages are read to check p, season rules aside.
+func (p Policy) Horizon() int {
+	return max(p.GlobalDays, p.PlayerDays)
+}
+
+// Evaluate : the first policy the usages of a source match keep it from sc by, empty when
+// it may be shown.
+func (p Policy) Evaluate(uu []Usage, sc Scope, now time.Time) string {
+
+	blocked := make(map[string]bool)
+	for _, u := range uu {
+
+		if p.SeasonWeek && sc.SeasonWeekID != "" && u.Consumer == sc.Consumer && u.SeasonWeekID == sc.SeasonWeekID {
+			blocked[SameSeasonWeek] = true
+		}
+
+		if p.Season && sc.SeasonID != "" && u.Consumer == sc.Consumer && u.SeasonID == sc.SeasonID {
+			blocked[SameSeason] = true
+		}
+
+		created, err := time.ParseInLocation("2006-01-02 15:04:05", u.Created, now.Location())
+		if err != nil {
+			continue
+		}
+
+		if p.PlayerDays > 0 && sc.PlayerID != "" && u.PlayerID == sc.PlayerID &&
+			now.Sub(created) < time.Duration(p.PlayerDays)*24*time.Hour {
+			blocked[PlayerCoolDown] = true
+		}
+
+		if p.GlobalDays > 0 &&
+			now.Sub(created) < time.Duration(p.GlobalDays)*24*time.Hour {
+			blocked[GlobalCoolDown] = true
+		}
+	}
+
+	for _, policy := range []string{SameSeasonWeek, SameSeason, PlayerCoolDown, GlobalCoolDown} {
+		if blocked[policy] {
+			return policy
+		}
+	}
+
+	return ""
+}
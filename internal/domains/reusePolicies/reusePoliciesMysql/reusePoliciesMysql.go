@@ -0,0 +1,239 @@
+package reusePoliciesMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies"
+)
+
+var _ reusePolicies.ReusePoliciesRepository = (*MysqlRepository)(nil)
+
+// querier : the connection or the transaction usages are read with.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type MysqlRepository struct {
+	db     *sql.DB
+	policy reusePolicies.Policy
+}
+
+// Create a new mysql repository enforcing policy
+func New(connectionString string, policy reusePolicies.Policy) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db:     db,
+		policy: policy,
+	}, nil
+}
+
+// Check : policy the usages of the source match of key keep it from sc by, a blocked key
+// is counted once per consumer a day. Empty when it may be shown.
+func (mr *MysqlRepository) Check(ctx context.Context, sc reusePolicies.Scope, key string) (string, error) {
+
+	country, matchID, err := reusePolicies.SourceMatch(key)
+	if err != nil {
+		return "", err
+	}
+
+	uu, err := mr.usages(ctx, mr.db, country, matchID, sc)
+	if err != nil {
+		return "", fmt.Errorf("unable to read usages of %s : %v", key, err)
+	}
+
+	policy := mr.policy.Evaluate(uu, sc, time.Now())
+	if policy == "" {
+		return "", nil
+	}
+
+	_, err = mr.db.Exec("INSERT IGNORE reuse_policy_counters SET consumer=?,policy=?,counter_date=curdate(), \n"+
+		"country=?,match_id=?,created=now()",
+		sc.Consumer, policy, country, matchID)
+	if err != nil {
+		log.Printf("Err : %v failed to count %s blocking %s", err, policy, key)
+	}
+
+	return policy, nil
+}
+
+// Use : records the source matches of keys as shown in sc in one transaction. Each usage
+// is only inserted when no usage the policy keeps it from sc by exists, so that a key
+// checked by two claims at once is only used by one of them. Nothing is recorded when
+// one of them is blocked.
+func (mr *MysqlRepository) Use(ctx context.Context, sc reusePolicies.Scope, keys []string) error {
+
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to start recording usages : %v", err)
+	}
+	defer tx.Rollback()
+
+	blocked, args := mr.blockedBy(sc)
+	for _, key := range keys {
+
+		country, matchID, err := reusePolicies.SourceMatch(key)
+		if err != nil {
+			return err
+		}
+
+		rs, err := tx.ExecContext(ctx, "INSERT match_usages (consumer,country,match_id,player_id,season_id,season_week_id,created) \n"+
+			"select ?,?,?,?,?,?,now() from dual where not exists (select 1 from match_usages \n"+
+			"where country=? and match_id=? and ("+blocked+"))",
+			append([]interface{}{sc.Consumer, country, matchID, sc.PlayerID, sc.SeasonID, sc.SeasonWeekID, country, matchID}, args...)...)
+		if err != nil {
+			return fmt.Errorf("unable to save usage of %s : %v", key, err)
+		}
+
+		n, err := rs.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("unable to save usage of %s : %v", key, err)
+		}
+
+		if n == 0 {
+			uu, err := mr.usages(ctx, tx, country, matchID, sc)
+			if err != nil {
+				return fmt.Errorf("unable to read usages of %s : %v", key, err)
+			}
+			return fmt.Errorf("%s blocks %s for %s %s", mr.policy.Evaluate(uu, sc, time.Now()), key, sc.Consumer, sc.SeasonWeekID)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to record usages : %v", err)
+	}
+
+	return nil
+}
+
+// Release : drops the usages Use recorded for keys in sc, for keys that were not shown
+// after all.
+func (mr *MysqlRepository) Release(ctx context.Context, sc reusePolicies.Scope, keys []string) error {
+
+	for _, key := range keys {
+
+		country, matchID, err := reusePolicies.SourceMatch(key)
+		if err != nil {
+			return err
+		}
+
+		_, err = mr.db.Exec("delete from match_usages where consumer=? and country=? and match_id=? and player_id=? \n"+
+			"and season_id=? and season_week_id=? order by match_usage_id desc limit 1",
+			sc.Consumer, country, matchID, sc.PlayerID, sc.SeasonID, sc.SeasonWeekID)
+		if err != nil {
+			return fmt.Errorf("unable to drop usage of %s : %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// blockedBy : condition a usage of a source match meets when the policy keeps it from sc,
+// as Evaluate reads it, with its arguments. False when every rule is off.
+func (mr *MysqlRepository) blockedBy(sc reusePolicies.Scope) (string, []interface{}) {
+
+	cc := []string{}
+	args := []interface{}{}
+
+	if mr.policy.SeasonWeek && sc.SeasonWeekID != "" {
+		cc = append(cc, "(consumer=? and season_week_id=?)")
+		args = append(args, sc.Consumer, sc.SeasonWeekID)
+	}
+
+	if mr.policy.Season && sc.SeasonID != "" {
+		cc = append(cc, "(consumer=? and season_id=?)")
+		args = append(args, sc.Consumer, sc.SeasonID)
+	}
+
+	if mr.policy.PlayerDays > 0 && sc.PlayerID != "" {
+		cc = append(cc, "(player_id=? and created > now() - interval ? day)")
+		args = append(args, sc.PlayerID, mr.policy.PlayerDays)
+	}
+
+	if mr.policy.GlobalDays > 0 {
+		cc = append(cc, "created > now() - interval ? day")
+		args = append(args, mr.policy.GlobalDays)
+	}
+
+	if len(cc) == 0 {
+		return "false", args
+	}
+
+	return strings.Join(cc, " or "), args
+}
+
+// usages : usages of a source match within the horizon of the policy, and those of the
+// season and season week of sc whenever they were.
+func (r *MysqlRepository) usages(ctx context.Context, q querier, country, matchID string, sc reusePolicies.Scope) ([]reusePolicies.Usage, error) {
+	statement := fmt.Sprintf("select match_usage_id,consumer,country,match_id,player_id,season_id,season_week_id,created \n" +
+		"from match_usages where country=? and match_id=? and (created > now() - interval ? day \n" +
+		"or (season_id<>'' and season_id=?) or (season_week_id<>'' and season_week_id=?))")
+
+	raws, err := q.QueryContext(ctx, statement, country, matchID, r.policy.Horizon(), sc.SeasonID, sc.SeasonWeekID)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	var gc []reusePolicies.Usage
+	for raws.Next() {
+		var g reusePolicies.Usage
+		err := raws.Scan(&g.MatchUsageID, &g.Consumer, &g.Country, &g.MatchID, &g.PlayerID, &g.SeasonID,
+			&g.SeasonWeekID, &g.Created)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err := raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+// Counters : keys every policy blocked for every consumer from from up to to, a key once a day.
+func (r *MysqlRepository) Counters(ctx context.Context, from, to string) ([]reusePolicies.Counter, error) {
+	statement := fmt.Sprintf("select consumer,policy,count(*) from reuse_policy_counters \n" +
+		"where counter_date >= ? and counter_date < ? group by consumer,policy order by consumer,policy")
+
+	raws, err := r.db.Query(statement, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	gc := []reusePolicies.Counter{}
+	for raws.Next() {
+		var g reusePolicies.Counter
+		err := raws.Scan(&g.Consumer, &g.Policy, &g.Blocked)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err := raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
@@ -0,0 +1,65 @@
+package reusePolicies
+
+// Policies a source match is checked against, in the order they are checked.
+const (
+	SameSeasonWeek = "same_season_week"
+	SameSeason     = "same_season"
+	PlayerCoolDown = "player_cool_down"
+	GlobalCoolDown = "global_cool_down"
+)
+
+// Consumers matches are shown by.
+const (
+	Production = "production"
+	Instant    = "instant"
+)
+
+// Policy : when a source match may be shown again. No consumer shows it again within
+// GlobalDays days of any consumer showing it, and never to the same player within
+// PlayerDays days. SeasonWeek and
+// Season keep it from being shown twice in one season week or one season. A rule is off
+// when its days are 0 or its flag is false.
+type Policy struct {
+	GlobalDays int  `json:"global_days"`
+	PlayerDays int  `json:"player_days"`
+	Season     bool `json:"season"`
+	SeasonWeek bool `json:"season_week"`
+}
+
+// Scope : where a match is about to be shown. PlayerID is only set by the instant server,
+// where SeasonID is the match request and SeasonWeekID the period. Production instant keys
+// show matches as the Instant consumer without a PlayerID.
+type Scope struct {
+	Consumer     string
+	PlayerID     string
+	SeasonID     string
+	SeasonWeekID string
+}
+
+// Usage : a source match shown in a scope.
+type Usage struct {
+	MatchUsageID string
+	Consumer     string
+	Country      string
+	MatchID      string
+	PlayerID     string
+	SeasonID     string
+	SeasonWeekID string
+	Created      string
+}
+
+// Counter : keys a policy blocked for a consumer, each counted once a day.
+type Counter struct {
+	Consumer string `json:"consumer"`
+	Policy   string `json:"policy"`
+	Blocked  int64  `json:"blocked"`
+}
+
+// CountersAPI : reuse policy counters api
+type CountersAPI struct {
+	StatusCode        string    `json:"status_code"`
+	StatusDescription string    `json:"status_description"`
+	From              string    `json:"from"`
+	To                string    `json:"to"`
+	Counters          []Counter `json:"counters"`
+}
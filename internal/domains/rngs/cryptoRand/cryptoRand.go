@@ -0,0 +1,59 @@
+package cryptoRand
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+)
+
+var _ rngs.RngsRepository = (*CryptoRandConfigs)(nil)
+
+// Name : source name put on reports.
+const Name = "crypto/rand"
+
+// CryptoRandConfigs : draws read from the operating system through crypto/rand. It holds no
+// state and is safe to share.
+type CryptoRandConfigs struct {
+	r *rand.Rand
+}
+
+// source : crypto/rand as a math/rand/v2 source, so that bounded draws are not biased.
+type source struct{}
+
+func (source) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		// Selection can not go on without a certified source.
+		panic(fmt.Sprintf("crypto/rand failed : %v", err))
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// New initializes a source over crypto/rand, failing when it can not be read.
+func New() (*CryptoRandConfigs, error) {
+
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("err : %v crypto/rand can not be read", err)
+	}
+
+	return &CryptoRandConfigs{r: rand.New(source{})}, nil
+}
+
+// Uint64 : next 64 random bits.
+func (s *CryptoRandConfigs) Uint64() uint64 {
+	return s.r.Uint64()
+}
+
+// IntN : draw in [0, n). It panics if n <= 0.
+func (s *CryptoRandConfigs) IntN(n int) int {
+	return s.r.IntN(n)
+}
+
+// Float64 : draw in [0, 1).
+func (s *CryptoRandConfigs) Float64() float64 {
+	return s.r.Float64()
+}
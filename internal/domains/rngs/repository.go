@@ -0,0 +1,8 @@
+package rngs
+
+// RngsRepository : source every selection draw comes from.
+type RngsRepository interface {
+	Uint64() uint64
+	IntN(n int) int
+	Float64() float64
+}
@@ -0,0 +1,258 @@
+package rngs
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// FrequencyTest : monobit test over the bits of draws words, whether ones and zeros are
+// as frequent as each other. NIST SP 800-22 section 2.1.
+func FrequencyTest(r RngsRepository, draws int) TestResult {
+
+	n := draws * 64
+	sum := 0
+	for i := 0; i < draws; i++ {
+		sum += 2*bits.OnesCount64(r.Uint64()) - 64
+	}
+
+	statistic := math.Abs(float64(sum)) / math.Sqrt(float64(n))
+
+	return TestResult{
+		Name:      Frequency,
+		Sample:    n,
+		Statistic: statistic,
+		PValue:    math.Erfc(statistic / math.Sqrt2),
+	}
+}
+
+// RunsTest : runs test over the bits of draws words, whether runs of ones and zeros
+// change as often as they should. NIST SP 800-22 section 2.3.
+func RunsTest(r RngsRepository, draws int) TestResult {
+
+	n := draws * 64
+	ones := 0
+	runs := 1
+
+	var previous uint64
+	for i := 0; i < draws; i++ {
+
+		w := r.Uint64()
+		ones += bits.OnesCount64(w)
+
+		// Bits read from the top, every change between neighbours starts a run.
+		runs += bits.OnesCount64((w ^ (w >> 1)) &^ (1 << 63))
+		if i > 0 && previous&1 != w>>63 {
+			runs++
+		}
+		previous = w
+	}
+
+	pi := float64(ones) / float64(n)
+
+	tr := TestResult{
+		Name:      Runs,
+		Sample:    n,
+		Statistic: float64(runs),
+	}
+
+	// The runs test is only meaningful once the frequency test would pass.
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(float64(n)) {
+		return tr
+	}
+
+	expected := 2 * float64(n) * pi * (1 - pi)
+	tr.PValue = math.Erfc(math.Abs(float64(runs)-expected) / (2 * math.Sqrt(2*float64(n)) * pi * (1 - pi)))
+
+	return tr
+}
+
+// ChiSquareTest : chi square goodness of fit of draws indexes drawn below buckets against
+// an even spread, with buckets - 1 degrees of freedom.
+func ChiSquareTest(r RngsRepository, draws, buckets int) TestResult {
+
+	counts := make([]int, buckets)
+	for i := 0; i < draws; i++ {
+		counts[r.IntN(buckets)]++
+	}
+
+	expected := float64(draws) / float64(buckets)
+	statistic := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		statistic += d * d / expected
+	}
+
+	return TestResult{
+		Name:      ChiSquare,
+		Sample:    draws,
+		Statistic: statistic,
+		PValue:    ChiSquarePValue(statistic, buckets-1),
+	}
+}
+
+// Battery : runs every test against source, each passes when its p value is at least alpha.
+func Battery(r RngsRepository, source string, draws, buckets int, alpha float64) (Report, error) {
+
+	if draws < 100 {
+		return Report{}, fmt.Errorf("at least 100 draws are needed, %d set", draws)
+	}
+
+	if buckets < 2 {
+		return Report{}, fmt.Errorf("at least 2 buckets are needed, %d set", buckets)
+	}
+
+	if float64(draws)/float64(buckets) < 5 {
+		return Report{}, fmt.Errorf("%d draws are too few for %d buckets, 5 per bucket are needed", draws, buckets)
+	}
+
+	if alpha <= 0 || alpha >= 1 {
+		return Report{}, fmt.Errorf("alpha %v must be between 0 and 1", alpha)
+	}
+
+	rp := Report{
+		Source:    source,
+		Draws:     draws,
+		Buckets:   buckets,
+		Alpha:     alpha,
+		StartedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	rp.Tests = []TestResult{
+		FrequencyTest(r, draws),
+		RunsTest(r, draws),
+		ChiSquareTest(r, draws, buckets),
+	}
+
+	rp.Passed = true
+	for i := range rp.Tests {
+		rp.Tests[i].Passed = rp.Tests[i].PValue >= alpha
+		if !rp.Tests[i].Passed {
+			rp.Passed = false
+		}
+	}
+
+	rp.FinishedAt = time.Now().Format("2006-01-02 15:04:05")
+
+	return rp, nil
+}
+
+// Sign : signs the json of a report with key.
+func Sign(rp Report, key ed25519.PrivateKey) (SignedReport, error) {
+
+	if len(key) != ed25519.PrivateKeySize {
+		return SignedReport{}, fmt.Errorf("signing key must be %d bytes, %d set", ed25519.PrivateKeySize, len(key))
+	}
+
+	data, err := json.Marshal(rp)
+	if err != nil {
+		return SignedReport{}, fmt.Errorf("err : %v failed to marshal report", err)
+	}
+
+	return SignedReport{
+		Report:    rp,
+		Algorithm: Algorithm,
+		PublicKey: hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: hex.EncodeToString(ed25519.Sign(key, data)),
+	}, nil
+}
+
+// VerifyReport : whether the signature of a report was made over it with its public key.
+func VerifyReport(sr SignedReport) (bool, error) {
+
+	if sr.Algorithm != Algorithm {
+		return false, fmt.Errorf("algorithm %q not supported", sr.Algorithm)
+	}
+
+	publicKey, err := hex.DecodeString(sr.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("public key %q not valid", sr.PublicKey)
+	}
+
+	signature, err := hex.DecodeString(sr.Signature)
+	if err != nil {
+		return false, fmt.Errorf("signature %q not valid", sr.Signature)
+	}
+
+	data, err := json.Marshal(sr.Report)
+	if err != nil {
+		return false, fmt.Errorf("err : %v failed to marshal report", err)
+	}
+
+	return ed25519.Verify(publicKey, data, signature), nil
+}
+
+// ChiSquarePValue : probability of a chi square statistic at least as large as statistic
+// with df degrees of freedom.
+func ChiSquarePValue(statistic float64, df int) float64 {
+	return gammaQ(float64(df)/2, statistic/2)
+}
+
+// gammaQ : regularized upper incomplete gamma function, the chi square p value of x / 2
+// with 2a degrees of freedom.
+func gammaQ(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaFraction(a, x)
+}
+
+// gammaSeries : regularized lower incomplete gamma function by its series.
+func gammaSeries(a, x float64) float64 {
+
+	lg, _ := math.Lgamma(a)
+
+	ap := a
+	del := 1 / a
+	sum := del
+	for n := 0; n < 1000; n++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*1e-15 {
+			break
+		}
+	}
+
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// gammaFraction : regularized upper incomplete gamma function by its continued fraction.
+func gammaFraction(a, x float64) float64 {
+
+	const tiny = 1e-300
+
+	lg, _ := math.Lgamma(a)
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < 1e-15 {
+			break
+		}
+	}
+
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
@@ -0,0 +1,43 @@
+package rngs
+
+// Tests of the battery.
+const (
+	Frequency = "frequency"
+	Runs      = "runs"
+	ChiSquare = "chi_square"
+)
+
+// Algorithm reports are signed with.
+const Algorithm = "ed25519"
+
+// TestResult : outcome of one test of the battery. Sample is the number of bits or draws
+// tested, the test passes when PValue is at least the alpha of the report.
+type TestResult struct {
+	Name      string  `json:"name"`
+	Sample    int     `json:"sample"`
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"p_value"`
+	Passed    bool    `json:"passed"`
+}
+
+// Report : battery run against a source. Draws is the number of 64 bit words the
+// frequency and runs tests read and the number of draws spread over Buckets for the chi
+// square test.
+type Report struct {
+	Source     string       `json:"source"`
+	Draws      int          `json:"draws"`
+	Buckets    int          `json:"buckets"`
+	Alpha      float64      `json:"alpha"`
+	Tests      []TestResult `json:"tests"`
+	Passed     bool         `json:"passed"`
+	StartedAt  string       `json:"started_at"`
+	FinishedAt string       `json:"finished_at"`
+}
+
+// SignedReport : report with the signature of its json, PublicKey and Signature in hex.
+type SignedReport struct {
+	Report    Report `json:"report"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
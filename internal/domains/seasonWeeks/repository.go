@@ -20,4 +20,6 @@ type SeasonWeeksRepository interface {
 	ApiSsnWeeksNew(ctx context.Context, seasonID string) ([]ProductionSeasonWeeksAPI, error)
 
 	UpcomingSsnWeeks2(ctx context.Context) ([]SeasonWkDetails, error)
+
+	PublishedSsnWeeks(ctx context.Context, competitionID, from, to string) ([]ProductionSeasonWeeksAPI, error)
 }
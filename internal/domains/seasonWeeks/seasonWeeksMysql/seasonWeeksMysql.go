@@ -345,3 +345,38 @@ func (r *MysqlRepository) ApiSsnWeeksNew(ctx context.Context, seasonID string) (
 
 	return gc, nil
 }
+
+// PublishedSsnWeeks : season weeks drawn from a competition published between from and to,
+// already started.
+func (r *MysqlRepository) PublishedSsnWeeks(ctx context.Context, competitionID, from, to string) ([]seasonWeeks.ProductionSeasonWeeksAPI, error) {
+	var gc []seasonWeeks.ProductionSeasonWeeksAPI
+
+	statement := fmt.Sprintf("select sw.season_week_id,s.league_id,sw.season_id,sw.week_number,sw.status,sw.start_time,sw.end_time, \n" +
+		"date(sw.start_time) as api_date,sw.created,sw.modified \n" +
+		"from sn_wks as sw \n" +
+		"inner join sns as s on sw.season_id=s.season_id \n" +
+		"inner join sn_wk_pts as pt on pt.season_week_id = sw.season_week_id \n" +
+		"where pt.competition_id = ? and sw.status='active' and sw.start_time >= ? and sw.start_time < ? \n" +
+		"and sw.start_time < now() order by sw.start_time asc")
+
+	raws, err := r.db.Query(statement, competitionID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g seasonWeeks.ProductionSeasonWeeksAPI
+		err := raws.Scan(&g.SeasonWeekID, &g.LeagueID, &g.SeasonID, &g.WeekNumber, &g.Status, &g.StartTime, &g.EndTime, &g.ApiDate, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
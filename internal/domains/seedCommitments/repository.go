@@ -0,0 +1,13 @@
+package seedCommitments
+
+import "context"
+
+// SeedCommitmentsRepository contains methods that implements seedCommitments struct
+type SeedCommitmentsRepository interface {
+	Save(ctx context.Context, t SeedCommitments) (int, error)
+	Close(ctx context.Context, scope, scopeID, revealAt string, dd []Draws) error
+	Revoke(ctx context.Context, scope, scopeID string) error
+	GetCommitment(ctx context.Context, scope, scopeID string) ([]SeedCommitments, error)
+	Committed(ctx context.Context, scope, scopeID string) (SeedCommitments, error)
+	GetDraws(ctx context.Context, scope, scopeID string) ([]Draws, error)
+}
@@ -0,0 +1,311 @@
+package seedCommitments
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+)
+
+var _ rngs.RngsRepository = (*Rand)(nil)
+
+// valueTolerance : a value read back from mysql may differ from the derived one in its
+// last bits.
+const valueTolerance = 1e-12
+
+// NewSeedCommitments : commitment of a scope to a new random server seed and to the pools
+// its draws select from.
+func NewSeedCommitments(scope, scopeID string, pools ...Pool) (*SeedCommitments, error) {
+
+	if scope != Season && scope != SeasonWeek && scope != InstantSeasonWeek && scope != MatchRequest {
+		return &SeedCommitments{}, fmt.Errorf("scope %q not supported", scope)
+	}
+
+	if scopeID == "" {
+		return &SeedCommitments{}, fmt.Errorf("scopeID not set")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return &SeedCommitments{}, fmt.Errorf("err : %v failed to create a server seed", err)
+	}
+
+	serverSeed := hex.EncodeToString(b)
+
+	return &SeedCommitments{
+		Scope:      scope,
+		ScopeID:    scopeID,
+		SeedHash:   HashSeed(serverSeed),
+		ServerSeed: serverSeed,
+		PoolHash:   HashPools(pools),
+		Pools:      pools,
+		Status:     Open,
+	}, nil
+}
+
+// HashPools : sha256 in hex of the pools by set, each written as its set, a new line, its
+// members joined with commas and a new line. Empty when there is no pool.
+func HashPools(pools []Pool) string {
+
+	if len(pools) == 0 {
+		return ""
+	}
+
+	pp := append([]Pool{}, pools...)
+	sort.SliceStable(pp, func(i, j int) bool { return pp[i].Set < pp[j].Set })
+
+	h := sha256.New()
+	for _, p := range pp {
+		h.Write([]byte(p.Set + "\n" + strings.Join(p.Members, ",") + "\n"))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashSeed : sha256 of a server seed in hex, the hash published before the draws.
+func HashSeed(serverSeed string) string {
+	h := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(h[:])
+}
+
+// Word : draw cursor of the round with this nonce as 64 bits, the first 8 bytes of
+// HMAC-SHA256 keyed with the server seed over "nonce:cursor", read big endian.
+func Word(serverSeed, nonce string, cursor int) uint64 {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(nonce + ":" + strconv.Itoa(cursor)))
+	sum := mac.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Value : draw cursor of the round with this nonce, in [0, 1). It is its Word as a
+// fraction of 2^64 kept to 53 bits.
+func Value(serverSeed, nonce string, cursor int) float64 {
+	return float64(Word(serverSeed, nonce, cursor)>>11) / (1 << 53)
+}
+
+// Result : value scaled to an index below bound, 0 when bound is not set.
+func Result(value float64, bound int) int {
+	if bound <= 0 {
+		return 0
+	}
+	return int(value * float64(bound))
+}
+
+// NewSession : session of a new commitment of a scope, its draws select from pools.
+func NewSession(scope, scopeID string, pools ...Pool) (*Session, error) {
+
+	c, err := NewSeedCommitments(scope, scopeID, pools...)
+	if err != nil {
+		return &Session{}, err
+	}
+
+	return &Session{
+		Commitment: *c,
+		draws:      []Draws{},
+		cursors:    make(map[string]int),
+	}, nil
+}
+
+// ResumeSession : session of a commitment already saved, so that a scope retried after a
+// failure draws from the seed whose hash was published.
+func ResumeSession(c SeedCommitments) (*Session, error) {
+
+	if c.Status != Open {
+		return &Session{}, fmt.Errorf("seed commitment of %s %s already closed", c.Scope, c.ScopeID)
+	}
+
+	if c.ServerSeed == "" || HashSeed(c.ServerSeed) != c.SeedHash {
+		return &Session{}, fmt.Errorf("server seed of %s %s does not match its seed hash", c.Scope, c.ScopeID)
+	}
+
+	if HashPools(c.Pools) != c.PoolHash {
+		return &Session{}, fmt.Errorf("pools of %s %s do not match their pool hash", c.Scope, c.ScopeID)
+	}
+
+	return &Session{
+		Commitment: c,
+		draws:      []Draws{},
+		cursors:    make(map[string]int),
+	}, nil
+}
+
+// BatteryRand : round of a new server seed that keeps no draws, so that the rng battery
+// can run as many draws as it needs through the derivation selections use.
+func BatteryRand() (*Rand, error) {
+
+	s, err := NewSession(Season, Name)
+	if err != nil {
+		return nil, err
+	}
+	s.discard = true
+
+	return s.Rand(Name), nil
+}
+
+// Rand : draws of the round with this nonce. Rounds of a session share its server seed,
+// the nonce must be public and differ between them.
+func (s *Session) Rand(nonce string) *Rand {
+	return &Rand{session: s, nonce: nonce}
+}
+
+// Draws : every draw made from the session so far.
+func (s *Session) Draws() []Draws {
+	return append([]Draws{}, s.draws...)
+}
+
+// Pool : members of set committed to, false when the session has no pool of set.
+func (r *Rand) Pool(set string) ([]string, bool) {
+	for _, p := range r.session.Commitment.Pools {
+		if p.Set == set {
+			return p.Members, true
+		}
+	}
+	return nil, false
+}
+
+// Uint64 : next draw of the round as its 64 bit Word.
+func (r *Rand) Uint64() uint64 {
+	_, w := r.next(0)
+	return w
+}
+
+// Float64 : next draw of the round, in [0, 1).
+func (r *Rand) Float64() float64 {
+	return r.draw(0).Value
+}
+
+// IntN : next draw of the round as an index in [0, n). It panics if n <= 0.
+func (r *Rand) IntN(n int) int {
+	if n <= 0 {
+		panic("invalid argument to IntN")
+	}
+	return r.draw(n).Result
+}
+
+// Pick : records what the last draw of the round selected.
+func (r *Rand) Pick(selected string) {
+	for i := len(r.session.draws) - 1; i >= 0; i-- {
+		if r.session.draws[i].Nonce == r.nonce {
+			r.session.draws[i].Selected = selected
+			return
+		}
+	}
+}
+
+// PickFrom : records what the last draw of the round selected and the candidates, in
+// order, it selected from. A draw made with Float64 is scaled to the candidates.
+func (r *Rand) PickFrom(candidates []string, selected string) {
+	for i := len(r.session.draws) - 1; i >= 0; i-- {
+		d := &r.session.draws[i]
+		if d.Nonce == r.nonce {
+			d.Bound = len(candidates)
+			d.Result = Result(d.Value, d.Bound)
+			d.Candidates = append([]string{}, candidates...)
+			d.Selected = selected
+			return
+		}
+	}
+}
+
+func (r *Rand) draw(bound int) Draws {
+	d, _ := r.next(bound)
+	return d
+}
+
+// next : next draw of the round and the Word its Value is taken from.
+func (r *Rand) next(bound int) (Draws, uint64) {
+
+	s := r.session
+	cursor := s.cursors[r.nonce]
+	s.cursors[r.nonce] = cursor + 1
+
+	w := Word(s.Commitment.ServerSeed, r.nonce, cursor)
+	v := float64(w>>11) / (1 << 53)
+	d := Draws{
+		Nonce:  r.nonce,
+		Cursor: cursor,
+		Bound:  bound,
+		Value:  v,
+		Result: Result(v, bound),
+	}
+
+	if !s.discard {
+		s.draws = append(s.draws, d)
+	}
+
+	return d, w
+}
+
+// Verify : works every draw out again from the server seed of the commitment, and what a
+// draw selected from its candidates. When pools were committed to, every candidate must be
+// in one of them. Nothing is checked before the seed is revealed.
+func Verify(c SeedCommitments, dd []Draws) Verification {
+
+	v := Verification{
+		Commitment: c,
+		Draws:      []DrawCheck{},
+	}
+
+	if c.ServerSeed == "" {
+		for _, d := range dd {
+			v.Draws = append(v.Draws, DrawCheck{Draws: d})
+		}
+		return v
+	}
+
+	v.Revealed = true
+	v.HashMatches = HashSeed(c.ServerSeed) == c.SeedHash
+	v.PoolHashMatches = HashPools(c.Pools) == c.PoolHash
+	v.Valid = v.HashMatches && v.PoolHashMatches
+
+	pooled := make(map[string]bool)
+	for _, p := range c.Pools {
+		for _, m := range p.Members {
+			pooled[m] = true
+		}
+	}
+
+	for _, d := range dd {
+
+		value := Value(c.ServerSeed, d.Nonce, d.Cursor)
+		result := Result(value, d.Bound)
+
+		dc := DrawCheck{
+			Draws:          d,
+			ExpectedValue:  value,
+			ExpectedResult: result,
+			Valid:          math.Abs(value-d.Value) < valueTolerance && result == d.Result,
+		}
+
+		if len(d.Candidates) > 0 {
+			if d.Bound == len(d.Candidates) && result < len(d.Candidates) {
+				dc.ExpectedSelected = d.Candidates[result]
+			}
+			dc.Valid = dc.Valid && dc.ExpectedSelected != "" && dc.ExpectedSelected == d.Selected
+
+			if c.PoolHash != "" {
+				for _, m := range d.Candidates {
+					if !pooled[m] {
+						dc.Valid = false
+					}
+				}
+			}
+		}
+
+		if !dc.Valid {
+			v.Valid = false
+		}
+
+		v.Draws = append(v.Draws, dc)
+	}
+
+	return v
+}
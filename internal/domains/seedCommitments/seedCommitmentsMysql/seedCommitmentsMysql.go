@@ -0,0 +1,239 @@
+package seedCommitmentsMysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+)
+
+var _ seedCommitments.SeedCommitmentsRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save : publishes the seed hash and pool hash of a scope. A published hash is only
+// replaced once it is revoked, a scope saved again keeps its seed and pools, read them back
+// with Committed.
+func (mr *MysqlRepository) Save(ctx context.Context, t seedCommitments.SeedCommitments) (int, error) {
+	var d int
+
+	pools, err := marshalPools(t.Pools)
+	if err != nil {
+		return d, err
+	}
+
+	rs, err := mr.db.Exec("INSERT seed_commitments SET scope=?,scope_id=?,seed_hash=?,server_seed=?,pool_hash=?,pools=?,status=?, \n"+
+		"created=now(),modified=now() ON DUPLICATE KEY UPDATE seed_commitment_id=LAST_INSERT_ID(seed_commitment_id)",
+		t.Scope, t.ScopeID, t.SeedHash, t.ServerSeed, t.PoolHash, pools, t.Status)
+
+	if err != nil {
+		return d, fmt.Errorf("unable to save seed commitment : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("unable to retrieve last seed commitment ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// Close : records the draws of an open scope and when its server seed is revealed.
+func (mr *MysqlRepository) Close(ctx context.Context, scope, scopeID, revealAt string, dd []seedCommitments.Draws) error {
+
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction : %v", err)
+	}
+
+	var commitmentID string
+	err = tx.QueryRowContext(ctx, "select seed_commitment_id from seed_commitments where scope=? and scope_id=? and current=1 and status='open' for update",
+		scope, scopeID).Scan(&commitmentID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("unable to find open seed commitment of %s %s : %v", scope, scopeID, err)
+	}
+
+	for _, d := range dd {
+		_, err = tx.ExecContext(ctx, "INSERT seed_draws SET seed_commitment_id=?,nonce=?,draw_cursor=?,bound=?,value=?,result=?,selected=?,candidates=?,created=now()",
+			commitmentID, d.Nonce, d.Cursor, d.Bound, d.Value, d.Result, d.Selected, strings.Join(d.Candidates, ","))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("unable to save draw %s:%d : %v", d.Nonce, d.Cursor, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "update seed_commitments set status='closed',reveal_at=?,modified=now() where seed_commitment_id=?",
+		revealAt, commitmentID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("unable to close seed commitment of %s %s : %v", scope, scopeID, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit draws of %s %s : %v", scope, scopeID, err)
+	}
+
+	return nil
+}
+
+// Revoke : retires the commitment of a scope taken back to be built again, the next Save
+// of the scope publishes a new seed. The revoked commitment and its draws are kept.
+func (mr *MysqlRepository) Revoke(ctx context.Context, scope, scopeID string) error {
+
+	_, err := mr.db.ExecContext(ctx, "update seed_commitments set status='revoked',current=null,modified=now() \n"+
+		"where scope=? and scope_id=? and current=1", scope, scopeID)
+	if err != nil {
+		return fmt.Errorf("unable to revoke seed commitment of %s %s : %v", scope, scopeID, err)
+	}
+
+	return nil
+}
+
+// GetCommitment : current commitment of a scope, its server seed and pools only once it is closed
+// and reveal_at has passed.
+func (r *MysqlRepository) GetCommitment(ctx context.Context, scope, scopeID string) ([]seedCommitments.SeedCommitments, error) {
+	statement := fmt.Sprintf("select seed_commitment_id,scope,scope_id,seed_hash, \n" +
+		"if(status='closed' and reveal_at<=now(),server_seed,''),pool_hash, \n" +
+		"if(status='closed' and reveal_at<=now(),pools,''),status,ifnull(reveal_at,''),created,modified \n" +
+		"from seed_commitments where scope=? and scope_id=? and current=1")
+
+	raws, err := r.db.Query(statement, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	var gc []seedCommitments.SeedCommitments
+	for raws.Next() {
+		var g seedCommitments.SeedCommitments
+		var pools string
+		err := raws.Scan(&g.SeedCommitmentID, &g.Scope, &g.ScopeID, &g.SeedHash, &g.ServerSeed, &g.PoolHash, &pools,
+			&g.Status, &g.RevealAt, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		g.Pools, err = unmarshalPools(pools)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err := raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+// Committed : current commitment of a scope with its server seed, for the draws of the scope only,
+// it must not be published before reveal_at.
+func (r *MysqlRepository) Committed(ctx context.Context, scope, scopeID string) (seedCommitments.SeedCommitments, error) {
+	var g seedCommitments.SeedCommitments
+	var pools string
+	err := r.db.QueryRowContext(ctx, "select seed_commitment_id,scope,scope_id,seed_hash,server_seed,pool_hash,pools,status, \n"+
+		"ifnull(reveal_at,''),created,modified from seed_commitments where scope=? and scope_id=? and current=1", scope, scopeID).Scan(
+		&g.SeedCommitmentID, &g.Scope, &g.ScopeID, &g.SeedHash, &g.ServerSeed, &g.PoolHash, &pools, &g.Status, &g.RevealAt,
+		&g.Created, &g.Modified)
+	if err != nil {
+		return g, fmt.Errorf("unable to return seed commitment of %s %s : %v", scope, scopeID, err)
+	}
+
+	g.Pools, err = unmarshalPools(pools)
+	if err != nil {
+		return g, err
+	}
+
+	return g, nil
+}
+
+// marshalPools : pools saved as json, empty when there is none.
+func marshalPools(pools []seedCommitments.Pool) (string, error) {
+	if len(pools) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(pools)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal pools : %v", err)
+	}
+
+	return string(b), nil
+}
+
+func unmarshalPools(pools string) ([]seedCommitments.Pool, error) {
+	if pools == "" {
+		return nil, nil
+	}
+
+	var pp []seedCommitments.Pool
+	err := json.Unmarshal([]byte(pools), &pp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pools : %v", err)
+	}
+
+	return pp, nil
+}
+
+// GetDraws : draws of the current commitment of a scope in the order they were made, none
+// until it is closed and reveal_at has passed.
+func (r *MysqlRepository) GetDraws(ctx context.Context, scope, scopeID string) ([]seedCommitments.Draws, error) {
+	statement := fmt.Sprintf("select d.nonce,d.draw_cursor,d.bound,d.value,d.result,d.selected,d.candidates \n" +
+		"from seed_draws d inner join seed_commitments c on c.seed_commitment_id=d.seed_commitment_id \n" +
+		"where c.scope=? and c.scope_id=? and c.current=1 and c.status='closed' and c.reveal_at<=now() \n" +
+		"order by d.seed_draw_id")
+
+	raws, err := r.db.Query(statement, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	var gc []seedCommitments.Draws
+	for raws.Next() {
+		var g seedCommitments.Draws
+		var candidates string
+		err := raws.Scan(&g.Nonce, &g.Cursor, &g.Bound, &g.Value, &g.Result, &g.Selected, &candidates)
+		if err != nil {
+			return nil, err
+		}
+		if candidates != "" {
+			g.Candidates = strings.Split(candidates, ",")
+		}
+		gc = append(gc, g)
+	}
+
+	if err := raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
@@ -0,0 +1,117 @@
+package seedCommitments
+
+// Scopes a server seed is committed for, every draw of the scope is derived from it.
+const (
+	Season            = "season"
+	SeasonWeek        = "season_week"
+	InstantSeasonWeek = "instant_season_week"
+	MatchRequest      = "match_request"
+)
+
+// Name : source name put on rng reports.
+const Name = "seed_commitments"
+
+// PoolSize : members of a set read into its pool when the seed is committed.
+const PoolSize = 500
+
+// Commitment statuses. The draws of an open commitment are still being made, a closed one
+// has them all and reveals its server seed at RevealAt. A revoked one was replaced when its
+// scope was taken back to be built again, its draws are kept.
+const (
+	Open    = "open"
+	Closed  = "closed"
+	Revoked = "revoked"
+)
+
+// | seed_commitments | CREATE TABLE `seed_commitments` (
+// 	`seed_commitment_id` int(11) NOT NULL AUTO_INCREMENT,
+// 	`scope` varchar(30) NOT NULL,
+// 	`scope_id` varchar(50) NOT NULL,
+// 	`seed_hash` char(64) NOT NULL,
+// 	`server_seed` char(64) NOT NULL,
+// 	`pool_hash` char(64) NOT NULL DEFAULT '',
+// 	`pools` mediumtext NOT NULL,
+// 	`status` enum('open','closed','revoked') NOT NULL DEFAULT 'open',
+// 	`current` tinyint(1) DEFAULT '1',
+// 	`reveal_at` datetime DEFAULT NULL,
+// 	`created` datetime NOT NULL,
+// 	`modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+// SeedCommitments : server seed the draws of a scope are derived from and the pools their
+// candidates are taken from. SeedHash and PoolHash are published before the first round
+// of the scope starts, ServerSeed and Pools stay empty when read back until RevealAt has passed.
+type SeedCommitments struct {
+	SeedCommitmentID string `json:"seed_commitment_id"`
+	Scope            string `json:"scope"`
+	ScopeID          string `json:"scope_id"`
+	SeedHash         string `json:"seed_hash"`
+	ServerSeed       string `json:"server_seed"`
+	PoolHash         string `json:"pool_hash"`
+	Pools            []Pool `json:"pools,omitempty"`
+	Status           string `json:"status"`
+	RevealAt         string `json:"reveal_at"`
+	Created          string `json:"created"`
+	Modified         string `json:"modified"`
+}
+
+// Pool : members of a set, in order, the draws of a scope may select from it.
+type Pool struct {
+	Set     string   `json:"set"`
+	Members []string `json:"members"`
+}
+
+// Draws : one draw of a commitment. Value is derived from the server seed, the public
+// Nonce of the round and Cursor, the position of the draw in the round. Result is Value
+// scaled to Bound, Bound is 0 when Value was used as is. Selected is what the draw picked,
+// Candidates what it picked from in order, Selected is the Result-th of them.
+type Draws struct {
+	Nonce      string   `json:"nonce"`
+	Cursor     int      `json:"cursor"`
+	Bound      int      `json:"bound"`
+	Value      float64  `json:"value"`
+	Result     int      `json:"result"`
+	Selected   string   `json:"selected"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// Session : commitment of a scope and the draws made from it so far. A session with
+// discard set keeps no draws.
+type Session struct {
+	Commitment SeedCommitments
+	draws      []Draws
+	cursors    map[string]int
+	discard    bool
+}
+
+// Rand : draws of one round of a session.
+type Rand struct {
+	session *Session
+	nonce   string
+}
+
+// DrawCheck : a recorded draw next to the one worked out again from the server seed.
+type DrawCheck struct {
+	Draws
+	ExpectedValue    float64 `json:"expected_value"`
+	ExpectedResult   int     `json:"expected_result"`
+	ExpectedSelected string  `json:"expected_selected,omitempty"`
+	Valid            bool    `json:"valid"`
+}
+
+// Verification : whether the revealed server seed and pools hash to the published hashes
+// and every draw of the commitment follows from them.
+type Verification struct {
+	Commitment      SeedCommitments `json:"commitment"`
+	Revealed        bool            `json:"revealed"`
+	HashMatches     bool            `json:"hash_matches"`
+	PoolHashMatches bool            `json:"pool_hash_matches"`
+	Draws           []DrawCheck     `json:"draws"`
+	Valid           bool            `json:"valid"`
+}
+
+// VerificationAPI : verification api
+type VerificationAPI struct {
+	StatusCode        string       `json:"status_code"`
+	StatusDescription string       `json:"status_description"`
+	Verification      Verification `json:"verification"`
+}
@@ -0,0 +1,14 @@
+package settlements
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+)
+
+// SettlementsRepository : settles the markets of a match from its score and goal timeline.
+type SettlementsRepository interface {
+	Settle(ctx context.Context, m Match) []Result
+	Wins(m Match, code, outcome string) (won bool, known bool)
+	Compare(ctx context.Context, m Match, wos []oddsFiles.RawWOs, offered []string) []Discrepancy
+}
@@ -0,0 +1,932 @@
+package settlementEngine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+)
+
+var _ settlements.SettlementsRepository = (*SettlementEngineConfigs)(nil)
+
+// market : how one market is settled.
+type market struct {
+	// timeline is set for markets that need the half time score or the first goal.
+	timeline bool
+	// winners : winning outcomes in the engine ids.
+	winners func(m settlements.Match) []string
+	// wins : whether an outcome, as named by the provider, won. known is false when the
+	// outcome could not be read.
+	wins func(m settlements.Match, outcome string) (won bool, known bool)
+	// ids : outcome ids of the feed read as the outcome they stand for, nil for markets the
+	// feed does not offer.
+	ids map[string]string
+}
+
+// SettlementEngineConfigs : markets settled, in the order results are returned.
+type SettlementEngineConfigs struct {
+	codes   []string
+	markets map[string]market
+}
+
+// New initializes the settlement engine for markets, every supported market when none is given.
+func New(markets ...string) (*SettlementEngineConfigs, error) {
+
+	supported := supportedMarkets()
+
+	if len(markets) == 0 {
+		markets = Supported()
+	}
+
+	c := &SettlementEngineConfigs{
+		markets: make(map[string]market),
+	}
+
+	for _, code := range markets {
+		mk, ok := supported[code]
+		if !ok {
+			return nil, fmt.Errorf("market %s can not be settled", code)
+		}
+
+		if _, ok := c.markets[code]; ok {
+			return nil, fmt.Errorf("market %s set twice", code)
+		}
+
+		c.markets[code] = mk
+		c.codes = append(c.codes, code)
+	}
+
+	return c, nil
+}
+
+// Supported : codes of every market the engine can settle.
+func Supported() []string {
+	codes := []string{}
+	for code := range supportedMarkets() {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Settle : winning outcomes of every market, markets that need the goal timeline are left
+// out when the live scores did not add up.
+func (s *SettlementEngineConfigs) Settle(ctx context.Context, m settlements.Match) []settlements.Result {
+
+	rr := []settlements.Result{}
+	for _, code := range s.codes {
+
+		mk := s.markets[code]
+		if mk.timeline && !m.Timeline {
+			continue
+		}
+
+		rr = append(rr, settlements.Result{Code: code, Outcomes: mk.winners(m)})
+	}
+
+	return rr
+}
+
+// Wins : whether outcome of market code won, known is false when the market is not settled
+// or the outcome could not be read. Outcome is a feed id of the market or an outcome name.
+func (s *SettlementEngineConfigs) Wins(m settlements.Match, code, outcome string) (bool, bool) {
+
+	mk, ok := s.markets[code]
+	if !ok || (mk.timeline && !m.Timeline) {
+		return false, false
+	}
+
+	if o, ok := mk.ids[norm(outcome)]; ok {
+		outcome = o
+	}
+
+	return mk.wins(m, outcome)
+}
+
+// Compare : provider winning outcomes the engine does not agree with. Outcomes are read from
+// the id when it is a feed id of the market, from the name otherwise. An outcome that can not
+// be read or that was voided is skipped. Markets of offered the engine settles but the
+// provider has no winning outcome for are returned as well.
+func (s *SettlementEngineConfigs) Compare(ctx context.Context, m settlements.Match, wos []oddsFiles.RawWOs, offered []string) []settlements.Discrepancy {
+
+	dd := []settlements.Discrepancy{}
+	settled := make(map[string]bool)
+	for _, w := range wos {
+
+		settled[w.SubTypeID] = true
+
+		provider, ok := providerWon(w.Result)
+		if !ok {
+			continue
+		}
+
+		outcome := w.OutcomeName
+		if _, ok := s.markets[w.SubTypeID].ids[norm(w.OutcomeID)]; ok {
+			outcome = w.OutcomeID
+		}
+
+		won, known := s.Wins(m, w.SubTypeID, outcome)
+		if !known || won == provider {
+			continue
+		}
+
+		dd = append(dd, settlements.Discrepancy{
+			SubTypeID:   w.SubTypeID,
+			OutcomeID:   w.OutcomeID,
+			OutcomeName: w.OutcomeName,
+			Provider:    provider,
+			Engine:      won,
+			Detail: fmt.Sprintf("%s outcome %s %s by the provider, %s from %s", w.SubTypeID, w.OutcomeID,
+				wonLost(provider), wonLost(won), describe(m)),
+		})
+	}
+
+	for _, code := range offered {
+
+		mk, ok := s.markets[code]
+		if !ok || settled[code] || (mk.timeline && !m.Timeline) {
+			continue
+		}
+		settled[code] = true
+
+		winners := strings.Join(mk.winners(m), ",")
+		dd = append(dd, settlements.Discrepancy{
+			SubTypeID: code,
+			OutcomeID: winners,
+			Engine:    true,
+			Detail:    fmt.Sprintf("%s offered but not settled by the provider, %s won from %s", code, winners, describe(m)),
+		})
+	}
+
+	return dd
+}
+
+// supportedMarkets : every market the engine knows, by code.
+func supportedMarkets() map[string]market {
+
+	mm := map[string]market{
+		"1X2":  matchResult(fullTime, 0, false),
+		"H1X2": matchResult(halfTime, 0, true),
+		"HX1":  matchResult(fullTime, 1, false),
+		"HX2":  matchResult(fullTime, 2, false),
+		"DC":   doubleChance(fullTime, false),
+		"DCH":  doubleChance(halfTime, true),
+		"CS":   correctScore(fullTime, false),
+		"HS":   correctScore(halfTime, true),
+		"GG":   bothScore(fullTime, false),
+		"HGG":  bothScore(halfTime, true),
+		"DR":   halfFull(),
+		"TG":   goalRange(),
+		"MG":   goalRange(),
+		"TGOE": oddEven(),
+		"TFG":  firstGoalTime(),
+		"FTS":  firstTeam(),
+		"1X2G": resultBothScore(),
+		"T1G":  teamScores(homeGoals),
+		"T2G":  teamScores(awayGoals),
+	}
+
+	for _, line := range []int{15, 25, 35} {
+		mm[fmt.Sprintf("TG%d", line)] = overUnder(totalGoals, line)
+	}
+
+	for _, line := range []int{15, 25, 35, 45, 55} {
+		mm[fmt.Sprintf("1X2OU%d", line)] = resultOverUnder(line)
+	}
+
+	for _, line := range []int{5, 15, 25} {
+		mm[fmt.Sprintf("T1OU%02d", line)] = overUnder(homeGoals, line)
+		mm[fmt.Sprintf("T2OU%02d", line)] = overUnder(awayGoals, line)
+	}
+
+	// Markets derived from the correct score prices.
+	mm["AHM15"] = asianHandicap(-15)
+	mm["AHM05"] = asianHandicap(-5)
+	mm["AHP05"] = asianHandicap(5)
+	mm["AHP15"] = asianHandicap(15)
+	mm["WM"] = winningMargin()
+	mm["EG"] = goalRange()
+	mm["CSH"] = cleanSheet(awayGoals)
+	mm["CSA"] = cleanSheet(homeGoals)
+	mm["WTNH"] = winToNil(settlements.Home, awayGoals)
+	mm["WTNA"] = winToNil(settlements.Away, homeGoals)
+	mm["HSH"] = highestScoringHalf()
+
+	for code, ids := range feedIDs() {
+		mk := mm[code]
+		mk.ids = ids
+		mm[code] = mk
+	}
+
+	return mm
+}
+
+// feedIDs : outcome ids the feed settles every market it offers with, see
+// cmd/wc/files/odds/matches.xml. Most are read as they are, TFG bands are numbered.
+func feedIDs() map[string]map[string]string {
+
+	results := same("1", "X", "2")
+	doubles := same("1X", "12", "X2")
+	overUnder := same("O", "U")
+	yesNo := same("Y", "N")
+
+	scores := make(map[string]string)
+	for h := 0; h <= 9; h++ {
+		for a := 0; a <= 9; a++ {
+			scores[fmt.Sprintf("%d-%d", h, a)] = fmt.Sprintf("%d-%d", h, a)
+		}
+	}
+
+	ii := map[string]map[string]string{
+		"1X2":  results,
+		"H1X2": results,
+		"HX1":  results,
+		"HX2":  results,
+		"DC":   doubles,
+		"DCH":  doubles,
+		"CS":   scores,
+		"HS":   scores,
+		"GG":   yesNo,
+		"HGG":  yesNo,
+		"T1G":  yesNo,
+		"T2G":  yesNo,
+		"DR":   same("HH", "HD", "HA", "DH", "DD", "DA", "AH", "AD", "AA"),
+		"TG":   same("0", "1", "2", "3", "4", "5", "6"),
+		"MG":   same("0-2", "1-3", "2-4", ">4"),
+		"TGOE": same("O", "E"),
+		"TFG": {
+			"0": settlements.NoGoal,
+			"1": "1-15",
+			"2": "16-30",
+			"3": "31-45",
+			"4": "46-60",
+			"5": "61-75",
+			"6": "76-90",
+		},
+		"FTS":  same("0", "H", "A"),
+		"1X2G": same("1G", "1NG", "XG", "XNG", "2G", "2NG"),
+	}
+
+	for _, line := range []int{15, 25, 35} {
+		ii[fmt.Sprintf("TG%d", line)] = overUnder
+	}
+
+	for _, line := range []int{15, 25, 35, 45, 55} {
+		ii[fmt.Sprintf("1X2OU%d", line)] = same("1O", "1U", "XO", "XU", "2O", "2U")
+	}
+
+	for _, line := range []int{5, 15, 25} {
+		ii[fmt.Sprintf("T1OU%02d", line)] = overUnder
+		ii[fmt.Sprintf("T2OU%02d", line)] = overUnder
+	}
+
+	return ii
+}
+
+// same : ids read as they are.
+func same(ids ...string) map[string]string {
+	mm := make(map[string]string)
+	for _, id := range ids {
+		mm[id] = id
+	}
+	return mm
+}
+
+// score : home and away goals at a point of the match.
+type score func(m settlements.Match) (int, int)
+
+func fullTime(m settlements.Match) (int, int) { return m.HomeScore, m.AwayScore }
+func halfTime(m settlements.Match) (int, int) { return m.HalfHomeScore, m.HalfAwayScore }
+
+// count : goals counted by a market.
+type count func(m settlements.Match) int
+
+func totalGoals(m settlements.Match) int { return m.HomeScore + m.AwayScore }
+func homeGoals(m settlements.Match) int  { return m.HomeScore }
+func awayGoals(m settlements.Match) int  { return m.AwayScore }
+
+// matchResult : 1X2, the home team starts handicap goals down (HX1, HX2).
+func matchResult(at score, handicap int, timeline bool) market {
+	return market{
+		timeline: timeline,
+		winners: func(m settlements.Match) []string {
+			h, a := at(m)
+			return []string{result(h-handicap, a)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			r, ok := readResult(outcome)
+			h, a := at(m)
+			return ok && r == result(h-handicap, a), ok
+		},
+	}
+}
+
+// doubleChance : 1X, 12 and X2.
+func doubleChance(at score, timeline bool) market {
+	return market{
+		timeline: timeline,
+		winners: func(m settlements.Match) []string {
+			r := result(at(m))
+			ww := []string{}
+			for _, dc := range []string{"1X", "12", "X2"} {
+				if strings.Contains(dc, r) {
+					ww = append(ww, dc)
+				}
+			}
+			return ww
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			rr, ok := readDoubleChance(outcome)
+			return ok && rr[result(at(m))], ok
+		},
+	}
+}
+
+// correctScore : CS at full time, HS at half time. Any other score can not be read and is skipped.
+func correctScore(at score, timeline bool) market {
+	return market{
+		timeline: timeline,
+		winners: func(m settlements.Match) []string {
+			h, a := at(m)
+			return []string{fmt.Sprintf("%d-%d", h, a)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			oh, oa, ok := readScore(outcome)
+			h, a := at(m)
+			return ok && oh == h && oa == a, ok
+		},
+	}
+}
+
+// bothScore : GG / NG.
+func bothScore(at score, timeline bool) market {
+	return market{
+		timeline: timeline,
+		winners: func(m settlements.Match) []string {
+			h, a := at(m)
+			return []string{yesNo(h > 0 && a > 0)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			yes, ok := readYesNo(outcome)
+			h, a := at(m)
+			return ok && yes == (h > 0 && a > 0), ok
+		},
+	}
+}
+
+// teamScores : T1G / T2G, GG when the team scored.
+func teamScores(goals count) market {
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{yesNo(goals(m) > 0)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			yes, ok := readYesNo(outcome)
+			return ok && yes == (goals(m) > 0), ok
+		},
+	}
+}
+
+// overUnder : line 25 is 2.5 goals.
+func overUnder(goals count, line int) market {
+	threshold := float64(line) / 10
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{overOrUnder(goals(m), threshold)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			ou, ok := readOverUnder(outcome)
+			return ok && ou == overOrUnder(goals(m), threshold), ok
+		},
+	}
+}
+
+// halfFull : DR, half time result / full time result.
+func halfFull() market {
+	return market{
+		timeline: true,
+		winners: func(m settlements.Match) []string {
+			return []string{result(halfTime(m)) + "/" + result(fullTime(m))}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			ht, ft, ok := readHalfFull(outcome)
+			return ok && ht == result(halfTime(m)) && ft == result(fullTime(m)), ok
+		},
+	}
+}
+
+// goalRange : TG exact goals and MG multi-goals, outcomes are 3, 1-3 or 6+.
+func goalRange() market {
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{strconv.Itoa(totalGoals(m))}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			lo, hi, ok := readRange(outcome)
+			n := totalGoals(m)
+			return ok && n >= lo && n <= hi, ok
+		},
+	}
+}
+
+// oddEven : TGOE, a goalless match is even.
+func oddEven() market {
+	oe := func(m settlements.Match) string {
+		if totalGoals(m)%2 == 1 {
+			return settlements.Odd
+		}
+		return settlements.Even
+	}
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{oe(m)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			o := norm(outcome)
+			switch o {
+			case "ODD", "O":
+				o = settlements.Odd
+			case "EVEN", "E":
+				o = settlements.Even
+			default:
+				return false, false
+			}
+			return o == oe(m), true
+		},
+	}
+}
+
+// firstGoalTime : TFG, 15 minute bands of the first goal or NG.
+func firstGoalTime() market {
+	return market{
+		timeline: true,
+		winners: func(m settlements.Match) []string {
+			if m.FirstGoalTeam == "" {
+				return []string{settlements.NoGoal}
+			}
+			return []string{band(m.FirstGoalMinute)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			if noGoal(outcome) {
+				return m.FirstGoalTeam == "", true
+			}
+			// A band is a range of minutes, a single number is not read.
+			lo, hi, ok := readRange(outcome)
+			ok = ok && hi > lo
+			minute := clamp(m.FirstGoalMinute, 1, 90)
+			return ok && m.FirstGoalTeam != "" && minute >= lo && minute <= hi, ok
+		},
+	}
+}
+
+// firstTeam : FTS, 1 or 2, NG for a goalless match.
+func firstTeam() market {
+	return market{
+		timeline: true,
+		winners: func(m settlements.Match) []string {
+			if m.FirstGoalTeam == "" {
+				return []string{settlements.NoGoal}
+			}
+			return []string{m.FirstGoalTeam}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			if noGoal(outcome) {
+				return m.FirstGoalTeam == "", true
+			}
+			r, ok := readResult(outcome)
+			if !ok {
+				return false, false
+			}
+			if r == settlements.Draw {
+				return m.FirstGoalTeam == "", true
+			}
+			return r == m.FirstGoalTeam, true
+		},
+	}
+}
+
+// resultOverUnder : 1X2OU25, 1&OV.
+func resultOverUnder(line int) market {
+	threshold := float64(line) / 10
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{result(fullTime(m)) + "&" + overOrUnder(totalGoals(m), threshold)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			r, rest, ok := readCombo(outcome)
+			if !ok {
+				return false, false
+			}
+			ou, ok := readOverUnder(rest)
+			return ok && r == result(fullTime(m)) && ou == overOrUnder(totalGoals(m), threshold), ok
+		},
+	}
+}
+
+// resultBothScore : 1X2G, 1&GG.
+func resultBothScore() market {
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{result(fullTime(m)) + "&" + yesNo(m.HomeScore > 0 && m.AwayScore > 0)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			r, rest, ok := readCombo(outcome)
+			if !ok {
+				return false, false
+			}
+			yes, ok := readYesNo(rest)
+			return ok && r == result(fullTime(m)) && yes == (m.HomeScore > 0 && m.AwayScore > 0), ok
+		},
+	}
+}
+
+// asianHandicap : half goal lines given to the home team, line -15 is -1.5. Outcomes are 1 and 2.
+func asianHandicap(line int) market {
+	covers := func(m settlements.Match) string {
+		if float64(m.HomeScore)+float64(line)/10 > float64(m.AwayScore) {
+			return settlements.Home
+		}
+		return settlements.Away
+	}
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{covers(m)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			r, ok := readResult(outcome)
+			if !ok || r == settlements.Draw {
+				return false, false
+			}
+			return r == covers(m), true
+		},
+	}
+}
+
+// winningMargin : WM, H1 H2 H3+ for the home team, A1 A2 A3+ for the away team, X for a draw.
+func winningMargin() market {
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{margin(m.HomeScore, m.AwayScore)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			o := norm(outcome)
+			if o == "DRAW" {
+				o = settlements.Draw
+			}
+			switch o {
+			case settlements.Draw, "H1", "H2", "H3+", "A1", "A2", "A3+":
+				return o == margin(m.HomeScore, m.AwayScore), true
+			}
+			return false, false
+		},
+	}
+}
+
+// cleanSheet : CSH / CSA, YES when the other team did not score.
+func cleanSheet(conceded count) market {
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{yesNoWord(conceded(m) == 0)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			yes, ok := readYesNo(outcome)
+			return ok && yes == (conceded(m) == 0), ok
+		},
+	}
+}
+
+// winToNil : WTNH / WTNA, YES when the team won without conceding.
+func winToNil(team string, conceded count) market {
+	won := func(m settlements.Match) bool {
+		return result(m.HomeScore, m.AwayScore) == team && conceded(m) == 0
+	}
+	return market{
+		winners: func(m settlements.Match) []string {
+			return []string{yesNoWord(won(m))}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			yes, ok := readYesNo(outcome)
+			return ok && yes == won(m), ok
+		},
+	}
+}
+
+// highestScoringHalf : HSH, 1H or 2H, X when both halves had as many goals.
+func highestScoringHalf() market {
+	half := func(m settlements.Match) string {
+		first := m.HalfHomeScore + m.HalfAwayScore
+		second := totalGoals(m) - first
+		switch {
+		case first > second:
+			return "1H"
+		case second > first:
+			return "2H"
+		}
+		return settlements.Draw
+	}
+	return market{
+		timeline: true,
+		winners: func(m settlements.Match) []string {
+			return []string{half(m)}
+		},
+		wins: func(m settlements.Match, outcome string) (bool, bool) {
+			o := norm(outcome)
+			switch o {
+			case "1H", "FIRST", "FIRSTHALF":
+				o = "1H"
+			case "2H", "SECOND", "SECONDHALF":
+				o = "2H"
+			case "X", "EQUAL", "DRAW", "TIE":
+				o = settlements.Draw
+			default:
+				return false, false
+			}
+			return o == half(m), true
+		},
+	}
+}
+
+// margin : H2 for a home win by two, A3+ for an away win by three or more.
+func margin(h, a int) string {
+	switch {
+	case h > a:
+		return "H" + marginGoals(h-a)
+	case a > h:
+		return "A" + marginGoals(a-h)
+	}
+	return settlements.Draw
+}
+
+func marginGoals(d int) string {
+	if d >= 3 {
+		return "3+"
+	}
+	return strconv.Itoa(d)
+}
+
+func yesNoWord(yes bool) string {
+	if yes {
+		return "YES"
+	}
+	return "NO"
+}
+
+func result(h, a int) string {
+	switch {
+	case h > a:
+		return settlements.Home
+	case h < a:
+		return settlements.Away
+	}
+	return settlements.Draw
+}
+
+func overOrUnder(goals int, threshold float64) string {
+	if float64(goals) > threshold {
+		return settlements.Over
+	}
+	return settlements.Under
+}
+
+func yesNo(yes bool) string {
+	if yes {
+		return settlements.Goal
+	}
+	return settlements.NoGoal
+}
+
+// band : 1-15, 16-30 ... 76-90, stoppage and extra time go to the last band.
+func band(minute int) string {
+	lo := (clamp(minute, 1, 90)-1)/15*15 + 1
+	return fmt.Sprintf("%d-%d", lo, lo+14)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// norm : upper case without spaces, "No Goal" -> NOGOAL
+func norm(outcome string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(outcome), ""))
+}
+
+var resultAliases = map[string]string{
+	"1":    settlements.Home,
+	"H":    settlements.Home,
+	"HOME": settlements.Home,
+	"X":    settlements.Draw,
+	"D":    settlements.Draw,
+	"DRAW": settlements.Draw,
+	"2":    settlements.Away,
+	"A":    settlements.Away,
+	"AWAY": settlements.Away,
+}
+
+// readResult : 1, X, 2 or H, D, A or HOME, DRAW, AWAY
+func readResult(outcome string) (string, bool) {
+	r, ok := resultAliases[norm(outcome)]
+	return r, ok
+}
+
+// readOverUnder : OV, OVER, O, OVER 2.5, +2.5 ... the line in the outcome is ignored,
+// it is the line of the market.
+func readOverUnder(outcome string) (string, bool) {
+	o := strings.TrimRight(norm(outcome), "0123456789.,()")
+	switch o {
+	case "OV", "OVER", "O", "+", ">":
+		return settlements.Over, true
+	case "UN", "UNDER", "U", "-", "<":
+		return settlements.Under, true
+	}
+	return "", false
+}
+
+// readYesNo : GG, G, YES, GOAL / NG, NO, NOGOAL
+func readYesNo(outcome string) (bool, bool) {
+	switch norm(outcome) {
+	case "GG", "G", "YES", "Y", "GOAL":
+		return true, true
+	case "NG", "NO", "N", "NOGOAL":
+		return false, true
+	}
+	return false, false
+}
+
+func noGoal(outcome string) bool {
+	switch norm(outcome) {
+	case "0", "NG", "NOGOAL", "NONE", "NOGOALS":
+		return true
+	}
+	return false
+}
+
+// readScore : reads 2-1 or 2:1
+func readScore(outcome string) (int, int, bool) {
+	o := norm(outcome)
+	sep := "-"
+	if strings.Contains(o, ":") {
+		sep = ":"
+	}
+
+	parts := strings.Split(o, sep)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 {
+		return 0, 0, false
+	}
+
+	a, err := strconv.Atoi(parts[1])
+	if err != nil || a < 0 {
+		return 0, 0, false
+	}
+
+	return h, a, true
+}
+
+// readRange : 3, 1-3, 6+, >5, <2, an optional GOALS or MIN suffix is dropped.
+func readRange(outcome string) (int, int, bool) {
+	o := norm(outcome)
+	for _, suffix := range []string{"GOALS", "GOAL", "MIN", "'"} {
+		o = strings.TrimSuffix(o, suffix)
+	}
+
+	atoi := func(v string) (int, bool) {
+		n, err := strconv.Atoi(v)
+		return n, err == nil && n >= 0
+	}
+
+	switch {
+	case strings.HasSuffix(o, "+"):
+		lo, ok := atoi(strings.TrimSuffix(o, "+"))
+		return lo, math.MaxInt32, ok
+	case strings.HasPrefix(o, ">="):
+		lo, ok := atoi(strings.TrimPrefix(o, ">="))
+		return lo, math.MaxInt32, ok
+	case strings.HasPrefix(o, ">"):
+		lo, ok := atoi(strings.TrimPrefix(o, ">"))
+		return lo + 1, math.MaxInt32, ok
+	case strings.HasPrefix(o, "<="):
+		hi, ok := atoi(strings.TrimPrefix(o, "<="))
+		return 0, hi, ok
+	case strings.HasPrefix(o, "<"):
+		hi, ok := atoi(strings.TrimPrefix(o, "<"))
+		return 0, hi - 1, ok && hi > 0
+	}
+
+	parts := strings.Split(o, "-")
+	switch len(parts) {
+	case 1:
+		n, ok := atoi(parts[0])
+		return n, n, ok
+	case 2:
+		lo, okLo := atoi(parts[0])
+		hi, okHi := atoi(parts[1])
+		return lo, hi, okLo && okHi && lo <= hi
+	}
+
+	return 0, 0, false
+}
+
+// stripSeparators : 1/X, 1-X, 1&X -> 1X
+func stripSeparators(outcome string) string {
+	return strings.NewReplacer("/", "", "-", "", "&", "", "OR", "").Replace(norm(outcome))
+}
+
+// readDoubleChance : 1X, 12, X2 in any order and with any separator.
+func readDoubleChance(outcome string) (map[string]bool, bool) {
+	o := stripSeparators(outcome)
+	if len(o) != 2 || o[0] == o[1] {
+		return nil, false
+	}
+
+	rr := make(map[string]bool)
+	for _, c := range o {
+		r, ok := resultAliases[string(c)]
+		if !ok {
+			return nil, false
+		}
+		rr[r] = true
+	}
+
+	return rr, true
+}
+
+// readHalfFull : 1/X, HOME/DRAW, 1X or HD
+func readHalfFull(outcome string) (string, string, bool) {
+	o := norm(outcome)
+
+	parts := strings.FieldsFunc(o, func(r rune) bool { return r == '/' || r == '-' || r == '&' })
+	if len(parts) == 1 && len(o) == 2 {
+		parts = []string{o[:1], o[1:]}
+	}
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	ht, okHt := resultAliases[parts[0]]
+	ft, okFt := resultAliases[parts[1]]
+	return ht, ft, okHt && okFt
+}
+
+// readCombo : splits 1&OV, 1 & Over, HOME/YES into the result and the rest.
+func readCombo(outcome string) (string, string, bool) {
+	o := norm(outcome)
+	for _, prefix := range []string{"HOME", "DRAW", "AWAY", "1", "X", "2"} {
+		if !strings.HasPrefix(o, prefix) {
+			continue
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(o, prefix), "&/+_,")
+		rest = strings.TrimPrefix(rest, "AND")
+		if rest == "" {
+			return "", "", false
+		}
+		return resultAliases[prefix], rest, true
+	}
+	return "", "", false
+}
+
+// providerWon : the winning outcome file lists winners, a result saying otherwise is read
+// as lost. Voided outcomes are not compared.
+func providerWon(result string) (bool, bool) {
+	switch norm(result) {
+	case "", "1", "W", "WIN", "WON", "WINNER", "TRUE", "Y", "YES":
+		return true, true
+	case "0", "L", "LOST", "LOSE", "LOSS", "LOSER", "FALSE", "N", "NO":
+		return false, true
+	}
+	return false, false
+}
+
+func wonLost(won bool) string {
+	if won {
+		return "won"
+	}
+	return "lost"
+}
+
+// describe : final 2-1, half time 1-0, first goal 12' home
+func describe(m settlements.Match) string {
+	d := fmt.Sprintf("final %d-%d", m.HomeScore, m.AwayScore)
+	if !m.Timeline {
+		return d
+	}
+	d += fmt.Sprintf(", half time %d-%d", m.HalfHomeScore, m.HalfAwayScore)
+	if m.FirstGoalTeam != "" {
+		d += fmt.Sprintf(", first goal %d' by %s", m.FirstGoalMinute, m.FirstGoalTeam)
+	}
+	return d
+}
@@ -0,0 +1,87 @@
+package settlements
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+)
+
+// halfTime : last minute of the first half, 45+2 is still the first half.
+const halfTime = 45
+
+// NewMatch : reads the final score and works out the half time score and the first goal
+// from the live scores. A timeline that does not add up is not an error, the markets that
+// need it are left unsettled.
+func NewMatch(homeScore, awayScore string, liveScores []oddsFiles.FinalLiveScores) (*Match, error) {
+
+	h, errH := strconv.Atoi(strings.TrimSpace(homeScore))
+	a, errA := strconv.Atoi(strings.TrimSpace(awayScore))
+	if errH != nil || errA != nil || h < 0 || a < 0 {
+		return nil, fmt.Errorf("final score %q-%q is not a valid score", homeScore, awayScore)
+	}
+
+	m := &Match{
+		HomeScore: h,
+		AwayScore: a,
+	}
+
+	if len(liveScores) != h+a {
+		return m, nil
+	}
+
+	prevHome, prevAway, prevMinute := 0, 0, 0
+	for i, x := range liveScores {
+
+		minute, ok := MinuteScored(x.MinuteScored)
+		if !ok || minute < prevMinute {
+			return m, nil
+		}
+
+		team := ""
+		switch {
+		case x.HomeScore == prevHome+1 && x.AwayScore == prevAway:
+			team = Home
+		case x.AwayScore == prevAway+1 && x.HomeScore == prevHome:
+			team = Away
+		default:
+			return m, nil
+		}
+
+		if i == 0 {
+			m.FirstGoalTeam = team
+			m.FirstGoalMinute = minute
+		}
+
+		if minute <= halfTime {
+			m.HalfHomeScore, m.HalfAwayScore = x.HomeScore, x.AwayScore
+		}
+
+		prevHome, prevAway, prevMinute = x.HomeScore, x.AwayScore, minute
+	}
+
+	// The last goal has to give the final score, 1-0 2-0 is not a timeline of a 1-1.
+	if prevHome != h || prevAway != a {
+		return m, nil
+	}
+
+	m.Timeline = true
+	return m, nil
+}
+
+// MinuteScored : reads 67 or 45+2 as the minute played, stoppage time counts as the
+// last minute of the half.
+func MinuteScored(minute string) (int, bool) {
+	minute = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(minute), "'"))
+	if i := strings.Index(minute, "+"); i > 0 {
+		minute = minute[:i]
+	}
+
+	m, err := strconv.Atoi(strings.TrimSpace(minute))
+	if err != nil || m < 0 {
+		return 0, false
+	}
+
+	return m, true
+}
@@ -0,0 +1,48 @@
+package settlements
+
+// Outcome ids the engine settles with. Provider ids and names are matched case
+// insensitively and common aliases (HOME, OVER, YES, ...) are understood.
+const (
+	Home   = "1"
+	Draw   = "X"
+	Away   = "2"
+	Over   = "OV"
+	Under  = "UN"
+	Goal   = "GG"
+	NoGoal = "NG"
+	Odd    = "ODD"
+	Even   = "EVEN"
+)
+
+// Match : what every market of a match is settled from.
+type Match struct {
+	HomeScore int `json:"home_score"`
+	AwayScore int `json:"away_score"`
+
+	// Timeline is set when the live scores add up to the final score one goal at a time
+	// and every minute could be read. Half time markets and the first goal are only
+	// settled then.
+	Timeline      bool `json:"timeline"`
+	HalfHomeScore int  `json:"half_home_score"`
+	HalfAwayScore int  `json:"half_away_score"`
+
+	// FirstGoalTeam is Home or Away, empty for a goalless match.
+	FirstGoalTeam   string `json:"first_goal_team"`
+	FirstGoalMinute int    `json:"first_goal_minute"`
+}
+
+// Result : winning outcomes of one market.
+type Result struct {
+	Code     string   `json:"code"`
+	Outcomes []string `json:"outcomes"`
+}
+
+// Discrepancy : a provider winning outcome the engine does not agree with.
+type Discrepancy struct {
+	SubTypeID   string `json:"sub_type_id"`
+	OutcomeID   string `json:"outcome_id"`
+	OutcomeName string `json:"outcome_name"`
+	Provider    bool   `json:"provider_won"`
+	Engine      bool   `json:"engine_won"`
+	Detail      string `json:"detail"`
+}
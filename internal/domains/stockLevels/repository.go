@@ -0,0 +1,8 @@
+package stockLevels
+
+import "context"
+
+// StockLevelsRepository : tells ops about category sets running dry.
+type StockLevelsRepository interface {
+	Send(ctx context.Context, a Alert) error
+}
@@ -0,0 +1,142 @@
+package stockLevels
+
+import (
+	"fmt"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+)
+
+// NewLevel : level of a category set of consumer, sanitized when consumer is empty.
+func NewLevel(consumer, category string, target, minimum int) (*Level, error) {
+
+	if consumer == "" {
+		consumer = goalCategories.Sanitized
+	}
+
+	if consumer != goalCategories.Sanitized && consumer != goalCategories.Production && consumer != goalCategories.Instant {
+		return nil, fmt.Errorf("consumer %s not known", consumer)
+	}
+
+	if category == "" {
+		return nil, fmt.Errorf("category not set")
+	}
+
+	if category == AllCategories && target > 0 {
+		return nil, fmt.Errorf("a target can only be set on a named category")
+	}
+
+	if target < 0 {
+		return nil, fmt.Errorf("target of %s must not be negative", category)
+	}
+
+	if minimum < 0 {
+		return nil, fmt.Errorf("minimum of %s must not be negative", category)
+	}
+
+	if target > 0 && minimum > target {
+		return nil, fmt.Errorf("minimum %d of %s is above its target %d", minimum, category, target)
+	}
+
+	return &Level{
+		Consumer: consumer,
+		Category: category,
+		Target:   target,
+		Minimum:  minimum,
+	}, nil
+}
+
+// Levels : checks every level, DefaultLevels when none is set.
+func Levels(ll []Level) ([]Level, error) {
+
+	if len(ll) == 0 {
+		return DefaultLevels(), nil
+	}
+
+	levels := []Level{}
+	seen := make(map[string]bool)
+	for _, l := range ll {
+
+		lv, err := NewLevel(l.Consumer, l.Category, l.Target, l.Minimum)
+		if err != nil {
+			return nil, err
+		}
+
+		k := lv.Consumer + "|" + lv.Category
+		if seen[k] {
+			return nil, fmt.Errorf("level of %s %s set twice", lv.Consumer, lv.Category)
+		}
+		seen[k] = true
+
+		levels = append(levels, *lv)
+	}
+
+	return levels, nil
+}
+
+// DefaultLevels : the stock the goals daemon has always filled the total goal sets up to,
+// a tenth of it as the minimum. Production draws 15 matches at most from a CL_ set.
+func DefaultLevels() []Level {
+
+	ll := []Level{
+		{Consumer: goalCategories.Sanitized, Category: "0", Target: 100000, Minimum: 10000},
+		{Consumer: goalCategories.Sanitized, Category: "1", Target: 100000, Minimum: 10000},
+		{Consumer: goalCategories.Sanitized, Category: "2", Target: 100000, Minimum: 10000},
+		{Consumer: goalCategories.Sanitized, Category: "3", Target: 50000, Minimum: 5000},
+		{Consumer: goalCategories.Sanitized, Category: "4", Target: 30000, Minimum: 3000},
+		{Consumer: goalCategories.Sanitized, Category: "5", Target: 20000, Minimum: 2000},
+		{Consumer: goalCategories.Sanitized, Category: "6", Target: 20000, Minimum: 2000},
+		{Consumer: goalCategories.Production, Category: AllCategories, Minimum: 15},
+	}
+
+	return ll
+}
+
+// Find : level of a category set of consumer, the AllCategories level of consumer when
+// the category has none.
+func Find(ll []Level, consumer, category string) (Level, bool) {
+
+	all, found := Level{}, false
+	for _, l := range ll {
+		if l.Consumer != consumer {
+			continue
+		}
+		if l.Category == category {
+			return l, true
+		}
+		if l.Category == AllCategories {
+			all, found = l, true
+		}
+	}
+
+	return all, found
+}
+
+// Filled : whether every category set of consumer with a target holds more than it,
+// never when none of them has a target. counts are keyed by category.
+func Filled(ll []Level, consumer string, counts map[string]int) bool {
+	targets := 0
+	for _, l := range ll {
+		if l.Consumer != consumer || l.Target == 0 {
+			continue
+		}
+		if counts[l.Category] <= l.Target {
+			return false
+		}
+		targets++
+	}
+	return targets > 0
+}
+
+// Status : status of a set holding count matches against its minimum, depleting when it
+// runs out within horizon hours. depletion is -1 when nothing is drawn from the set.
+func Status(count, minimum int, depletion, horizon float64) string {
+	switch {
+	case count == 0 && minimum > 0:
+		return Empty
+	case count < minimum:
+		return Low
+	case horizon > 0 && depletion >= 0 && depletion < horizon:
+		return Depleting
+	}
+	return Ok
+}
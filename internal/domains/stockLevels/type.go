@@ -0,0 +1,57 @@
+package stockLevels
+
+// Stock statuses of a category set.
+const (
+	// Ok : at or above its minimum and not running out within the alert horizon.
+	Ok = "ok"
+	// Depleting : above its minimum but running out within the alert horizon.
+	Depleting = "depleting"
+	// Low : under its minimum.
+	Low = "low"
+	// Empty : no match left.
+	Empty = "empty"
+)
+
+// AllCategories : level of every category of a consumer without a level of its own. It
+// only sets a minimum.
+const AllCategories = "*"
+
+// Level : stock a category set is filled up to and stock it must not fall under. Consumer
+// is one of the goalCategories consumers, sanitized when empty. A target of 0 is never
+// filled up to, a minimum of 0 never alerts.
+type Level struct {
+	Consumer string `mapstructure:"consumer" json:"consumer"`
+	Category string `mapstructure:"category" json:"category"`
+	Target   int    `mapstructure:"target" json:"target"`
+	Minimum  int    `mapstructure:"minimum" json:"minimum"`
+}
+
+// Stock : live count of a category set against its level, with the rate matches are
+// drawn from it and the hours until it runs out at that rate, -1 when nothing is drawn.
+type Stock struct {
+	Consumer   string  `json:"consumer"`
+	Category   string  `json:"category"`
+	Set        string  `json:"set"`
+	Count      int     `json:"count"`
+	Target     int     `json:"target"`
+	Minimum    int     `json:"minimum"`
+	Rate       float64 `json:"rate_per_hour"`
+	Depletion  float64 `json:"hours_to_depletion"`
+	DepletesAt string  `json:"depletes_at,omitempty"`
+	Status     string  `json:"status"`
+	CheckedAt  string  `json:"checked_at"`
+}
+
+// Alert : stocks posted to the alert webhook.
+type Alert struct {
+	Service string  `json:"service"`
+	Stocks  []Stock `json:"stocks"`
+	SentAt  string  `json:"sent_at"`
+}
+
+// StockAPI : stock levels api
+type StockAPI struct {
+	StatusCode        string  `json:"status_code"`
+	StatusDescription string  `json:"status_description"`
+	Stocks            []Stock `json:"stocks"`
+}
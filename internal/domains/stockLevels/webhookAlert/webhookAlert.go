@@ -0,0 +1,73 @@
+package webhookAlert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels"
+)
+
+var _ stockLevels.StockLevelsRepository = (*WebhookAlertConfigs)(nil)
+
+// WebhookAlertConfigs : endpoint alerts are posted to.
+type WebhookAlertConfigs struct {
+	endPoint string
+	client   *http.Client
+}
+
+// New initializes the webhook, alerts are posted as json to endPoint.
+func New(endPoint string) (*WebhookAlertConfigs, error) {
+
+	u, err := url.Parse(endPoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("webhook endpoint %s not valid", endPoint)
+	}
+
+	return &WebhookAlertConfigs{
+		endPoint: u.String(),
+		client: &http.Client{
+			Timeout: time.Second * 15,
+			Transport: &http.Transport{
+				Dial: (&net.Dialer{
+					Timeout: time.Second * 15,
+				}).Dial,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}, nil
+}
+
+// Send : posts the alert, any status other than 2xx is an error.
+func (s *WebhookAlertConfigs) Send(ctx context.Context, a stockLevels.Alert) error {
+
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshall alert : %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endPoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to initialize new request : %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook %s : %w", s.endPoint, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("webhook %s returned %d : %s", s.endPoint, res.StatusCode, string(body))
+	}
+
+	return nil
+}
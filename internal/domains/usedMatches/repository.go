@@ -9,5 +9,6 @@ import (
 type UsedMatchesRepository interface {
 	Save(ctx context.Context, t UsedMatches) (int, error)
 	GetAvailable(ctx context.Context, category string) ([]goals.Goals, error)
+	GetReusable(ctx context.Context, category string, days int) ([]goals.Goals, error)
 	GetMatchDetails(ctx context.Context, category, matchID string) ([]goals.Goals, error)
 }
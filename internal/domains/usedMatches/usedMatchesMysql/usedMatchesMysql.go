@@ -93,6 +93,35 @@ func (r *MysqlRepository) GetAvailable(ctx context.Context, category string) ([]
 	return gc, nil
 }
 
+// GetReusable : matches of category not used in the last days days.
+func (r *MysqlRepository) GetReusable(ctx context.Context, category string, days int) ([]goals.Goals, error) {
+	var gc []goals.Goals
+	statement := fmt.Sprintf("select goal_id,country,project_id,match_id,category,\n" +
+		"created,modified from goals where category = ? and \n" +
+		"match_id not in(select match_id from used_matches where category = ? and modified > now() - interval ? day) ")
+
+	raws, err := r.db.Query(statement, category, category, days)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g goals.Goals
+		err := raws.Scan(&g.GoalID, &g.Country, &g.ProjectID, &g.MatchID, &g.Category, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
 // GetMatchDetails : used matchID and category
 func (r *MysqlRepository) GetMatchDetails(ctx context.Context, category, matchID string) ([]goals.Goals, error) {
 	var gc []goals.Goals
@@ -0,0 +1,76 @@
+package wcFeeds
+
+import "encoding/xml"
+
+// UpcomingEvents : root of the World Cup tournament feed.
+type UpcomingEvents struct {
+	XMLName     xml.Name      `xml:"UpcomingEvents"`
+	LocalTime   string        `xml:"LocalTime,attr"`
+	UtcTime     string        `xml:"UtcTime,attr"`
+	RoundEvents []RoundEvents `xml:"FootballTournamentRoundEvent"`
+}
+
+// RoundEvents : a tournament round (Group Stage, Round of 16 ... Final).
+type RoundEvents struct {
+	RoundName         string        `xml:"RoundName,attr"`
+	ID                string        `xml:"ID,attr"`
+	EventType         string        `xml:"EventType,attr"`
+	EventNumber       string        `xml:"EventNumber,attr"`
+	EventTime         string        `xml:"EventTime,attr"`
+	FinishTime        string        `xml:"FinishTime,attr"`
+	EventStatus       string        `xml:"EventStatus,attr"`
+	TournamentEventID string        `xml:"TournamentEventID,attr"`
+	MatchEvents       []MatchEvents `xml:"FootballTournamentMatchEvent"`
+}
+
+// MatchEvents : a single match inside a round. Teams and markets are only
+// published once the match is open for betting, scores once it is resulted.
+type MatchEvents struct {
+	ID                      string            `xml:"ID,attr"`
+	EventType               string            `xml:"EventType,attr"`
+	EventNumber             string            `xml:"EventNumber,attr"`
+	EventTime               string            `xml:"EventTime,attr"`
+	FinishTime              string            `xml:"FinishTime,attr"`
+	EventStatus             string            `xml:"EventStatus,attr"`
+	TournamentRoundEventID  string            `xml:"TournamentRoundEventID,attr"`
+	TournamentEventID       string            `xml:"TournamentEventID,attr"`
+	HomeTeam                string            `xml:"HomeTeam,attr"`
+	AwayTeam                string            `xml:"AwayTeam,attr"`
+	HomeTeamAbbr            string            `xml:"HomeTeamAbbr,attr"`
+	AwayTeamAbbr            string            `xml:"AwayTeamAbbr,attr"`
+	HomeTeamID              string            `xml:"HomeTeamID,attr"`
+	AwayTeamID              string            `xml:"AwayTeamID,attr"`
+	HomeScore               string            `xml:"HomeScore,attr"`
+	AwayScore               string            `xml:"AwayScore,attr"`
+	Markets                 []Markets         `xml:"Market"`
+	AsianHandicapMarkets    []HandicapMarkets `xml:"AsianHandicapMarket"`
+	AmericanHandicapMarkets []HandicapMarkets `xml:"AmericanHandicapMarket"`
+}
+
+// Markets : plain market e.g 1X2, CS, TG25.
+type Markets struct {
+	ID          string       `xml:"ID,attr"`
+	Description string       `xml:"Description,attr"`
+	Selections  []Selections `xml:"Selection"`
+}
+
+// HandicapMarkets : asian and american handicap lines e.g AHF_3, AMF_1.
+type HandicapMarkets struct {
+	ID          string       `xml:"ID,attr"`
+	Description string       `xml:"Description,attr"`
+	Value       string       `xml:"Value,attr"`
+	Split       string       `xml:"Split,attr"`
+	AppliedTo   string       `xml:"AppliedTo,attr"`
+	Selections  []Selections `xml:"Selection"`
+}
+
+// Selections : a single outcome. Result is only set on resulted matches.
+type Selections struct {
+	ID          string `xml:"ID,attr"`
+	Description string `xml:"Description,attr"`
+	OddsDecimal string `xml:"OddsDecimal,attr"`
+	Team        string `xml:"Team,attr"`
+	TeamAbbr    string `xml:"TeamAbbr,attr"`
+	TeamID      string `xml:"TeamID,attr"`
+	Result      string `xml:"Result,attr"`
+}
@@ -0,0 +1,19 @@
+package wcFeeds
+
+import (
+	"context"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
+)
+
+// WcFeedRepository : converts the World Cup tournament feed into the raw
+// structures used by the league pipeline. Results are read from the matches the
+// feed carries a final score for, the upcoming feed carries none of them.
+type WcFeedRepository interface {
+	RawOdds(ctx context.Context) ([]oddsFiles.RawOdds, error)
+	RawWinningOutcomes(ctx context.Context) ([]oddsFiles.RawWinningOutcomes, error)
+	RoundWinningOutcomes(ctx context.Context) ([]woFiles.RawWinningOutcome, error)
+	RoundLiveScores(ctx context.Context) ([]lsFiles.LsData, error)
+}
@@ -0,0 +1,358 @@
+package wcXml
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/wcFeeds"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
+)
+
+var _ wcFeeds.WcFeedRepository = (*WcXmlConfigs)(nil)
+
+type WcXmlConfigs struct {
+	feedFile      string
+	projectID     string
+	competitionID string
+}
+
+// New initializes a new instance of the World Cup xml feed reader.
+func New(feedFile, projectID, competitionID string) (*WcXmlConfigs, error) {
+
+	if feedFile == "" {
+		return nil, fmt.Errorf("feedFile not set")
+	}
+
+	if projectID == "" {
+		return nil, fmt.Errorf("projectID not set")
+	}
+
+	if competitionID == "" {
+		return nil, fmt.Errorf("competitionID not set")
+	}
+
+	c := &WcXmlConfigs{
+		feedFile:      feedFile,
+		projectID:     projectID,
+		competitionID: competitionID,
+	}
+
+	return c, nil
+}
+
+// readFeed : opens and decodes the feed file.
+func (s *WcXmlConfigs) readFeed() (wcFeeds.UpcomingEvents, error) {
+	var ue wcFeeds.UpcomingEvents
+
+	byteValue, err := os.ReadFile(s.feedFile)
+	if err != nil {
+		return ue, fmt.Errorf("Err : %v failed to read feed %s", err, s.feedFile)
+	}
+
+	err = xml.Unmarshal(byteValue, &ue)
+	if err != nil {
+		return ue, fmt.Errorf("Err : %v failed to decode feed %s", err, s.feedFile)
+	}
+
+	return ue, nil
+}
+
+// RawOdds : returns odds for every match whose teams and markets are published.
+func (s *WcXmlConfigs) RawOdds(ctx context.Context) ([]oddsFiles.RawOdds, error) {
+
+	odds := []oddsFiles.RawOdds{}
+
+	ue, err := s.readFeed()
+	if err != nil {
+		return odds, err
+	}
+
+	for _, r := range ue.RoundEvents {
+		for _, m := range r.MatchEvents {
+
+			if m.HomeTeamID == "" || m.AwayTeamID == "" {
+				log.Printf("Skip match %s [%s] teams not yet published", m.ID, r.RoundName)
+				continue
+			}
+
+			if len(m.Markets) == 0 && len(m.AsianHandicapMarkets) == 0 && len(m.AmericanHandicapMarkets) == 0 {
+				log.Printf("Skip match %s [%s] no markets", m.ID, r.RoundName)
+				continue
+			}
+
+			o := oddsFiles.RawOdds{
+				ParentMatchID: m.ID,
+				ProjectID:     s.projectID,
+				MatchID:       m.EventNumber,
+				TournamentID:  m.TournamentEventID,
+				RoundID:       r.ID,
+				RoundName:     r.RoundName,
+				RoundNumber:   r.EventNumber,
+				EventNumber:   m.EventNumber,
+				StartTime:     feedTime(m.EventTime),
+				EndTime:       feedTime(m.FinishTime),
+				HomeTeamID:    m.HomeTeamID,
+				HomeTeam:      m.HomeTeam,
+				HomeAlias:     m.HomeTeamAbbr,
+				AwayTeamID:    m.AwayTeamID,
+				AwayTeam:      m.AwayTeam,
+				AwayAlias:     m.AwayTeamAbbr,
+			}
+
+			for _, x := range m.Markets {
+				mkt := oddsFiles.RawMarkets{
+					Name:        x.Description,
+					SubTypeID:   x.ID,
+					RawOutcomes: rawOutcomes(x.Selections),
+				}
+				o.RawMarkets = append(o.RawMarkets, mkt)
+			}
+
+			for _, x := range append(m.AsianHandicapMarkets, m.AmericanHandicapMarkets...) {
+				mkt := oddsFiles.RawMarkets{
+					Name:        x.Description,
+					SubTypeID:   lineSubTypeID(x),
+					Handicap:    x.Value,
+					Split:       x.Split,
+					AppliedTo:   x.AppliedTo,
+					RawOutcomes: rawOutcomes(x.Selections),
+				}
+				o.RawMarkets = append(o.RawMarkets, mkt)
+			}
+
+			odds = append(odds, o)
+		}
+	}
+
+	return odds, nil
+}
+
+// RawWinningOutcomes : returns results for every match that carries a final score.
+func (s *WcXmlConfigs) RawWinningOutcomes(ctx context.Context) ([]oddsFiles.RawWinningOutcomes, error) {
+
+	wos := []oddsFiles.RawWinningOutcomes{}
+
+	ue, err := s.readFeed()
+	if err != nil {
+		return wos, err
+	}
+
+	for _, r := range ue.RoundEvents {
+		for _, m := range r.MatchEvents {
+
+			if m.HomeScore == "" || m.AwayScore == "" {
+				continue
+			}
+
+			wo := oddsFiles.RawWinningOutcomes{
+				RoundNumberID: r.ID,
+				TournamentID:  m.TournamentEventID,
+				RoundName:     r.RoundName,
+				EventNumber:   m.EventNumber,
+				HomeTeamID:    m.HomeTeamID,
+				AwayTeamID:    m.AwayTeamID,
+				HomeScore:     m.HomeScore,
+				AwayScore:     m.AwayScore,
+			}
+
+			for _, x := range matchWinningOutcomes(m) {
+				wo.RawWOs = append(wo.RawWOs, oddsFiles.RawWOs{
+					ParentMatchID: x.ParentMatchID,
+					SubTypeID:     x.SubTypeID,
+					OutcomeID:     x.OutcomeID,
+					OutcomeName:   x.OutcomeName,
+					Result:        x.Result,
+				})
+			}
+
+			wos = append(wos, wo)
+		}
+	}
+
+	return wos, nil
+}
+
+// RoundWinningOutcomes : returns results grouped per round, in the same layout
+// as the league winning outcome files (wo_<round>_<project>_<competition>.txt).
+// Only rounds where every match is resulted are returned.
+func (s *WcXmlConfigs) RoundWinningOutcomes(ctx context.Context) ([]woFiles.RawWinningOutcome, error) {
+
+	rounds := []woFiles.RawWinningOutcome{}
+
+	ue, err := s.readFeed()
+	if err != nil {
+		return rounds, err
+	}
+
+	for _, r := range ue.RoundEvents {
+
+		if !resulted(r) {
+			continue
+		}
+
+		rw := woFiles.RawWinningOutcome{
+			RoundNumberID: r.ID,
+			ProjectID:     s.projectID,
+			CompetitionID: s.competitionID,
+			StartTime:     feedTime(r.EventTime),
+			EndTime:       feedTime(r.FinishTime),
+			SportType:     "football",
+			TournamentID:  r.TournamentEventID,
+			RoundName:     r.RoundName,
+		}
+
+		for _, m := range r.MatchEvents {
+
+			rw.Results = append(rw.Results, woFiles.Results{
+				ParentMatchID: m.ID,
+				HomeTeamID:    m.HomeTeamID,
+				AwayTeamID:    m.AwayTeamID,
+				EventNumber:   m.EventNumber,
+				HomeScore:     m.HomeScore,
+				AwayScore:     m.AwayScore,
+			})
+
+			rw.WinningOutcomes = append(rw.WinningOutcomes, matchWinningOutcomes(m)...)
+		}
+
+		rounds = append(rounds, rw)
+	}
+
+	return rounds, nil
+}
+
+// RoundLiveScores : returns the live scores of every resulted round in the layout of
+// the league live score files (<round>_<project>_<competition>.txt). The feed carries
+// no goal minutes, each match has its final score only so that no market settled from
+// the timeline is settled from it.
+func (s *WcXmlConfigs) RoundLiveScores(ctx context.Context) ([]lsFiles.LsData, error) {
+
+	rounds := []lsFiles.LsData{}
+
+	ue, err := s.readFeed()
+	if err != nil {
+		return rounds, err
+	}
+
+	for _, r := range ue.RoundEvents {
+
+		if !resulted(r) {
+			continue
+		}
+
+		ls := lsFiles.LsData{
+			RoundNumberID: r.ID,
+			ProjectID:     s.projectID,
+			CompetitionID: s.competitionID,
+			StartTime:     feedTime(r.EventTime),
+			EndTime:       feedTime(r.FinishTime),
+		}
+
+		for _, m := range r.MatchEvents {
+
+			home, errH := strconv.Atoi(m.HomeScore)
+			away, errA := strconv.Atoi(m.AwayScore)
+			if errH != nil || errA != nil {
+				return rounds, fmt.Errorf("score %s-%s of match %s not valid", m.HomeScore, m.AwayScore, m.ID)
+			}
+
+			ls.LsMarkets = append(ls.LsMarkets, lsFiles.LsMarkets{
+				ParentMatchID: m.ID,
+				HomeScore:     home,
+				AwayScore:     away,
+			})
+		}
+
+		rounds = append(rounds, ls)
+	}
+
+	return rounds, nil
+}
+
+// resulted : every match of the round carries a final score.
+func resulted(r wcFeeds.RoundEvents) bool {
+	if len(r.MatchEvents) == 0 {
+		return false
+	}
+
+	for _, m := range r.MatchEvents {
+		if m.HomeScore == "" || m.AwayScore == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchWinningOutcomes : returns the selections the feed flagged with a result, handicap
+// lines keyed as RawOdds keys them.
+func matchWinningOutcomes(m wcFeeds.MatchEvents) []woFiles.WinningOutcomes {
+	wos := []woFiles.WinningOutcomes{}
+
+	add := func(subTypeID string, selections []wcFeeds.Selections) {
+		for _, i := range selections {
+			if i.Result == "" {
+				continue
+			}
+			wos = append(wos, woFiles.WinningOutcomes{
+				ParentMatchID: m.ID,
+				SubTypeID:     subTypeID,
+				OutcomeID:     i.ID,
+				OutcomeName:   i.Description,
+				Result:        i.Result,
+			})
+		}
+	}
+
+	for _, x := range m.Markets {
+		add(x.ID, x.Selections)
+	}
+
+	for _, x := range append(m.AsianHandicapMarkets, m.AmericanHandicapMarkets...) {
+		add(lineSubTypeID(x), x.Selections)
+	}
+
+	return wos
+}
+
+// lineSubTypeID : the feed ranks handicap lines (AHF_1 is the 1st line) and the rank
+// moves between revisions, so each line is keyed by its family and value e.g AHF_-0.25.
+func lineSubTypeID(x wcFeeds.HandicapMarkets) string {
+	family := x.ID
+	if i := strings.LastIndex(x.ID, "_"); i > 0 {
+		family = x.ID[:i]
+	}
+	return fmt.Sprintf("%s_%s", family, x.Value)
+}
+
+// rawOutcomes : maps feed selections to raw outcomes.
+func rawOutcomes(selections []wcFeeds.Selections) []oddsFiles.RawOutcomes {
+	outcomes := []oddsFiles.RawOutcomes{}
+	for _, i := range selections {
+
+		alias := i.TeamAbbr
+		if alias == "" {
+			alias = i.ID
+		}
+
+		outcomes = append(outcomes, oddsFiles.RawOutcomes{
+			OutcomeID:    i.ID,
+			OutcomeName:  i.Description,
+			OddValue:     i.OddsDecimal,
+			OutcomeAlias: alias,
+		})
+	}
+	return outcomes
+}
+
+// feedTime : converts 2024-12-19T14:42:00 to 2024-12-19 14:42:00
+func feedTime(t string) string {
+	return strings.Replace(t, "T", " ", 1)
+}
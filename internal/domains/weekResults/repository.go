@@ -0,0 +1,9 @@
+package weekResults
+
+import "context"
+
+// WeekResultsRepository contains methods that implements week results struct
+type WeekResultsRepository interface {
+	Save(ctx context.Context, t WeekResults) (int, error)
+	GetSeasonWeekResults(ctx context.Context, seasonWeekID string) ([]WeekResults, error)
+}
@@ -0,0 +1,22 @@
+package weekResults
+
+// | week_results | CREATE TABLE `week_results` (
+// 	`week_result_id` bigint(20) NOT NULL AUTO_INCREMENT,
+// 	`season_week_id` varchar(50) NOT NULL,
+// 	`match_id` varchar(50) NOT NULL,
+// 	`home_score` tinyint(3) NOT NULL,
+// 	`away_score` tinyint(3) NOT NULL,
+// 	`created` datetime NOT NULL,
+// 	PRIMARY KEY (`week_result_id`),
+// 	UNIQUE KEY `week_match` (`season_week_id`,`match_id`)
+
+// WeekResults : final score of a match of a published season week, kept after the pr_wo
+// key of the week expires.
+type WeekResults struct {
+	WeekResultID string
+	SeasonWeekID string
+	MatchID      string
+	HomeScore    int
+	AwayScore    int
+	Created      string
+}
@@ -0,0 +1,30 @@
+package weekResults
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewWeekResults instantiate week results struct
+func NewWeekResults(seasonWeekID, matchID string, homeScore, awayScore int) (*WeekResults, error) {
+
+	if seasonWeekID == "" {
+		return &WeekResults{}, fmt.Errorf("seasonWeekID not set")
+	}
+
+	if matchID == "" {
+		return &WeekResults{}, fmt.Errorf("matchID not set")
+	}
+
+	if homeScore < 0 || awayScore < 0 {
+		return &WeekResults{}, fmt.Errorf("score %d-%d of match %s not valid", homeScore, awayScore, matchID)
+	}
+
+	return &WeekResults{
+		SeasonWeekID: seasonWeekID,
+		MatchID:      matchID,
+		HomeScore:    homeScore,
+		AwayScore:    awayScore,
+		Created:      time.Now().Format("2006-01-02 15:04:05"),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package weekResultsMysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults"
+)
+
+var _ weekResults.WeekResultsRepository = (*MysqlRepository)(nil)
+
+type MysqlRepository struct {
+	db *sql.DB
+}
+
+// Create a new mysql repository
+func New(connectionString string) (*MysqlRepository, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	// Maximum Open Connections
+	db.SetMaxOpenConns(10)
+	// Idle Connection Timeout
+	db.SetConnMaxIdleTime(5 * time.Second)
+	// Connection Lifetime
+	db.SetConnMaxLifetime(15 * time.Second)
+
+	return &MysqlRepository{
+		db: db,
+	}, nil
+}
+
+// Save : result of a match of a season week, a week published again keeps its last scores.
+func (mr *MysqlRepository) Save(ctx context.Context, t weekResults.WeekResults) (int, error) {
+	var d int
+	rs, err := mr.db.Exec("INSERT week_results SET season_week_id=?,match_id=?,home_score=?,away_score=?,created=now() \n"+
+		"ON DUPLICATE KEY UPDATE home_score=values(home_score),away_score=values(away_score)",
+		t.SeasonWeekID, t.MatchID, t.HomeScore, t.AwayScore)
+
+	if err != nil {
+		return d, fmt.Errorf("Unable to save week result : %v", err)
+	}
+
+	lastInsertedID, err := rs.LastInsertId()
+	if err != nil {
+		return d, fmt.Errorf("Unable to retrieve last week result ID [primary key] : %v", err)
+	}
+
+	return int(lastInsertedID), nil
+}
+
+// GetSeasonWeekResults : results of every match of a season week.
+func (r *MysqlRepository) GetSeasonWeekResults(ctx context.Context, seasonWeekID string) ([]weekResults.WeekResults, error) {
+	var gc []weekResults.WeekResults
+
+	raws, err := r.db.QueryContext(ctx, "select week_result_id,season_week_id,match_id,home_score,away_score,created \n"+
+		"from week_results where season_week_id=? order by match_id asc", seasonWeekID)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g weekResults.WeekResults
+		err := raws.Scan(&g.WeekResultID, &g.SeasonWeekID, &g.MatchID, &g.HomeScore, &g.AwayScore, &g.Created)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
@@ -15,4 +15,9 @@ type WoFilesRepository interface {
 	GetWO(ctx context.Context, statement string) ([]WinningOutcomeFiles, error)
 	GetPendingWo(ctx context.Context, status string) ([]WoFiles, error)
 	UpdateWoStatus(ctx context.Context, status, woFileID string) (int64, error)
+
+	GetWoFileByExtID(ctx context.Context, extID, country string) ([]WoFiles, error)
+	GetWoFilesByDate(ctx context.Context, country, fromDate, toDate string) ([]WoFiles, error)
+	GetWoFilesByExtIDs(ctx context.Context, extIDs []string, country string) ([]WoFiles, error)
+	UpdateWoRevision(ctx context.Context, woFileName, woDir, sha256 string, fileSize int64, revision int, status, woFileID, previousSha256 string) (int64, error)
 }
@@ -22,6 +22,9 @@ type WoFiles struct {
 	ProjectID     string
 	CompetitionID string
 	Status        string
+	Sha256        string
+	FileSize      int64
+	Revision      int
 	Created       string
 	Modified      string
 }
@@ -33,6 +36,8 @@ type RawWinningOutcome struct {
 	StartTime       string            `json:"start_time"`
 	EndTime         string            `json:"end_time"`
 	SportType       string            `json:"sport_type"`
+	TournamentID    string            `json:"tournament_id,omitempty"`
+	RoundName       string            `json:"round_name,omitempty"`
 	WinningOutcomes []WinningOutcomes `json:"winning_outcomes"`
 	Results         []Results         `json:"results"`
 }
@@ -49,12 +54,18 @@ type Results struct {
 	ParentMatchID string `json:"parent_match_id"`
 	HomeTeamID    string `json:"home_team_id"`
 	AwayTeamID    string `json:"away_team_id"`
+	EventNumber   string `json:"event_number,omitempty"`
 	HomeScore     string `json:"home_score"`
 	AwayScore     string `json:"away_score"`
 }
 
 type MatchWO struct {
 	RoundNumberID    string             `json:"round_number_id"`
+	TournamentID     string             `json:"tournament_id,omitempty"`
+	RoundName        string             `json:"round_name,omitempty"`
+	EventNumber      string             `json:"event_number,omitempty"`
+	HomeTeamID       string             `json:"home_team_id,omitempty"`
+	AwayTeamID       string             `json:"away_team_id,omitempty"`
 	HomeScore        string             `json:"home_score"`
 	AwayScore        string             `json:"away_score"`
 	MWinningOutcomes []MWinningOutcomes `json:"winning_outcomes"`
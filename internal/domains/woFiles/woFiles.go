@@ -6,7 +6,7 @@ import (
 )
 
 // NewWoFile instantiate
-func NewWoFile(woFileName, woDir, country, woExtID, projectID, competitionID, status string) (*WoFiles, error) {
+func NewWoFile(woFileName, woDir, country, woExtID, projectID, competitionID, status, sha256 string, fileSize int64) (*WoFiles, error) {
 
 	if woFileName == "" {
 		return &WoFiles{}, fmt.Errorf("woFileName not set")
@@ -36,6 +36,10 @@ func NewWoFile(woFileName, woDir, country, woExtID, projectID, competitionID, st
 		return &WoFiles{}, fmt.Errorf("status not set")
 	}
 
+	if len(sha256) != 64 {
+		return &WoFiles{}, fmt.Errorf("sha256 not set")
+	}
+
 	created := time.Now().Format("2006-01-02 15:04:05")
 	modified := time.Now().Format("2006-01-02 15:04:05")
 
@@ -47,6 +51,9 @@ func NewWoFile(woFileName, woDir, country, woExtID, projectID, competitionID, st
 		CompetitionID: competitionID,
 		Country:       country,
 		Status:        status,
+		Sha256:        sha256,
+		FileSize:      fileSize,
+		Revision:      1,
 		Created:       created,
 		Modified:      modified,
 	}, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
@@ -65,12 +66,12 @@ func (mr *MysqlRepository) Save(ctx context.Context, t woFiles.WoFiles) (int, er
 // 	`created` datetime NOT NULL,
 // 	`modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
-// Save :
+// SaveWo : 0 is returned when the ext id of the country is already saved
 func (mr *MysqlRepository) SaveWo(ctx context.Context, t woFiles.WoFiles) (int, error) {
 	var d int
-	rs, err := mr.db.Exec("INSERT winning_outcome_files SET wo_file_name=?,wo_dir=?,country=?,ext_id=?, \n"+
-		"project_id=?,competition_id=?,created=now(),modified=now() ON DUPLICATE KEY UPDATE modified=now()",
-		t.WoFileName, t.WoDir, t.Country, t.WoExtID, t.ProjectID, t.CompetitionID)
+	rs, err := mr.db.Exec("INSERT IGNORE winning_outcome_files SET wo_file_name=?,wo_dir=?,country=?,ext_id=?, \n"+
+		"project_id=?,competition_id=?,status=?,sha256=?,file_size=?,revision=?,created=now(),modified=now()",
+		t.WoFileName, t.WoDir, t.Country, t.WoExtID, t.ProjectID, t.CompetitionID, t.Status, t.Sha256, t.FileSize, t.Revision)
 
 	if err != nil {
 		return d, fmt.Errorf("unable to save wo files : %v", err)
@@ -255,3 +256,115 @@ func (mr *MysqlRepository) UpdateWoStatus(ctx context.Context, status, woFileID
 	}
 	return result.RowsAffected()
 }
+
+// GetWoFileByExtID : returns the winning outcome file saved for a round
+func (r *MysqlRepository) GetWoFileByExtID(ctx context.Context, extID, country string) ([]woFiles.WoFiles, error) {
+	var gc []woFiles.WoFiles
+	statement := fmt.Sprintf("select wo_file_id,wo_file_name,wo_dir,country,ext_id,project_id,competition_id,status,\n"+
+		"sha256,file_size,revision,created,modified from winning_outcome_files where ext_id='%s' and country='%s'",
+		extID, country)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g woFiles.WoFiles
+		err := raws.Scan(&g.WoFileID, &g.WoFileName, &g.WoDir, &g.Country, &g.WoExtID, &g.ProjectID, &g.CompetitionID, &g.Status,
+			&g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
+
+// GetWoFilesByExtIDs : winning outcome files saved for any of extIDs, in one query
+func (r *MysqlRepository) GetWoFilesByExtIDs(ctx context.Context, extIDs []string, country string) ([]woFiles.WoFiles, error) {
+	var gc []woFiles.WoFiles
+
+	if len(extIDs) == 0 {
+		return gc, nil
+	}
+
+	args := []interface{}{country}
+	for _, id := range extIDs {
+		args = append(args, id)
+	}
+
+	statement := fmt.Sprintf("select wo_file_id,wo_file_name,wo_dir,country,ext_id,project_id,competition_id,status,\n"+
+		"sha256,file_size,revision,created,modified from winning_outcome_files where country=? and ext_id in (%s)",
+		strings.TrimSuffix(strings.Repeat("?,", len(extIDs)), ","))
+
+	raws, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer raws.Close()
+
+	for raws.Next() {
+		var g woFiles.WoFiles
+		err := raws.Scan(&g.WoFileID, &g.WoFileName, &g.WoDir, &g.Country, &g.WoExtID, &g.ProjectID, &g.CompetitionID, &g.Status,
+			&g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+// UpdateWoRevision : saves the content of a re-delivered winning outcome file, only while
+// the row still holds previousSha256
+func (mr *MysqlRepository) UpdateWoRevision(ctx context.Context, woFileName, woDir, sha256 string, fileSize int64, revision int, status, woFileID, previousSha256 string) (int64, error) {
+	var rs int64
+	result, err := mr.db.Exec("update winning_outcome_files set wo_file_name=?,wo_dir=?,sha256=?,file_size=?,revision=?,status=?,modified=now() \n"+
+		"where wo_file_id = ? and sha256 = ? ", woFileName, woDir, sha256, fileSize, revision, status, woFileID, previousSha256)
+	if err != nil {
+		return rs, fmt.Errorf("unable to update wo file revision : %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetWoFilesByDate : winning outcome files received for a country between two dates (yyyy-mm-dd)
+func (r *MysqlRepository) GetWoFilesByDate(ctx context.Context, country, fromDate, toDate string) ([]woFiles.WoFiles, error) {
+	var gc []woFiles.WoFiles
+	statement := fmt.Sprintf("select wo_file_id,wo_file_name,wo_dir,country,ext_id,project_id,competition_id,status,\n"+
+		"sha256,file_size,revision,created,modified from winning_outcome_files where country='%s' \n"+
+		"and date(created) between '%s' and '%s' order by wo_file_id", country, fromDate, toDate)
+
+	raws, err := r.db.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	for raws.Next() {
+		var g woFiles.WoFiles
+		err := raws.Scan(&g.WoFileID, &g.WoFileName, &g.WoDir, &g.Country, &g.WoExtID, &g.ProjectID, &g.CompetitionID, &g.Status,
+			&g.Sha256, &g.FileSize, &g.Revision, &g.Created, &g.Modified)
+		if err != nil {
+			return nil, err
+		}
+		gc = append(gc, g)
+	}
+
+	if err = raws.Err(); err != nil {
+		return nil, err
+	}
+	raws.Close()
+
+	return gc, nil
+}
@@ -65,3 +65,249 @@ ALTER TABLE `match_requests` ADD `key_created` ENUM('pending','created','spoilt'
 
 /*** New ***/
 ALTER TABLE `winning_outcome_files` ADD `status` ENUM('pending','processed') NOT NULL AFTER `competition_id`, ADD INDEX (`status`);
+
+/*** New ***/
+CREATE TABLE `file_cursors` (
+  `file_cursor_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `cursor_name` varchar(100) NOT NULL,
+  `last_mod_time` bigint(20) NOT NULL DEFAULT '0',
+  `last_file_name` varchar(300) NOT NULL,
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`file_cursor_id`),
+  UNIQUE KEY `cursor_name` (`cursor_name`),
+  KEY `modified` (`modified`)
+);
+
+/*** New ***/
+ALTER TABLE `o_files` ADD `sha256` CHAR(64) NOT NULL DEFAULT '' AFTER `match_id`, ADD `file_size` BIGINT(20) NOT NULL DEFAULT '0' AFTER `sha256`, ADD `revision` INT(11) NOT NULL DEFAULT '1' AFTER `file_size`, ADD INDEX (`sha256`);
+ALTER TABLE `winning_outcome_files` ADD `sha256` CHAR(64) NOT NULL DEFAULT '' AFTER `status`, ADD `file_size` BIGINT(20) NOT NULL DEFAULT '0' AFTER `sha256`, ADD `revision` INT(11) NOT NULL DEFAULT '1' AFTER `file_size`, ADD INDEX (`sha256`);
+ALTER TABLE `live_scores_files` ADD `sha256` CHAR(64) NOT NULL DEFAULT '' AFTER `competition_id`, ADD `file_size` BIGINT(20) NOT NULL DEFAULT '0' AFTER `sha256`, ADD `revision` INT(11) NOT NULL DEFAULT '1' AFTER `file_size`, ADD INDEX (`sha256`);
+
+CREATE TABLE `file_revisions` (
+  `file_revision_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `file_type` enum('odds','wo','ls') NOT NULL,
+  `country` varchar(50) NOT NULL,
+  `ext_id` varchar(30) NOT NULL,
+  `revision` int(11) NOT NULL,
+  `sha256` char(64) NOT NULL,
+  `file_size` bigint(20) NOT NULL,
+  `status` enum('pending','processed') NOT NULL,
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`file_revision_id`),
+  UNIQUE KEY `file_type_2` (`file_type`,`country`,`ext_id`,`revision`),
+  KEY `status` (`status`),
+  KEY `created` (`created`)
+);
+
+/*** New ***/
+CREATE TABLE `inconsistent_matches` (
+  `inconsistent_match_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `parent_match_id` varchar(30) NOT NULL,
+  `country` varchar(10) NOT NULL,
+  `codes` varchar(300) NOT NULL,
+  `reasons` text NOT NULL,
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`inconsistent_match_id`),
+  UNIQUE KEY `parent_match_id` (`parent_match_id`,`country`),
+  KEY `codes` (`codes`),
+  KEY `modified` (`modified`)
+);
+
+/*** New ***/
+CREATE TABLE `o_files_archive` LIKE `o_files`;
+CREATE TABLE `winning_outcome_files_archive` LIKE `winning_outcome_files`;
+CREATE TABLE `live_scores_files_archive` LIKE `live_scores_files`;
+
+/*** New ***/
+CREATE TABLE `market_catalogues` (
+  `market_catalogue_id` int(11) NOT NULL AUTO_INCREMENT,
+  `code` varchar(20) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `outcome_order` varchar(500) NOT NULL DEFAULT '',
+  `priority` int(11) NOT NULL DEFAULT '100',
+  `status` enum('enabled','disabled') NOT NULL DEFAULT 'enabled',
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`market_catalogue_id`),
+  UNIQUE KEY `code` (`code`)
+);
+
+CREATE TABLE `market_catalogue_names` (
+  `market_catalogue_name_id` int(11) NOT NULL AUTO_INCREMENT,
+  `code` varchar(20) NOT NULL,
+  `locale` varchar(10) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`market_catalogue_name_id`),
+  UNIQUE KEY `code` (`code`,`locale`)
+);
+
+CREATE TABLE `market_catalogue_overrides` (
+  `market_catalogue_override_id` int(11) NOT NULL AUTO_INCREMENT,
+  `code` varchar(20) NOT NULL,
+  `competition_id` varchar(20) NOT NULL DEFAULT '',
+  `client_id` varchar(20) NOT NULL DEFAULT '',
+  `status` enum('enabled','disabled','inherit') NOT NULL DEFAULT 'inherit',
+  `name` varchar(100) NOT NULL DEFAULT '',
+  `priority` int(11) NOT NULL DEFAULT '0',
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`market_catalogue_override_id`),
+  UNIQUE KEY `code` (`code`,`competition_id`,`client_id`)
+);
+
+INSERT INTO `market_catalogues` (`code`, `name`, `outcome_order`, `priority`, `status`, `created`, `modified`) VALUES
+('CS','Correct Score (FT)','',10,'enabled',now(),now()),
+('HS','Half Time Score','',20,'enabled',now(),now()),
+('1X2','Match Result','',30,'enabled',now(),now()),
+('H1X2','Half Time Result','',40,'enabled',now(),now()),
+('DC','Double Chance','',50,'enabled',now(),now()),
+('DCH','Double Chance (HT)','',60,'enabled',now(),now()),
+('TG15','Over/Under 1.5','',70,'enabled',now(),now()),
+('TG25','Over/Under 2.5','',80,'enabled',now(),now()),
+('TG35','Over/Under 3.5','',90,'enabled',now(),now()),
+('HX1','Handicap -1','',100,'enabled',now(),now()),
+('HX2','Handicap -2','',110,'enabled',now(),now()),
+('DR','Half Time / Full Time','',120,'enabled',now(),now()),
+('TG','Total Goals','',130,'enabled',now(),now()),
+('GG','Goal:Goal FT','',140,'enabled',now(),now()),
+('HGG','Goal:Goal HT','',150,'enabled',now(),now()),
+('1X2OU15','1X2 and Over/Under 1.5','',160,'enabled',now(),now()),
+('1X2OU25','1X2 and Over/Under 2.5','',170,'enabled',now(),now()),
+('1X2OU35','1X2 and Over/Under 3.5','',180,'enabled',now(),now()),
+('1X2OU45','1X2 and Over/Under 4.5','',190,'enabled',now(),now()),
+('1X2OU55','1X2 and Over/Under 5.5','',200,'enabled',now(),now()),
+('1X2G','1X2 and Goal/No Goal','',210,'enabled',now(),now()),
+('T1OU15','Team 1 Over/Under 1.5','',220,'enabled',now(),now()),
+('T2OU15','Team 2 Over/Under 1.5','',230,'enabled',now(),now()),
+('T1G','Team 1 Goal/No Goal','',240,'enabled',now(),now()),
+('T2G','Team 2 Goal/No Goal','',250,'enabled',now(),now()),
+('TGOE','Total Goals Odd/Even','',260,'enabled',now(),now()),
+('TFG','Time of First Goal','',270,'enabled',now(),now()),
+('FTS','First Team to Score','',280,'enabled',now(),now()),
+('MG','Multi-Goals','',290,'enabled',now(),now());
+
+/*** New ***/
+
+-- Markets priced from the correct score matrix.
+INSERT INTO `market_catalogues` (`code`, `name`, `outcome_order`, `priority`, `status`, `created`, `modified`) VALUES
+('AHM15','Asian Handicap -1.5','',300,'enabled',now(),now()),
+('AHM05','Asian Handicap -0.5','',310,'enabled',now(),now()),
+('AHP05','Asian Handicap +0.5','',320,'enabled',now(),now()),
+('AHP15','Asian Handicap +1.5','',330,'enabled',now(),now()),
+('WM','Winning Margin','',340,'enabled',now(),now()),
+('EG','Exact Goals','',350,'enabled',now(),now()),
+('T1OU05','Team 1 Over/Under 0.5','',360,'enabled',now(),now()),
+('T1OU25','Team 1 Over/Under 2.5','',370,'enabled',now(),now()),
+('T2OU05','Team 2 Over/Under 0.5','',380,'enabled',now(),now()),
+('T2OU25','Team 2 Over/Under 2.5','',390,'enabled',now(),now()),
+('CSH','Home Clean Sheet','',400,'enabled',now(),now()),
+('CSA','Away Clean Sheet','',410,'enabled',now(),now()),
+('WTNH','Home Win to Nil','',420,'enabled',now(),now()),
+('WTNA','Away Win to Nil','',430,'enabled',now(),now()),
+('HSH','Highest Scoring Half','',440,'enabled',now(),now());
+
+/*** New ***/
+CREATE TABLE `odds_profiles` (
+  `odds_profile_id` int(11) NOT NULL AUTO_INCREMENT,
+  `client_id` varchar(20) NOT NULL,
+  `method` varchar(20) NOT NULL DEFAULT '',
+  `margin` decimal(6,4) NOT NULL DEFAULT '0.0000',
+  `min_odd` decimal(8,2) NOT NULL DEFAULT '1.01',
+  `max_odd` decimal(8,2) NOT NULL DEFAULT '0.00',
+  `ladder` varchar(500) NOT NULL DEFAULT '',
+  `markets` varchar(1000) NOT NULL DEFAULT '',
+  `status` enum('active','inactive') NOT NULL DEFAULT 'active',
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`odds_profile_id`),
+  UNIQUE KEY `client_id` (`client_id`)
+);
+
+/*** New ***/
+CREATE TABLE `seed_commitments` (
+  `seed_commitment_id` int(11) NOT NULL AUTO_INCREMENT,
+  `scope` varchar(30) NOT NULL,
+  `scope_id` varchar(50) NOT NULL,
+  `seed_hash` char(64) NOT NULL,
+  `server_seed` char(64) NOT NULL,
+  `pool_hash` char(64) NOT NULL DEFAULT '',
+  `pools` mediumtext NOT NULL,
+  `status` enum('open','closed') NOT NULL DEFAULT 'open',
+  `reveal_at` datetime DEFAULT NULL,
+  `created` datetime NOT NULL,
+  `modified` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`seed_commitment_id`),
+  UNIQUE KEY `scope` (`scope`,`scope_id`)
+);
+
+CREATE TABLE `seed_draws` (
+  `seed_draw_id` int(11) NOT NULL AUTO_INCREMENT,
+  `seed_commitment_id` int(11) NOT NULL,
+  `nonce` varchar(50) NOT NULL,
+  `draw_cursor` int(11) NOT NULL,
+  `bound` int(11) NOT NULL DEFAULT '0',
+  `value` double NOT NULL,
+  `result` int(11) NOT NULL DEFAULT '0',
+  `selected` varchar(100) NOT NULL DEFAULT '',
+  `candidates` mediumtext NOT NULL,
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`seed_draw_id`),
+  KEY `seed_commitment_id` (`seed_commitment_id`)
+);
+
+/*** New ***/
+CREATE TABLE `match_usages` (
+  `match_usage_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `consumer` varchar(20) NOT NULL,
+  `country` varchar(10) NOT NULL,
+  `match_id` varchar(100) NOT NULL,
+  `player_id` varchar(50) NOT NULL DEFAULT '',
+  `season_id` varchar(50) NOT NULL DEFAULT '',
+  `season_week_id` varchar(50) NOT NULL DEFAULT '',
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`match_usage_id`),
+  KEY `source_match` (`country`,`match_id`,`created`)
+);
+
+CREATE TABLE `reuse_policy_counters` (
+  `reuse_policy_counter_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `consumer` varchar(20) NOT NULL,
+  `policy` varchar(30) NOT NULL,
+  `counter_date` date NOT NULL,
+  `country` varchar(10) NOT NULL,
+  `match_id` varchar(100) NOT NULL,
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`reuse_policy_counter_id`),
+  UNIQUE KEY `counter` (`consumer`,`counter_date`,`country`,`match_id`),
+  KEY `counter_date` (`counter_date`)
+);
+
+/*** New ***/
+ALTER TABLE `goal_patterns`
+  ADD COLUMN `strategy` varchar(20) NOT NULL DEFAULT 'shuffle' AFTER `competition_id`,
+  ADD COLUMN `status` enum('active','retired') NOT NULL DEFAULT 'active' AFTER `strategy`,
+  ADD KEY `competition_status` (`competition_id`,`status`);
+
+/*** New ***/
+CREATE TABLE `week_results` (
+  `week_result_id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `season_week_id` varchar(50) NOT NULL,
+  `match_id` varchar(50) NOT NULL,
+  `home_score` tinyint(3) NOT NULL,
+  `away_score` tinyint(3) NOT NULL,
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`week_result_id`),
+  UNIQUE KEY `week_match` (`season_week_id`,`match_id`)
+);
+
+/*** New ***/
+ALTER TABLE `seed_commitments`
+  MODIFY COLUMN `status` enum('open','closed','revoked') NOT NULL DEFAULT 'open',
+  ADD COLUMN `current` tinyint(1) DEFAULT '1' AFTER `status`,
+  DROP KEY `scope`,
+  ADD UNIQUE KEY `scope` (`scope`,`scope_id`,`current`);
@@ -1,20 +1,38 @@
 package dataServerApi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/betBuilders"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/betBuilders/scoreMatrix"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories/categoryInventory"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalDrifts"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalDrifts/driftReport"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues/leaguesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs/mrsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFormats"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFormats/oddsConverter"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles/oddsProfilesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies/reusePoliciesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
 	seasonWeekMysql "github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks/seasonWeeksMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments/seedCommitmentsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults/weekResultsMysql"
 )
 
 // DataServerApiConfiguration is an alias for a function that will take in a pointer to an DataServerApiService and modify it
@@ -25,6 +43,12 @@ type DataServerApiService struct {
 	leaguesMysql    leagues.LeaguesRepository
 	seasonWeekMysql seasonWeeks.SeasonWeeksRepository
 	redisProdConn   processRedis.RunRedis
+	betBuilder      betBuilders.BetBuildersRepository
+	inventory       goalCategories.GoalCategoriesRepository
+	seedCommitments seedCommitments.SeedCommitmentsRepository
+	goalDrift       goalDrifts.GoalDriftsRepository
+	reusePolicy     reusePolicies.ReusePoliciesRepository
+	oddsProfiles    oddsProfiles.OddsProfilesRepository
 }
 
 // NewDataServerApiService : instantiate dataServerApi
@@ -77,12 +101,119 @@ func WithRedisProdRepository(redisServer string, dbNum int, maxIdle int, maxActi
 	}
 }
 
-// GetProdMatches : used to return matches
+// WithBetBuilder : prices and settles same match combinations. overround is the margin
+// of a combination, maxLegs and maxOdd are not enforced when 0
+func WithBetBuilder(overround float64, maxLegs int, maxOdd float64) DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		d, err := scoreMatrix.New(overround, maxLegs, maxOdd)
+		if err != nil {
+			return err
+		}
+		os.betBuilder = d
+		return nil
+	}
+}
+
+// WithCategoryInventory : counts the goal category sets in the production redis, must
+// come after WithRedisProdRepository
+func WithCategoryInventory() DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		d, err := categoryInventory.New(os.redisProdConn)
+		if err != nil {
+			return err
+		}
+		os.inventory = d
+		return nil
+	}
+}
+
+// WithMysqlSeedCommitmentsRepository : serves the seed commitments and draws of every scope
+// so that they can be verified
+func WithMysqlSeedCommitmentsRepository(connectionString string) DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		d, err := seedCommitmentsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seedCommitments = d
+		return nil
+	}
+}
+
+// WithGoalDrift : compares the saved results of published season weeks with the mrs
+// history, must come after WithMysqlSeasonWeeksRepository
+func WithGoalDrift(connectionString string, goalsPerMatch, rate, alpha float64, minMatches int) DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		b, err := goalDrifts.NewBounds(goalsPerMatch, rate, alpha, minMatches)
+		if err != nil {
+			return err
+		}
+
+		m, err := mrsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+
+		wr, err := weekResultsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+
+		d, err := driftReport.New(wr, m, os.seasonWeekMysql, *b)
+		if err != nil {
+			return err
+		}
+		os.goalDrift = d
+		return nil
+	}
+}
+
+// WithMysqlReusePoliciesRepository : serves the keys every reuse policy blocked
+func WithMysqlReusePoliciesRepository(connectionString string) DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		d, err := reusePoliciesMysql.New(connectionString, reusePolicies.Policy{})
+		if err != nil {
+			return err
+		}
+		os.reusePolicy = d
+		return nil
+	}
+}
+
+// WithMysqlOddsProfilesRepository : clients with an active odds profile are only served
+// the odds priced for them
+func WithMysqlOddsProfilesRepository(connectionString string) DataServerApiConfiguration {
+	return func(os *DataServerApiService) error {
+		d, err := oddsProfilesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.oddsProfiles = d
+		return nil
+	}
+}
+
+// GetProdMatches : used to return matches. Prices are shown in odds_format next to the
+// decimal odd_value, decimal when odds_format is not set. A client_id with an odds profile
+// gets the prices of its profile, it must be the client of the league. The client of the
+// league is used when client_id is not sent, its profile still applies.
 func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 	season_week_id := c.DefaultQuery("season_week_id", "0")
+	clientID := c.Query("client_id")
 
 	log.Println("season_week_id", season_week_id)
 
+	converter, err := oddsConverter.New(c.Query("odds_format"))
+	if err != nil {
+		log.Printf("Err : %v", err)
+
+		c.JSON(400, oddsFiles.MatchAPI{
+			StatusCode:        "400",
+			StatusDescription: err.Error(),
+		})
+		return
+	}
+
 	selSeasonWeekID := []string{}
 
 	if season_week_id == "0" || season_week_id == "" {
@@ -116,6 +247,18 @@ func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 		log.Printf("m.ClientID:%s, m.League:%s, m.LeagueAbbrv:%s, m.LeagueID:%s, m.MatchDate:%s, m.SeasonID:%s",
 			m.ClientID, m.League, m.LeagueAbbrv, m.LeagueID, m.MatchDate, m.SeasonID)
 
+		if clientID != "" && clientID != m.ClientID {
+			vl.StatusCode = "403"
+			vl.StatusDescription = "client_id is not the client of this league"
+			c.JSON(403, vl)
+			return
+		}
+
+		// A league priced by an odds profile is never served the published odds.
+		if clientID == "" {
+			clientID = m.ClientID
+		}
+
 		md := oddsFiles.MatchDetails{
 			MatchDate:   m.MatchDate,
 			League:      m.LeagueAbbrv,
@@ -133,10 +276,7 @@ func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 		for _, y := range ssn {
 			log.Printf("season week id %s y.SeasonID :%s, y.ApiDate :%s, y.LeagueID :%s", y.SeasonWeekID, y.SeasonID, y.ApiDate, y.LeagueID)
 
-			keyName := fmt.Sprintf("%s_%s_%s", "pr_odds", y.ApiDate, y.SeasonWeekID)
-			log.Printf(">>> match key saved >>>> %s", keyName)
-
-			matchDay, err := s.redisProdConn.Get(c, keyName)
+			matchDay, err := s.prodOdds(c, clientID, y.ApiDate, y.SeasonWeekID)
 			if err != nil {
 				log.Printf("Err : %v failed to get match day from redis", err)
 			}
@@ -150,6 +290,7 @@ func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 				log.Printf("Err unable to unmarshal match : %v", err)
 			} else {
 				// md.MatchDay = msg
+				convertOdds(msg.FinalMatches, converter)
 				dd = append(dd, msg)
 			}
 
@@ -158,6 +299,7 @@ func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 		md.MatchDay = dd
 
 		vl.MatchDetails = md
+		vl.OddsFormat = converter.Format()
 
 		// End of match day here
 
@@ -183,6 +325,94 @@ func (s *DataServerApiService) GetProdMatches(c *gin.Context) {
 
 }
 
+// prodOdds : odds of a season week priced for clientID, the published odds when the
+// client has no active odds profile. A client with one is never served the published odds.
+func (s *DataServerApiService) prodOdds(ctx context.Context, clientID, apiDate, seasonWeekID string) (string, error) {
+
+	if clientID != "" {
+
+		profiled, err := s.hasProfile(ctx, clientID)
+		if err != nil {
+			return "", err
+		}
+
+		if profiled {
+			keyName := oddsProfiles.OddsKey(apiDate, seasonWeekID, clientID)
+			log.Printf(">>> match key saved >>>> %s", keyName)
+
+			data, err := s.redisProdConn.Get(ctx, keyName)
+			if err != nil {
+				return "", fmt.Errorf("err : %v odds of client %s not saved", err, clientID)
+			}
+			return data, nil
+		}
+	}
+
+	keyName := fmt.Sprintf("%s_%s_%s", "pr_odds", apiDate, seasonWeekID)
+	log.Printf(">>> match key saved >>>> %s", keyName)
+
+	return s.redisProdConn.Get(ctx, keyName)
+}
+
+// hasProfile : whether clientID has an active odds profile.
+func (s *DataServerApiService) hasProfile(ctx context.Context, clientID string) (bool, error) {
+
+	if s.oddsProfiles == nil {
+		return false, nil
+	}
+
+	pp, err := s.oddsProfiles.GetProfileByClientID(ctx, clientID)
+	if err != nil {
+		return false, fmt.Errorf("err : %v failed to read the odds profile of client %s", err, clientID)
+	}
+
+	for _, p := range pp {
+		if p.Status == oddsProfiles.Active {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// seasonWeekClient : whether clientID is the client of the league of a season week, any
+// caller may ask for the published odds.
+func (s *DataServerApiService) seasonWeekClient(ctx context.Context, clientID, seasonWeekID string) (bool, error) {
+
+	if clientID == "" {
+		return true, nil
+	}
+
+	ll, err := s.leaguesMysql.GetLeagueBySeasonWeekID(ctx, seasonWeekID)
+	if err != nil {
+		return false, fmt.Errorf("err : %v failed to read the league of season week %s", err, seasonWeekID)
+	}
+
+	for _, l := range ll {
+		if l.ClientID == clientID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// convertOdds : sets the display price of every outcome, decimal prices are left as they are.
+func convertOdds(mm []oddsFiles.FinalMatches, converter oddsFormats.OddsFormatsRepository) {
+
+	if converter.Format() == oddsFormats.Decimal {
+		return
+	}
+
+	for _, m := range mm {
+		for _, mkt := range m.FinalMarkets {
+			for n, o := range mkt.FinalOutcomes {
+				mkt.FinalOutcomes[n].DisplayOdd = converter.Convert(o.OddValue)
+			}
+		}
+	}
+}
+
 // GetProdWinningOutcomes : used to return matches
 func (s *DataServerApiService) GetProdWinningOutcomes(c *gin.Context) {
 	seasonWeekID := c.DefaultQuery("season_week_id", "0")
@@ -340,3 +570,402 @@ func (s *DataServerApiService) GetProdLiveScores(c *gin.Context) {
 	return
 
 }
+
+// PriceBetBuilder : prices a combination of outcomes of one match.
+func (s *DataServerApiService) PriceBetBuilder(c *gin.Context) {
+
+	var vl betBuilders.BetBuilderAPI
+
+	r, ok := s.betBuilderRequest(c, &vl)
+	if !ok {
+		return
+	}
+
+	apiDate, err := s.upcomingWeekDate(c, r.SeasonWeekID)
+	if err != nil {
+		log.Printf("Err : %v", err)
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Season week not found"
+		c.JSON(404, vl)
+		return
+	}
+
+	owned, err := s.seasonWeekClient(c, r.ClientID, r.SeasonWeekID)
+	if err != nil {
+		log.Printf("Err : %v", err)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to read the league of the season week"
+		c.JSON(500, vl)
+		return
+	}
+
+	if !owned {
+		vl.StatusCode = "403"
+		vl.StatusDescription = "client_id is not the client of this league"
+		c.JSON(403, vl)
+		return
+	}
+
+	matchDay, err := s.prodOdds(c, r.ClientID, apiDate, r.SeasonWeekID)
+	if err != nil {
+		log.Printf("Err : %v failed to get match day from redis", err)
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Odds not found"
+		c.JSON(404, vl)
+		return
+	}
+
+	var msg oddsFiles.FinalSeasonWeek
+	err = json.Unmarshal([]byte(matchDay), &msg)
+	if err != nil {
+		log.Printf("Err unable to unmarshal match : %v", err)
+	}
+
+	for _, m := range msg.FinalMatches {
+
+		if m.MatchID != r.MatchID {
+			continue
+		}
+
+		q, err := s.betBuilder.Price(c, m.MatchID, m.FinalMarkets, r.Selections)
+		if err != nil {
+			vl.StatusCode = "400"
+			vl.StatusDescription = err.Error()
+			c.JSON(400, vl)
+			return
+		}
+
+		vl.StatusCode = "200"
+		vl.StatusDescription = "success"
+		vl.Quote = &q
+		c.JSON(200, vl)
+		return
+	}
+
+	vl.StatusCode = "404"
+	vl.StatusDescription = "Match not found"
+	c.JSON(404, vl)
+}
+
+// SettleBetBuilder : settles a combination of outcomes of one match from its results.
+func (s *DataServerApiService) SettleBetBuilder(c *gin.Context) {
+
+	var vl betBuilders.BetBuilderAPI
+
+	r, ok := s.betBuilderRequest(c, &vl)
+	if !ok {
+		return
+	}
+
+	apiDate, err := s.seasonWeekDate(c, r.SeasonWeekID)
+	if err != nil {
+		log.Printf("Err : %v", err)
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Season week not found"
+		c.JSON(404, vl)
+		return
+	}
+
+	keyNameWO := fmt.Sprintf("%s_%s_%s", "pr_wo", apiDate, r.SeasonWeekID)
+	matchDayWo, err := s.redisProdConn.Get(c, keyNameWO)
+	if err != nil {
+		log.Printf("Err : %v failed to get match day winning outcome from redis", err)
+	}
+
+	var wo oddsFiles.FinalSeasonWeekWO
+	err = json.Unmarshal([]byte(matchDayWo), &wo)
+	if err != nil {
+		log.Printf("Err unable to unmarshal winning outcome : %v", err)
+	}
+
+	keyNameLS := fmt.Sprintf("%s_%s_%s", "pr_ls", apiDate, r.SeasonWeekID)
+	matchDayLs, err := s.redisProdConn.Get(c, keyNameLS)
+	if err != nil {
+		log.Printf("Err : %v failed to get match day live scores from redis", err)
+	}
+
+	var ls oddsFiles.FinalSeasonWeekLS
+	err = json.Unmarshal([]byte(matchDayLs), &ls)
+	if err != nil {
+		log.Printf("Err unable to unmarshal live score : %v", err)
+	}
+
+	lsc := []oddsFiles.FinalLiveScores{}
+	for _, m := range ls.FinalMatchesLS {
+		if m.MatchID == r.MatchID {
+			lsc = m.FinalLiveScores
+		}
+	}
+
+	for _, m := range wo.FinalMatchesWO {
+
+		if m.MatchID != r.MatchID {
+			continue
+		}
+
+		st, err := s.betBuilder.Settle(c, m.MatchID, r.Selections, m.FinalScore, lsc)
+		if err != nil {
+			vl.StatusCode = "400"
+			vl.StatusDescription = err.Error()
+			c.JSON(400, vl)
+			return
+		}
+
+		vl.StatusCode = "200"
+		vl.StatusDescription = "success"
+		vl.Settlement = &st
+		c.JSON(200, vl)
+		return
+	}
+
+	vl.StatusCode = "404"
+	vl.StatusDescription = "Result not found"
+	c.JSON(404, vl)
+}
+
+// betBuilderRequest : reads the combination sent, the response is written when it can not be read.
+func (s *DataServerApiService) betBuilderRequest(c *gin.Context, vl *betBuilders.BetBuilderAPI) (betBuilders.Request, bool) {
+
+	var r betBuilders.Request
+
+	if s.betBuilder == nil {
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Bet builder not enabled"
+		c.JSON(404, vl)
+		return r, false
+	}
+
+	err := c.ShouldBindJSON(&r)
+	if err != nil || r.SeasonWeekID == "" || r.MatchID == "" {
+		vl.StatusCode = "400"
+		vl.StatusDescription = "season_week_id, match_id and selections are required"
+		c.JSON(400, vl)
+		return r, false
+	}
+
+	return r, true
+}
+
+// upcomingWeekDate : date the keys of a season week are saved under, read from the season
+// weeks GetProdMatches serves so that every week shown can be priced.
+func (s *DataServerApiService) upcomingWeekDate(ctx context.Context, seasonWeekID string) (string, error) {
+
+	ww, err := s.seasonWeekMysql.GetSeasonWeekByID(ctx, seasonWeekID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, w := range ww {
+
+		ssn, err := s.seasonWeekMysql.ApiSsnWeeksNew(ctx, w.SeasonID)
+		if err != nil {
+			return "", err
+		}
+
+		for _, y := range ssn {
+			if y.SeasonWeekID == seasonWeekID {
+				return y.ApiDate, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("season week %s not upcoming", seasonWeekID)
+}
+
+// seasonWeekDate : date the keys of a season week that has started are saved under.
+func (s *DataServerApiService) seasonWeekDate(ctx context.Context, seasonWeekID string) (string, error) {
+
+	data, err := s.leaguesMysql.GetProductionWinningOutcomesNew(ctx, seasonWeekID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range data {
+		sTime, err := time.Parse("2006-01-02 15:04:05", m.StartTime)
+		if err != nil {
+			return "", fmt.Errorf("err : %v failed to convert string to time", err)
+		}
+		return sTime.Format("2006-01-02"), nil
+	}
+
+	return "", fmt.Errorf("season week %s not found", seasonWeekID)
+}
+
+// CategoryInventory : live count of every goal category set for every consumer. set is the
+// sanitized set, SANITIZED_ODDS when not sent. The CL_ sets of the players listed in
+// player_id are counted too.
+func (s *DataServerApiService) CategoryInventory(c *gin.Context) {
+
+	var vl goalCategories.InventoryAPI
+
+	if s.inventory == nil {
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Category inventory not enabled"
+		c.JSON(404, vl)
+		return
+	}
+
+	set := c.DefaultQuery("set", goalCategories.DefaultSet)
+
+	playerIDs := []string{}
+	for _, p := range strings.Split(c.Query("player_id"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			playerIDs = append(playerIDs, p)
+		}
+	}
+
+	ii, err := s.inventory.Inventory(c, goalCategories.Consumers(set, playerIDs...))
+	if err != nil {
+		log.Printf("Err : %v failed to read category inventory", err)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to read category inventory"
+		c.JSON(500, vl)
+		return
+	}
+
+	vl.StatusCode = "200"
+	vl.StatusDescription = "success"
+	vl.Inventory = ii
+	c.JSON(200, vl)
+}
+
+// VerifyDraws : seed commitment of a scope and its draws, each worked out again. The draws
+// are only served once the server seed is revealed, they would give away the rounds still to
+// be played. scope is season_week when not sent, scope_id is required.
+func (s *DataServerApiService) VerifyDraws(c *gin.Context) {
+
+	var vl seedCommitments.VerificationAPI
+
+	if s.seedCommitments == nil {
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Seed commitments not enabled"
+		c.JSON(404, vl)
+		return
+	}
+
+	scope := c.DefaultQuery("scope", seedCommitments.SeasonWeek)
+	scopeID := c.Query("scope_id")
+
+	if scopeID == "" {
+		vl.StatusCode = "400"
+		vl.StatusDescription = "scope_id not set"
+		c.JSON(400, vl)
+		return
+	}
+
+	cc, err := s.seedCommitments.GetCommitment(c, scope, scopeID)
+	if err != nil {
+		log.Printf("Err : %v failed to read seed commitment of %s %s", err, scope, scopeID)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to read seed commitment"
+		c.JSON(500, vl)
+		return
+	}
+
+	if len(cc) == 0 {
+		vl.StatusCode = "404"
+		vl.StatusDescription = fmt.Sprintf("No seed committed for %s %s", scope, scopeID)
+		c.JSON(404, vl)
+		return
+	}
+
+	dd, err := s.seedCommitments.GetDraws(c, scope, scopeID)
+	if err != nil {
+		log.Printf("Err : %v failed to read draws of %s %s", err, scope, scopeID)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to read draws"
+		c.JSON(500, vl)
+		return
+	}
+
+	vl.StatusCode = "200"
+	vl.StatusDescription = "success"
+	vl.Verification = seedCommitments.Verify(cc[0], dd)
+	c.JSON(200, vl)
+}
+
+// GoalDrift : published season weeks of competition_id between from and to (yyyy-mm-dd,
+// to excluded) against the historical results of the competition. The last 30 days are
+// reported when from and to are not sent.
+func (s *DataServerApiService) GoalDrift(c *gin.Context) {
+
+	var vl goalDrifts.DriftAPI
+
+	if s.goalDrift == nil {
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Goal drift report not enabled"
+		c.JSON(404, vl)
+		return
+	}
+
+	competitionID := c.Query("competition_id")
+	if competitionID == "" {
+		vl.StatusCode = "400"
+		vl.StatusDescription = "competition_id not set"
+		c.JSON(400, vl)
+		return
+	}
+
+	from, to, err := goalDrifts.Window(c.Query("from"), c.Query("to"))
+	if err != nil {
+		vl.StatusCode = "400"
+		vl.StatusDescription = err.Error()
+		c.JSON(400, vl)
+		return
+	}
+
+	rp, err := s.goalDrift.Report(c, competitionID, from, to)
+	if err != nil {
+		log.Printf("Err : %v failed to report goal drift of competition %s", err, competitionID)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to report goal drift"
+		c.JSON(500, vl)
+		return
+	}
+
+	vl.StatusCode = "200"
+	vl.StatusDescription = "success"
+	vl.Report = rp
+	c.JSON(200, vl)
+}
+
+// ReuseCounters : keys every reuse policy blocked per consumer, once a day each, between
+// from and to (yyyy-mm-dd, to excluded), today when they are not sent.
+func (s *DataServerApiService) ReuseCounters(c *gin.Context) {
+
+	var vl reusePolicies.CountersAPI
+
+	if s.reusePolicy == nil {
+		vl.StatusCode = "404"
+		vl.StatusDescription = "Reuse policy not enabled"
+		c.JSON(404, vl)
+		return
+	}
+
+	now := time.Now()
+	vl.From = c.DefaultQuery("from", now.Format("2006-01-02"))
+	vl.To = c.DefaultQuery("to", now.AddDate(0, 0, 1).Format("2006-01-02"))
+
+	for _, d := range []string{vl.From, vl.To} {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			vl.StatusCode = "400"
+			vl.StatusDescription = fmt.Sprintf("%q is not a yyyy-mm-dd date", d)
+			c.JSON(400, vl)
+			return
+		}
+	}
+
+	cc, err := s.reusePolicy.Counters(c, vl.From, vl.To)
+	if err != nil {
+		log.Printf("Err : %v failed to read reuse policy counters", err)
+		vl.StatusCode = "500"
+		vl.StatusDescription = "Unable to read reuse policy counters"
+		c.JSON(500, vl)
+		return
+	}
+
+	vl.StatusCode = "200"
+	vl.StatusDescription = "success"
+	vl.Counters = cc
+	c.JSON(200, vl)
+}
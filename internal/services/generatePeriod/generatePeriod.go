@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"strings"
 	"time"
 
@@ -14,6 +13,8 @@ import (
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/scheduledTimes"
 	scheduledTimeMysql "github.com/lukemakhanu/magic_carpet/internal/domains/scheduledTimes/scheduledTimesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments/seedCommitmentsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/snwkpts"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/snwkpts/snwkptsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/ssns"
@@ -29,6 +30,7 @@ type GeneratePeriodService struct {
 	redisConn          processRedis.RunRedis
 	goalPatternsMysql  goalPatterns.GoalPatternsRepository
 	snwkptsMysql       snwkpts.SnWkPtsRepository
+	seedCommitments    seedCommitments.SeedCommitmentsRepository
 }
 
 func NewGeneratePeriodService(cfgs ...GeneratePeriodConfiguration) (*GeneratePeriodService, error) {
@@ -97,6 +99,19 @@ func WithMysqlSnWkPtsRepository(connectionString string) GeneratePeriodConfigura
 	}
 }
 
+// WithMysqlSeedCommitmentsRepository : publishes the seed hash of every season before its
+// goal patterns are drawn and records the draws once it is created
+func WithMysqlSeedCommitmentsRepository(connectionString string) GeneratePeriodConfiguration {
+	return func(os *GeneratePeriodService) error {
+		d, err := seedCommitmentsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seedCommitments = d
+		return nil
+	}
+}
+
 // CreateScheduledTime : used to create scheduled start time for each date
 func (s *GeneratePeriodService) CreateScheduledTime(ctx context.Context, locale *time.Location, competitionID, status string, addTime int64) error {
 
@@ -188,14 +203,22 @@ func (s *GeneratePeriodService) PrepareGames(ctx context.Context, locale *time.L
 
 			if ssnID > 0 {
 
+				// Goal patterns are drawn from a seed committed to before the season starts.
+				fs, err := s.commitSeed(ctx, seedCommitments.Season, fmt.Sprintf("%d", ssnID))
+				if err != nil {
+					return fmt.Errorf("err : %v failed to commit the seed of season %d", err, ssnID)
+				}
+
+				lastEndTime := scheduledTime
+
 				for i := 1; i <= 19; i++ {
 
 					log.Printf("Proceed to saving the rest of the data..")
 
-					// For each season create 38 season weeks.
+					// For each season create a season week per round of its pattern.
 
 					// At this point select the patterns to use for this games
-					// We get 38 patterns, put them in a map and use them below
+					// We get a pattern's rounds, put them in a map and use them below
 					// to decide how goals will be destributed.
 
 					goalDistribution, err := s.goalPatternsMysql.GoalDistributions(ctx, competitionID)
@@ -208,15 +231,18 @@ func (s *GeneratePeriodService) PrepareGames(ctx context.Context, locale *time.L
 					}
 
 					availableList := len(goalDistribution)
-					selCategory := rand.IntN(availableList)
+
+					r := fs.Rand(fmt.Sprintf("%d-%d", ssnID, i))
+					selCategory := r.IntN(availableList)
 
 					selectedBatch := goalDistribution[selCategory]
+					r.Pick(selectedBatch.RoundNumberID)
 
 					log.Println("selCategory", selCategory, "selectedBatch >>>> ", selectedBatch)
 
 					parentIDs := strings.Split(selectedBatch.RoundNumberID, ",")
 
-					if len(parentIDs) != 38 {
+					if len(parentIDs) == 0 || selectedBatch.RoundNumberID == "" {
 						return fmt.Errorf("err : %v there round number ids returned arent enough %d", err, len(parentIDs))
 					}
 
@@ -227,7 +253,7 @@ func (s *GeneratePeriodService) PrepareGames(ctx context.Context, locale *time.L
 						kk++
 					}
 
-					for h := 1; h <= 38; h++ {
+					for h := 1; h <= len(parentIDs); h++ {
 
 						if x < 715 {
 
@@ -242,6 +268,7 @@ func (s *GeneratePeriodService) PrepareGames(ctx context.Context, locale *time.L
 
 							matchDuration := 35
 							var endTime = enTime.Add(time.Second * time.Duration(matchDuration)).Format("2006-01-02 15:04:05")
+							lastEndTime = endTime
 
 							seasonID := fmt.Sprintf("%d", ssnID)
 							weekNumber := fmt.Sprintf("%d", h)
@@ -312,6 +339,8 @@ func (s *GeneratePeriodService) PrepareGames(ctx context.Context, locale *time.L
 
 				}
 
+				s.revealSeed(ctx, fs, lastEndTime)
+
 			} else {
 				log.Printf("ssnID return 0 --> %d", ssnID)
 			}
@@ -482,3 +511,43 @@ func (s *GeneratePeriodService) CheckTime(ctx context.Context, competitionID str
 	return false, selTime, fmt.Errorf("Err : %v time not due.. ", err)
 
 }
+
+// commitSeed : session the draws of a scope are made from, its seed hash is published when
+// the seed commitments repository is set. A scope retried after a failure draws from the
+// seed committed the first time, a published hash never changes.
+func (s *GeneratePeriodService) commitSeed(ctx context.Context, scope, scopeID string) (*seedCommitments.Session, error) {
+
+	fs, err := seedCommitments.NewSession(scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.seedCommitments == nil {
+		return fs, nil
+	}
+
+	_, err = s.seedCommitments.Save(ctx, fs.Commitment)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.seedCommitments.Committed(ctx, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return seedCommitments.ResumeSession(c)
+}
+
+// revealSeed : records the draws of the session, its seed is revealed from revealAt.
+func (s *GeneratePeriodService) revealSeed(ctx context.Context, fs *seedCommitments.Session, revealAt string) {
+
+	if s.seedCommitments == nil {
+		return
+	}
+
+	err := s.seedCommitments.Close(ctx, fs.Commitment.Scope, fs.Commitment.ScopeID, revealAt, fs.Draws())
+	if err != nil {
+		log.Printf("Err : %v failed to record the draws of %s %s", err, fs.Commitment.Scope, fs.Commitment.ScopeID)
+	}
+}
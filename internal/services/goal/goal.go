@@ -5,20 +5,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches/checkMatchesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goals"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goals/goalsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/inconsistentMatches"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/inconsistentMatches/inconsistentMatchesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks/crossCheck"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis/rExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels"
 )
 
 type Job struct {
@@ -38,12 +44,20 @@ type GoalService struct {
 	redisConn         processRedis.RunRedis
 	slowRedisConn     slowRedis.SlowRedis
 	goalMysql         goals.GoalsRepository
+	inconsistentMysql inconsistentMatches.InconsistentMatchesRepository
+	levels            []stockLevels.Level
+	rng               rngs.RngsRepository
 }
 
 // NewGoalService : instantiate every connection we need to run current game service
 func NewGoalService(cfgs ...GoalConfiguration) (*GoalService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &GoalService{}
+	os := &GoalService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -55,6 +69,17 @@ func NewGoalService(cfgs ...GoalConfiguration) (*GoalService, error) {
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) GoalConfiguration {
+	return func(os *GoalService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 // WithMysqlCheckMatchesRepository : instantiates mysql to connect to matches interface
 func WithMysqlCheckMatchesRepository(connectionString string) GoalConfiguration {
 	return func(os *GoalService) error {
@@ -103,6 +128,31 @@ func WithMysqlGoalsRepository(connectionString string) GoalConfiguration {
 	}
 }
 
+// WithMysqlInconsistentMatchesRepository : instantiates mysql to connect to inconsistent matches interface
+func WithMysqlInconsistentMatchesRepository(connectionString string) GoalConfiguration {
+	return func(os *GoalService) error {
+		d, err := inconsistentMatchesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.inconsistentMysql = d
+		return nil
+	}
+}
+
+// WithStockLevels : levels the sanitized category sets are filled up to, the default
+// levels when none is set
+func WithStockLevels(ll []stockLevels.Level) GoalConfiguration {
+	return func(os *GoalService) error {
+		d, err := stockLevels.Levels(ll)
+		if err != nil {
+			return err
+		}
+		os.levels = d
+		return nil
+	}
+}
+
 // RandomIndexes : generate random numbers
 func (s *GoalService) RandomIndexes(ctx context.Context) map[int]int {
 	min := 1
@@ -110,94 +160,125 @@ func (s *GoalService) RandomIndexes(ctx context.Context) map[int]int {
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
 	return m
 }
 
-// SelectKeys :
-func (s *GoalService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKeysSet string, matches []string, projectID string) error {
-
-	tgOver25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGO25")
-	tgUnder25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGU25")
-	tgUnder15 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGU15")
-
-	tg0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-	tg1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-
-	tg2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-	// tg2gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "gg")
-	// tg2ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "ng")
-
-	tg3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-	// tg3gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "gg")
-	// tg3ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "ng")
-
-	tg4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-	// tg4gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "gg")
-	// tg4ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "ng")
-
-	tg5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-	// tg5gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "gg")
-	// tg5ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "ng")
+// ConsistentMatch : cross checks the odds, winning outcome and live score saved for a match.
+// Inconsistent matches are recorded with the reasons and dropped from the odds set so that
+// they never reach the sanitized sets used by the key builders. A match whose live score is
+// not saved yet is left in the odds set and checked again on the next pass.
+func (s *GoalService) ConsistentMatch(ctx context.Context, oddsSortedSet, matchID, country, parentMatchID, woPayload string) bool {
 
-	tg6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
-	/* 	tg6gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "gg")
-	   	tg6ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "ng") */
-
-	sanitizedSetLen, err := s.redisConn.SortedSetLen(ctx, sanitizedKeysSet)
+	oddsPayload, err := s.redisConn.Get(ctx, matchID)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, sanitizedKeysSet)
+		log.Printf("Err : %v unable to get odds %s from redis ", err, matchID)
+		return false
 	}
-	log.Printf("sanitizedSetLen :: %d", sanitizedSetLen)
 
-	tg0Len, err := s.redisConn.SortedSetLen(ctx, tg0)
+	lsKey := fmt.Sprintf("%s%s%s", country, "Ls:", parentMatchID)
+	lsPayload, err := s.redisConn.Get(ctx, lsKey)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg0)
+		// Live scores are saved by their own jobs and may not be in yet, goalless
+		// matches have none.
+		if scored(woPayload) {
+			log.Printf("Err : %v live score %s not saved yet, %s checked later ", err, lsKey, matchID)
+			return false
+		}
+		lsPayload = ""
 	}
 
-	tg1Len, err := s.redisConn.SortedSetLen(ctx, tg1)
+	cc, err := crossCheck.New(parentMatchID, oddsPayload, woPayload, lsPayload)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg1)
+		log.Printf("Err : %v unable to cross check %s ", err, matchID)
+		return false
 	}
 
-	tg2Len, err := s.redisConn.SortedSetLen(ctx, tg2)
+	issues, err := cc.Check(ctx)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg2)
+		log.Printf("Err : %v unable to cross check %s ", err, matchID)
+		return false
 	}
 
-	tg3Len, err := s.redisConn.SortedSetLen(ctx, tg3)
-	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg3)
+	if len(issues) == 0 {
+		return true
 	}
 
-	tg4Len, err := s.redisConn.SortedSetLen(ctx, tg4)
-	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg4)
+	for _, x := range issues {
+		log.Printf("Inconsistent match %s | %s : %s", matchID, x.Code, x.Detail)
+	}
+
+	if s.inconsistentMysql != nil {
+		im, err := inconsistentMatches.NewInconsistentMatch(parentMatchID, country, issues)
+		if err != nil {
+			log.Printf("Err : %v unable to instantiate inconsistent match %s ", err, matchID)
+		} else {
+			_, err = s.inconsistentMysql.Save(ctx, *im)
+			if err != nil {
+				log.Printf("Err : %v unable to save inconsistent match %s ", err, matchID)
+			}
+		}
 	}
 
-	tg5Len, err := s.redisConn.SortedSetLen(ctx, tg5)
+	_, err = s.redisConn.ZRem(ctx, oddsSortedSet, matchID)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg5)
+		log.Printf("Err : %v unable to remove %s from %s set ", err, matchID, oddsSortedSet)
+	}
+
+	return false
+}
+
+// scored : whether the winning outcome has a final score with goals, a payload that can not
+// be read is left to the cross check.
+func scored(woPayload string) bool {
+	var wo oddsFiles.RawWinningOutcomes
+	if err := json.Unmarshal([]byte(woPayload), &wo); err != nil {
+		return false
 	}
 
-	tg6Len, err := s.redisConn.SortedSetLen(ctx, tg6)
+	h, errH := strconv.Atoi(wo.HomeScore)
+	a, errA := strconv.Atoi(wo.AwayScore)
+	return errH == nil && errA == nil && h+a > 0
+}
+
+// SelectKeys :
+func (s *GoalService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKeysSet string, matches []string, projectID string) error {
+
+	sanitizedSetLen, err := s.redisConn.SortedSetLen(ctx, sanitizedKeysSet)
 	if err != nil {
-		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, tg6)
+		return fmt.Errorf("err : %v failed to get zcard for key %s ", err, sanitizedKeysSet)
+	}
+	log.Printf("sanitizedSetLen :: %d", sanitizedSetLen)
+
+	// Stop once every category is stocked up to its target.
+	levels := s.levels
+	if len(levels) == 0 {
+		levels = stockLevels.DefaultLevels()
+	}
+
+	counts := make(map[string]int)
+	stocked := []string{}
+	for _, l := range levels {
+
+		if l.Consumer != goalCategories.Sanitized || l.Target == 0 {
+			continue
+		}
+
+		key := goalCategories.Key(sanitizedKeysSet, l.Category)
+		count, err := s.redisConn.SortedSetLen(ctx, key)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to get zcard for key %s ", err, key)
+		}
+
+		counts[l.Category] = count
+		stocked = append(stocked, fmt.Sprintf("%s [%d/%d]", key, count, l.Target))
 	}
 
-	if tg0Len > 100000 &&
-		tg1Len > 100000 &&
-		tg2Len > 100000 &&
-		tg3Len > 50000 &&
-		tg4Len > 30000 &&
-		tg5Len > 20000 &&
-		tg6Len > 20000 {
-		return fmt.Errorf("there are enough games sanitized tg0Len [%d] | tg1Len [%d] |tg2Len [%d] |tg3Len [%d] |tg4Len [%d] |tg5Len [%d] |tg6Len [%d] | ",
-			tg0Len, tg1Len, tg2Len, tg3Len, tg4Len, tg5Len, tg6Len)
+	if stockLevels.Filled(levels, goalCategories.Sanitized, counts) {
+		return fmt.Errorf("there are enough games sanitized %s", strings.Join(stocked, " | "))
 	}
 
 	log.Printf("oddsSortedSet len is %d", len(matches))
@@ -253,7 +334,7 @@ func (s *GoalService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKe
 						selWo = append(selWo, i.Result)
 					}
 
-					if len(selWo) > 25 {
+					if len(selWo) > 25 && s.ConsistentMatch(ctx, oddsSortedSet, matchID, parentID[0], parentID[1], selectedWo) {
 
 						selCountry := parentID[0]
 						//selMatchID := parentID[1]
@@ -274,415 +355,20 @@ func (s *GoalService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKe
 									log.Printf("Err : %v unable to add %s into %s set ", err, matchID, oddsSortedSet)
 								}
 
-								totalGoals := hScore + aScore
-
-								log.Printf("Total goals %d", totalGoals)
-
-								// To control over under 2.5 market
-								if totalGoals > 2 {
-
-									err := s.redisConn.ZAdd(ctx, tgOver25, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgOver25)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, tgOver25)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-								} else {
-
-									err := s.redisConn.ZAdd(ctx, tgUnder25, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder25)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, tgUnder25)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-								}
-
-								// For future iteration
-
-								if totalGoals == 0 {
-									// Save total 0
-
-									// Can only be ng under (goal goal market)
-
-									s0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-									err := s.redisConn.ZAdd(ctx, s0, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s0)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s0)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									err = s.redisConn.ZAdd(ctx, tgUnder15, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder15)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, tgUnder15)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-								} else if totalGoals == 1 {
-
-									s1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-									err := s.redisConn.ZAdd(ctx, s1, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s1)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									err = s.redisConn.ZAdd(ctx, tgUnder15, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder15)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, tgUnder15)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									// Categorise gg and ng (goal goal // no goal)
-									// total goal 1 cant be categoried, can only be ng
-
-									if hScore == 1 {
-
-										s1h := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "1", "h")
-										err := s.redisConn.ZAdd(ctx, s1h, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1h)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s1h)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
+								// File the match under every category of its score.
+								for _, category := range goalCategories.Categories(hScore, aScore) {
 
-									} else {
-
-										s1a := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "1", "a")
-										err := s.redisConn.ZAdd(ctx, s1a, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1a)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s1a)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-									}
-
-								} else if totalGoals == 2 {
-
-									s2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-									err := s.redisConn.ZAdd(ctx, s2, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s2)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									// Categorise goal goal // no goal market
-
-									if hScore > 0 && aScore > 0 {
-
-										s2gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "gg")
-										err := s.redisConn.ZAdd(ctx, s2gg, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2gg)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s2gg)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s2gg, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2gg)
-										}
-
-									} else {
-
-										s2ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "ng")
-										err := s.redisConn.ZAdd(ctx, s2ng, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2ng)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s2ng)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s2ng, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2ng)
-										}
-
-									}
-
-								} else if totalGoals == 3 {
-
-									s3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-									err := s.redisConn.ZAdd(ctx, s3, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s3)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									// Categorise goal goal // no goal market
-
-									if hScore > 0 && aScore > 0 {
-
-										s3gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "gg")
-										err := s.redisConn.ZAdd(ctx, s3gg, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3gg)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s3gg)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s3gg, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3gg)
-										}
-
-									} else {
-
-										s3ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "ng")
-										err := s.redisConn.ZAdd(ctx, s3ng, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3ng)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s3ng)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s3ng, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3ng)
-										}
-
-									}
-
-								} else if totalGoals == 4 {
-
-									s4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-									err := s.redisConn.ZAdd(ctx, s4, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s4)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									// Categorise goal goal // no goal market
-
-									if hScore > 0 && aScore > 0 {
-
-										s4gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "gg")
-										err := s.redisConn.ZAdd(ctx, s4gg, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4gg)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s4gg)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s4gg, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4gg)
-										}
-
-									} else {
-
-										s4ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "ng")
-										err := s.redisConn.ZAdd(ctx, s4ng, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4ng)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s4ng)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s4ng, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4ng)
-										}
-
-									}
-
-								} else if totalGoals == 5 {
-
-									s5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-									err := s.redisConn.ZAdd(ctx, s5, priority, matchID)
+									key := goalCategories.Key(sanitizedKeysSet, category)
+									err := s.redisConn.ZAdd(ctx, key, priority, matchID)
 									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5)
+										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, key)
 									}
 
 									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s5)
+									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, key)
 									if err != nil {
 										log.Printf("err : %v ", err)
 									}
-
-									// Categorise goal goal // no goal market
-
-									if hScore > 0 && aScore > 0 {
-
-										s5gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "gg")
-										err := s.redisConn.ZAdd(ctx, s5gg, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5gg)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s5gg)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s5gg, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5gg)
-										}
-
-									} else {
-
-										s5ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "ng")
-										err := s.redisConn.ZAdd(ctx, s5ng, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5ng)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s5ng)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s5ng, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5ng)
-										}
-
-									}
-
-								} else if totalGoals == 6 {
-
-									s6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
-									err := s.redisConn.ZAdd(ctx, s6, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6)
-									}
-
-									// save Goal category
-									err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s6)
-									if err != nil {
-										log.Printf("err : %v ", err)
-									}
-
-									// Categorise goal goal // no goal market
-
-									if hScore > 0 && aScore > 0 {
-
-										s6gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "gg")
-										err := s.redisConn.ZAdd(ctx, s6gg, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6gg)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s6gg)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s6gg, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6gg)
-										}
-
-									} else {
-
-										s6ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "ng")
-										err := s.redisConn.ZAdd(ctx, s6ng, priority, matchID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6ng)
-										}
-
-										// save Goal category
-										err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, s6ng)
-										if err != nil {
-											log.Printf("err : %v ", err)
-										}
-
-										err = s.Save1X2(ctx, s6ng, priority, matchID, hScore, aScore, selCountry, projectID)
-										if err != nil {
-											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6ng)
-										}
-
-									}
-
-								} else {
-
-									s7 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "7")
-									err := s.redisConn.ZAdd(ctx, s7, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s7)
-									}
-
 								}
 
 								// Save record
@@ -730,66 +416,13 @@ func (s *GoalService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKe
 	return nil
 }
 
-// Save1X2 :
-func (s *GoalService) Save1X2(ctx context.Context, baseKey, priority, matchID string, homeScore, awayScore int, selCountry, projectID string) error {
-
-	if homeScore > awayScore {
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "h")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-		// save Goal category
-		err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, hm)
-		if err != nil {
-			log.Printf("err : %v ", err)
-		}
-
-	} else if awayScore > homeScore {
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "a")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-		// save Goal category
-		err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, hm)
-		if err != nil {
-			log.Printf("err : %v ", err)
-		}
-
-	} else {
-
-		// Draw
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "d")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-		// save Goal category
-		err = s.SaveGoalCategory(ctx, matchID, selCountry, projectID, hm)
-		if err != nil {
-			log.Printf("err : %v ", err)
-		}
-
-	}
-
-	return nil
-}
-
 // NewRandomIndexes : used to create new randomization.
 func (s *GoalService) NewRandomIndexes(ctx context.Context, max int) map[int]int {
 	min := 1
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
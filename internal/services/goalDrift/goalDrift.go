@@ -0,0 +1,98 @@
+package goalDrift
+
+import (
+	"context"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalDrifts"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalDrifts/driftReport"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs/mrsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
+	seasonWeekMysql "github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks/seasonWeeksMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults/weekResultsMysql"
+)
+
+// GoalDriftConfiguration is an alias for a function that will take in a pointer to an GoalDriftService and modify it
+type GoalDriftConfiguration func(os *GoalDriftService) error
+
+// GoalDriftService is a implementation of the GoalDriftService
+type GoalDriftService struct {
+	seasonWeekMysql seasonWeeks.SeasonWeeksRepository
+	goalDrift       goalDrifts.GoalDriftsRepository
+}
+
+// NewGoalDriftService : instantiate every connection we need to report goal drift
+func NewGoalDriftService(cfgs ...GoalDriftConfiguration) (*GoalDriftService, error) {
+	os := &GoalDriftService{}
+	for _, cfg := range cfgs {
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithMysqlSeasonWeeksRepository : season weeks published for every competition
+func WithMysqlSeasonWeeksRepository(connectionString string) GoalDriftConfiguration {
+	return func(os *GoalDriftService) error {
+		d, err := seasonWeekMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seasonWeekMysql = d
+		return nil
+	}
+}
+
+// WithGoalDrift : compares the saved results of published season weeks with the mrs
+// history, must come after WithMysqlSeasonWeeksRepository
+func WithGoalDrift(connectionString string, goalsPerMatch, rate, alpha float64, minMatches int) GoalDriftConfiguration {
+	return func(os *GoalDriftService) error {
+		b, err := goalDrifts.NewBounds(goalsPerMatch, rate, alpha, minMatches)
+		if err != nil {
+			return err
+		}
+
+		m, err := mrsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+
+		wr, err := weekResultsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+
+		d, err := driftReport.New(wr, m, os.seasonWeekMysql, *b)
+		if err != nil {
+			return err
+		}
+		os.goalDrift = d
+		return nil
+	}
+}
+
+// Report : published season weeks of every competition between from and to against its
+// historical results.
+func (s *GoalDriftService) Report(ctx context.Context, competitionIDs []string, from, to string) ([]goalDrifts.Report, error) {
+
+	from, to, err := goalDrifts.Window(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := []goalDrifts.Report{}
+	for _, competitionID := range competitionIDs {
+
+		rp, err := s.goalDrift.Report(ctx, competitionID, from, to)
+		if err != nil {
+			return rr, fmt.Errorf("err : %v failed to report goal drift of competition %s", err, competitionID)
+		}
+
+		rr = append(rr, rp)
+	}
+
+	return rr, nil
+}
@@ -4,15 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns/goalPatternsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs/mrsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis/rExec"
 )
@@ -34,12 +36,19 @@ type GoalPatternService struct {
 	slowRedisConn     slowRedis.SlowRedis
 	mrsMysql          mrs.MrsRepository
 	goalPatternsMysql goalPatterns.GoalPatternsRepository
+	competitions      []goalPatterns.Settings
+	rng               rngs.RngsRepository
 }
 
 // NewGoalPatternService : instantiate every connection we need to run current game service
 func NewGoalPatternService(cfgs ...GoalPatternConfiguration) (*GoalPatternService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &GoalPatternService{}
+	os := &GoalPatternService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -99,69 +108,166 @@ func WithMysqGoalPatternsRepository(connectionString string) GoalPatternConfigur
 	}
 }
 
-// ProcessGoalPattern : used to select keys to be used later.
-func (s *GoalPatternService) ProcessGoalPattern(ctx context.Context) error {
+// WithCompetitions : season length and strategy of every competition patterns are built for.
+func WithCompetitions(ss []goalPatterns.Settings) GoalPatternConfiguration {
+	return func(os *GoalPatternService) error {
+		for _, x := range ss {
+			st, err := goalPatterns.NewSettings(x.CompetitionID, x.SeasonLength, x.Strategy, x.RetireDays)
+			if err != nil {
+				return err
+			}
+			os.competitions = append(os.competitions, *st)
+		}
+		return nil
+	}
+}
+
+// WithRng : source of randomness rounds are shuffled with.
+func WithRng(r rngs.RngsRepository) GoalPatternConfiguration {
+	return func(os *GoalPatternService) error {
+		os.rng = r
+		return nil
+	}
+}
+
+// Settings : how the patterns of competitionID are built, the defaults when it is not configured.
+func (s *GoalPatternService) Settings(competitionID string) (goalPatterns.Settings, error) {
+	for _, x := range s.Competitions() {
+		if x.CompetitionID == competitionID {
+			return x, nil
+		}
+	}
+	return goalPatterns.Settings{}, fmt.Errorf("competition %s not configured", competitionID)
+}
 
-	ss := []string{"1", "2", "3", "4"}
+// Competitions : configured competitions, competitions 1 to 4 when none is set.
+func (s *GoalPatternService) Competitions() []goalPatterns.Settings {
+	if len(s.competitions) == 0 {
+		return goalPatterns.DefaultSettings()
+	}
+	return s.competitions
+}
 
-	for _, c := range ss {
+// Preview : builds the seasons of a competition without saving them. Rounds already in an
+// active pattern are left out.
+func (s *GoalPatternService) Preview(ctx context.Context, st goalPatterns.Settings) (goalPatterns.Build, error) {
 
-		m := make(map[int]int)
+	data, err := s.mrsMysql.RawScores(ctx, st.CompetitionID)
+	if err != nil {
+		return goalPatterns.Build{}, fmt.Errorf("err : %v failed to return rounds of competition %s", err, st.CompetitionID)
+	}
 
-		data, err := s.mrsMysql.GoalPatterns(ctx, c)
+	rounds := []goalPatterns.Round{}
+	for _, x := range data {
+		ss, err := goalCategories.ParseRawScores(x.RawScores)
 		if err != nil {
-			return fmt.Errorf("err : %v failed to return goal patterns from db ", err)
+			return goalPatterns.Build{}, fmt.Errorf("err : %v failed to parse scores of round %d", err, x.RoundNumberID)
 		}
 
-		for _, x := range data {
-			m[x.RoundNumberID] = x.RoundNumberID
+		goals := 0
+		for _, sc := range ss {
+			goals += sc.Home + sc.Away
 		}
 
-		// push this ids into list
+		rounds = append(rounds, goalPatterns.Round{
+			RoundNumberID: x.RoundNumberID,
+			StartTime:     x.StartTime,
+			Goals:         goals,
+		})
+	}
+
+	active, err := s.goalPatternsMysql.GoalDistributions(ctx, st.CompetitionID)
+	if err != nil {
+		return goalPatterns.Build{}, fmt.Errorf("err : %v failed to return active patterns of competition %s", err, st.CompetitionID)
+	}
+
+	used, err := goalPatterns.InUse(active)
+	if err != nil {
+		return goalPatterns.Build{}, err
+	}
+
+	lastSeasonID, err := s.goalPatternsMysql.LastSeasonID(ctx, st.CompetitionID)
+	if err != nil {
+		return goalPatterns.Build{}, err
+	}
+
+	return goalPatterns.BuildSeasons(rounds, used, st, lastSeasonID+1, s.rng)
+}
 
-		comp := []int{}
-		for _, v := range m {
-			comp = append(comp, v)
+// Save : saves the seasons of a build as active patterns. A build whose rounds or seasons
+// were saved since it was previewed, by the daemon or another preview, is not saved.
+func (s *GoalPatternService) Save(ctx context.Context, b goalPatterns.Build) error {
+
+	if len(b.Seasons) == 0 {
+		return nil
+	}
+
+	gg := []goalPatterns.GoalPatterns{}
+	for _, x := range b.Seasons {
+
+		dd, err := goalPatterns.NewGoalPatterns(x.SeasonID, x.RoundNumberIDs(), b.Settings.CompetitionID, b.Settings.Strategy)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to initialize goal pattern ", err)
 		}
+		gg = append(gg, *dd)
+	}
 
-		dd := s.GoalChunks(comp, 38)
+	err := s.goalPatternsMysql.SaveSeasons(ctx, b.Settings.CompetitionID, gg)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to save goal patterns ", err)
+	}
 
-		for a, x := range dd {
-			log.Println("competition ", c, " id ", a, " >> xx selected ", x)
+	for _, dd := range gg {
+		log.Printf("competitionID %s | season %s | strategy %s | games %s",
+			dd.CompetitionID, dd.SeasonID, dd.Strategy, dd.RoundNumberID)
+	}
 
-			if len(x) == 38 {
+	return nil
+}
 
-				ff := []string{}
-				for _, r := range x {
-					gg := fmt.Sprintf("%d", r)
-					ff = append(ff, gg)
-				}
+// Retire : retires the patterns of a competition saved more than st.RetireDays ago so that
+// their rounds are built into new seasons, nothing is retired when st.RetireDays is 0.
+func (s *GoalPatternService) Retire(ctx context.Context, st goalPatterns.Settings) error {
 
-				log.Printf("proceed as we have the correct number of games")
+	if st.RetireDays == 0 {
+		return nil
+	}
 
-				roundNumberIDs := strings.Join(ff, ",")
-				log.Printf("competitionID %s | number %d | games %s", c, a, roundNumberIDs)
+	retired, err := s.goalPatternsMysql.Retire(ctx, st.CompetitionID, st.RetireDays)
+	if err != nil {
+		return err
+	}
 
-				// // Save into database
-				seasonID := fmt.Sprintf("%d", a)
-				dd, err := goalPatterns.NewGoalPatterns(seasonID, roundNumberIDs, c)
-				if err != nil {
-					return fmt.Errorf("err : %v failed to initialize goal pattern ", err)
-				}
+	if retired > 0 {
+		log.Printf("competitionID %s | retired %d patterns older than %d days", st.CompetitionID, retired, st.RetireDays)
+	}
 
-				lastID, err := s.goalPatternsMysql.Save(ctx, *dd)
-				if err != nil {
-					return fmt.Errorf("err : %v failed to save a goal pattern ", err)
-				}
+	return nil
+}
 
-				log.Printf("lastID : %d", lastID)
+// ProcessGoalPattern : retires the patterns of every competition due, and puts the rounds
+// not in an active pattern into new ones.
+func (s *GoalPatternService) ProcessGoalPattern(ctx context.Context) error {
 
-			} else {
-				log.Printf("Skip, we have less games...")
-			}
+	for _, st := range s.Competitions() {
+
+		err := s.Retire(ctx, st)
+		if err != nil {
+			return err
+		}
 
+		b, err := s.Preview(ctx, st)
+		if err != nil {
+			return err
 		}
 
+		log.Printf("competitionID %s | seasons %d | leftover %d | in use %d",
+			st.CompetitionID, len(b.Seasons), len(b.Leftover), b.InUse)
+
+		err = s.Save(ctx, b)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
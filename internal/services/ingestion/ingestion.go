@@ -0,0 +1,371 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobS3"
+	"github.com/lukemakhanu/magic_carpet/internal/services/saveFile"
+)
+
+// CountryFeed : directories delivered by the provider for one country. A file type
+// without a directory is not ingested (e.g the World Cup has no live scores).
+type CountryFeed struct {
+	Country       string `mapstructure:"country" json:"country"`
+	OddsDirectory string `mapstructure:"odds_directory" json:"odds_directory"`
+	WoDirectory   string `mapstructure:"wo_directory" json:"wo_directory"`
+	LsDirectory   string `mapstructure:"ls_directory" json:"ls_directory"`
+	Combination   string `mapstructure:"combination" json:"combination"`
+	Disabled      bool   `mapstructure:"disabled" json:"disabled"`
+}
+
+// WorkerCounters : state of one country / file type worker.
+type WorkerCounters struct {
+	Country   string `json:"country"`
+	FileType  string `json:"file_type"`
+	Directory string `json:"directory"`
+	Running   bool   `json:"running"`
+	Restarts  int    `json:"restarts"`
+	LastError string `json:"last_error"`
+	saveFile.CountersSnapshot
+}
+
+// worker : saves one directory of one country.
+type worker struct {
+	country  string
+	fileType string
+	dir      string
+	sf       *saveFile.SaveFileService
+	counters *saveFile.Counters
+
+	mu        sync.Mutex
+	running   bool
+	restarts  int
+	lastError string
+}
+
+// IngestionConfiguration is an alias for a function that will take in a pointer to an IngestionService and modify it
+type IngestionConfiguration func(os *IngestionService) error
+
+// IngestionService is a implementation of the IngestionService
+type IngestionService struct {
+	connectionString    string
+	quarantineDirectory string
+	projectIDs          string
+	competitionIDs      string
+	combination         string
+	mode                string
+	pollInterval        time.Duration
+	maxBackoff          time.Duration
+
+	countries []CountryFeed
+	workers   []*worker
+
+	// blobs is shared by every worker, the local disk when not set.
+	blobs blobs.BlobsRepository
+}
+
+// NewIngestionService : instantiate a worker per country and file type
+func NewIngestionService(cfgs ...IngestionConfiguration) (*IngestionService, error) {
+	// Create the IngestionService
+	os := &IngestionService{
+		mode:         "watch",
+		pollInterval: 2 * time.Second,
+		maxBackoff:   time.Minute,
+	}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := os.buildWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	return os, nil
+}
+
+// WithMysqlRepository : mysql connection used by every worker
+func WithMysqlRepository(connectionString string) IngestionConfiguration {
+	return func(os *IngestionService) error {
+		if connectionString == "" {
+			return fmt.Errorf("connectionString not set")
+		}
+		os.connectionString = connectionString
+		return nil
+	}
+}
+
+// WithFileNameValidation : quarantine directory and the allowed ids, combination is the default
+// filename combination for countries that do not set their own.
+func WithFileNameValidation(combination, quarantineDirectory, projectIDs, competitionIDs string) IngestionConfiguration {
+	return func(os *IngestionService) error {
+		os.combination = combination
+		os.quarantineDirectory = quarantineDirectory
+		os.projectIDs = projectIDs
+		os.competitionIDs = competitionIDs
+		return nil
+	}
+}
+
+// WithMode : watch (fsnotify) or poll, poll lists the directories every pollInterval.
+// maxBackoff caps the wait before a crashed worker is restarted.
+func WithMode(mode string, pollInterval, maxBackoff time.Duration) IngestionConfiguration {
+	return func(os *IngestionService) error {
+		if mode != "watch" && mode != "poll" {
+			return fmt.Errorf("mode %q not supported", mode)
+		}
+		os.mode = mode
+		if pollInterval > 0 {
+			os.pollInterval = pollInterval
+		}
+		if maxBackoff > 0 {
+			os.maxBackoff = maxBackoff
+		}
+		return nil
+	}
+}
+
+// WithCountries : the countries to ingest
+func WithCountries(countries []CountryFeed) IngestionConfiguration {
+	return func(os *IngestionService) error {
+		seen := make(map[string]bool)
+		for _, c := range countries {
+			if c.Country == "" {
+				return fmt.Errorf("country not set")
+			}
+			if seen[c.Country] {
+				return fmt.Errorf("country %s set twice", c.Country)
+			}
+			seen[c.Country] = true
+		}
+		os.countries = countries
+		return nil
+	}
+}
+
+// WithS3BlobRepository : feed files are dropped by the provider into an S3 compatible bucket,
+// the country directories are used as key prefixes. Watch mode lists the bucket every few seconds.
+func WithS3BlobRepository(endpoint, region, bucket, accessKey, secretKey string, useSSL bool) IngestionConfiguration {
+	return func(os *IngestionService) error {
+		d, err := blobS3.New(endpoint, region, bucket, accessKey, secretKey, useSSL)
+		if err != nil {
+			return err
+		}
+		os.blobs = d
+		return nil
+	}
+}
+
+// buildWorkers : one save file service per country and file type.
+func (s *IngestionService) buildWorkers() error {
+
+	for _, c := range s.countries {
+
+		if c.Disabled {
+			log.Printf("Country %s disabled", c.Country)
+			continue
+		}
+
+		combination := c.Combination
+		if combination == "" {
+			combination = s.combination
+		}
+
+		for _, ft := range []struct{ fileType, dir string }{
+			{"ls", c.LsDirectory},
+			{"wo", c.WoDirectory},
+			{"odds", c.OddsDirectory},
+		} {
+
+			if ft.dir == "" {
+				continue
+			}
+
+			counters := &saveFile.Counters{}
+
+			cfgs := []saveFile.SaveFileConfiguration{}
+			if s.blobs != nil {
+				cfgs = append(cfgs, saveFile.WithBlobRepository(s.blobs))
+			}
+
+			cfgs = append(cfgs,
+				saveFile.WithValidatedDirectoryRepository(ft.dir, combination, c.Country, s.quarantineDirectory,
+					s.projectIDs, s.competitionIDs),
+				saveFile.WithMysqlFileCursorsRepository(s.connectionString),
+				saveFile.WithMysqlFileRevisionsRepository(s.connectionString),
+				saveFile.WithCounters(counters),
+			)
+
+			switch ft.fileType {
+			case "ls":
+				cfgs = append(cfgs, saveFile.WithMysqlLiveScoreFilesRepository(s.connectionString))
+			case "wo":
+				cfgs = append(cfgs, saveFile.WithMysqlWoFilesRepository(s.connectionString))
+			default:
+				cfgs = append(cfgs, saveFile.WithMysqlOddsFilesRepository(s.connectionString))
+			}
+
+			sf, err := saveFile.NewSaveFileService(cfgs...)
+			if err != nil {
+				return fmt.Errorf("err : %v unable to start %s %s worker", err, c.Country, ft.fileType)
+			}
+
+			s.workers = append(s.workers, &worker{
+				country:  c.Country,
+				fileType: ft.fileType,
+				dir:      ft.dir,
+				sf:       sf,
+				counters: counters,
+			})
+		}
+	}
+
+	if len(s.workers) == 0 {
+		return fmt.Errorf("no country to ingest")
+	}
+
+	return nil
+}
+
+// Start : runs every worker under supervision until ctx is done.
+func (s *IngestionService) Start(ctx context.Context) {
+	for _, w := range s.workers {
+		log.Printf("Starting %s %s worker on %s [%s]", w.country, w.fileType, w.dir, s.mode)
+		go s.supervise(ctx, w)
+	}
+}
+
+// supervise : restarts a worker that returned an error or panicked, waiting a little
+// longer after every consecutive crash.
+func (s *IngestionService) supervise(ctx context.Context, w *worker) {
+
+	backoff := time.Second
+
+	for {
+
+		w.setRunning(true)
+		err := s.run(ctx, w)
+		w.setRunning(false)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := s.pollInterval
+		if err != nil {
+			w.crashed(err)
+			log.Printf("Err : %v %s %s worker stopped, restarting in %s", err, w.country, w.fileType, backoff)
+
+			wait = backoff
+			backoff *= 2
+			if backoff > s.maxBackoff {
+				backoff = s.maxBackoff
+			}
+		} else {
+			backoff = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// run : one pass of the worker, a panic is returned as an error.
+func (s *IngestionService) run(ctx context.Context, w *worker) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic : %v", r)
+		}
+	}()
+
+	if s.mode == "watch" {
+		return w.sf.WatchFiles(ctx, w.fileType, w.dir, w.country)
+	}
+
+	switch w.fileType {
+	case "ls":
+		return w.sf.SaveLiveScoreFiles(ctx, w.dir, w.country)
+	case "wo":
+		return w.sf.SaveWinningOutcomesFiles(ctx, w.dir, w.country)
+	default:
+		return w.sf.SaveFiles(ctx, w.dir, w.country)
+	}
+}
+
+// Counters : counters of every worker, ordered by country and file type.
+func (s *IngestionService) Counters() []WorkerCounters {
+
+	counters := []WorkerCounters{}
+	for _, w := range s.workers {
+
+		w.mu.Lock()
+		c := WorkerCounters{
+			Country:          w.country,
+			FileType:         w.fileType,
+			Directory:        w.dir,
+			Running:          w.running,
+			Restarts:         w.restarts,
+			LastError:        w.lastError,
+			CountersSnapshot: w.counters.Snapshot(),
+		}
+		w.mu.Unlock()
+
+		counters = append(counters, c)
+	}
+
+	sort.SliceStable(counters, func(i, j int) bool {
+		if counters[i].Country != counters[j].Country {
+			return counters[i].Country < counters[j].Country
+		}
+		return counters[i].FileType < counters[j].FileType
+	})
+
+	return counters
+}
+
+// LogCounters : writes the counters of every worker to the log every interval.
+func (s *IngestionService) LogCounters(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, c := range s.Counters() {
+				log.Printf("Ingestion %s %-4s | running %t | saved %d | duplicates %d | revisions %d | failed %d | restarts %d | last %s %s",
+					c.Country, c.FileType, c.Running, c.Saved, c.Duplicates, c.Revisions, c.Failed, c.Restarts, c.LastFile, c.LastSaved)
+			}
+		}
+	}
+}
+
+func (w *worker) setRunning(running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running = running
+}
+
+func (w *worker) crashed(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.restarts++
+	w.lastError = err.Error()
+}
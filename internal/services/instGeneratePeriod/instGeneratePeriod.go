@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"strings"
 	"time"
 
@@ -12,6 +11,8 @@ import (
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns/goalPatternsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/scheduledTimes"
 	scheduledTimeMysql "github.com/lukemakhanu/magic_carpet/internal/domains/scheduledTimes/scheduledTimesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/snwkpts"
@@ -29,10 +30,16 @@ type InstGeneratePeriodService struct {
 	redisConn          processRedis.RunRedis
 	goalPatternsMysql  goalPatterns.GoalPatternsRepository
 	snwkptsMysql       snwkpts.SnWkPtsRepository
+	rng                rngs.RngsRepository
 }
 
 func NewInstGeneratePeriodService(cfgs ...InstGeneratePeriodConfiguration) (*InstGeneratePeriodService, error) {
-	os := &InstGeneratePeriodService{}
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
+	os := &InstGeneratePeriodService{rng: rng}
 	for _, cfg := range cfgs {
 		err := cfg(os)
 		if err != nil {
@@ -42,6 +49,17 @@ func NewInstGeneratePeriodService(cfgs ...InstGeneratePeriodConfiguration) (*Ins
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) InstGeneratePeriodConfiguration {
+	return func(os *InstGeneratePeriodService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 func WithMysqlSsnsRepository(connectionString string) InstGeneratePeriodConfiguration {
 	return func(os *InstGeneratePeriodService) error {
 		d, err := ssnsMysql.New(connectionString)
@@ -192,10 +210,10 @@ func (s *InstGeneratePeriodService) PrepareGames(ctx context.Context, locale *ti
 
 					log.Printf("Proceed to saving the rest of the data..")
 
-					// For each season create 38 season weeks.
+					// For each season create a season week per round of its pattern.
 
 					// At this point select the patterns to use for this games
-					// We get 38 patterns, put them in a map and use them below
+					// We get a pattern's rounds, put them in a map and use them below
 					// to decide how goals will be destributed.
 
 					goalDistribution, err := s.goalPatternsMysql.GoalDistributions(ctx, competitionID)
@@ -208,7 +226,7 @@ func (s *InstGeneratePeriodService) PrepareGames(ctx context.Context, locale *ti
 					}
 
 					availableList := len(goalDistribution)
-					selCategory := rand.IntN(availableList)
+					selCategory := s.rng.IntN(availableList)
 
 					selectedBatch := goalDistribution[selCategory]
 
@@ -216,7 +234,7 @@ func (s *InstGeneratePeriodService) PrepareGames(ctx context.Context, locale *ti
 
 					parentIDs := strings.Split(selectedBatch.RoundNumberID, ",")
 
-					if len(parentIDs) != 38 {
+					if len(parentIDs) == 0 || selectedBatch.RoundNumberID == "" {
 						return fmt.Errorf("err : %v there round number ids returned arent enough %d", err, len(parentIDs))
 					}
 
@@ -227,7 +245,7 @@ func (s *InstGeneratePeriodService) PrepareGames(ctx context.Context, locale *ti
 						kk++
 					}
 
-					for h := 1; h <= 38; h++ {
+					for h := 1; h <= len(parentIDs); h++ {
 
 						if x < 715 {
 
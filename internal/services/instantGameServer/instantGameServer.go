@@ -4,16 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goalPatterns/goalPatternsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goals"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matchRequests"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matchRequests/matchRequestsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs"
@@ -27,6 +27,9 @@ import (
 	"github.com/lukemakhanu/magic_carpet/internal/domains/players/playersMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies/reusePoliciesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/selectedMatches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/selectedMatches/selectedMatchesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/httpClients/sharedHttp"
@@ -56,6 +59,8 @@ type InstantGameServerService struct {
 	periodMysql             periods.PeriodsRepository
 	mrsMysql                mrs.MrsRepository
 	playersUsedMatchesMysql playerUsedMatches.PlayerUsedMatchesRepository
+	reusePolicy             reusePolicies.ReusePoliciesRepository
+	maxBlocked              int
 }
 
 func NewInstantGameServerService(cfgs ...InstantGameServerConfiguration) (*InstantGameServerService, error) {
@@ -183,6 +188,29 @@ func WithMysqlPlayerUsedMatchesRepository(connectionString string) InstantGameSe
 	}
 }
 
+// WithMysqlReusePoliciesRepository : keeps claimed matches the reuse policy blocks from the
+// player, they are put back once maxBlocked of them were drawn in a row
+func WithMysqlReusePoliciesRepository(connectionString string, globalDays, playerDays int, season, seasonWeek bool, maxBlocked int) InstantGameServerConfiguration {
+	return func(os *InstantGameServerService) error {
+		p, err := reusePolicies.NewPolicy(globalDays, playerDays, season, seasonWeek)
+		if err != nil {
+			return err
+		}
+
+		if maxBlocked < 1 {
+			return fmt.Errorf("maxBlocked must be at least 1")
+		}
+
+		d, err := reusePoliciesMysql.New(connectionString, *p)
+		if err != nil {
+			return err
+		}
+		os.reusePolicy = d
+		os.maxBlocked = maxBlocked
+		return nil
+	}
+}
+
 // GetCORS : return cors
 func (s *InstantGameServerService) GetCORS() gin.HandlerFunc {
 	return s.httpConf.CORSMiddleware()
@@ -359,7 +387,11 @@ func (s *InstantGameServerService) FetchInstantGames(c *gin.Context) {
 	return
 }*/
 
-func (s *InstantGameServerService) FetchRoundMatches(ctx context.Context, playerID, oddsSortedSet string, distr []mrs.Mrs) ([]string, error) {
+// FetchRoundMatches : claims a match of the player of sc for every score of the goal
+// distribution, each drawn from r, the round of the match request the player committed to.
+// The claims are held until the round is made up and committed together, they are all
+// released when one score can not be drawn.
+func (s *InstantGameServerService) FetchRoundMatches(ctx context.Context, sc reusePolicies.Scope, oddsSortedSet string, distr []mrs.Mrs, r *seedCommitments.Rand) ([]string, error) {
 
 	list := []string{}
 
@@ -368,72 +400,115 @@ func (s *InstantGameServerService) FetchRoundMatches(ctx context.Context, player
 		return list, fmt.Errorf("err : %v failed to return GetGoalPattern", err)
 	}
 
-	for _, dd := range m {
+	leases := []processRedis.Lease{}
+	details := [][]goals.Goals{}
+
+	release := func() {
+		for _, l := range leases {
+			s.ReleaseKey(ctx, l.Set, l.Member)
+		}
+	}
+
+	// Scores are drawn for in order so that the draws can be worked out again.
+	for k := 1; k <= len(m); k++ {
 
-		sortedSetName := fmt.Sprintf("%s_%s_%s", "CL", playerID, dd.Category)
+		dd := m[k]
+		sortedSetName := goalCategories.PlayerClaimKey(sc.PlayerID, dd.Category)
 
-		data, err := s.redisConn.GetZRangeWithLimit(ctx, sortedSetName, 15)
+		// Claimed in one step so that no other request is handed the same match.
+		selectedMatchID, err := s.claimAllowed(ctx, sortedSetName, sc, r)
 		if err != nil {
-			return list, fmt.Errorf("err : %v failed to read from %s z range", err, sortedSetName)
+			release()
+			return []string{}, err
 		}
 
-		categoryCount := len(data)
+		if selectedMatchID == "" {
 
-		if categoryCount > 0 {
+			log.Printf("Key ::::: %s does not have enough data ", sortedSetName)
 
-			log.Println("*** sel category *** ", sortedSetName, " data returned ", data)
-			if len(data) > 0 {
+			release()
+			return []string{}, fmt.Errorf("%s does not have enough data", sortedSetName)
+		}
 
-				// shuffle data
+		log.Printf("*** sel category *** %s claimed %s", sortedSetName, selectedMatchID)
 
-				categoryIndex := rand.IntN(categoryCount)
-				selectedMatchID := data[categoryIndex]
+		// Query this record from db so we know which record save as used
 
-				// Query this record from db so we know which record save as used
+		ss, err := s.playersUsedMatchesMysql.GetMatchDetails(ctx, dd.Category, selectedMatchID)
+		if err != nil {
+			s.ReleaseKey(ctx, sortedSetName, selectedMatchID)
+			release()
+			return []string{}, fmt.Errorf("err : %v failed to return match details of key %s category %s",
+				err, selectedMatchID, dd.Category)
+		}
 
-				ss, err := s.playersUsedMatchesMysql.GetMatchDetails(ctx, dd.Category, selectedMatchID)
-				if err != nil {
-					return list, fmt.Errorf("err : %v failed to return match details of key %s category %s",
-						err, selectedMatchID, dd.Category)
-				}
+		if len(ss) == 0 {
+			log.Printf("No details of key %s category %s, left in %s", selectedMatchID, dd.Category, sortedSetName)
+			s.ReleaseKey(ctx, sortedSetName, selectedMatchID)
+			continue
+		}
 
-				for _, r := range ss {
+		leases = append(leases, processRedis.Lease{Set: sortedSetName, Member: selectedMatchID})
+		details = append(details, ss)
+	}
 
-					log.Printf("category : %s | GoalID %s | MatchID %s", r.Category, r.GoalID, r.MatchID)
+	keys := []string{}
+	for _, l := range leases {
+		keys = append(keys, l.Member)
+	}
 
-					// Add value into used table
+	err = s.useKeys(ctx, sc, keys)
+	if err != nil {
+		release()
+		return []string{}, err
+	}
 
-					aa, err := playerUsedMatches.NewPlayerUsedMatches(playerID, r.Country, r.ProjectID, r.MatchID, r.Category)
-					if err != nil {
-						return list, fmt.Errorf("err : %v failed to instantiate UsedMatches", err)
-					}
+	err = s.redisConn.ZCommitAll(ctx, leases)
+	if err != nil {
+		s.releaseUsages(ctx, sc, keys)
+		release()
+		return []string{}, err
+	}
 
-					inserted, err := s.playersUsedMatchesMysql.Save(ctx, *aa)
-					if err != nil {
-						return list, fmt.Errorf("err : %v failed to save playerUsedMatch", err)
-					} else {
-						log.Printf("Last inserted playeUsedMatchID %d", inserted)
+	for n, l := range leases {
 
-						list = append(list, selectedMatchID)
-						s.RemoveUsedKeys(ctx, sortedSetName, selectedMatchID)
-					}
+		for _, r := range details[n] {
 
-				}
+			log.Printf("category : %s | GoalID %s | MatchID %s", r.Category, r.GoalID, r.MatchID)
 
+			// Add value into used table
+
+			aa, err := playerUsedMatches.NewPlayerUsedMatches(sc.PlayerID, r.Country, r.ProjectID, r.MatchID, r.Category)
+			if err != nil {
+				return list, fmt.Errorf("err : %v failed to instantiate UsedMatches", err)
 			}
-		} else {
 
-			log.Printf("Key ::::: %s does not have enough data ", sortedSetName)
+			inserted, err := s.playersUsedMatchesMysql.Save(ctx, *aa)
+			if err != nil {
+				return list, fmt.Errorf("err : %v failed to save playerUsedMatch", err)
+			}
 
-			return list, fmt.Errorf("err : %v does not have enough data %s", err, sortedSetName)
+			log.Printf("Last inserted playeUsedMatchID %d", inserted)
 		}
-
+		list = append(list, l.Member)
 	}
 
 	return list, nil
 
 }
 
+// ReapLeases : puts the keys whose lease ran out back in their sets, so that keys held by
+// a request that never finished are not kept out until the next claim of their set.
+func (s *InstantGameServerService) ReapLeases(ctx context.Context) {
+	n, err := s.redisConn.ZReap(ctx)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	} else if n > 0 {
+		log.Printf("###### %d keys with a lease that ran out put back ######", n)
+	}
+}
+
+// GetGoalPattern : category every score of the goal distribution is drawn from.
 func (s *InstantGameServerService) GetGoalPattern(oddsSortedSet string, distr []mrs.Mrs) (map[int]MatchDetails, error) {
 
 	m := make(map[int]MatchDetails)
@@ -443,141 +518,20 @@ func (s *InstantGameServerService) GetGoalPattern(oddsSortedSet string, distr []
 
 		log.Printf(">>>>> Raw string >>>>> %s", d.RawScores)
 
-		scores := strings.Split(d.RawScores, "**")
-		// raw_scores: 52824375#1#2**52824376#1#1**52824377#2#1**52824378#2#2**52824379#1#0**52824380#0#1**52824381#2#0**52824382#1#1**52824383#3#1**52824384#1#1
-
-		for _, cc := range scores {
-
-			goals := strings.Split(cc, "#")
-
-			if len(goals) == 3 {
-
-				hSc := goals[1]
-				aSc := goals[2]
-
-				log.Printf("**** HomeScore %s | Away Score %s ****", hSc, aSc)
-
-				homeGoals, err := strconv.Atoi(hSc)
-				if err != nil {
-					return m, fmt.Errorf("err : %v failed to convert hsc to int", err)
-				}
-
-				awayGoals, err := strconv.Atoi(aSc)
-				if err != nil {
-					return m, fmt.Errorf("err : %v failed to convert asc to int", err)
-				}
-
-				totalGoals := homeGoals + awayGoals
-
-				if totalGoals == 0 {
-
-					sel0 := fmt.Sprintf("%s_%d", oddsSortedSet, 0)
-					m[x] = MatchDetails{
-						Home:     homeGoals,
-						Away:     awayGoals,
-						Total:    totalGoals,
-						Category: sel0,
-					}
-
-				} else if totalGoals == 1 {
-
-					if homeGoals == 1 {
-
-						sel1h := fmt.Sprintf("%s_%s_%s", oddsSortedSet, "1", "h")
-						m[x] = MatchDetails{
-							Home:     homeGoals,
-							Away:     awayGoals,
-							Total:    totalGoals,
-							Category: sel1h,
-						}
-
-					} else {
-
-						sel1a := fmt.Sprintf("%s_%s_%s", oddsSortedSet, "1", "a")
-						m[x] = MatchDetails{
-							Home:     homeGoals,
-							Away:     awayGoals,
-							Total:    totalGoals,
-							Category: sel1a,
-						}
-
-					}
-
-				} else {
-
-					if homeGoals > 0 && awayGoals > 0 {
-
-						// Query from Goal Goal batch
-
-						if homeGoals > awayGoals {
-
-							selggh := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "h")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selggh,
-							}
-
-						} else if awayGoals > homeGoals {
-
-							selgga := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "a")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selgga,
-							}
-
-						} else {
-
-							selggd := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "d")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selggd,
-							}
-
-						}
-
-					} else {
-
-						// Query from No goal
-
-						if homeGoals > awayGoals {
-
-							selngh := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "h")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selngh,
-							}
-
-						} else if awayGoals > homeGoals {
-
-							selnga := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "a")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selnga,
-							}
+		scores, err := goalCategories.ParseRawScores(d.RawScores)
+		if err != nil {
+			return m, err
+		}
 
-						} else {
+		for _, sc := range scores {
 
-							selngd := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "d")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selngd,
-							}
+			log.Printf("**** HomeScore %d | Away Score %d ****", sc.Home, sc.Away)
 
-						}
-					}
-				}
+			m[x] = MatchDetails{
+				Home:     sc.Home,
+				Away:     sc.Away,
+				Total:    sc.Home + sc.Away,
+				Category: goalCategories.Key(oddsSortedSet, goalCategories.Category(sc.Home, sc.Away)),
 			}
 
 			x++
@@ -587,7 +541,7 @@ func (s *InstantGameServerService) GetGoalPattern(oddsSortedSet string, distr []
 	return m, nil
 }
 
-func (s *InstantGameServerService) Validate(ctx context.Context, playerID, oddsSortedSet string, distr []mrs.Mrs, competitionID string) (map[int]oddsFiles.CheckKeys, error) {
+func (s *InstantGameServerService) Validate(ctx context.Context, sc reusePolicies.Scope, oddsSortedSet string, distr []mrs.Mrs, competitionID string, r *seedCommitments.Rand) (map[int]oddsFiles.CheckKeys, error) {
 
 	m := make(map[int]oddsFiles.CheckKeys)
 
@@ -605,7 +559,7 @@ func (s *InstantGameServerService) Validate(ctx context.Context, playerID, oddsS
 
 	keysList := []oddsFiles.CheckKeys{}
 	//data, err := s.DecideRatio(ctx, oddsSortedSet, fetched, distr, competitionID)
-	data, err := s.FetchRoundMatches(ctx, playerID, oddsSortedSet, distr)
+	data, err := s.FetchRoundMatches(ctx, sc, oddsSortedSet, distr, r)
 
 	if err != nil {
 		return m, fmt.Errorf("err : %v failed to read from %s z range", err, oddsSortedSet)
@@ -728,6 +682,83 @@ func (s *InstantGameServerService) RemoveUsedKeys(ctx context.Context, key, valu
 	}
 }
 
+// ReleaseKey : puts a claimed key back in its sorted set when it could not be used.
+func (s *InstantGameServerService) ReleaseKey(ctx context.Context, key, value string) {
+	err := s.redisConn.ZRelease(ctx, key, value)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	} else {
+		log.Printf("###### released key %s back into zset %s ######", value, key)
+	}
+}
+
+// claimAllowed : claims a key of sortedSetName the reuse policy lets sc show. Keys it
+// blocks are held while the next one is claimed so that they are not drawn again, and
+// are put back once a key is found or maxBlocked were held.
+func (s *InstantGameServerService) claimAllowed(ctx context.Context, sortedSetName string, sc reusePolicies.Scope, r *seedCommitments.Rand) (string, error) {
+
+	held := []string{}
+	defer func() {
+		for _, key := range held {
+			s.ReleaseKey(ctx, sortedSetName, key)
+		}
+	}()
+
+	for {
+
+		key, candidates, err := s.redisConn.ZClaim(ctx, sortedSetName, 15, r.Float64(), processRedis.DefaultLease)
+		if err != nil {
+			return "", fmt.Errorf("err : %v failed to claim from %s z range", err, sortedSetName)
+		}
+		if key != "" {
+			r.PickFrom(candidates, key)
+		}
+
+		if key == "" || s.reusePolicy == nil {
+			return key, nil
+		}
+
+		policy, err := s.reusePolicy.Check(ctx, sc, key)
+		if err != nil {
+			s.ReleaseKey(ctx, sortedSetName, key)
+			return "", fmt.Errorf("err : %v failed to check reuse policy of %s", err, key)
+		}
+
+		if policy == "" {
+			return key, nil
+		}
+
+		log.Printf("%s blocks %s of %s for player %s", policy, key, sortedSetName, sc.PlayerID)
+
+		held = append(held, key)
+		if len(held) >= s.maxBlocked {
+			return "", fmt.Errorf("reuse policy blocked %d keys of %s in a row", len(held), sortedSetName)
+		}
+	}
+}
+
+// useKeys : records keys as shown in sc for the reuse policy, it fails without recording
+// any of them when one was shown where the policy keeps it from sc since it was checked.
+func (s *InstantGameServerService) useKeys(ctx context.Context, sc reusePolicies.Scope, keys []string) error {
+	if s.reusePolicy == nil {
+		return nil
+	}
+
+	return s.reusePolicy.Use(ctx, sc, keys)
+}
+
+// releaseUsages : drops the usages of keys recorded in sc that were not shown after all.
+func (s *InstantGameServerService) releaseUsages(ctx context.Context, sc reusePolicies.Scope, keys []string) {
+	if s.reusePolicy == nil {
+		return
+	}
+
+	err := s.reusePolicy.Release(ctx, sc, keys)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	}
+}
+
 func (s *InstantGameServerService) TeamInfo(leagueID string, teamID string) (string, string) {
 
 	switch leagueID {
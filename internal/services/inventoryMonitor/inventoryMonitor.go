@@ -0,0 +1,329 @@
+package inventoryMonitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories/categoryInventory"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/stockLevels/webhookAlert"
+)
+
+// sample : count of a set when it was checked.
+type sample struct {
+	at    time.Time
+	count int
+}
+
+// InventoryMonitorConfiguration is an alias for a function that will take in a pointer to an InventoryMonitorService and modify it
+type InventoryMonitorConfiguration func(os *InventoryMonitorService) error
+
+// InventoryMonitorService is a implementation of the InventoryMonitorService
+type InventoryMonitorService struct {
+	inventory goalCategories.GoalCategoriesRepository
+	alert     stockLevels.StockLevelsRepository
+	set       string
+	playerIDs []string
+	levels    []stockLevels.Level
+	window    time.Duration
+	horizon   float64
+	cooldown  time.Duration
+
+	mu      sync.Mutex
+	samples map[string][]sample
+	alerted map[string]string
+	sentAt  map[string]time.Time
+	stocks  []stockLevels.Stock
+}
+
+// NewInventoryMonitorService : instantiate every connection we need to watch the category sets
+func NewInventoryMonitorService(cfgs ...InventoryMonitorConfiguration) (*InventoryMonitorService, error) {
+	// Create the InventoryMonitorService
+	os := &InventoryMonitorService{
+		set:     goalCategories.DefaultSet,
+		levels:  stockLevels.DefaultLevels(),
+		window:  time.Hour,
+		samples: make(map[string][]sample),
+		alerted: make(map[string]string),
+		sentAt:  make(map[string]time.Time),
+	}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithRedisRepository : instantiates redis connections, the category sets are counted there
+func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) InventoryMonitorConfiguration {
+	return func(os *InventoryMonitorService) error {
+		r, err := redisExec.New(redisServer, dbNum, maxIdle, maxActive, idleTimeout)
+		if err != nil {
+			return err
+		}
+		d, err := categoryInventory.New(r)
+		if err != nil {
+			return err
+		}
+		os.inventory = d
+		return nil
+	}
+}
+
+// WithStockLevels : sanitized set whose categories are watched, the players whose CL_ sets
+// are watched too and the level of each category, the default levels when none is set
+func WithStockLevels(set string, playerIDs []string, ll []stockLevels.Level) InventoryMonitorConfiguration {
+	return func(os *InventoryMonitorService) error {
+		if set == "" {
+			return fmt.Errorf("set not set")
+		}
+		d, err := stockLevels.Levels(ll)
+		if err != nil {
+			return err
+		}
+		os.set = set
+		os.playerIDs = playerIDs
+		os.levels = d
+		return nil
+	}
+}
+
+// WithConsumptionWindow : how far back the draw rate of a set is measured, and the hours
+// to depletion under which a set is reported as depleting, never when 0
+func WithConsumptionWindow(window time.Duration, horizon float64) InventoryMonitorConfiguration {
+	return func(os *InventoryMonitorService) error {
+		if window <= 0 {
+			return fmt.Errorf("consumption window must be positive")
+		}
+		if horizon < 0 {
+			return fmt.Errorf("depletion horizon must not be negative")
+		}
+		os.window = window
+		os.horizon = horizon
+		return nil
+	}
+}
+
+// WithWebhook : posts alerts to endPoint. A set still in the same status is alerted again
+// once cooldown has passed
+func WithWebhook(endPoint string, cooldown time.Duration) InventoryMonitorConfiguration {
+	return func(os *InventoryMonitorService) error {
+		d, err := webhookAlert.New(endPoint)
+		if err != nil {
+			return err
+		}
+		os.alert = d
+		os.cooldown = cooldown
+		return nil
+	}
+}
+
+// Check : counts every category set, works out how fast each is drawn from and how long
+// it lasts at that rate, then alerts on the sets under their minimum or running out.
+func (s *InventoryMonitorService) Check(ctx context.Context) error {
+
+	ii, err := s.inventory.Inventory(ctx, goalCategories.Consumers(s.set, s.playerIDs...))
+	if err != nil {
+		return fmt.Errorf("err : %v failed to count category sets", err)
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+
+	stocks := []stockLevels.Stock{}
+	for _, i := range ii {
+
+		rate := s.rate(i.Set, i.Count, now)
+
+		depletion := -1.0
+		if rate > 0 {
+			depletion = math.Round(float64(i.Count)/rate*100) / 100
+		}
+
+		level, _ := stockLevels.Find(s.levels, i.Consumer, i.Category)
+
+		st := stockLevels.Stock{
+			Consumer:  i.Consumer,
+			Category:  i.Category,
+			Set:       i.Set,
+			Count:     i.Count,
+			Target:    level.Target,
+			Minimum:   level.Minimum,
+			Rate:      math.Round(rate*100) / 100,
+			Depletion: depletion,
+			Status:    stockLevels.Status(i.Count, level.Minimum, depletion, s.horizon),
+			CheckedAt: now.Format("2006-01-02 15:04:05"),
+		}
+
+		if depletion >= 0 {
+			st.DepletesAt = now.Add(time.Duration(depletion * float64(time.Hour))).Format("2006-01-02 15:04:05")
+		}
+
+		stocks = append(stocks, st)
+	}
+
+	s.stocks = stocks
+	due := s.due(now)
+
+	s.mu.Unlock()
+
+	// Posted without the lock, Stocks is not held up by a slow webhook.
+	s.notify(ctx, due, now)
+
+	return nil
+}
+
+// Stocks : stock of every category set at the last check, the worst first.
+func (s *InventoryMonitorService) Stocks() []stockLevels.Stock {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stocks := append([]stockLevels.Stock{}, s.stocks...)
+	sort.SliceStable(stocks, func(i, j int) bool {
+		return severity(stocks[i].Status) > severity(stocks[j].Status)
+	})
+
+	return stocks
+}
+
+// GetStocks : serves the stock of every category set at the last check. status filters
+// on a status, consumer on a consumer.
+func (s *InventoryMonitorService) GetStocks(c *gin.Context) {
+
+	var vl stockLevels.StockAPI
+
+	status := c.Query("status")
+	consumer := c.Query("consumer")
+
+	vl.Stocks = []stockLevels.Stock{}
+	for _, st := range s.Stocks() {
+		if status != "" && st.Status != status {
+			continue
+		}
+		if consumer != "" && st.Consumer != consumer {
+			continue
+		}
+		vl.Stocks = append(vl.Stocks, st)
+	}
+
+	vl.StatusCode = "200"
+	vl.StatusDescription = "success"
+	c.JSON(200, vl)
+}
+
+// rate : matches drawn from a set per hour over the window. Only falls in the count are
+// draws, the goals daemon topping a set up is not taken off.
+func (s *InventoryMonitorService) rate(set string, count int, now time.Time) float64 {
+
+	ss := append(s.samples[set], sample{at: now, count: count})
+
+	// Keep the newest sample at or before the start of the window so the rate spans it.
+	start := now.Add(-s.window)
+	first := 0
+	for i := range ss {
+		if !ss[i].at.After(start) {
+			first = i
+		}
+	}
+	ss = ss[first:]
+	s.samples[set] = ss
+
+	elapsed := ss[len(ss)-1].at.Sub(ss[0].at).Hours()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	drawn := 0
+	for i := 1; i < len(ss); i++ {
+		if ss[i].count < ss[i-1].count {
+			drawn += ss[i-1].count - ss[i].count
+		}
+	}
+
+	return float64(drawn) / elapsed
+}
+
+// due : sets to post, the ones that are not ok. A set is posted when its status changes
+// and again once the cooldown has passed, sets back to ok are posted once. s.mu must be
+// held.
+func (s *InventoryMonitorService) due(now time.Time) []stockLevels.Stock {
+
+	if s.alert == nil {
+		return nil
+	}
+
+	due := []stockLevels.Stock{}
+	for _, st := range s.stocks {
+
+		previous, known := s.alerted[st.Set]
+		if !known {
+			previous = stockLevels.Ok
+		}
+
+		switch {
+		case st.Status != previous:
+		case st.Status != stockLevels.Ok && now.Sub(s.sentAt[st.Set]) >= s.cooldown:
+		default:
+			continue
+		}
+
+		due = append(due, st)
+	}
+
+	return due
+}
+
+// notify : posts the due sets and records them as alerted.
+func (s *InventoryMonitorService) notify(ctx context.Context, due []stockLevels.Stock, now time.Time) {
+
+	if len(due) == 0 {
+		return
+	}
+
+	a := stockLevels.Alert{
+		Service: "inventory_monitor",
+		Stocks:  due,
+		SentAt:  now.Format("2006-01-02 15:04:05"),
+	}
+
+	err := s.alert.Send(ctx, a)
+	if err != nil {
+		log.Printf("Err : %v failed to send inventory alert", err)
+		return
+	}
+
+	s.mu.Lock()
+	for _, st := range due {
+		s.alerted[st.Set] = st.Status
+		s.sentAt[st.Set] = now
+	}
+	s.mu.Unlock()
+
+	log.Printf("Inventory alert sent for %d sets", len(due))
+}
+
+func severity(status string) int {
+	switch status {
+	case stockLevels.Empty:
+		return 3
+	case stockLevels.Low:
+		return 2
+	case stockLevels.Depleting:
+		return 1
+	}
+	return 0
+}
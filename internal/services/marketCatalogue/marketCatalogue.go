@@ -0,0 +1,248 @@
+package marketCatalogue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues/marketCataloguesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+)
+
+// MarketCatalogueConfiguration is an alias for a function that will take in a pointer to an MarketCatalogueService and modify it
+type MarketCatalogueConfiguration func(os *MarketCatalogueService) error
+
+// MarketCatalogueService is a implementation of the MarketCatalogueService
+type MarketCatalogueService struct {
+	marketCataloguesMysql marketCatalogues.MarketCataloguesRepository
+	redisConn             processRedis.RunRedis
+	cacheKey              string
+	cacheTTL              time.Duration
+
+	mu   sync.Mutex
+	last *marketCatalogues.Catalogue
+}
+
+// NewMarketCatalogueService : instantiate every connection we need to read the market catalogue
+func NewMarketCatalogueService(cfgs ...MarketCatalogueConfiguration) (*MarketCatalogueService, error) {
+	// Create the MarketCatalogueService
+	os := &MarketCatalogueService{
+		cacheKey: "MARKET_CATALOGUE",
+		cacheTTL: 5 * time.Minute,
+	}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithMysqlMarketCataloguesRepository : instantiates mysql to connect to the market catalogue
+func WithMysqlMarketCataloguesRepository(connectionString string) MarketCatalogueConfiguration {
+	return func(os *MarketCatalogueService) error {
+		d, err := marketCataloguesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.marketCataloguesMysql = d
+		return nil
+	}
+}
+
+// WithRedisRepository : instantiates redis connections, the catalogue is cached there
+func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) MarketCatalogueConfiguration {
+	return func(os *MarketCatalogueService) error {
+		d, err := redisExec.New(redisServer, dbNum, maxIdle, maxActive, idleTimeout)
+		if err != nil {
+			return err
+		}
+		os.redisConn = d
+		return nil
+	}
+}
+
+// WithCache : redis key of the catalogue and how long it is kept, changes made straight
+// in mysql are picked up once it expires
+func WithCache(cacheKey string, cacheTTL time.Duration) MarketCatalogueConfiguration {
+	return func(os *MarketCatalogueService) error {
+		if cacheKey != "" {
+			os.cacheKey = cacheKey
+		}
+		if cacheTTL > 0 {
+			os.cacheTTL = cacheTTL
+		}
+		return nil
+	}
+}
+
+// Catalogue : the cached catalogue, read from mysql and cached again when missing.
+func (s *MarketCatalogueService) Catalogue(ctx context.Context) (*marketCatalogues.Catalogue, error) {
+
+	if s.redisConn != nil {
+		data, err := s.redisConn.Get(ctx, s.cacheKey)
+		if err == nil {
+			var c marketCatalogues.Catalogue
+			err = json.Unmarshal([]byte(data), &c)
+			if err == nil && len(c.Markets) > 0 {
+				s.keep(&c)
+				return &c, nil
+			}
+			log.Printf("Err : %v cached catalogue %s not readable", err, s.cacheKey)
+		}
+	}
+
+	c, err := s.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.keep(c)
+	return c, nil
+}
+
+// keep : remembers the last catalogue read, served while the store can not be read.
+func (s *MarketCatalogueService) keep(c *marketCatalogues.Catalogue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = c
+}
+
+// lastCatalogue : the last catalogue read, nil until one was.
+func (s *MarketCatalogueService) lastCatalogue() *marketCatalogues.Catalogue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Refresh : reads the catalogue from mysql and caches it.
+func (s *MarketCatalogueService) Refresh(ctx context.Context) (*marketCatalogues.Catalogue, error) {
+
+	if s.marketCataloguesMysql == nil {
+		return nil, fmt.Errorf("market catalogue repository not set")
+	}
+
+	markets, err := s.marketCataloguesMysql.GetMarkets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("err : %v failed to read markets", err)
+	}
+
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("market catalogue is empty")
+	}
+
+	names, err := s.marketCataloguesMysql.GetMarketNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("err : %v failed to read market names", err)
+	}
+
+	overrides, err := s.marketCataloguesMysql.GetMarketOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("err : %v failed to read market overrides", err)
+	}
+
+	c := &marketCatalogues.Catalogue{
+		Markets:   markets,
+		Names:     names,
+		Overrides: overrides,
+	}
+
+	if s.redisConn == nil {
+		return c, nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		// Still usable, only the cache is missed.
+		log.Printf("Err : %v failed to marshal catalogue", err)
+		return c, nil
+	}
+
+	expiry := strconv.Itoa(int(s.cacheTTL.Seconds()))
+	err = s.redisConn.SetWithExpiry(ctx, s.cacheKey, string(data), expiry)
+	if err != nil {
+		// Still usable, the next read goes to mysql again.
+		log.Printf("Err : %v failed to cache catalogue in %s", err, s.cacheKey)
+	}
+
+	return c, nil
+}
+
+// Selection : markets offered to a competition and a client. The last catalogue read is
+// used while none can be read, an error is returned when none was ever read so that
+// markets the catalogue disabled are never published.
+func (s *MarketCatalogueService) Selection(ctx context.Context, competitionID, clientID, locale string) (*marketCatalogues.Selection, error) {
+
+	c, err := s.Catalogue(ctx)
+	if err != nil {
+		last := s.lastCatalogue()
+		if last == nil {
+			return nil, err
+		}
+		log.Printf("Err : %v using the last market catalogue read", err)
+		c = last
+	}
+
+	return c.Select(competitionID, clientID, locale), nil
+}
+
+// SaveMarket : adds or updates a market then refreshes the cache.
+func (s *MarketCatalogueService) SaveMarket(ctx context.Context, code, name, outcomeOrder string, priority int, status string) error {
+
+	m, err := marketCatalogues.NewMarketCatalogue(code, name, outcomeOrder, priority, status)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate market", err)
+	}
+
+	_, err = s.marketCataloguesMysql.SaveMarket(ctx, *m)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Refresh(ctx)
+	return err
+}
+
+// SaveMarketName : names a market in a locale then refreshes the cache.
+func (s *MarketCatalogueService) SaveMarketName(ctx context.Context, code, locale, name string) error {
+
+	n, err := marketCatalogues.NewMarketName(code, locale, name)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate market name", err)
+	}
+
+	_, err = s.marketCataloguesMysql.SaveMarketName(ctx, *n)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Refresh(ctx)
+	return err
+}
+
+// SaveMarketOverride : enables, disables or renames a market for a competition / client
+// then refreshes the cache.
+func (s *MarketCatalogueService) SaveMarketOverride(ctx context.Context, code, competitionID, clientID, status, name string, priority int) error {
+
+	o, err := marketCatalogues.NewMarketOverride(code, competitionID, clientID, status, name, priority)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate market override", err)
+	}
+
+	_, err = s.marketCataloguesMysql.SaveMarketOverride(ctx, *o)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Refresh(ctx)
+	return err
+}
@@ -0,0 +1,232 @@
+package oddsProfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins/marginEngine"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsProfiles/oddsProfilesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+)
+
+// overlapping : markets where one result wins several outcomes, their book is not an
+// overround so their prices are only rounded and capped.
+var overlapping = []string{"DC", "DCH", "MG"}
+
+// OddsProfileConfiguration is an alias for a function that will take in a pointer to an OddsProfileService and modify it
+type OddsProfileConfiguration func(os *OddsProfileService) error
+
+// OddsProfileService is a implementation of the OddsProfileService
+type OddsProfileService struct {
+	oddsProfilesMysql oddsProfiles.OddsProfilesRepository
+	redisConn         processRedis.RunRedis
+}
+
+// NewOddsProfileService : instantiate every connection we need to price odds per client
+func NewOddsProfileService(cfgs ...OddsProfileConfiguration) (*OddsProfileService, error) {
+	// Create the OddsProfileService
+	os := &OddsProfileService{}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithMysqlOddsProfilesRepository : instantiates mysql to connect to the odds profiles
+func WithMysqlOddsProfilesRepository(connectionString string) OddsProfileConfiguration {
+	return func(os *OddsProfileService) error {
+		d, err := oddsProfilesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.oddsProfilesMysql = d
+		return nil
+	}
+}
+
+// WithRedisRepository : instantiates redis connections, priced odds are saved there
+func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) OddsProfileConfiguration {
+	return func(os *OddsProfileService) error {
+		d, err := redisExec.New(redisServer, dbNum, maxIdle, maxActive, idleTimeout)
+		if err != nil {
+			return err
+		}
+		os.redisConn = d
+		return nil
+	}
+}
+
+// Profiles : every active profile.
+func (s *OddsProfileService) Profiles(ctx context.Context) ([]oddsProfiles.OddsProfiles, error) {
+
+	pp, err := s.oddsProfilesMysql.GetProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("err : %v failed to read odds profiles", err)
+	}
+
+	active := []oddsProfiles.OddsProfiles{}
+	for _, p := range pp {
+		if p.Status == oddsProfiles.Active {
+			active = append(active, p)
+		}
+	}
+
+	return active, nil
+}
+
+// SaveProfile : adds or replaces the odds profile of a client.
+func (s *OddsProfileService) SaveProfile(ctx context.Context, clientID, method string, margin, minOdd, maxOdd float64, ladder, markets, status string) error {
+
+	p, err := oddsProfiles.NewOddsProfile(clientID, method, margin, minOdd, maxOdd, ladder, markets, status)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate odds profile", err)
+	}
+
+	_, err = s.oddsProfilesMysql.Save(ctx, *p)
+	return err
+}
+
+// Publish : saves the odds of a season week priced for every active profile and returns
+// the keys saved. A profile that fails is logged and skipped, its client is served the
+// published odds.
+func (s *OddsProfileService) Publish(ctx context.Context, apiDate, seasonWeekID string, hh oddsFiles.FinalSeasonWeek) ([]string, error) {
+
+	keys := []string{}
+
+	pp, err := s.Profiles(ctx)
+	if err != nil {
+		return keys, err
+	}
+
+	for _, p := range pp {
+
+		priced, err := s.Price(ctx, p, hh)
+		if err != nil {
+			log.Printf("Err : %v failed to price season week %s for client %s", err, seasonWeekID, p.ClientID)
+			continue
+		}
+
+		oddsData, err := json.Marshal(priced)
+		if err != nil {
+			log.Printf("Err: %v failed to marshall odds json", err)
+			continue
+		}
+
+		keyName := oddsProfiles.OddsKey(apiDate, seasonWeekID, p.ClientID)
+		log.Printf(">>> Client %s odds key saved >>>> %s", p.ClientID, keyName)
+
+		expiry := "108000"
+		err = s.redisConn.SetWithExpiry(ctx, keyName, string(oddsData), expiry)
+		if err != nil {
+			log.Printf("Err: %v failed to odds set", err)
+			continue
+		}
+		keys = append(keys, keyName)
+	}
+
+	return keys, nil
+}
+
+// Price : a copy of the season week with the markets of the profile only. Each market
+// has the published margin taken out and the profile margin put back, prices are then
+// rounded down the ladder and capped.
+func (s *OddsProfileService) Price(ctx context.Context, p oddsProfiles.OddsProfiles, hh oddsFiles.FinalSeasonWeek) (oddsFiles.FinalSeasonWeek, error) {
+
+	ladder, err := oddsProfiles.ParseLadder(p.Ladder)
+	if err != nil {
+		return hh, err
+	}
+
+	var engine margins.MarginsRepository
+	if p.Method != "" {
+		mm := []margins.MarketMargin{{Code: margins.DefaultMarket, Method: p.Method, Overround: p.Margin}}
+		for _, code := range overlapping {
+			mm = append(mm, margins.MarketMargin{Code: code, Method: margins.Keep})
+		}
+
+		engine, err = marginEngine.New(mm)
+		if err != nil {
+			return hh, err
+		}
+	}
+
+	offered := p.MarketCodes()
+
+	priced := hh
+	priced.FinalMatches = []oddsFiles.FinalMatches{}
+
+	for _, m := range hh.FinalMatches {
+
+		pm := m
+		pm.FinalMarkets = []oddsFiles.FinalMarkets{}
+
+		for _, mkt := range m.FinalMarkets {
+
+			if len(offered) > 0 && !offered[mkt.Code] {
+				continue
+			}
+
+			odds := make([]float64, len(mkt.FinalOutcomes))
+			for i, o := range mkt.FinalOutcomes {
+				odds[i] = o.OddValue
+			}
+
+			if engine != nil {
+				r, err := engine.Reprice(ctx, mkt.Code, odds)
+				if err != nil {
+					log.Printf("Err : %v client %s keeps the published %s prices of match %s", err, p.ClientID, mkt.Code, m.MatchID)
+				} else {
+					odds = r.Odds
+				}
+			}
+
+			pk := mkt
+			pk.FinalOutcomes = make([]oddsFiles.FinalOutcomes, len(mkt.FinalOutcomes))
+			for i, o := range mkt.FinalOutcomes {
+				o.OddValue = capOdd(p, roundDown(ladder, odds[i]))
+				pk.FinalOutcomes[i] = o
+			}
+
+			pm.FinalMarkets = append(pm.FinalMarkets, pk)
+		}
+
+		priced.FinalMatches = append(priced.FinalMatches, pm)
+	}
+
+	return priced, nil
+}
+
+// roundDown : price rounded down to the increment of its ladder step, 0.01 past the ladder.
+func roundDown(ladder []oddsProfiles.Step, odd float64) float64 {
+
+	increment := 0.01
+	for _, x := range ladder {
+		if x.UpTo < 0 || odd <= x.UpTo {
+			increment = x.Increment
+			break
+		}
+	}
+
+	return math.Floor(math.Floor(odd/increment+1e-9)*increment*100+1e-9) / 100
+}
+
+func capOdd(p oddsProfiles.OddsProfiles, odd float64) float64 {
+	odd = math.Max(odd, p.MinOdd)
+	if p.MaxOdd > 0 {
+		odd = math.Min(odd, p.MaxOdd)
+	}
+	return odd
+}
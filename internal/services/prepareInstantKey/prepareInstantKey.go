@@ -5,15 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis/rExec"
 )
@@ -34,12 +36,18 @@ type PrepareInstantKeyService struct {
 	checkMatchesMysql checkMatches.CheckMatchesRepository
 	redisConn         processRedis.RunRedis
 	slowRedisConn     slowRedis.SlowRedis
+	rng               rngs.RngsRepository
 }
 
 // NewPrepareInstantKeyService : instantiate every connection we need to run current game service
 func NewPrepareInstantKeyService(cfgs ...PrepareInstantKeyConfiguration) (*PrepareInstantKeyService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &PrepareInstantKeyService{}
+	os := &PrepareInstantKeyService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -51,6 +59,17 @@ func NewPrepareInstantKeyService(cfgs ...PrepareInstantKeyConfiguration) (*Prepa
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) PrepareInstantKeyConfiguration {
+	return func(os *PrepareInstantKeyService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 // WithRedisRepository : instantiates redis connections
 func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) PrepareInstantKeyConfiguration {
 	return func(os *PrepareInstantKeyService) error {
@@ -82,8 +101,7 @@ func (s *PrepareInstantKeyService) RandomIndexes(ctx context.Context) map[int]in
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
@@ -96,8 +114,7 @@ func (s *PrepareInstantKeyService) NewRandomIndexes(ctx context.Context, max int
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
@@ -119,8 +136,8 @@ func (s *PrepareInstantKeyService) ReturnOdds(ctx context.Context, oddsSortedSet
 func (s *PrepareInstantKeyService) SelectKeys(ctx context.Context, oddsSortedSet, sanitizedKeysSet string, matches []string) error {
 
 	// Over Under 2.5 market
-	tgOver25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGO25")
-	tgUnder25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGU25")
+	tgOver25 := goalCategories.Key(sanitizedKeysSet, goalCategories.Over25)
+	tgUnder25 := goalCategories.Key(sanitizedKeysSet, goalCategories.Under25)
 
 	log.Printf("tgOver25 %s | tgUnder25 %s", tgOver25, tgUnder25)
 
@@ -232,93 +249,13 @@ func (s *PrepareInstantKeyService) SelectKeys(ctx context.Context, oddsSortedSet
 									log.Printf("Err : %v unable to add %s into %s set ", err, matchID, oddsSortedSet)
 								}
 
-								totalGoals := hScore + aScore
-
-								log.Printf("Total goals %d", totalGoals)
-
-								// To control over under 2.5 market
-								if totalGoals > 3 {
-
-									err := s.redisConn.ZAdd(ctx, tgOver25, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgOver25)
-									}
-
-								} else {
-
-									err := s.redisConn.ZAdd(ctx, tgUnder25, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder25)
-									}
-								}
-
-								// For future iteration
-
-								if totalGoals == 0 {
-									// Save total 0
-
-									s0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-									err := s.redisConn.ZAdd(ctx, s0, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s0)
-									}
-
-								} else if totalGoals == 1 {
-
-									s1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-									err := s.redisConn.ZAdd(ctx, s1, priority, matchID)
+								// File the match under every category of its score.
+								for _, category := range goalCategories.Categories(hScore, aScore) {
+									key := goalCategories.Key(sanitizedKeysSet, category)
+									err := s.redisConn.ZAdd(ctx, key, priority, matchID)
 									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1)
+										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, key)
 									}
-
-								} else if totalGoals == 2 {
-
-									s2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-									err := s.redisConn.ZAdd(ctx, s2, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2)
-									}
-
-								} else if totalGoals == 3 {
-
-									s3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-									err := s.redisConn.ZAdd(ctx, s3, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3)
-									}
-
-								} else if totalGoals == 4 {
-
-									s4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-									err := s.redisConn.ZAdd(ctx, s4, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4)
-									}
-
-								} else if totalGoals == 5 {
-
-									s5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-									err := s.redisConn.ZAdd(ctx, s5, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5)
-									}
-
-								} else if totalGoals == 6 {
-
-									s6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
-									err := s.redisConn.ZAdd(ctx, s6, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6)
-									}
-
-								} else {
-
-									s7 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "7")
-									err := s.redisConn.ZAdd(ctx, s7, priority, matchID)
-									if err != nil {
-										log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s7)
-									}
-
 								}
 
 							}
@@ -5,16 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches/checkMatchesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/slowRedis/rExec"
 )
@@ -35,12 +37,18 @@ type PrepareKeyService struct {
 	checkMatchesMysql checkMatches.CheckMatchesRepository
 	redisConn         processRedis.RunRedis
 	slowRedisConn     slowRedis.SlowRedis
+	rng               rngs.RngsRepository
 }
 
 // NewPrepareKeyService : instantiate every connection we need to run current game service
 func NewPrepareKeyService(cfgs ...PrepareKeyConfiguration) (*PrepareKeyService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &PrepareKeyService{}
+	os := &PrepareKeyService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -52,6 +60,17 @@ func NewPrepareKeyService(cfgs ...PrepareKeyConfiguration) (*PrepareKeyService,
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) PrepareKeyConfiguration {
+	return func(os *PrepareKeyService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 // WithMysqlCheckMatchesRepository : instantiates mysql to connect to matches interface
 func WithMysqlCheckMatchesRepository(connectionString string) PrepareKeyConfiguration {
 	return func(os *PrepareKeyService) error {
@@ -95,8 +114,7 @@ func (s *PrepareKeyService) RandomIndexes(ctx context.Context) map[int]int {
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
@@ -263,32 +281,13 @@ func (s *PrepareKeyService) SelectKeys(ctx context.Context, oddsSortedSet, sanit
 // SelectKeys : used to select keys to be used later.
 func (s *PrepareKeyService) SelectKeys2(ctx context.Context, oddsSortedSet, sanitizedKeysSet string, matches []string) error {
 
-	tgOver25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGO25")
-	tgUnder25 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGU25")
-	tgUnder15 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "TGU15")
-
-	tg0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-	tg1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-
-	tg2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-	// tg2gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "gg")
-	// tg2ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "ng")
-
-	tg3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-	// tg3gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "gg")
-	// tg3ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "ng")
-
-	tg4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-	// tg4gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "gg")
-	// tg4ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "ng")
-
-	tg5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-	// tg5gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "gg")
-	// tg5ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "ng")
-
-	tg6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
-	/* 	tg6gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "gg")
-	   	tg6ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "ng") */
+	tg0 := goalCategories.Key(sanitizedKeysSet, "0")
+	tg1 := goalCategories.Key(sanitizedKeysSet, "1")
+	tg2 := goalCategories.Key(sanitizedKeysSet, "2")
+	tg3 := goalCategories.Key(sanitizedKeysSet, "3")
+	tg4 := goalCategories.Key(sanitizedKeysSet, "4")
+	tg5 := goalCategories.Key(sanitizedKeysSet, "5")
+	tg6 := goalCategories.Key(sanitizedKeysSet, "6")
 
 	sanitizedSetLen, err := s.redisConn.SortedSetLen(ctx, sanitizedKeysSet)
 	if err != nil {
@@ -429,281 +428,15 @@ func (s *PrepareKeyService) SelectKeys2(ctx context.Context, oddsSortedSet, sani
 											log.Printf("Err : %v unable to add %s into %s set ", err, matchID, oddsSortedSet)
 										}
 
-										totalGoals := hScore + aScore
-
-										log.Printf("Total goals %d", totalGoals)
-
-										// To control over under 2.5 market
-										if totalGoals > 2 {
-
-											err := s.redisConn.ZAdd(ctx, tgOver25, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgOver25)
-											}
-
-										} else {
-
-											err := s.redisConn.ZAdd(ctx, tgUnder25, priority, matchID)
+										// File the match under every category of its score.
+										for _, category := range goalCategories.Categories(hScore, aScore) {
+											key := goalCategories.Key(sanitizedKeysSet, category)
+											err := s.redisConn.ZAdd(ctx, key, priority, matchID)
 											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder25)
+												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, key)
 											}
 										}
 
-										// For future iteration
-
-										if totalGoals == 0 {
-											// Save total 0
-
-											// Can only be ng under (goal goal market)
-
-											s0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-											err := s.redisConn.ZAdd(ctx, s0, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s0)
-											}
-
-											err = s.redisConn.ZAdd(ctx, tgUnder15, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder15)
-											}
-
-										} else if totalGoals == 1 {
-
-											s1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-											err := s.redisConn.ZAdd(ctx, s1, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1)
-											}
-
-											err = s.redisConn.ZAdd(ctx, tgUnder15, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, tgUnder15)
-											}
-
-											// Categorise gg and ng (goal goal // no goal)
-											// total goal 1 cant be categoried, can only be ng
-
-											if hScore == 1 {
-
-												s1h := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "1", "h")
-												err := s.redisConn.ZAdd(ctx, s1h, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1h)
-												}
-
-											} else {
-
-												s1a := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "1", "a")
-												err := s.redisConn.ZAdd(ctx, s1a, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s1a)
-												}
-
-											}
-
-										} else if totalGoals == 2 {
-
-											s2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-											err := s.redisConn.ZAdd(ctx, s2, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2)
-											}
-
-											// Categorise goal goal // no goal market
-
-											if hScore > 0 && aScore > 0 {
-
-												s2gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "gg")
-												err := s.redisConn.ZAdd(ctx, s2gg, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2gg)
-												}
-
-												err = s.Save1X2(ctx, s2gg, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2gg)
-												}
-
-											} else {
-
-												s2ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "2", "ng")
-												err := s.redisConn.ZAdd(ctx, s2ng, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2ng)
-												}
-
-												err = s.Save1X2(ctx, s2ng, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s2ng)
-												}
-
-											}
-
-										} else if totalGoals == 3 {
-
-											s3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-											err := s.redisConn.ZAdd(ctx, s3, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3)
-											}
-
-											// Categorise goal goal // no goal market
-
-											if hScore > 0 && aScore > 0 {
-
-												s3gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "gg")
-												err := s.redisConn.ZAdd(ctx, s3gg, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3gg)
-												}
-
-												err = s.Save1X2(ctx, s3gg, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3gg)
-												}
-
-											} else {
-
-												s3ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "3", "ng")
-												err := s.redisConn.ZAdd(ctx, s3ng, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3ng)
-												}
-
-												err = s.Save1X2(ctx, s3ng, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s3ng)
-												}
-
-											}
-
-										} else if totalGoals == 4 {
-
-											s4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-											err := s.redisConn.ZAdd(ctx, s4, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4)
-											}
-
-											// Categorise goal goal // no goal market
-
-											if hScore > 0 && aScore > 0 {
-
-												s4gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "gg")
-												err := s.redisConn.ZAdd(ctx, s4gg, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4gg)
-												}
-
-												err = s.Save1X2(ctx, s4gg, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4gg)
-												}
-
-											} else {
-
-												s4ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "4", "ng")
-												err := s.redisConn.ZAdd(ctx, s4ng, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4ng)
-												}
-
-												err = s.Save1X2(ctx, s4ng, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s4ng)
-												}
-
-											}
-
-										} else if totalGoals == 5 {
-
-											s5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-											err := s.redisConn.ZAdd(ctx, s5, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5)
-											}
-
-											// Categorise goal goal // no goal market
-
-											if hScore > 0 && aScore > 0 {
-
-												s5gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "gg")
-												err := s.redisConn.ZAdd(ctx, s5gg, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5gg)
-												}
-
-												err = s.Save1X2(ctx, s5gg, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5gg)
-												}
-
-											} else {
-
-												s5ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "5", "ng")
-												err := s.redisConn.ZAdd(ctx, s5ng, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5ng)
-												}
-
-												err = s.Save1X2(ctx, s5ng, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s5ng)
-												}
-
-											}
-
-										} else if totalGoals == 6 {
-
-											s6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
-											err := s.redisConn.ZAdd(ctx, s6, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6)
-											}
-
-											// Categorise goal goal // no goal market
-
-											if hScore > 0 && aScore > 0 {
-
-												s6gg := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "gg")
-												err := s.redisConn.ZAdd(ctx, s6gg, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6gg)
-												}
-
-												err = s.Save1X2(ctx, s6gg, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6gg)
-												}
-
-											} else {
-
-												
-												
-												
-												s6ng := fmt.Sprintf("%s_%s_%s", sanitizedKeysSet, "6", "ng")
-												err := s.redisConn.ZAdd(ctx, s6ng, priority, matchID)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6ng)
-												}
-
-												err = s.Save1X2(ctx, s6ng, priority, matchID, hScore, aScore)
-												if err != nil {
-													log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s6ng)
-												}
-
-											}
-
-										} else {
-
-											s7 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "7")
-											err := s.redisConn.ZAdd(ctx, s7, priority, matchID)
-											if err != nil {
-												log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s7)
-											}
-
-										}
-
 										// Save record
 
 										matchDate := time.Now().Format("2006-01-02")
@@ -752,48 +485,13 @@ func (s *PrepareKeyService) SelectKeys2(ctx context.Context, oddsSortedSet, sani
 	return nil
 }
 
-// Save1X2 :
-func (s *PrepareKeyService) Save1X2(ctx context.Context, baseKey, priority, matchID string, homeScore, awayScore int) error {
-
-	if homeScore > awayScore {
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "h")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-	} else if awayScore > homeScore {
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "a")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-	} else {
-
-		// Draw
-
-		hm := fmt.Sprintf("%s_%s", baseKey, "d")
-		err := s.redisConn.ZAdd(ctx, hm, priority, matchID)
-		if err != nil {
-			return fmt.Errorf("err : %v unable to add %s into %s set ", err, matchID, hm)
-		}
-
-	}
-
-	return nil
-}
-
 // NewRandomIndexes : used to create new randomization.
 func (s *PrepareKeyService) NewRandomIndexes(ctx context.Context, max int) map[int]int {
 	min := 1
 
 	m := make(map[int]int)
 	for x := 0; x < 50; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := s.rng.IntN(max-min+1) + min
 		m[val] = val
 	}
 
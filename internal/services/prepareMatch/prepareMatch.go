@@ -8,6 +8,7 @@ import (
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/cleanUps"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/cleanUps/cleanUpsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goals"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
@@ -22,6 +23,7 @@ type PrepareMatchService struct {
 	cleanUpMysql   cleanUps.CleanUpsRepository
 	usedMatchMysql usedMatches.UsedMatchesRepository
 	redisConn      processRedis.RunRedis
+	reuseAfterDays int
 }
 
 // NewPrepareMatchService : instantiate every connection we need to run current game service
@@ -50,6 +52,18 @@ func WithMysqlUsedMatchesRepository(connectionString string) PrepareMatchConfigu
 	}
 }
 
+// WithReuseAfterDays : matches used more than days days ago go back into the CL_ sets,
+// used matches are never reused when not set
+func WithReuseAfterDays(days int) PrepareMatchConfiguration {
+	return func(os *PrepareMatchService) error {
+		if days < 0 {
+			return fmt.Errorf("reuse after days must not be negative")
+		}
+		os.reuseAfterDays = days
+		return nil
+	}
+}
+
 // WithMysqlCleanUpsRepository : returns cleanups
 func WithMysqlCleanUpsRepository(connectionString string) PrepareMatchConfiguration {
 	return func(os *PrepareMatchService) error {
@@ -107,7 +121,7 @@ func (s *PrepareMatchService) TodaysGame(ctx context.Context) error {
 			for k, v := range dd {
 
 				// loop through to get each match
-				newKeyName := fmt.Sprintf("%s_%s", "CL", k)
+				newKeyName := goalCategories.ClaimKey(k)
 
 				// Start by deleting previous records
 
@@ -192,38 +206,22 @@ func (s *PrepareMatchService) TodaysGame(ctx context.Context) error {
 
 func (s *PrepareMatchService) AvailableMatches(ctx context.Context) (map[string][]goals.Goals, error) {
 
-	categories := []string{
-		"SANITIZED_ODDS_0",
-		"SANITIZED_ODDS_1_a",
-		"SANITIZED_ODDS_1_h",
-		"SANITIZED_ODDS_2_gg_d",
-		"SANITIZED_ODDS_2_ng_a",
-		"SANITIZED_ODDS_2_ng_h",
-		"SANITIZED_ODDS_3_gg_a",
-		"SANITIZED_ODDS_3_gg_h",
-		"SANITIZED_ODDS_3_ng_a",
-		"SANITIZED_ODDS_3_ng_h",
-		"SANITIZED_ODDS_4_gg_a",
-		"SANITIZED_ODDS_4_gg_h",
-		"SANITIZED_ODDS_4_ng_a",
-		"SANITIZED_ODDS_4_ng_h",
-		"SANITIZED_ODDS_4_gg_d",
-		"SANITIZED_ODDS_5_gg_a",
-		"SANITIZED_ODDS_5_gg_h",
-		"SANITIZED_ODDS_5_ng_a",
-		"SANITIZED_ODDS_5_ng_h",
-		"SANITIZED_ODDS_6_gg_a",
-		"SANITIZED_ODDS_6_gg_d",
-		"SANITIZED_ODDS_6_gg_h",
-		"SANITIZED_ODDS_6_ng_a",
-		"SANITIZED_ODDS_6_ng_h",
+	categories := []string{}
+	for _, c := range goalCategories.Finals() {
+		categories = append(categories, goalCategories.Key(goalCategories.DefaultSet, c))
 	}
 
 	m := make(map[string][]goals.Goals)
 
 	for _, d := range categories {
 
-		data, err := s.usedMatchMysql.GetAvailable(ctx, d)
+		var data []goals.Goals
+		var err error
+		if s.reuseAfterDays > 0 {
+			data, err = s.usedMatchMysql.GetReusable(ctx, d, s.reuseAfterDays)
+		} else {
+			data, err = s.usedMatchMysql.GetAvailable(ctx, d)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to return available matches : %v", err)
 		}
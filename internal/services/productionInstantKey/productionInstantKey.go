@@ -5,20 +5,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"strings"
 	"time"
 
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches/checkMatchesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets/csMatrix"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues/leaguesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins/marginEngine"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matches/matchesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsConfigs/processOdds"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies/reusePoliciesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
 	seasonWeekMysql "github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks/seasonWeeksMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments/seedCommitmentsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements/settlementEngine"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/lukemakhanu/magic_carpet/internal/services/oddsProfile"
 )
 
 type Job struct {
@@ -38,12 +55,28 @@ type ProcessInstantKeyService struct {
 	matchesMysql      matches.MatchesRepository
 	checkMatchesMysql checkMatches.CheckMatchesRepository
 	redisConn         processRedis.RunRedis
+	margins           margins.MarginsRepository
+	leaguesMysql      leagues.LeaguesRepository
+	marketCatalogue   *marketCatalogue.MarketCatalogueService
+	marketLocale      string
+	settlements       settlements.SettlementsRepository
+	derivedMarkets    derivedMarkets.DerivedMarketsRepository
+	oddsProfile       *oddsProfile.OddsProfileService
+	seedCommitments   seedCommitments.SeedCommitmentsRepository
+	reusePolicy       reusePolicies.ReusePoliciesRepository
+	maxBlocked        int
+	rng               rngs.RngsRepository
 }
 
 // NewProcessInstantKeyService : instantiate every connection we need to run current game service
 func NewProcessInstantKeyService(cfgs ...ProcessInstantKeyConfiguration) (*ProcessInstantKeyService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &ProcessInstantKeyService{}
+	os := &ProcessInstantKeyService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -55,6 +88,17 @@ func NewProcessInstantKeyService(cfgs ...ProcessInstantKeyConfiguration) (*Proce
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 // WithMysqlSeasonWeeksRepository : instantiates mysql to connect to season weeks interface
 func WithMysqlSeasonWeeksRepository(connectionString string) ProcessInstantKeyConfiguration {
 	return func(os *ProcessInstantKeyService) error {
@@ -91,6 +135,118 @@ func WithMysqlCheckMatchesRepository(connectionString string) ProcessInstantKeyC
 	}
 }
 
+// WithMarginEngine : odds are repriced to the target overround of every market instead
+// of taking a flat factor off every price
+func WithMarginEngine(mm []margins.MarketMargin) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		d, err := marginEngine.New(mm)
+		if err != nil {
+			return err
+		}
+		os.margins = d
+		return nil
+	}
+}
+
+// WithSettlementEngine : winning outcomes of every match are settled again from the final
+// score and the live scores, disagreements are flagged before the season week is published
+func WithSettlementEngine() ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		d, err := settlementEngine.New()
+		if err != nil {
+			return err
+		}
+		os.settlements = d
+		return nil
+	}
+}
+
+// WithDerivedMarkets : markets priced from the correct scores of every match, every derived
+// market when codes is empty. The catalogue decides which of them are offered
+func WithDerivedMarkets(codes []string) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		d, err := csMatrix.New(codes...)
+		if err != nil {
+			return err
+		}
+		os.derivedMarkets = d
+		return nil
+	}
+}
+
+// WithMysqlLeaguesRepository : leagues, used to find the client of a season week
+func WithMysqlLeaguesRepository(connectionString string) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		d, err := leaguesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.leaguesMysql = d
+		return nil
+	}
+}
+
+// WithMarketCatalogueService : markets offered, their names in locale and their order
+// are read from the market catalogue
+func WithMarketCatalogueService(mc *marketCatalogue.MarketCatalogueService, locale string) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		if mc == nil {
+			return fmt.Errorf("market catalogue not set")
+		}
+		os.marketCatalogue = mc
+		os.marketLocale = locale
+		return nil
+	}
+}
+
+// WithOddsProfileService : the odds of every season week are also saved priced for each
+// client with an active odds profile
+func WithOddsProfileService(op *oddsProfile.OddsProfileService) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		if op == nil {
+			return fmt.Errorf("odds profile service not set")
+		}
+		os.oddsProfile = op
+		return nil
+	}
+}
+
+// WithMysqlSeedCommitmentsRepository : publishes the seed hash of every season week before
+// its matches are drawn and records the draws once it is published
+func WithMysqlSeedCommitmentsRepository(connectionString string) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		d, err := seedCommitmentsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seedCommitments = d
+		return nil
+	}
+}
+
+// WithMysqlReusePoliciesRepository : skips matches the reuse policy keeps from a season week,
+// drawing fails once maxBlocked of them were read past in a set
+func WithMysqlReusePoliciesRepository(connectionString string, globalDays, playerDays int, season, seasonWeek bool, maxBlocked int) ProcessInstantKeyConfiguration {
+	return func(os *ProcessInstantKeyService) error {
+		p, err := reusePolicies.NewPolicy(globalDays, playerDays, season, seasonWeek)
+		if err != nil {
+			return err
+		}
+
+		if maxBlocked < 1 {
+			return fmt.Errorf("maxBlocked must be at least 1")
+		}
+
+		d, err := reusePoliciesMysql.New(connectionString, *p)
+		if err != nil {
+			return err
+		}
+		os.reusePolicy = d
+		os.maxBlocked = maxBlocked
+		return nil
+	}
+}
+
 // WithRedisRepository : instantiates redis connections
 func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) ProcessInstantKeyConfiguration {
 	return func(os *ProcessInstantKeyService) error {
@@ -134,7 +290,27 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 
 		n := 0
 
-		matchMap, err := s.Validate(ctx, x.LeagueID, oddsSortedSet, woSortedSet, liveScoreSortedSet)
+		// Instant season weeks carry no competition, only client overrides apply.
+		markets, err := s.marketSelection(ctx, x.LeagueID, "")
+		if err != nil {
+			log.Printf("Err : %v season week %s is not built", err, x.SeasonWeekID)
+			continue
+		}
+
+		// Ratios and the order of matches are drawn from a seed committed to before the
+		// season week starts, the season week is the public nonce. The sets the matches
+		// are read from are committed to with it.
+		fs, err := s.commitSeed(ctx, seedCommitments.InstantSeasonWeek, x.SeasonWeekID,
+			goalCategories.Key(oddsSortedSet, goalCategories.Over25), goalCategories.Key(oddsSortedSet, goalCategories.Under25))
+		if err != nil {
+			log.Printf("Err : %v failed to commit the seed of season week %s", err, x.SeasonWeekID)
+			continue
+		}
+		r := fs.Rand(x.SeasonWeekID)
+
+		sc := reusePolicies.Scope{Consumer: reusePolicies.Instant, SeasonID: x.SeasonID, SeasonWeekID: x.SeasonWeekID}
+
+		matchMap, err := s.Validate(ctx, x.LeagueID, oddsSortedSet, woSortedSet, liveScoreSortedSet, sc, r)
 		if err != nil {
 			log.Printf("Err : %v unable to create season week >>>>> ", err)
 		} else {
@@ -149,6 +325,7 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 			hh := oddsFiles.FinalSeasonWeek{}
 			wo := oddsFiles.FinalSeasonWeekWO{}
 			lsc := oddsFiles.FinalSeasonWeekLS{}
+			discrepancies := make(map[string][]settlements.Discrepancy)
 
 			hh.SeasonWeeKID = x.SeasonWeekID
 			hh.StartTime = x.StartTime
@@ -218,14 +395,11 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 				fd := matchMap[n]
 
 				log.Println("odds ---> ", len(fd.ValidateKeys.Odds))
-				mts, err := processOdds.New(fd.ValidateKeys.Odds, fd.ValidateKeys.Wo, fd.ValidateKeys.Ls, oddsFactor)
-				if err != nil {
-					log.Printf("Err : %v failed to initialize odds ", err)
-				}
-
-				mtk, winningOutcomes, liveScores, err := mts.FormulateOdds(ctx)
+				mtk, winningOutcomes, liveScores, err := s.formulateOdds(ctx, fd.ValidateKeys.Odds, fd.ValidateKeys.Wo, fd.ValidateKeys.Ls, oddsFactor, markets)
 				if err != nil {
 					log.Printf("Err : %v failed to formulate odds ", err)
+				} else if dd := s.checkSettlement(ctx, x.SeasonWeekID, g.MatchID, mtk, winningOutcomes, liveScores); len(dd) > 0 {
+					discrepancies[g.MatchID] = dd
 				}
 
 				matches.FinalMarkets = mtk
@@ -254,13 +428,28 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 				log.Printf("updated record : %d", updated)
 			}
 
-			// Save Match odds into redis for further use
-
 			sTime, err := time.Parse("2006-01-02 15:04:05", x.StartTime)
 			if err != nil {
 				log.Printf("Err : %v failed to convert string to time", err)
 			}
 
+			// Results the settlement engine does not agree with are never published, the
+			// week needs a look before it is set back to inactive.
+			if len(discrepancies) > 0 {
+				s.saveDiscrepancies(ctx, sTime.Format("2006-01-02"), x.SeasonWeekID, discrepancies)
+
+				status := "failed"
+				updated, err := s.seasonWeekMysql.UpdateSsnWeekStatus(ctx, x.SeasonWeekID, x.SeasonID, status)
+				if err != nil {
+					log.Printf("Err : %v failed to update failed season week", err)
+				}
+
+				log.Printf("updated record : %d", updated)
+				continue
+			}
+
+			// Save Match odds into redis for further use
+
 			keyName := fmt.Sprintf("%s_%s_%s", "pr_odds", sTime.Format("2006-01-02"), x.SeasonWeekID)
 			log.Printf(">>> Odds key saved >>>> %s", keyName)
 
@@ -276,6 +465,13 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 				}
 			}
 
+			if s.oddsProfile != nil {
+				_, err := s.oddsProfile.Publish(ctx, sTime.Format("2006-01-02"), x.SeasonWeekID, hh)
+				if err != nil {
+					log.Printf("Err: %v failed to save client odds", err)
+				}
+			}
+
 			keyNameWO := fmt.Sprintf("%s_%s_%s", "pr_wo", sTime.Format("2006-01-02"), x.SeasonWeekID)
 			log.Printf(">>> WinningOutcome key saved >>>> %s", keyNameWO)
 
@@ -321,6 +517,8 @@ func (s *ProcessInstantKeyService) GetUpcomingSeasonWeeks(ctx context.Context, o
 			}
 
 			log.Printf("updated record : %d", updated)
+
+			s.revealSeed(ctx, fs, x.EndTime)
 		}
 	}
 
@@ -513,7 +711,7 @@ func (s *ProcessInstantKeyService) TeamInfo(leagueID string, teamID string) (str
 }
 
 // Validate : rewrites odds the right way
-func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsSortedSet, woSortedSet, liveScoreSortedSet string) (map[int]oddsFiles.CheckKeys, error) {
+func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsSortedSet, woSortedSet, liveScoreSortedSet string, sc reusePolicies.Scope, r *seedCommitments.Rand) (map[int]oddsFiles.CheckKeys, error) {
 
 	m := make(map[int]oddsFiles.CheckKeys)
 
@@ -530,7 +728,7 @@ func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsS
 	// Get the games ration for over TG25
 
 	keysList := []oddsFiles.CheckKeys{}
-	data, err := s.DecideRatio(ctx, oddsSortedSet, fetched)
+	data, err := s.DecideRatio(ctx, oddsSortedSet, fetched, sc, r)
 	if err != nil {
 		return m, fmt.Errorf("err : %v failed to read from %s z range", err, oddsSortedSet)
 	}
@@ -610,6 +808,11 @@ func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsS
 
 				// Save this match as used to avoid repetition in the coming days.
 
+				err = s.useKeys(ctx, sc, []string{o})
+				if err != nil {
+					return m, err
+				}
+
 				matchDate := time.Now().Format("2006-01-02")
 				cm, err := checkMatches.NewCheckMatches(parentID[0], parentID[1], matchDate)
 				if err != nil {
@@ -623,83 +826,14 @@ func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsS
 
 				log.Printf("Last inserted id %d into checkMatches tbl", lastID)
 
-				_, err = s.redisConn.ZRem(ctx, oddsSortedSet, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, oddsSortedSet)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, oddsSortedSet)
-				}
-
-				// Remove from the Over and Under 2.5 sets
-
-				tgOver25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGO25")
-				tgUnder25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGU25")
-				tgUnder0 := fmt.Sprintf("%s_%s", oddsSortedSet, "0")
-				tgUnder1 := fmt.Sprintf("%s_%s", oddsSortedSet, "1")
-				tgUnder2 := fmt.Sprintf("%s_%s", oddsSortedSet, "2")
-				tgUnder3 := fmt.Sprintf("%s_%s", oddsSortedSet, "3")
-				tgUnder4 := fmt.Sprintf("%s_%s", oddsSortedSet, "4")
-				tgUnder5 := fmt.Sprintf("%s_%s", oddsSortedSet, "5")
-				tgUnder6 := fmt.Sprintf("%s_%s", oddsSortedSet, "6")
-				tgUnder7 := fmt.Sprintf("%s_%s", oddsSortedSet, "7")
+				// Remove from the sanitized set and every category set
 
-				_, err = s.redisConn.ZRem(ctx, tgOver25, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgOver25)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgOver25)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder25, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder25)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder25)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder0, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder0)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder0)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder1, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder1)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder1)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder2, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder2)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder2)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder3, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder3)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder3)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder4, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder4)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder4)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder5, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder5)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder5)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder6, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder6)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder6)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder7, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder7)
-					return m, fmt.Errorf("err : %v failed to delete from %s z range", err, tgUnder7)
+				for _, set := range goalCategories.Sets(oddsSortedSet) {
+					_, err = s.redisConn.ZRem(ctx, set, o)
+					if err != nil {
+						log.Printf("Err : %v failed to delete from %s z range", err, set)
+						return m, fmt.Errorf("err : %v failed to delete from %s z range", err, set)
+					}
 				}
 
 			} else {
@@ -731,13 +865,13 @@ func (s *ProcessInstantKeyService) Validate(ctx context.Context, leagueID, oddsS
 	return m, fmt.Errorf("final match count : %d not enough to create season week", len(data))
 }
 
-func (s *ProcessInstantKeyService) DecideRatio(ctx context.Context, oddsSortedSet string, totalGames int) ([]string, error) {
+func (s *ProcessInstantKeyService) DecideRatio(ctx context.Context, oddsSortedSet string, totalGames int, sc reusePolicies.Scope, r *seedCommitments.Rand) ([]string, error) {
 
 	list := []string{}
 
 	goals := s.TotalGoalsPerSession(ctx)
 	max := len(goals)
-	selectedRations := s.NewRandomIndexes(ctx, max)
+	selectedRations := s.NewRandomIndexes(ctx, r, max)
 
 	rgO25 := goals[selectedRations[5]] + 1
 	rgU25 := totalGames - rgO25 + 1
@@ -746,17 +880,17 @@ func (s *ProcessInstantKeyService) DecideRatio(ctx context.Context, oddsSortedSe
 
 	// Now figure out how the matches will be arranged randomly.
 
-	oddsOv25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGO25")
-	oddsU25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGU25")
+	oddsOv25 := goalCategories.Key(oddsSortedSet, goalCategories.Over25)
+	oddsU25 := goalCategories.Key(oddsSortedSet, goalCategories.Under25)
 
-	data, err := s.redisConn.GetZRangeWithLimit(ctx, oddsOv25, rgO25)
+	data, err := s.allowedRange(ctx, oddsOv25, rgO25, sc, r)
 	if err != nil {
 		return list, fmt.Errorf("err : %v failed to read from %s z range", err, oddsOv25)
 	}
 
-	data2, err := s.redisConn.GetZRangeWithLimit(ctx, oddsU25, rgU25)
+	data2, err := s.allowedRange(ctx, oddsU25, rgU25, sc, r)
 	if err != nil {
-		return list, fmt.Errorf("err : %v failed to read from %s z range", err, oddsOv25)
+		return list, fmt.Errorf("err : %v failed to read from %s z range", err, oddsU25)
 	}
 
 	for _, x := range data {
@@ -769,9 +903,9 @@ func (s *ProcessInstantKeyService) DecideRatio(ctx context.Context, oddsSortedSe
 
 	log.Println("List before shuffle : ", list)
 
-	rand.Seed(time.Now().UnixNano())
 	for i := len(list) - 1; i > 0; i-- { // Fisher–Yates shuffle
-		j := rand.Intn(i + 1)
+		j := r.IntN(i + 1)
+		r.PickFrom(list[:i+1], list[j])
 		list[i], list[j] = list[j], list[i]
 	}
 
@@ -781,6 +915,92 @@ func (s *ProcessInstantKeyService) DecideRatio(ctx context.Context, oddsSortedSe
 
 }
 
+// allowedRange : members of set as zRange returns them, less those the reuse policy keeps
+// from sc. Blocked members stay in set for other season weeks, reading fails once
+// maxBlocked of them were read past.
+func (s *ProcessInstantKeyService) allowedRange(ctx context.Context, set string, fetched int, sc reusePolicies.Scope, r *seedCommitments.Rand) ([]string, error) {
+
+	if s.reusePolicy == nil {
+		return s.zRange(ctx, set, fetched, r)
+	}
+
+	data, err := s.zRange(ctx, set, fetched+s.maxBlocked, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// ZRANGE 0 fetched returns fetched+1 members.
+	want := min(len(data), fetched+1)
+
+	list := []string{}
+	blocked := 0
+	for _, key := range data {
+
+		if len(list) == want {
+			break
+		}
+
+		policy, err := s.reusePolicy.Check(ctx, sc, key)
+		if err != nil {
+			return nil, fmt.Errorf("err : %v failed to check reuse policy of %s", err, key)
+		}
+
+		if policy == "" {
+			list = append(list, key)
+			continue
+		}
+
+		log.Printf("%s blocks %s of %s for season week %s", policy, key, set, sc.SeasonWeekID)
+
+		blocked++
+		if blocked >= s.maxBlocked {
+			return nil, fmt.Errorf("reuse policy blocked %d keys of %s in a row", blocked, set)
+		}
+	}
+
+	return list, nil
+}
+
+// zRange : members of set as GetZRangeWithLimit returns them. When r committed to a pool
+// of set they are read from the pool members still in set, in pool order.
+func (s *ProcessInstantKeyService) zRange(ctx context.Context, set string, fetched int, r *seedCommitments.Rand) ([]string, error) {
+
+	pool, ok := r.Pool(set)
+	if !ok {
+		return s.redisConn.GetZRangeWithLimit(ctx, set, fetched)
+	}
+
+	// ZRANGE 0 fetched returns fetched+1 members.
+	list := []string{}
+	for _, key := range pool {
+
+		if len(list) > fetched {
+			break
+		}
+
+		ok, err := s.redisConn.IsZMember(ctx, set, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			list = append(list, key)
+		}
+	}
+
+	return list, nil
+}
+
+// useKeys : records keys as shown in sc for the reuse policy, it fails without recording
+// any of them when one was shown where the policy keeps it from sc since it was checked.
+func (s *ProcessInstantKeyService) useKeys(ctx context.Context, sc reusePolicies.Scope, keys []string) error {
+	if s.reusePolicy == nil {
+		return nil
+	}
+
+	return s.reusePolicy.Use(ctx, sc, keys)
+}
+
 // TotalGoalsPerSession : this helps with distribution of total goals per match session.
 func (s *ProcessInstantKeyService) TotalGoalsPerSession(ctx context.Context) []int {
 
@@ -797,16 +1017,172 @@ func (s *ProcessInstantKeyService) TotalGoalsPerSession(ctx context.Context) []i
 	return data
 }
 
-// NewRandomIndexes : used to create new randomization.
-func (s *ProcessInstantKeyService) NewRandomIndexes(ctx context.Context, max int) map[int]int {
+// NewRandomIndexes : used to create new randomization, drawn from r.
+func (s *ProcessInstantKeyService) NewRandomIndexes(ctx context.Context, r *seedCommitments.Rand, max int) map[int]int {
 	min := 1
 
 	m := make(map[int]int)
 	for x := 0; x < 10; x++ {
-		rand.Seed(time.Now().UnixNano())
-		val := rand.Intn(max-min+1) + min
+		val := r.IntN(max-min+1) + min
 		m[val] = val
 	}
 
 	return m
 }
+
+// marketSelection : markets offered to the client of the league, nil keeps the built in
+// catalogue when no market catalogue is set. Fails when the catalogue can not be read.
+func (s *ProcessInstantKeyService) marketSelection(ctx context.Context, leagueID, competitionID string) (*marketCatalogues.Selection, error) {
+
+	if s.marketCatalogue == nil {
+		return nil, nil
+	}
+
+	clientID := ""
+	if s.leaguesMysql != nil {
+		l, err := s.leaguesMysql.GetLeagueByID(ctx, leagueID)
+		if err != nil {
+			log.Printf("Err : %v failed to read league %s", err, leagueID)
+		} else if len(l) > 0 {
+			clientID = l[0].ClientID
+		}
+	}
+
+	sel, err := s.marketCatalogue.Selection(ctx, competitionID, clientID, s.marketLocale)
+	if err != nil {
+		return nil, fmt.Errorf("Err : %v failed to read the market catalogue", err)
+	}
+
+	return sel, nil
+}
+
+// checkSettlement : provider winning outcomes of a match the settlement engine does not agree
+// with, and markets published for it that were not settled.
+func (s *ProcessInstantKeyService) checkSettlement(ctx context.Context, seasonWeekID, matchID string, mkts []oddsFiles.FinalMarkets, fs oddsFiles.FinalScores, ls []oddsFiles.FinalLiveScores) []settlements.Discrepancy {
+
+	if s.settlements == nil {
+		return nil
+	}
+
+	m, err := settlements.NewMatch(fs.HomeScore, fs.AwayScore, ls)
+	if err != nil {
+		log.Printf("Err : %v unable to settle match %s of season week %s", err, matchID, seasonWeekID)
+		return []settlements.Discrepancy{{Detail: err.Error()}}
+	}
+
+	wos := []oddsFiles.RawWOs{}
+	for _, w := range fs.FinalWinningOutcomes {
+		wos = append(wos, oddsFiles.RawWOs{
+			SubTypeID:   w.SubTypeID,
+			OutcomeID:   w.OutcomeID,
+			OutcomeName: w.OutcomeName,
+			Result:      w.Result,
+		})
+	}
+
+	offered := []string{}
+	for _, mk := range mkts {
+		offered = append(offered, mk.Code)
+	}
+
+	dd := s.settlements.Compare(ctx, *m, wos, offered)
+	for _, d := range dd {
+		log.Printf("Settlement discrepancy season week %s match %s | %s", seasonWeekID, matchID, d.Detail)
+	}
+
+	return dd
+}
+
+// saveDiscrepancies : settlement discrepancies of a season week kept for review.
+func (s *ProcessInstantKeyService) saveDiscrepancies(ctx context.Context, apiDate, seasonWeekID string, discrepancies map[string][]settlements.Discrepancy) {
+
+	keyNameST := fmt.Sprintf("%s_%s_%s", "pr_settlement", apiDate, seasonWeekID)
+	log.Printf(">>> Season week %s has %d matches the provider settled differently, saved in %s",
+		seasonWeekID, len(discrepancies), keyNameST)
+
+	discrepanciesData, err := json.Marshal(discrepancies)
+	if err != nil {
+		log.Printf("Err: %v failed to marshall settlement json", err)
+		return
+	}
+
+	expiry := "108000"
+	err = s.redisConn.SetWithExpiry(ctx, keyNameST, string(discrepanciesData), expiry)
+	if err != nil {
+		log.Printf("Err: %v failed to save settlement discrepancies", err)
+	}
+}
+
+// formulateOdds : odds repriced by the margin engine when one is set, oddsFactor is
+// taken off every price otherwise.
+func (s *ProcessInstantKeyService) formulateOdds(ctx context.Context, oddsPayload, woPayload, lsPayload string, oddsFactor float64, markets *marketCatalogues.Selection) ([]oddsFiles.FinalMarkets, oddsFiles.FinalScores, []oddsFiles.FinalLiveScores, error) {
+
+	if s.margins != nil {
+		mts, err := processOdds.NewWithMargins(oddsPayload, woPayload, lsPayload, s.margins)
+		if err != nil {
+			return nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("Err : %v failed to initialize odds", err)
+		}
+		mts.SetMarkets(markets)
+		mts.SetDerivedMarkets(s.derivedMarkets)
+		return mts.FormulateOdds(ctx)
+	}
+
+	mts, err := processOdds.New(oddsPayload, woPayload, lsPayload, oddsFactor)
+	if err != nil {
+		return nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("Err : %v failed to initialize odds", err)
+	}
+	mts.SetMarkets(markets)
+	mts.SetDerivedMarkets(s.derivedMarkets)
+	mts.SetRng(s.rng)
+	return mts.FormulateOdds(ctx)
+}
+
+// commitSeed : session the draws of a scope are made from, its seed hash is published when
+// the seed commitments repository is set, with the hash of the first PoolSize members of
+// sets. A scope retried after a failure draws from the seed and pools committed the first
+// time, a published hash never changes.
+func (s *ProcessInstantKeyService) commitSeed(ctx context.Context, scope, scopeID string, sets ...string) (*seedCommitments.Session, error) {
+
+	if s.seedCommitments == nil {
+		return seedCommitments.NewSession(scope, scopeID)
+	}
+
+	pools := []seedCommitments.Pool{}
+	for _, set := range sets {
+		members, err := s.redisConn.GetZRangeWithLimit(ctx, set, seedCommitments.PoolSize)
+		if err != nil {
+			return nil, fmt.Errorf("err : %v failed to read the pool of %s", err, set)
+		}
+		pools = append(pools, seedCommitments.Pool{Set: set, Members: members})
+	}
+
+	fs, err := seedCommitments.NewSession(scope, scopeID, pools...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.seedCommitments.Save(ctx, fs.Commitment)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.seedCommitments.Committed(ctx, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return seedCommitments.ResumeSession(c)
+}
+
+// revealSeed : records the draws of the session, its seed is revealed from revealAt.
+func (s *ProcessInstantKeyService) revealSeed(ctx context.Context, fs *seedCommitments.Session, revealAt string) {
+
+	if s.seedCommitments == nil {
+		return
+	}
+
+	err := s.seedCommitments.Close(ctx, fs.Commitment.Scope, fs.Commitment.ScopeID, revealAt, fs.Draws())
+	if err != nil {
+		log.Printf("Err : %v failed to record the draws of %s %s", err, fs.Commitment.Scope, fs.Commitment.ScopeID)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"strconv"
 	"strings"
 	"time"
@@ -14,19 +13,42 @@ import (
 	"github.com/lukemakhanu/magic_carpet/internal/domains/checkMatches/checkMatchesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/cleanUps"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/cleanUps/cleanUpsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/derivedMarkets/csMatrix"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/goals"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/leagues/leaguesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/margins/marginEngine"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/marketCatalogues"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/matches/matchesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/mrs/mrsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsChecks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsChecks/oddsValidator"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsConfigs/processOdds"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/reusePolicies/reusePoliciesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
 	seasonWeekMysql "github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks/seasonWeeksMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments/seedCommitmentsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/settlements/settlementEngine"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/usedMatches"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/usedMatches/usedMatchesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/weekResults/weekResultsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/services/marketCatalogue"
+	"github.com/lukemakhanu/magic_carpet/internal/services/oddsProfile"
 )
 
 type Job struct {
@@ -49,12 +71,31 @@ type ProcessKeyService struct {
 	checkMatchesMysql checkMatches.CheckMatchesRepository
 	cleanUpMysql      cleanUps.CleanUpsRepository
 	redisConn         processRedis.RunRedis
+	margins           margins.MarginsRepository
+	leaguesMysql      leagues.LeaguesRepository
+	marketCatalogue   *marketCatalogue.MarketCatalogueService
+	marketLocale      string
+	settlements       settlements.SettlementsRepository
+	derivedMarkets    derivedMarkets.DerivedMarketsRepository
+	oddsProfile       *oddsProfile.OddsProfileService
+	oddsValidator     oddsChecks.OddsChecksRepository
+	maxSwaps          int
+	seedCommitments   seedCommitments.SeedCommitmentsRepository
+	rng               rngs.RngsRepository
+	reusePolicy       reusePolicies.ReusePoliciesRepository
+	maxBlocked        int
+	weekResults       weekResults.WeekResultsRepository
 }
 
 // NewProcessKeyService : instantiate every connection we need to run current game service
 func NewProcessKeyService(cfgs ...ProcessKeyConfiguration) (*ProcessKeyService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &ProcessKeyService{}
+	os := &ProcessKeyService{rng: rng}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -66,6 +107,17 @@ func NewProcessKeyService(cfgs ...ProcessKeyConfiguration) (*ProcessKeyService,
 	return os, nil
 }
 
+// WithRng : source selection draws come from, crypto/rand when not set
+func WithRng(r rngs.RngsRepository) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		os.rng = r
+		return nil
+	}
+}
+
 // WithMysqlSeasonWeeksRepository : instantiates mysql to connect to season weeks interface
 func WithMysqlSeasonWeeksRepository(connectionString string) ProcessKeyConfiguration {
 	return func(os *ProcessKeyService) error {
@@ -126,6 +178,100 @@ func WithMysqlUsedMatchesRepository(connectionString string) ProcessKeyConfigura
 	}
 }
 
+// WithMarginEngine : odds are repriced to the target overround of every market instead
+// of taking a flat factor off every price
+func WithMarginEngine(mm []margins.MarketMargin) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := marginEngine.New(mm)
+		if err != nil {
+			return err
+		}
+		os.margins = d
+		return nil
+	}
+}
+
+// WithSettlementEngine : winning outcomes of every match are settled again from the final
+// score and the live scores, disagreements are flagged before the season week is published
+func WithSettlementEngine() ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := settlementEngine.New()
+		if err != nil {
+			return err
+		}
+		os.settlements = d
+		return nil
+	}
+}
+
+// WithOddsValidator : markets of every match are checked before the season week is published.
+// A match that fails is swapped for another of the same score category, after maxSwaps
+// failed candidates the season week is marked as failed
+func WithOddsValidator(rules oddsChecks.Rules, maxSwaps int) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := oddsValidator.New(rules)
+		if err != nil {
+			return err
+		}
+		if maxSwaps < 0 {
+			return fmt.Errorf("maxSwaps must not be negative")
+		}
+		os.oddsValidator = d
+		os.maxSwaps = maxSwaps
+		return nil
+	}
+}
+
+// WithDerivedMarkets : markets priced from the correct scores of every match, every derived
+// market when codes is empty. The catalogue decides which of them are offered
+func WithDerivedMarkets(codes []string) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := csMatrix.New(codes...)
+		if err != nil {
+			return err
+		}
+		os.derivedMarkets = d
+		return nil
+	}
+}
+
+// WithMysqlLeaguesRepository : leagues, used to find the client of a season week
+func WithMysqlLeaguesRepository(connectionString string) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := leaguesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.leaguesMysql = d
+		return nil
+	}
+}
+
+// WithMarketCatalogueService : markets offered, their names in locale and their order
+// are read from the market catalogue
+func WithMarketCatalogueService(mc *marketCatalogue.MarketCatalogueService, locale string) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		if mc == nil {
+			return fmt.Errorf("market catalogue not set")
+		}
+		os.marketCatalogue = mc
+		os.marketLocale = locale
+		return nil
+	}
+}
+
+// WithOddsProfileService : the odds of every season week are also saved priced for each
+// client with an active odds profile
+func WithOddsProfileService(op *oddsProfile.OddsProfileService) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		if op == nil {
+			return fmt.Errorf("odds profile service not set")
+		}
+		os.oddsProfile = op
+		return nil
+	}
+}
+
 // WithRedisRepository : instantiates redis connections
 func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) ProcessKeyConfiguration {
 	return func(os *ProcessKeyService) error {
@@ -138,6 +284,54 @@ func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive i
 	}
 }
 
+// WithMysqlWeekResultsRepository : keeps the results of every published season week
+func WithMysqlWeekResultsRepository(connectionString string) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := weekResultsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.weekResults = d
+		return nil
+	}
+}
+
+// WithMysqlSeedCommitmentsRepository : publishes the seed hash of every season week before
+// its matches are drawn and records the draws once it is published
+func WithMysqlSeedCommitmentsRepository(connectionString string) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		d, err := seedCommitmentsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seedCommitments = d
+		return nil
+	}
+}
+
+// WithMysqlReusePoliciesRepository : keeps claimed matches the reuse policy blocks out of
+// the season week, they are put back once maxBlocked of them were drawn in a row
+func WithMysqlReusePoliciesRepository(connectionString string, globalDays, playerDays int, season, seasonWeek bool, maxBlocked int) ProcessKeyConfiguration {
+	return func(os *ProcessKeyService) error {
+		p, err := reusePolicies.NewPolicy(globalDays, playerDays, season, seasonWeek)
+		if err != nil {
+			return err
+		}
+
+		if maxBlocked < 1 {
+			return fmt.Errorf("maxBlocked must be at least 1")
+		}
+
+		d, err := reusePoliciesMysql.New(connectionString, *p)
+		if err != nil {
+			return err
+		}
+		os.reusePolicy = d
+		os.maxBlocked = maxBlocked
+		return nil
+	}
+}
+
 // WithMysqlCleanUpsRepository : returns cleanups
 func WithMysqlCleanUpsRepository(connectionString string) ProcessKeyConfiguration {
 	return func(os *ProcessKeyService) error {
@@ -150,6 +344,360 @@ func WithMysqlCleanUpsRepository(connectionString string) ProcessKeyConfiguratio
 	}
 }
 
+// marketSelection : markets offered to the client of the league, nil keeps the built in
+// catalogue when no market catalogue is set. Fails when the catalogue can not be read.
+func (s *ProcessKeyService) marketSelection(ctx context.Context, leagueID, competitionID string) (*marketCatalogues.Selection, error) {
+
+	if s.marketCatalogue == nil {
+		return nil, nil
+	}
+
+	clientID := ""
+	if s.leaguesMysql != nil && leagueID != "" {
+		l, err := s.leaguesMysql.GetLeagueByID(ctx, leagueID)
+		if err != nil {
+			log.Printf("Err : %v failed to read league %s", err, leagueID)
+		} else if len(l) > 0 {
+			clientID = l[0].ClientID
+		}
+	}
+
+	sel, err := s.marketCatalogue.Selection(ctx, competitionID, clientID, s.marketLocale)
+	if err != nil {
+		return nil, fmt.Errorf("Err : %v failed to read the market catalogue", err)
+	}
+
+	return sel, nil
+}
+
+// validateOdds : issues found on the markets of a match, logged against its odds key.
+func (s *ProcessKeyService) validateOdds(ctx context.Context, oddsKey string, mkts []oddsFiles.FinalMarkets) []matchChecks.Issue {
+
+	if s.oddsValidator == nil {
+		return nil
+	}
+
+	issues := s.oddsValidator.Validate(ctx, mkts)
+	for _, x := range issues {
+		log.Printf("Odds of %s rejected | %s : %s", oddsKey, x.Code, x.Detail)
+	}
+
+	return issues
+}
+
+// swapMatch : replaces a match whose odds were rejected by another key of the same score
+// category so that the goal distribution of the week is kept, the key swapped in is
+// returned still claimed. Rejected candidates are dropped from the category.
+func (s *ProcessKeyService) swapMatch(ctx context.Context, oddsSortedSet string, fd oddsFiles.CheckKeys, markets *marketCatalogues.Selection, sc reusePolicies.Scope, r *seedCommitments.Rand) (claim, []oddsFiles.FinalMarkets, oddsFiles.FinalScores, []oddsFiles.FinalLiveScores, error) {
+
+	var wo oddsFiles.RawWinningOutcomes
+	err := json.Unmarshal([]byte(fd.ValidateKeys.Wo), &wo)
+	if err != nil {
+		return claim{}, nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("Err : %v failed to read the score of %s", err, fd.OddsKey)
+	}
+
+	homeGoals, errH := strconv.Atoi(wo.HomeScore)
+	awayGoals, errA := strconv.Atoi(wo.AwayScore)
+	if errH != nil || errA != nil {
+		return claim{}, nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("score %s-%s of %s not valid", wo.HomeScore, wo.AwayScore, fd.OddsKey)
+	}
+
+	category := goalCategories.Key(oddsSortedSet, goalCategories.Category(homeGoals, awayGoals))
+	sortedSetName := goalCategories.ClaimKey(category)
+
+	// Candidates are claimed one at a time so that no round being built is handed them too.
+	tries := 0
+	for tries < s.maxSwaps {
+
+		key, err := s.claimAllowed(ctx, sortedSetName, sc, r)
+		if err != nil {
+			return claim{}, nil, oddsFiles.FinalScores{}, nil, err
+		}
+
+		if key == "" {
+			break
+		}
+
+		// The rejected key is not swapped for itself.
+		if key == fd.OddsKey {
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+		tries++
+
+		parentID := strings.Split(key, "O:") // example tzO:31475633 or keO:31475634
+		if len(parentID) != 2 {
+			log.Printf("Data saved in bad format : %s", key)
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+
+		oddsData, err := s.redisConn.Get(ctx, key)
+		if err != nil {
+			log.Printf("Err : %v failed to get match odds from redis ", err)
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+
+		woData, err := s.redisConn.Get(ctx, fmt.Sprintf("%s%s%s", parentID[0], "Wo:", parentID[1]))
+		if err != nil {
+			log.Printf("Err : %v failed to get match winning outcomes from redis ", err)
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+
+		lsData, err := s.redisConn.Get(ctx, fmt.Sprintf("%s%s%s", parentID[0], "Ls:", parentID[1]))
+		if err != nil {
+			log.Printf("Err : %v failed to get live score from redis ", err)
+		}
+
+		mtk, winningOutcomes, liveScores, err := s.formulateOdds(ctx, oddsData, woData, lsData, markets)
+		if err != nil {
+			log.Printf("Err : %v failed to formulate odds ", err)
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+
+		if issues := s.validateOdds(ctx, key, mtk); len(issues) > 0 {
+			s.DropKey(ctx, sortedSetName, key)
+			continue
+		}
+
+		log.Printf("Swapped %s for %s from %s", fd.OddsKey, key, sortedSetName)
+
+		return claim{Set: sortedSetName, Category: category, Key: key}, mtk, winningOutcomes, liveScores, nil
+	}
+
+	return claim{}, nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("no match in %s could replace %s after %d tries", sortedSetName, fd.OddsKey, tries)
+}
+
+// claim : a key held on its claim set for a season week being built, and the category
+// set it was drawn for.
+type claim struct {
+	Set      string
+	Category string
+	Key      string
+}
+
+// useClaims : keeps the keys of a season week out of their sets for good once the week is
+// built and records them as used. The leases are committed together, when one of them ran
+// out none is and every key is released.
+func (s *ProcessKeyService) useClaims(ctx context.Context, oddsSortedSet string, sc reusePolicies.Scope, cc []claim) error {
+
+	leases := []processRedis.Lease{}
+	keys := []string{}
+	for _, c := range cc {
+		leases = append(leases, processRedis.Lease{Set: c.Set, Member: c.Key})
+		keys = append(keys, c.Key)
+	}
+
+	err := s.useKeys(ctx, sc, keys)
+	if err != nil {
+		s.releaseClaims(ctx, cc)
+		return err
+	}
+
+	err = s.redisConn.ZCommitAll(ctx, leases)
+	if err != nil {
+		s.releaseUsages(ctx, sc, keys)
+		s.releaseClaims(ctx, cc)
+		return err
+	}
+
+	for _, c := range cc {
+
+		parentID := strings.Split(c.Key, "O:") // example tzO:31475633 or keO:31475634
+		if len(parentID) != 2 {
+			return fmt.Errorf("Data saved in bad format : %s", c.Key)
+		}
+
+		err = s.claimKey(ctx, oddsSortedSet, c.Category, c.Key, parentID[0], parentID[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseClaims : puts the keys of a season week that was not built back in their sets.
+func (s *ProcessKeyService) releaseClaims(ctx context.Context, cc []claim) {
+	for _, c := range cc {
+		s.ReleaseKey(ctx, c.Set, c.Key)
+	}
+}
+
+// claimKey : records a key of a built season week as used and drops it from the sanitized
+// sets.
+func (s *ProcessKeyService) claimKey(ctx context.Context, oddsSortedSet, category, key, country, parentMatchID string) error {
+
+	ss, err := s.usedMatchMysql.GetMatchDetails(ctx, category, key)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to return match details of key %s category %s", err, key, category)
+	}
+
+	for _, r := range ss {
+
+		aa, err := usedMatches.NewUsedMatches(r.Country, r.ProjectID, r.MatchID, r.Category)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to instantiate UsedMatches", err)
+		}
+
+		inserted, err := s.usedMatchMysql.Save(ctx, *aa)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to save usedMatch", err)
+		}
+
+		log.Printf("Last inserted usedMatchID %d", inserted)
+	}
+
+	matchDate := time.Now().Format("2006-01-02")
+	cm, err := checkMatches.NewCheckMatches(country, parentMatchID, matchDate)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to instantiate checkMatches struct", err)
+	}
+
+	lastID, err := s.checkMatchesMysql.Save(ctx, *cm)
+	if err != nil {
+		return fmt.Errorf("Err : %v failed to save into checkMatches table", err)
+	}
+
+	log.Printf("Last inserted id %d into checkMatches tbl", lastID)
+
+	for _, set := range goalCategories.Sets(oddsSortedSet) {
+		_, err = s.redisConn.ZRem(ctx, set, key)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to delete from %s z range", err, set)
+		}
+	}
+
+	return nil
+}
+
+// recordBuiltWeek : lists w under every source match it was published from, so that
+// save_keys takes the week back when a revision of one of them lands before it starts.
+func (s *ProcessKeyService) recordBuiltWeek(ctx context.Context, sources []string, w oddsFiles.BuiltWeek) {
+
+	for _, source := range sources {
+
+		key := oddsFiles.BuiltWeeksKey(source)
+
+		ww := []oddsFiles.BuiltWeek{}
+		saved, err := s.redisConn.Get(ctx, key)
+		if err == nil {
+			err = json.Unmarshal([]byte(saved), &ww)
+			if err != nil {
+				log.Printf("Err : %v failed to read %s", err, key)
+			}
+		}
+		ww = append(ww, w)
+
+		data, err := json.Marshal(ww)
+		if err != nil {
+			log.Printf("Err: %v failed to marshall built weeks json", err)
+			continue
+		}
+
+		expiry := "108000"
+		err = s.redisConn.SetWithExpiry(ctx, key, string(data), expiry)
+		if err != nil {
+			log.Printf("Err: %v failed to save %s", err, key)
+		}
+	}
+}
+
+// checkSettlement : provider winning outcomes of a match the settlement engine does not agree
+// with, and markets published for it that were not settled.
+func (s *ProcessKeyService) checkSettlement(ctx context.Context, seasonWeekID, matchID string, mkts []oddsFiles.FinalMarkets, fs oddsFiles.FinalScores, ls []oddsFiles.FinalLiveScores) []settlements.Discrepancy {
+
+	if s.settlements == nil {
+		return nil
+	}
+
+	m, err := settlements.NewMatch(fs.HomeScore, fs.AwayScore, ls)
+	if err != nil {
+		log.Printf("Err : %v unable to settle match %s of season week %s", err, matchID, seasonWeekID)
+		return []settlements.Discrepancy{{Detail: err.Error()}}
+	}
+
+	wos := []oddsFiles.RawWOs{}
+	for _, w := range fs.FinalWinningOutcomes {
+		wos = append(wos, oddsFiles.RawWOs{
+			SubTypeID:   w.SubTypeID,
+			OutcomeID:   w.OutcomeID,
+			OutcomeName: w.OutcomeName,
+			Result:      w.Result,
+		})
+	}
+
+	offered := []string{}
+	for _, mk := range mkts {
+		offered = append(offered, mk.Code)
+	}
+
+	dd := s.settlements.Compare(ctx, *m, wos, offered)
+	for _, d := range dd {
+		log.Printf("Settlement discrepancy season week %s match %s | %s", seasonWeekID, matchID, d.Detail)
+	}
+
+	return dd
+}
+
+// saveDiscrepancies : settlement discrepancies of a season week kept for review.
+func (s *ProcessKeyService) saveDiscrepancies(ctx context.Context, apiDate, seasonWeekID string, discrepancies map[string][]settlements.Discrepancy) {
+
+	keyNameST := fmt.Sprintf("%s_%s_%s", "pr_settlement", apiDate, seasonWeekID)
+	log.Printf(">>> Season week %s has %d matches the provider settled differently, saved in %s",
+		seasonWeekID, len(discrepancies), keyNameST)
+
+	discrepanciesData, err := json.Marshal(discrepancies)
+	if err != nil {
+		log.Printf("Err: %v failed to marshall settlement json", err)
+		return
+	}
+
+	expiry := "108000"
+	err = s.redisConn.SetWithExpiry(ctx, keyNameST, string(discrepanciesData), expiry)
+	if err != nil {
+		log.Printf("Err: %v failed to save settlement discrepancies", err)
+	}
+}
+
+// FormulateMatch : the markets, winning outcomes and live scores a match is published with,
+// priced the way every season week is. Used by the replay to rebuild production output.
+func (s *ProcessKeyService) FormulateMatch(ctx context.Context, competitionID, oddsPayload, woPayload, lsPayload string) ([]oddsFiles.FinalMarkets, oddsFiles.FinalScores, []oddsFiles.FinalLiveScores, error) {
+	markets, err := s.marketSelection(ctx, "", competitionID)
+	if err != nil {
+		return nil, oddsFiles.FinalScores{}, nil, err
+	}
+	return s.formulateOdds(ctx, oddsPayload, woPayload, lsPayload, markets)
+}
+
+// formulateOdds : odds repriced by the margin engine when one is set, a random flat
+// factor is taken off every price otherwise.
+func (s *ProcessKeyService) formulateOdds(ctx context.Context, oddsPayload, woPayload, lsPayload string, markets *marketCatalogues.Selection) ([]oddsFiles.FinalMarkets, oddsFiles.FinalScores, []oddsFiles.FinalLiveScores, error) {
+
+	if s.margins != nil {
+		mts, err := processOdds.NewWithMargins(oddsPayload, woPayload, lsPayload, s.margins)
+		if err != nil {
+			return nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("Err : %v failed to initialize odds", err)
+		}
+		mts.SetMarkets(markets)
+		mts.SetDerivedMarkets(s.derivedMarkets)
+		return mts.FormulateOdds(ctx)
+	}
+
+	oddsFactor := 0.01
+	mts, err := processOdds.New(oddsPayload, woPayload, lsPayload, oddsFactor)
+	if err != nil {
+		return nil, oddsFiles.FinalScores{}, nil, fmt.Errorf("Err : %v failed to initialize odds", err)
+	}
+	mts.SetMarkets(markets)
+	mts.SetDerivedMarkets(s.derivedMarkets)
+	mts.SetRng(s.rng)
+	return mts.FormulateOdds2(ctx)
+}
+
 // ReturnParentMatchIDs : returns all parent match ids in batches
 func (s *ProcessKeyService) ReturnZRangeData(ctx context.Context, zSetKey string, fetched int) ([]string, error) {
 	data, err := s.redisConn.GetZRangeWithLimit(ctx, zSetKey, fetched)
@@ -202,7 +750,31 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 
 		n := 0
 
-		matchMap, err := s.Validate(ctx, x.LeagueID, oddsSortedSet, distr, x.CompetitionID)
+		markets, err := s.marketSelection(ctx, x.LeagueID, x.CompetitionID)
+		if err != nil {
+			log.Printf("Err : %v season week %s is not built", err, x.SeasonWeekID)
+			continue
+		}
+
+		// Matches are drawn from a seed committed to before the season week starts, the
+		// season week is the public nonce. The sets they are drawn from are committed to
+		// with it.
+		sets, err := claimSets(oddsSortedSet, distr)
+		if err != nil {
+			log.Printf("Err : %v season week %s is not built", err, x.SeasonWeekID)
+			continue
+		}
+
+		fs, err := s.commitSeed(ctx, seedCommitments.SeasonWeek, x.SeasonWeekID, sets...)
+		if err != nil {
+			log.Printf("Err : %v failed to commit the seed of season week %s", err, x.SeasonWeekID)
+			continue
+		}
+		r := fs.Rand(x.SeasonWeekID)
+
+		sc := reusePolicies.Scope{Consumer: reusePolicies.Production, SeasonID: x.SeasonID, SeasonWeekID: x.SeasonWeekID}
+
+		matchMap, claims, err := s.Validate(ctx, x.LeagueID, oddsSortedSet, distr, x.CompetitionID, sc, r)
 		if err != nil {
 			log.Printf("Err : %v unable to create season week >>>>> ", err)
 		} else {
@@ -217,6 +789,10 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 			hh := oddsFiles.FinalSeasonWeek{}
 			wo := oddsFiles.FinalSeasonWeekWO{}
 			lsc := oddsFiles.FinalSeasonWeekLS{}
+			discrepancies := make(map[string][]settlements.Discrepancy)
+			weekFailed := false
+			sources := []string{}
+			matchIDs := []string{}
 
 			hh.SeasonWeeKID = x.SeasonWeekID
 			hh.StartTime = x.StartTime
@@ -283,23 +859,41 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 					AwayTeam:  awayTeamName,
 				}
 
-				fd := matchMap[n]
+				fd := matchMap[n]
+				source := fd.OddsKey
+
+				log.Println("odds ---> ", len(fd.ValidateKeys.Odds))
+				mtk, winningOutcomes, liveScores, err := s.formulateOdds(ctx, fd.ValidateKeys.Odds, fd.ValidateKeys.Wo, fd.ValidateKeys.Ls, markets)
+				if err != nil {
+					log.Printf("Err : %v failed to formulate odds of %s", err, fd.OddsKey)
+				}
+
+				// A key whose odds cannot be formulated is rejected like one whose odds fail validation.
+				if err != nil || len(s.validateOdds(ctx, fd.OddsKey, mtk)) > 0 {
+
+					// The rejected key is kept out of its category whether it is replaced or not.
+					s.DropKey(ctx, claims[n].Set, claims[n].Key)
 
-				log.Println("odds ---> ", len(fd.ValidateKeys.Odds))
-				oddsFactor := 0.01
-				mts, err := processOdds.New(fd.ValidateKeys.Odds, fd.ValidateKeys.Wo, fd.ValidateKeys.Ls, oddsFactor)
-				if err != nil {
-					log.Printf("Err : %v failed to initialize odds ", err)
+					var swapped claim
+					swapped, mtk, winningOutcomes, liveScores, err = s.swapMatch(ctx, oddsSortedSet, fd, markets, sc, r)
+					if err != nil {
+						log.Printf("Err : %v season week %s failed", err, x.SeasonWeekID)
+						claims = append(claims[:n], claims[n+1:]...)
+						weekFailed = true
+						break
+					}
+					claims[n] = swapped
+					source = swapped.Key
 				}
 
-				mtk, winningOutcomes, liveScores, err := mts.FormulateOdds2(ctx) // FormulateOdds(ctx)
-				if err != nil {
-					log.Printf("Err : %v failed to formulate odds ", err)
+				if dd := s.checkSettlement(ctx, x.SeasonWeekID, g.MatchID, mtk, winningOutcomes, liveScores); len(dd) > 0 {
+					discrepancies[g.MatchID] = dd
 				}
 
 				matches.FinalMarkets = mtk
 				woMatches.FinalScore = winningOutcomes
 				lsMatches.FinalLiveScores = liveScores
+				sources = append(sources, source)
 
 				log.Printf("oddsKey :::--> %s ", fd.OddsKey)
 				log.Println("liveScores ---> ", liveScores)
@@ -309,29 +903,50 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 				hh.FinalMatches = append(hh.FinalMatches, matches)
 				wo.FinalMatchesWO = append(wo.FinalMatchesWO, woMatches)
 				lsc.FinalMatchesLS = append(lsc.FinalMatchesLS, lsMatches)
+				matchIDs = append(matchIDs, g.MatchID)
+			}
+
+			sTime, err := time.Parse("2006-01-02 15:04:05", x.StartTime)
+			if err != nil {
+				log.Printf("Err : %v failed to convert string to time", err)
+			}
 
-				// Start working on winning outcomes
+			// Results the settlement engine does not agree with are never published.
+			if len(discrepancies) > 0 {
+				s.saveDiscrepancies(ctx, sTime.Format("2006-01-02"), x.SeasonWeekID, discrepancies)
+				weekFailed = true
+			}
+
+			// Keys are only used up once the week is sure to be published, a failed week
+			// hands them back to the weeks built after it.
+			if weekFailed {
+				s.releaseClaims(ctx, claims)
+			} else {
+				err = s.useClaims(ctx, oddsSortedSet, sc, claims)
+				if err != nil {
+					log.Printf("Err : %v season week %s failed", err, x.SeasonWeekID)
+					weekFailed = true
+				}
+			}
 
-				// Update this match as processed
+			if weekFailed {
 
-				status := "active"
-				updated, err := s.matchesMysql.UpdateGameStatus(ctx, status, g.MatchID)
+				// Nothing is published, the week needs a look before it is set back to inactive.
+				status := "failed"
+				updated, err := s.seasonWeekMysql.UpdateSsnWeekStatus(ctx, x.SeasonWeekID, x.SeasonID, status)
 				if err != nil {
-					log.Printf("Err : %v failed to update processed season week", err)
+					log.Printf("Err : %v failed to update failed season week", err)
 				}
 
 				log.Printf("updated record : %d", updated)
+				continue
 			}
 
 			// Save Match odds into redis for further use
 
-			sTime, err := time.Parse("2006-01-02 15:04:05", x.StartTime)
-			if err != nil {
-				log.Printf("Err : %v failed to convert string to time", err)
-			}
-
 			keyName := fmt.Sprintf("%s_%s_%s", "pr_odds", sTime.Format("2006-01-02"), x.SeasonWeekID)
 			log.Printf(">>> Odds key saved >>>> %s", keyName)
+			published := []string{keyName}
 
 			oddsData, err := json.Marshal(hh)
 			if err != nil {
@@ -345,8 +960,17 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 				}
 			}
 
+			if s.oddsProfile != nil {
+				clientKeys, err := s.oddsProfile.Publish(ctx, sTime.Format("2006-01-02"), x.SeasonWeekID, hh)
+				if err != nil {
+					log.Printf("Err: %v failed to save client odds", err)
+				}
+				published = append(published, clientKeys...)
+			}
+
 			keyNameWO := fmt.Sprintf("%s_%s_%s", "pr_wo", sTime.Format("2006-01-02"), x.SeasonWeekID)
 			log.Printf(">>> WinningOutcome key saved >>>> %s", keyNameWO)
+			published = append(published, keyNameWO)
 
 			winningOutcomesData, err := json.Marshal(wo)
 			if err != nil {
@@ -360,8 +984,11 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 				}
 			}
 
+			s.saveResults(ctx, wo)
+
 			keyNameLS := fmt.Sprintf("%s_%s_%s", "pr_ls", sTime.Format("2006-01-02"), x.SeasonWeekID)
 			log.Printf(">>> LiveScore key saved >>>> %s", keyNameLS)
+			published = append(published, keyNameLS)
 
 			liveScoresData, err := json.Marshal(lsc)
 			if err != nil {
@@ -375,6 +1002,9 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 				}
 			}
 
+			s.recordBuiltWeek(ctx, sources, oddsFiles.BuiltWeek{SeasonID: x.SeasonID, SeasonWeekID: x.SeasonWeekID,
+				StartTime: x.StartTime, Keys: published})
+
 			daysListKeys := fmt.Sprintf("%s_%s", "pr_keys", sTime.Format("2006-01-02"))
 			daysListValues := fmt.Sprintf("%s_%s_%s", "pr_keys", sTime.Format("2006-01-02"), x.SeasonWeekID)
 
@@ -383,13 +1013,25 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 				log.Printf("Err: %v failed to save into todays list", err)
 			}
 
+			// Matches are only set active once their week is published.
 			status := "active"
+			for _, matchID := range matchIDs {
+				updated, err := s.matchesMysql.UpdateGameStatus(ctx, status, matchID)
+				if err != nil {
+					log.Printf("Err : %v failed to update processed match %s", err, matchID)
+				}
+
+				log.Printf("updated record : %d", updated)
+			}
+
 			updated, err := s.seasonWeekMysql.UpdateSsnWeekStatus(ctx, x.SeasonWeekID, x.SeasonID, status)
 			if err != nil {
 				log.Printf("Err : %v failed to update processed season week", err)
 			}
 
 			log.Printf("updated record : %d", updated)
+
+			s.revealSeed(ctx, fs, x.EndTime)
 		}
 	}
 
@@ -427,31 +1069,9 @@ func (s *ProcessKeyService) GetUpcomingSeasonWeeks(ctx context.Context, oddsSort
 
 func (s *ProcessKeyService) AvailableMatches(ctx context.Context) (map[string][]goals.Goals, error) {
 
-	categories := []string{
-		"SANITIZED_ODDS_0",
-		"SANITIZED_ODDS_1_a",
-		"SANITIZED_ODDS_1_h",
-		"SANITIZED_ODDS_2_gg_d",
-		"SANITIZED_ODDS_2_ng_a",
-		"SANITIZED_ODDS_2_ng_h",
-		"SANITIZED_ODDS_3_gg_a",
-		"SANITIZED_ODDS_3_gg_h",
-		"SANITIZED_ODDS_3_ng_a",
-		"SANITIZED_ODDS_3_ng_h",
-		"SANITIZED_ODDS_4_gg_a",
-		"SANITIZED_ODDS_4_gg_h",
-		"SANITIZED_ODDS_4_ng_a",
-		"SANITIZED_ODDS_4_ng_h",
-		"SANITIZED_ODDS_4_gg_d",
-		"SANITIZED_ODDS_5_gg_a",
-		"SANITIZED_ODDS_5_gg_h",
-		"SANITIZED_ODDS_5_ng_a",
-		"SANITIZED_ODDS_5_ng_h",
-		"SANITIZED_ODDS_6_gg_a",
-		"SANITIZED_ODDS_6_gg_d",
-		"SANITIZED_ODDS_6_gg_h",
-		"SANITIZED_ODDS_6_ng_a",
-		"SANITIZED_ODDS_6_ng_h",
+	categories := []string{}
+	for _, c := range goalCategories.Finals() {
+		categories = append(categories, goalCategories.Key(goalCategories.DefaultSet, c))
 	}
 
 	m := make(map[string][]goals.Goals)
@@ -835,7 +1455,9 @@ func (s *ProcessKeyService) TeamInfo(leagueID string, teamID string) (string, st
 	}
 }
 
-func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSet string, distr []mrs.Mrs, competitionID string) (map[int]oddsFiles.CheckKeys, error) {
+// Validate : the keys of a season week read from redis, in the order of their claims. The
+// claims are returned held, they are all released when the week can not be made up.
+func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSet string, distr []mrs.Mrs, competitionID string, sc reusePolicies.Scope, r *seedCommitments.Rand) (map[int]oddsFiles.CheckKeys, []claim, error) {
 
 	m := make(map[int]oddsFiles.CheckKeys)
 
@@ -852,23 +1474,31 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 	// Get the games ration for over TG25
 
 	keysList := []oddsFiles.CheckKeys{}
-	data, err := s.DecideRatio(ctx, oddsSortedSet, fetched, distr, competitionID)
+	data, err := s.DecideRatio(ctx, oddsSortedSet, fetched, distr, competitionID, sc, r)
 	if err != nil {
-		return m, fmt.Errorf("err : %v failed to read from %s z range", err, oddsSortedSet)
+		return m, nil, fmt.Errorf("err : %v failed to read from %s z range", err, oddsSortedSet)
 	}
 
+	built := false
+	defer func() {
+		if !built {
+			s.releaseClaims(ctx, data)
+		}
+	}()
+
 	if len(data) < 10 && fetched == 10 {
-		return m, fmt.Errorf("*** There are no enough matches ready to create a seen week *** count **** %d", len(data))
+		return m, nil, fmt.Errorf("*** There are no enough matches ready to create a seen week *** count **** %d", len(data))
 	}
 
 	if len(data) < 9 && fetched == 9 {
-		return m, fmt.Errorf("*** There are no enough matches ready to create a seen week *** count **** %d", len(data))
+		return m, nil, fmt.Errorf("*** There are no enough matches ready to create a seen week *** count **** %d", len(data))
 	}
 
 	num := 0
 
-	for _, o := range data {
+	for _, c := range data {
 
+		o := c.Key
 		log.Printf(">>>>>> data fetched >>>>> %s", o)
 
 		if num < fetched {
@@ -883,7 +1513,7 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 
 				status, count, message, err := s.checkMatchesMysql.MatchExist(ctx, parentID[0], parentID[1])
 				if err != nil {
-					return m, fmt.Errorf("Err : %v on checking if match exists ", err)
+					return m, nil, fmt.Errorf("Err : %v on checking if match exists ", err)
 				}
 
 				log.Printf("status : %t, count : %d, message : %s", status, count, message)
@@ -893,7 +1523,7 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 				log.Printf("oddKey --> %s", o)
 				oddsData, err := s.redisConn.Get(ctx, o)
 				if err != nil {
-					return m, fmt.Errorf("Err : %v failed to get match odds from redis ", err)
+					return m, nil, fmt.Errorf("Err : %v failed to get match odds from redis ", err)
 				}
 
 				// 2. Find Winning outcomes
@@ -902,7 +1532,7 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 				log.Printf("woKey --> %s", woKey)
 				woData, err := s.redisConn.Get(ctx, woKey)
 				if err != nil {
-					return m, fmt.Errorf("Err : %v failed to get match winning outcomes from redis ", err)
+					return m, nil, fmt.Errorf("Err : %v failed to get match winning outcomes from redis ", err)
 				}
 
 				// 3. Find Live scores
@@ -931,117 +1561,18 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 
 				} else {
 					// Delete this record and retry again
-					return m, fmt.Errorf("Skip this record oddKey : %s wo %s ls %s", o, woKey, lsKey)
-				}
-
-				// Save this match as used to avoid repetition in the coming days.
-
-				matchDate := time.Now().Format("2006-01-02")
-				cm, err := checkMatches.NewCheckMatches(parentID[0], parentID[1], matchDate)
-				if err != nil {
-					return m, fmt.Errorf("Err : %v failed to instantiate checkMatches struct", err)
-				}
-
-				lastID, err := s.checkMatchesMysql.Save(ctx, *cm)
-				if err != nil {
-					return m, fmt.Errorf("Err : %v failed to save into checkMatches table", err)
-				}
-
-				log.Printf("Last inserted id %d into checkMatches tbl", lastID)
-
-				_, err = s.redisConn.ZRem(ctx, oddsSortedSet, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, oddsSortedSet)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, oddsSortedSet)
-				}
-
-				// Remove from the Over and Under 2.5 sets
-
-				tgOver25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGO25")
-				tgUnder25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGU25")
-				tgUnder15 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGU15")
-				tgUnder0 := fmt.Sprintf("%s_%s", oddsSortedSet, "0")
-				tgUnder1 := fmt.Sprintf("%s_%s", oddsSortedSet, "1")
-				tgUnder2 := fmt.Sprintf("%s_%s", oddsSortedSet, "2")
-				tgUnder3 := fmt.Sprintf("%s_%s", oddsSortedSet, "3")
-				tgUnder4 := fmt.Sprintf("%s_%s", oddsSortedSet, "4")
-				tgUnder5 := fmt.Sprintf("%s_%s", oddsSortedSet, "5")
-				tgUnder6 := fmt.Sprintf("%s_%s", oddsSortedSet, "6")
-				tgUnder7 := fmt.Sprintf("%s_%s", oddsSortedSet, "7")
-
-				_, err = s.redisConn.ZRem(ctx, tgOver25, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgOver25)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgOver25)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder15, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder15)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder15)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder25, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder25)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder25)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder0, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder0)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder0)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder1, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder1)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder1)
+					return m, nil, fmt.Errorf("Skip this record oddKey : %s wo %s ls %s", o, woKey, lsKey)
 				}
 
-				_, err = s.redisConn.ZRem(ctx, tgUnder2, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder2)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder2)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder3, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder3)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder3)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder4, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder4)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder4)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder5, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder5)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder5)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder6, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder6)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder6)
-				}
-
-				_, err = s.redisConn.ZRem(ctx, tgUnder7, o)
-				if err != nil {
-					log.Printf("Err : %v failed to delete from %s z range", err, tgUnder7)
-					return m, fmt.Errorf("Err : %v failed to delete from %s z range", err, tgUnder7)
-				}
+				// The match is saved as used with the rest of its week once it is built.
 
 			} else {
-				return m, fmt.Errorf("Data saved in bad format : %s", parentID)
+				return m, nil, fmt.Errorf("Data saved in bad format : %s", parentID)
 			}
 
 		} else {
 
-			//return m, fmt.Errorf("skip %s from adding this game as the season week is full ", o)
+			//return m, nil, fmt.Errorf("skip %s from adding this game as the season week is full ", o)
 			log.Printf("skip %s from adding this game as the season week is full ", o)
 		}
 
@@ -1057,11 +1588,15 @@ func (s *ProcessKeyService) Validate(ctx context.Context, leagueID, oddsSortedSe
 			d++
 		}
 
-		return m, nil
+		// Keys past a full season week are not shown.
+		s.releaseClaims(ctx, data[fetched:])
+
+		built = true
+		return m, data[:fetched], nil
 
 	}
 
-	return m, fmt.Errorf("final match count : %d not enough to create season week", len(data))
+	return m, nil, fmt.Errorf("final match count : %d not enough to create season week", len(data))
 }
 
 // TotalGoalsPerSession : this helps with distribution of total goals per match session.
@@ -1079,8 +1614,8 @@ func (s *ProcessKeyService) OddsCombination(ctx context.Context) []int {
 	return data
 }
 
-func (s *ProcessKeyService) DecideRatio(ctx context.Context, oddsSortedSet string, totalGames int, distr []mrs.Mrs, competitionID string) ([]string, error) {
-	list := []string{}
+func (s *ProcessKeyService) DecideRatio(ctx context.Context, oddsSortedSet string, totalGames int, distr []mrs.Mrs, competitionID string, sc reusePolicies.Scope, r *seedCommitments.Rand) ([]claim, error) {
+	list := []claim{}
 
 	type MatchDetails struct {
 		Home     int
@@ -1096,141 +1631,20 @@ func (s *ProcessKeyService) DecideRatio(ctx context.Context, oddsSortedSet strin
 
 		log.Printf(">>>>> Raw string >>>>> %s", d.RawScores)
 
-		scores := strings.Split(d.RawScores, "**")
-		// raw_scores: 52824375#1#2**52824376#1#1**52824377#2#1**52824378#2#2**52824379#1#0**52824380#0#1**52824381#2#0**52824382#1#1**52824383#3#1**52824384#1#1
-
-		for _, cc := range scores {
-
-			goals := strings.Split(cc, "#")
-
-			if len(goals) == 3 {
-
-				hSc := goals[1]
-				aSc := goals[2]
-
-				log.Printf("**** HomeScore %s | Away Score %s ****", hSc, aSc)
-
-				homeGoals, err := strconv.Atoi(hSc)
-				if err != nil {
-					return list, fmt.Errorf("err : %v failed to convert hsc to int", err)
-				}
-
-				awayGoals, err := strconv.Atoi(aSc)
-				if err != nil {
-					return list, fmt.Errorf("err : %v failed to convert asc to int", err)
-				}
-
-				totalGoals := homeGoals + awayGoals
-
-				if totalGoals == 0 {
-
-					sel0 := fmt.Sprintf("%s_%d", oddsSortedSet, 0)
-					m[x] = MatchDetails{
-						Home:     homeGoals,
-						Away:     awayGoals,
-						Total:    totalGoals,
-						Category: sel0,
-					}
-
-				} else if totalGoals == 1 {
-
-					if homeGoals == 1 {
-
-						sel1h := fmt.Sprintf("%s_%s_%s", oddsSortedSet, "1", "h")
-						m[x] = MatchDetails{
-							Home:     homeGoals,
-							Away:     awayGoals,
-							Total:    totalGoals,
-							Category: sel1h,
-						}
-
-					} else {
-
-						sel1a := fmt.Sprintf("%s_%s_%s", oddsSortedSet, "1", "a")
-						m[x] = MatchDetails{
-							Home:     homeGoals,
-							Away:     awayGoals,
-							Total:    totalGoals,
-							Category: sel1a,
-						}
-
-					}
-
-				} else {
-
-					if homeGoals > 0 && awayGoals > 0 {
-
-						// Query from Goal Goal batch
-
-						if homeGoals > awayGoals {
-
-							selggh := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "h")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selggh,
-							}
-
-						} else if awayGoals > homeGoals {
-
-							selgga := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "a")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selgga,
-							}
-
-						} else {
-
-							selggd := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "gg", "d")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selggd,
-							}
-
-						}
-
-					} else {
-
-						// Query from No goal
-
-						if homeGoals > awayGoals {
-
-							selngh := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "h")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selngh,
-							}
-
-						} else if awayGoals > homeGoals {
-
-							selnga := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "a")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selnga,
-							}
+		scores, err := goalCategories.ParseRawScores(d.RawScores)
+		if err != nil {
+			return nil, err
+		}
 
-						} else {
+		for _, sc := range scores {
 
-							selngd := fmt.Sprintf("%s_%d_%s_%s", oddsSortedSet, totalGoals, "ng", "d")
-							m[x] = MatchDetails{
-								Home:     homeGoals,
-								Away:     awayGoals,
-								Total:    totalGoals,
-								Category: selngd,
-							}
+			log.Printf("**** HomeScore %d | Away Score %d ****", sc.Home, sc.Away)
 
-						}
-					}
-				}
+			m[x] = MatchDetails{
+				Home:     sc.Home,
+				Away:     sc.Away,
+				Total:    sc.Home + sc.Away,
+				Category: goalCategories.Key(oddsSortedSet, goalCategories.Category(sc.Home, sc.Away)),
 			}
 
 			x++
@@ -1243,65 +1657,47 @@ func (s *ProcessKeyService) DecideRatio(ctx context.Context, oddsSortedSet strin
 		log.Printf("Generated map b.Category : %s, b.Home :%d, b.Away :%d, b.Total %d >> ", b.Category, b.Home, b.Away, b.Total)
 	}
 
-	for _, dd := range m {
+	// Scores are drawn for in order so that the draws can be worked out again.
+	for k := 1; k < x; k++ {
 
-		sortedSetName := fmt.Sprintf("%s_%s", "CL", dd.Category)
+		dd := m[k]
+		sortedSetName := goalCategories.ClaimKey(dd.Category)
 
-		data, err := s.redisConn.GetZRangeWithLimit(ctx, sortedSetName, 15)
+		// Claimed in one step so that no other request is handed the same match, the
+		// claims are held until the week is built.
+		selectedMatchID, err := s.claimAllowed(ctx, sortedSetName, sc, r)
 		if err != nil {
-			return list, fmt.Errorf("err : %v failed to read from %s z range", err, sortedSetName)
+			s.releaseClaims(ctx, list)
+			return nil, err
 		}
 
-		categoryCount := len(data)
+		if selectedMatchID == "" {
 
-		if categoryCount > 0 {
-
-			log.Println("*** sel category *** ", sortedSetName, " data returned ", data)
-			if len(data) > 0 {
-
-				// shuffle data
-
-				categoryIndex := rand.IntN(categoryCount)
-				selectedMatchID := data[categoryIndex]
-
-				// Query this record from db so we know which record save as used
-
-				ss, err := s.usedMatchMysql.GetMatchDetails(ctx, dd.Category, selectedMatchID)
-				if err != nil {
-					return list, fmt.Errorf("err : %v failed to return match details of key %s category %s",
-						err, selectedMatchID, dd.Category)
-				}
-
-				for _, r := range ss {
-
-					log.Printf("category : %s | GoalID %s | MatchID %s", r.Category, r.GoalID, r.MatchID)
-
-					// Add value into used table
-
-					aa, err := usedMatches.NewUsedMatches(r.Country, r.ProjectID, r.MatchID, r.Category)
-					if err != nil {
-						return list, fmt.Errorf("err : %v failed to instantiate UsedMatches", err)
-					}
-
-					inserted, err := s.usedMatchMysql.Save(ctx, *aa)
-					if err != nil {
-						return list, fmt.Errorf("err : %v failed to save usedMatch", err)
-					} else {
-						log.Printf("Last inserted usedMatchID %d", inserted)
+			log.Printf("Key ::::: %s does not have enough data ", sortedSetName)
 
-						list = append(list, selectedMatchID)
-						s.RemoveUsedKeys(ctx, sortedSetName, selectedMatchID)
-					}
+			s.releaseClaims(ctx, list)
+			return nil, fmt.Errorf("%s does not have enough data", sortedSetName)
+		}
 
-				}
+		log.Printf("*** sel category *** %s claimed %s", sortedSetName, selectedMatchID)
 
-			}
-		} else {
+		// Query this record from db so we know which record save as used
 
-			log.Printf("Key ::::: %s does not have enough data ", sortedSetName)
+		ss, err := s.usedMatchMysql.GetMatchDetails(ctx, dd.Category, selectedMatchID)
+		if err != nil {
+			s.ReleaseKey(ctx, sortedSetName, selectedMatchID)
+			s.releaseClaims(ctx, list)
+			return nil, fmt.Errorf("err : %v failed to return match details of key %s category %s",
+				err, selectedMatchID, dd.Category)
+		}
 
-			return list, fmt.Errorf("err : %v does not have enough data %s", err, sortedSetName)
+		if len(ss) == 0 {
+			log.Printf("No details of key %s category %s, left in %s", selectedMatchID, dd.Category, sortedSetName)
+			s.ReleaseKey(ctx, sortedSetName, selectedMatchID)
+			continue
 		}
+
+		list = append(list, claim{Set: sortedSetName, Category: dd.Category, Key: selectedMatchID})
 	}
 
 	//
@@ -1326,18 +1722,123 @@ func (s *ProcessKeyService) RemoveUsedKeys(ctx context.Context, key, value strin
 	}
 }
 
+// DropKey : keeps a claimed key that can not be used out of its sorted set for good.
+func (s *ProcessKeyService) DropKey(ctx context.Context, key, value string) {
+	err := s.redisConn.ZCommit(ctx, key, value)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	} else {
+		log.Printf("###### dropped key %s from zset %s ######", value, key)
+	}
+}
+
+// ReleaseKey : puts a claimed key back in its sorted set when it could not be used.
+func (s *ProcessKeyService) ReleaseKey(ctx context.Context, key, value string) {
+	err := s.redisConn.ZRelease(ctx, key, value)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	} else {
+		log.Printf("###### released key %s back into zset %s ######", value, key)
+	}
+}
+
+// ReapLeases : puts the keys whose lease ran out back in their sets, so that keys held by
+// a daemon that stopped are not kept out until the next claim of their set.
+func (s *ProcessKeyService) ReapLeases(ctx context.Context) {
+	n, err := s.redisConn.ZReap(ctx)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	} else if n > 0 {
+		log.Printf("###### %d keys with a lease that ran out put back ######", n)
+	}
+}
+
+// claimAllowed : claims a key of sortedSetName the reuse policy lets sc show. Keys it
+// blocks are held while the next one is claimed so that they are not drawn again, and
+// are put back for other season weeks once a key is found or maxBlocked were held.
+func (s *ProcessKeyService) claimAllowed(ctx context.Context, sortedSetName string, sc reusePolicies.Scope, r *seedCommitments.Rand) (string, error) {
+
+	held := []string{}
+	defer func() {
+		for _, key := range held {
+			s.ReleaseKey(ctx, sortedSetName, key)
+		}
+	}()
+
+	for {
+
+		var key string
+		var candidates []string
+		var err error
+		if pool, ok := r.Pool(sortedSetName); ok {
+			key, candidates, err = s.redisConn.ZClaimFrom(ctx, sortedSetName, pool, 15, r.Float64(), processRedis.DefaultLease)
+		} else {
+			key, candidates, err = s.redisConn.ZClaim(ctx, sortedSetName, 15, r.Float64(), processRedis.DefaultLease)
+		}
+		if err != nil {
+			return "", fmt.Errorf("err : %v failed to claim from %s z range", err, sortedSetName)
+		}
+		if key != "" {
+			r.PickFrom(candidates, key)
+		}
+
+		if key == "" || s.reusePolicy == nil {
+			return key, nil
+		}
+
+		policy, err := s.reusePolicy.Check(ctx, sc, key)
+		if err != nil {
+			s.ReleaseKey(ctx, sortedSetName, key)
+			return "", fmt.Errorf("err : %v failed to check reuse policy of %s", err, key)
+		}
+
+		if policy == "" {
+			return key, nil
+		}
+
+		log.Printf("%s blocks %s of %s for season week %s", policy, key, sortedSetName, sc.SeasonWeekID)
+
+		held = append(held, key)
+		if len(held) >= s.maxBlocked {
+			return "", fmt.Errorf("reuse policy blocked %d keys of %s in a row", len(held), sortedSetName)
+		}
+	}
+}
+
+// useKeys : records keys as shown in sc for the reuse policy, it fails without recording
+// any of them when one was shown where the policy keeps it from sc since it was checked.
+func (s *ProcessKeyService) useKeys(ctx context.Context, sc reusePolicies.Scope, keys []string) error {
+	if s.reusePolicy == nil {
+		return nil
+	}
+
+	return s.reusePolicy.Use(ctx, sc, keys)
+}
+
+// releaseUsages : drops the usages of keys recorded in sc that were not shown after all.
+func (s *ProcessKeyService) releaseUsages(ctx context.Context, sc reusePolicies.Scope, keys []string) {
+	if s.reusePolicy == nil {
+		return
+	}
+
+	err := s.reusePolicy.Release(ctx, sc, keys)
+	if err != nil {
+		log.Printf("Err : %v", err)
+	}
+}
+
 func (s *ProcessKeyService) DecideRatio3(ctx context.Context, oddsSortedSet string, oddsu15Set string, totalGames int) ([]string, error) {
 
 	list := []string{}
 
 	availableList := 3
-	selCategory := rand.IntN(availableList)
+	selCategory := s.rng.IntN(availableList)
 	log.Printf("selCategory %d", selCategory)
 
 	combinations := s.NewOddsCombination(ctx, selCategory)
 	max := len(combinations)
 
-	selectedCombination := rand.IntN(max)
+	selectedCombination := s.rng.IntN(max)
 
 	selCombination := combinations[selectedCombination]
 	log.Printf("rand picked ov15 index %d | selected combination %d",
@@ -1360,7 +1861,7 @@ func (s *ProcessKeyService) DecideRatio3(ctx context.Context, oddsSortedSet stri
 
 	goals := s.TotalGoalsPerSession(ctx)
 	maxG := len(goals)
-	selectedRations := rand.IntN(maxG)
+	selectedRations := s.rng.IntN(maxG)
 
 	rgO25 := goals[selectedRations] // + 1
 	rgU25 := over15Limit - rgO25    //+ 1
@@ -1369,8 +1870,8 @@ func (s *ProcessKeyService) DecideRatio3(ctx context.Context, oddsSortedSet stri
 
 	// Now figure out how the matches will be arranged randomly.
 
-	oddsOv25 := fmt.Sprintf("%s_%s", oddsSortedSet, "TGO25") // sum of goals more than 2
-	oddsU25 := fmt.Sprintf("%s_%s", oddsSortedSet, "2")      // 2 goals in total ie 1-1,0-2,2-0,
+	oddsOv25 := goalCategories.Key(oddsSortedSet, goalCategories.Over25) // sum of goals more than 2
+	oddsU25 := goalCategories.Key(oddsSortedSet, "2")                    // 2 goals in total ie 1-1,0-2,2-0,
 
 	data2, err := s.redisConn.GetZRangeWithLimit(ctx, oddsOv25, rgO25)
 	if err != nil {
@@ -1411,7 +1912,7 @@ func (s *ProcessKeyService) DecideRatio3(ctx context.Context, oddsSortedSet stri
 	log.Println("List before shuffle : ", list)
 
 	for i := len(list) - 1; i > 0; i-- { // Fisher–Yates shuffle
-		j := rand.IntN(i + 1)
+		j := s.rng.IntN(i + 1)
 		list[i], list[j] = list[j], list[i]
 	}
 
@@ -1478,13 +1979,13 @@ func (s *ProcessKeyService) NewOddsCombination(ctx context.Context, sel int) []i
 // CheckForData : checks if minimum data required to generate a game is available
 func (s *ProcessKeyService) CheckForData(ctx context.Context, sanitizedKeysSet string, minimumRequired int) error {
 
-	tot0 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "0")
-	tot1 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "1")
-	tot2 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "2")
-	tot3 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "3")
-	tot4 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "4")
-	tot5 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "5")
-	tot6 := fmt.Sprintf("%s_%s", sanitizedKeysSet, "6")
+	tot0 := goalCategories.Key(sanitizedKeysSet, "0")
+	tot1 := goalCategories.Key(sanitizedKeysSet, "1")
+	tot2 := goalCategories.Key(sanitizedKeysSet, "2")
+	tot3 := goalCategories.Key(sanitizedKeysSet, "3")
+	tot4 := goalCategories.Key(sanitizedKeysSet, "4")
+	tot5 := goalCategories.Key(sanitizedKeysSet, "5")
+	tot6 := goalCategories.Key(sanitizedKeysSet, "6")
 
 	tot0Len, err := s.redisConn.SortedSetLen(ctx, tot0)
 	if err != nil {
@@ -1551,3 +2052,107 @@ func (s *ProcessKeyService) CheckForData(ctx context.Context, sanitizedKeysSet s
 
 	return nil
 }
+
+// commitSeed : session the draws of a scope are made from, its seed hash is published when
+// the seed commitments repository is set, with the hash of the first PoolSize members of
+// sets. A scope retried after a failure draws from the seed and pools committed the first
+// time. A published hash only changes when save_keys takes the season week back and revokes
+// it, the week is then drawn from a new seed.
+func (s *ProcessKeyService) commitSeed(ctx context.Context, scope, scopeID string, sets ...string) (*seedCommitments.Session, error) {
+
+	if s.seedCommitments == nil {
+		return seedCommitments.NewSession(scope, scopeID)
+	}
+
+	pools := []seedCommitments.Pool{}
+	for _, set := range sets {
+		members, err := s.redisConn.GetZRangeWithLimit(ctx, set, seedCommitments.PoolSize)
+		if err != nil {
+			return nil, fmt.Errorf("err : %v failed to read the pool of %s", err, set)
+		}
+		pools = append(pools, seedCommitments.Pool{Set: set, Members: members})
+	}
+
+	fs, err := seedCommitments.NewSession(scope, scopeID, pools...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.seedCommitments.Save(ctx, fs.Commitment)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.seedCommitments.Committed(ctx, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return seedCommitments.ResumeSession(c)
+}
+
+// claimSets : category sets the scores of distr are claimed from, in the order first drawn.
+func claimSets(oddsSortedSet string, distr []mrs.Mrs) ([]string, error) {
+
+	sets := []string{}
+	seen := make(map[string]bool)
+	for _, d := range distr {
+
+		scores, err := goalCategories.ParseRawScores(d.RawScores)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sc := range scores {
+			set := goalCategories.ClaimKey(goalCategories.Key(oddsSortedSet, goalCategories.Category(sc.Home, sc.Away)))
+			if !seen[set] {
+				seen[set] = true
+				sets = append(sets, set)
+			}
+		}
+	}
+
+	return sets, nil
+}
+
+// saveResults : keeps the results of a published season week once its pr_wo key expires.
+func (s *ProcessKeyService) saveResults(ctx context.Context, wo oddsFiles.FinalSeasonWeekWO) {
+
+	if s.weekResults == nil {
+		return
+	}
+
+	for _, m := range wo.FinalMatchesWO {
+
+		home, errH := strconv.Atoi(m.FinalScore.HomeScore)
+		away, errA := strconv.Atoi(m.FinalScore.AwayScore)
+		if errH != nil || errA != nil {
+			log.Printf("Err : score %s-%s of match %s not valid", m.FinalScore.HomeScore, m.FinalScore.AwayScore, m.MatchID)
+			continue
+		}
+
+		wr, err := weekResults.NewWeekResults(wo.SeasonWeeKID, m.MatchID, home, away)
+		if err != nil {
+			log.Printf("Err : %v failed to instantiate week result", err)
+			continue
+		}
+
+		_, err = s.weekResults.Save(ctx, *wr)
+		if err != nil {
+			log.Printf("Err : %v failed to save result of match %s", err, m.MatchID)
+		}
+	}
+}
+
+// revealSeed : records the draws of the session, its seed is revealed from revealAt.
+func (s *ProcessKeyService) revealSeed(ctx context.Context, fs *seedCommitments.Session, revealAt string) {
+
+	if s.seedCommitments == nil {
+		return
+	}
+
+	err := s.seedCommitments.Close(ctx, fs.Commitment.Scope, fs.Commitment.ScopeID, revealAt, fs.Draws())
+	if err != nil {
+		log.Printf("Err : %v failed to record the draws of %s %s", err, fs.Commitment.Scope, fs.Commitment.ScopeID)
+	}
+}
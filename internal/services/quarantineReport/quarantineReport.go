@@ -0,0 +1,88 @@
+package quarantineReport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/quarantines"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/quarantines/quarantineDir"
+)
+
+// QuarantineReportConfiguration is an alias for a function that will take in a pointer to an QuarantineReportService and modify it
+type QuarantineReportConfiguration func(os *QuarantineReportService) error
+
+// QuarantineReportService is a implementation of the QuarantineReportService
+type QuarantineReportService struct {
+	quarantine quarantines.QuarantinesRepository
+}
+
+// NewQuarantineReportService : instantiate every connection we need to run current game service
+func NewQuarantineReportService(cfgs ...QuarantineReportConfiguration) (*QuarantineReportService, error) {
+	os := &QuarantineReportService{}
+	for _, cfg := range cfgs {
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithQuarantineDirectoryRepository : reads quarantined files from quarantineDirectory
+func WithQuarantineDirectoryRepository(quarantineDirectory string) QuarantineReportConfiguration {
+	return func(os *QuarantineReportService) error {
+		d, err := quarantineDir.New(quarantineDirectory)
+		if err != nil {
+			return err
+		}
+		os.quarantine = d
+		return nil
+	}
+}
+
+// Report : groups quarantined files per day and per country. An empty country returns all.
+func (s *QuarantineReportService) Report(ctx context.Context, fromDay, toDay, country string) ([]quarantines.QuarantineReport, error) {
+
+	files, err := s.quarantine.QuarantinedFiles(ctx, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("err : %v failed to read quarantined files", err)
+	}
+
+	m := make(map[string]*quarantines.QuarantineReport)
+	for _, f := range files {
+
+		if country != "" && f.Country != country {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%s", f.Day, f.Country)
+		r, ok := m[key]
+		if !ok {
+			r = &quarantines.QuarantineReport{
+				Day:     f.Day,
+				Country: f.Country,
+				Codes:   make(map[string]int),
+			}
+			m[key] = r
+		}
+
+		r.Total++
+		r.Codes[f.Rejection.Code]++
+		r.Files = append(r.Files, f)
+	}
+
+	reports := []quarantines.QuarantineReport{}
+	for _, r := range m {
+		reports = append(reports, *r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Day == reports[j].Day {
+			return reports[i].Country < reports[j].Country
+		}
+		return reports[i].Day < reports[j].Day
+	})
+
+	return reports, nil
+}
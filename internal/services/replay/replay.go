@@ -0,0 +1,456 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobS3"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/matchChecks/crossCheck"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles/woFilesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/services/productionKey"
+	"github.com/lukemakhanu/magic_carpet/internal/services/saveFileRedis"
+)
+
+// ReplaySets : sorted sets used by the live pipeline, the replay writes them under its key prefix.
+type ReplaySets struct {
+	Odds      string
+	Wo        string
+	Ls        string
+	Sanitized string
+}
+
+// MatchDiff : a value that differs between live and the replay.
+type MatchDiff struct {
+	ParentMatchID string `json:"parent_match_id"`
+	Field         string `json:"field"`
+	Live          string `json:"live"`
+	Replay        string `json:"replay"`
+}
+
+// ReplayReport : summary of a replay compared with what is live.
+type ReplayReport struct {
+	Country   string      `json:"country"`
+	FromDate  string      `json:"from_date"`
+	ToDate    string      `json:"to_date"`
+	KeyPrefix string      `json:"key_prefix"`
+	WoFiles   int         `json:"wo_files"`
+	Matches   int         `json:"matches"`
+	Identical int         `json:"identical"`
+	Changed   int         `json:"changed"`
+	NotLive   int         `json:"not_live"`
+	Refused   int         `json:"refused"`
+	Diffs     []MatchDiff `json:"diffs"`
+}
+
+// ReplayConfiguration is an alias for a function that will take in a pointer to an ReplayService and modify it
+type ReplayConfiguration func(os *ReplayService) error
+
+// ReplayService is a implementation of the ReplayService
+type ReplayService struct {
+	woFilesMysql woFiles.WoFilesRepository
+	processor    *saveFileRedis.FileProcessorService
+	liveRedis    processRedis.RunRedis
+	replayRedis  processRedis.RunRedis
+	keyPrefix    string
+	blobs        blobs.BlobsRepository
+	production   *productionKey.ProcessKeyService
+}
+
+// NewReplayService : instantiate every connection we need to run a replay
+func NewReplayService(cfgs ...ReplayConfiguration) (*ReplayService, error) {
+	// Create the ReplayService
+	os := &ReplayService{}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if os.blobs != nil && os.processor != nil {
+		err := saveFileRedis.WithBlobRepository(os.blobs)(os.processor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return os, nil
+}
+
+// WithS3BlobRepository : the stored files are read from an S3 compatible bucket
+func WithS3BlobRepository(endpoint, region, bucket, accessKey, secretKey string, useSSL bool) ReplayConfiguration {
+	return func(os *ReplayService) error {
+		d, err := blobS3.New(endpoint, region, bucket, accessKey, secretKey, useSSL)
+		if err != nil {
+			return err
+		}
+		os.blobs = d
+		return nil
+	}
+}
+
+// WithProductionKeyService : replayed and live matches are priced through the pipeline
+// production keys are built with, the priced markets are compared as well
+func WithProductionKeyService(pk *productionKey.ProcessKeyService) ReplayConfiguration {
+	return func(os *ReplayService) error {
+		if pk == nil {
+			return fmt.Errorf("production key service not set")
+		}
+		os.production = pk
+		return nil
+	}
+}
+
+// WithMysqlWinningOutcomesRepository : instantiates mysql to connect to winning outcome files interface
+func WithMysqlWinningOutcomesRepository(connectionString string) ReplayConfiguration {
+	return func(os *ReplayService) error {
+		d, err := woFilesMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.woFilesMysql = d
+		return nil
+	}
+}
+
+// WithLiveRedisRepository : instantiates the redis connection the replay is compared with
+func WithLiveRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) ReplayConfiguration {
+	return func(os *ReplayService) error {
+		d, err := redisExec.New(redisServer, dbNum, maxIdle, maxActive, idleTimeout)
+		if err != nil {
+			return err
+		}
+		os.liveRedis = d
+		return nil
+	}
+}
+
+// WithReplayRedisRepository : instantiates the redis connection the replay writes to. The stored files
+// are pushed through saveFileRedis with every key and set under keyPrefix. Replaying into the live
+// database without a prefix is refused.
+func WithReplayRedisRepository(connectionString, redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration, keyPrefix string) ReplayConfiguration {
+	return func(os *ReplayService) error {
+		d, err := redisExec.New(redisServer, dbNum, maxIdle, maxActive, idleTimeout)
+		if err != nil {
+			return err
+		}
+
+		p, err := saveFileRedis.NewFileProcessorService(
+			saveFileRedis.WithMysqlLiveScoreRepository(connectionString),
+			saveFileRedis.WithMysqlWinningOutcomesRepository(connectionString),
+			saveFileRedis.WithMysqlRawOddsRepository(connectionString),
+			saveFileRedis.WithRedisRepository(redisServer, dbNum, maxIdle, maxActive, idleTimeout),
+			saveFileRedis.WithKeyPrefix(keyPrefix),
+		)
+		if err != nil {
+			return err
+		}
+
+		os.replayRedis = d
+		os.processor = p
+		os.keyPrefix = keyPrefix
+		return nil
+	}
+}
+
+// Replay : re-reads the winning outcome files of a country received between fromDate and toDate
+// (yyyy-mm-dd) together with their odds and live scores, categorises the matches again and
+// compares every match with the live keys.
+func (s *ReplayService) Replay(ctx context.Context, country, fromDate, toDate string, sets ReplaySets, sameDatabase bool) (ReplayReport, error) {
+
+	report := ReplayReport{
+		Country:   country,
+		FromDate:  fromDate,
+		ToDate:    toDate,
+		KeyPrefix: s.keyPrefix,
+		Diffs:     []MatchDiff{},
+	}
+
+	if sameDatabase && s.keyPrefix == "" {
+		return report, fmt.Errorf("replay into the live redis database needs a key prefix")
+	}
+
+	files, err := s.woFilesMysql.GetWoFilesByDate(ctx, country, fromDate, toDate)
+	if err != nil {
+		return report, fmt.Errorf("Err : %v failed to get winning outcome files for %s", err, country)
+	}
+	report.WoFiles = len(files)
+
+	parents := make(map[string]string)
+	for _, f := range files {
+
+		matches, err := s.replayFile(ctx, f, sets)
+		if err != nil {
+			log.Printf("Err : %v failed to replay %s/%s", err, f.WoDir, f.WoFileName)
+			continue
+		}
+
+		for _, m := range matches {
+			parents[m] = f.CompetitionID
+		}
+	}
+
+	parentIDs := []string{}
+	for p := range parents {
+		parentIDs = append(parentIDs, p)
+	}
+	sort.Strings(parentIDs)
+
+	for _, p := range parentIDs {
+
+		report.Matches++
+
+		diffs, refused, live := s.compareMatch(ctx, country, p, parents[p], sets)
+		if refused {
+			report.Refused++
+		}
+
+		if !live {
+			report.NotLive++
+		} else if len(diffs) == 0 {
+			report.Identical++
+		} else {
+			report.Changed++
+		}
+
+		report.Diffs = append(report.Diffs, diffs...)
+	}
+
+	return report, nil
+}
+
+// replayFile : pushes one winning outcome file and the odds and live scores of its matches
+// through saveFileRedis. Returns the parent match ids found.
+func (s *ReplayService) replayFile(ctx context.Context, f woFiles.WoFiles, sets ReplaySets) ([]string, error) {
+
+	matchChan := make(chan saveFileRedis.Job, 10000)
+
+	fullPath := fmt.Sprintf("%s/%s", f.WoDir, f.WoFileName)
+	err := s.processor.ReturnRawWO(ctx, sets.Wo, fullPath, matchChan)
+	if err != nil {
+		return nil, err
+	}
+	close(matchChan)
+
+	matches := []string{}
+	rounds := make(map[string]bool)
+	for v := range matchChan {
+
+		if v.JobType == "query_odds" {
+
+			matches = append(matches, v.WorkStr)
+			err := s.processor.ReturnOdds(ctx, sets.Odds, v.WorkStr, v.WorkStr1, v.WorkStr2, nil)
+			if err != nil {
+				log.Printf("Err : %v failed to replay odds of %s", err, v.WorkStr)
+			}
+
+		} else if v.JobType == "query_live_scores" {
+
+			// Live scores are saved per round, every match of the round asks for them.
+			if rounds[v.WorkStr1] {
+				continue
+			}
+			rounds[v.WorkStr1] = true
+
+			err := s.processor.ReturnRawLs(ctx, sets.Ls, v.WorkStr1, v.WorkStr2, nil)
+			if err != nil {
+				log.Printf("Err : %v failed to replay live scores of round %s", err, v.WorkStr1)
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// compareMatch : categorises the replayed match and lists what differs from live.
+func (s *ReplayService) compareMatch(ctx context.Context, country, parentMatchID, competitionID string, sets ReplaySets) ([]MatchDiff, bool, bool) {
+
+	diffs := []MatchDiff{}
+	refused := false
+
+	oddsKey := fmt.Sprintf("%s%s%s", country, "O:", parentMatchID)
+	woKey := fmt.Sprintf("%s%s%s", country, "Wo:", parentMatchID)
+	lsKey := fmt.Sprintf("%s%s%s", country, "Ls:", parentMatchID)
+
+	replayOdds, _ := s.replayRedis.Get(ctx, s.keyPrefix+oddsKey)
+	replayWo, _ := s.replayRedis.Get(ctx, s.keyPrefix+woKey)
+	replayLs, _ := s.replayRedis.Get(ctx, s.keyPrefix+lsKey)
+
+	liveOdds, _ := s.liveRedis.Get(ctx, oddsKey)
+	liveWo, _ := s.liveRedis.Get(ctx, woKey)
+	liveLs, _ := s.liveRedis.Get(ctx, lsKey)
+
+	live := liveOdds != "" || liveWo != "" || liveLs != ""
+
+	replayCategories := []string{}
+	cc, err := crossCheck.New(parentMatchID, replayOdds, replayWo, replayLs)
+	if err != nil {
+		refused = true
+		diffs = append(diffs, MatchDiff{ParentMatchID: parentMatchID, Field: "refused", Replay: err.Error()})
+	} else {
+		issues, err := cc.Check(ctx)
+		if err != nil || len(issues) > 0 {
+			refused = true
+			reasons := []string{}
+			for _, x := range issues {
+				reasons = append(reasons, x.Code)
+			}
+			if err != nil {
+				reasons = append(reasons, err.Error())
+			}
+			diffs = append(diffs, MatchDiff{ParentMatchID: parentMatchID, Field: "refused", Replay: strings.Join(reasons, ",")})
+		} else {
+			replayCategories = s.categorise(ctx, oddsKey, replayWo, sets.Sanitized)
+		}
+	}
+
+	if !live {
+		return diffs, refused, live
+	}
+
+	for _, x := range []struct{ field, live, replay string }{
+		{"odds", liveOdds, replayOdds},
+		{"wo", liveWo, replayWo},
+		{"ls", liveLs, replayLs},
+	} {
+		if normalise(x.live) != normalise(x.replay) {
+			diffs = append(diffs, MatchDiff{ParentMatchID: parentMatchID, Field: x.field, Live: x.live, Replay: x.replay})
+		}
+	}
+
+	if s.production != nil && !refused {
+		l, r := s.formulate(ctx, competitionID, liveOdds, liveWo, liveLs), s.formulate(ctx, competitionID, replayOdds, replayWo, replayLs)
+		if l != r {
+			diffs = append(diffs, MatchDiff{ParentMatchID: parentMatchID, Field: "priced", Live: l, Replay: r})
+		}
+	}
+
+	liveCategories := s.liveCategories(ctx, oddsKey, liveWo, replayCategories, sets.Sanitized)
+	l, r := strings.Join(liveCategories, ","), strings.Join(replayCategories, ",")
+	if l != r {
+		diffs = append(diffs, MatchDiff{ParentMatchID: parentMatchID, Field: "categories", Live: l, Replay: r})
+	}
+
+	return diffs, refused, live
+}
+
+// formulate : the match priced by the production pipeline, the error when it can not be.
+func (s *ReplayService) formulate(ctx context.Context, competitionID, oddsPayload, woPayload, lsPayload string) string {
+
+	mkts, wo, ls, err := s.production.FormulateMatch(ctx, competitionID, oddsPayload, woPayload, lsPayload)
+	if err != nil {
+		return err.Error()
+	}
+
+	data, err := json.Marshal(struct {
+		Markets         []oddsFiles.FinalMarkets    `json:"markets"`
+		WinningOutcomes oddsFiles.FinalScores       `json:"winning_outcomes"`
+		LiveScores      []oddsFiles.FinalLiveScores `json:"live_scores"`
+	}{mkts, wo, ls})
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(data)
+}
+
+// categorise : files the replayed match under the prefixed goal categories.
+func (s *ReplayService) categorise(ctx context.Context, matchID, woPayload, sanitizedKeysSet string) []string {
+
+	hScore, aScore, ok := finalScore(woPayload)
+	if !ok {
+		return []string{}
+	}
+
+	categories := goalCategories.Keys(sanitizedKeysSet, hScore, aScore)
+	for _, c := range categories {
+		err := s.replayRedis.ZAdd(ctx, s.keyPrefix+c, "1", matchID)
+		if err != nil {
+			log.Printf("Err : %v unable to add %s into %s set ", err, matchID, s.keyPrefix+c)
+		}
+	}
+
+	return categories
+}
+
+// liveCategories : the categories the match is filed under live. Only the categories of the
+// live and replayed scores can hold the match, those are the ones looked up.
+func (s *ReplayService) liveCategories(ctx context.Context, matchID, woPayload string, replayCategories []string, sanitizedKeysSet string) []string {
+
+	candidates := append([]string{}, replayCategories...)
+	hScore, aScore, ok := finalScore(woPayload)
+	if ok {
+		candidates = append(candidates, goalCategories.Keys(sanitizedKeysSet, hScore, aScore)...)
+	}
+
+	seen := make(map[string]bool)
+	categories := []string{}
+	for _, c := range candidates {
+
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		found, err := s.liveRedis.IsZMember(ctx, c, matchID)
+		if err != nil {
+			log.Printf("Err : %v unable to check %s in %s set ", err, matchID, c)
+			continue
+		}
+
+		if found {
+			categories = append(categories, c)
+		}
+	}
+
+	return categories
+}
+
+// finalScore : final score of a saved winning outcome, only matches SelectKeys would accept.
+func finalScore(woPayload string) (int, int, bool) {
+
+	var wo oddsFiles.RawWinningOutcomes
+	err := json.Unmarshal([]byte(woPayload), &wo)
+	if err != nil || len(wo.RawWOs) <= 25 {
+		return 0, 0, false
+	}
+
+	hScore, err := strconv.Atoi(wo.HomeScore)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	aScore, err := strconv.Atoi(wo.AwayScore)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return hScore, aScore, true
+}
+
+// normalise : json payloads compared regardless of key order and spacing.
+func normalise(payload string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(payload), &v); err != nil {
+		return payload
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return payload
+	}
+	return string(data)
+}
@@ -0,0 +1,291 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/archives"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/archives/archiveBlob"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/archives/archiveDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobS3"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/retentions"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/retentions/retentionsMysql"
+)
+
+// batchSize : rows handled per query.
+const batchSize = 500
+
+// TableReport : what a run did, or would do in dry run, on one table.
+type TableReport struct {
+	Table         string   `json:"table"`
+	FilesArchived int      `json:"files_archived"`
+	FilesMissing  int      `json:"files_missing"`
+	FilesFailed   int      `json:"files_failed"`
+	RowsArchived  int64    `json:"rows_archived"`
+	RowsPurged    int64    `json:"rows_purged"`
+	Samples       []string `json:"samples,omitempty"`
+}
+
+// RetentionConfiguration is an alias for a function that will take in a pointer to an RetentionService and modify it
+type RetentionConfiguration func(os *RetentionService) error
+
+// RetentionService is a implementation of the RetentionService
+type RetentionService struct {
+	retentionsMysql  retentions.RetentionsRepository
+	archive          archives.ArchivesRepository
+	archiveDirectory string
+	policies         []retentions.Policy
+	dryRun           bool
+}
+
+// NewRetentionService : instantiate every connection we need to run the retention
+func NewRetentionService(cfgs ...RetentionConfiguration) (*RetentionService, error) {
+	// Create the RetentionService
+	os := &RetentionService{}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithMysqlRetentionsRepository : instantiates mysql to connect to the file tracking tables
+func WithMysqlRetentionsRepository(connectionString string) RetentionConfiguration {
+	return func(os *RetentionService) error {
+		d, err := retentionsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.retentionsMysql = d
+		return nil
+	}
+}
+
+// WithArchiveDirectoryRepository : archived files go under archiveDirectory, gzipped when compress is set
+func WithArchiveDirectoryRepository(archiveDirectory string, compress bool) RetentionConfiguration {
+	return func(os *RetentionService) error {
+		d, err := archiveDir.New(archiveDirectory, compress)
+		if err != nil {
+			return err
+		}
+		os.archive = d
+		os.archiveDirectory = archiveDirectory
+		return nil
+	}
+}
+
+// WithS3ArchiveRepository : feed files read from an S3 compatible bucket are archived in the
+// same bucket under archiveDirectory, gzipped when compress is set
+func WithS3ArchiveRepository(endpoint, region, bucket, accessKey, secretKey string, useSSL bool, archiveDirectory string, compress bool) RetentionConfiguration {
+	return func(os *RetentionService) error {
+		b, err := blobS3.New(endpoint, region, bucket, accessKey, secretKey, useSSL)
+		if err != nil {
+			return err
+		}
+		d, err := archiveBlob.New(b, archiveDirectory, compress)
+		if err != nil {
+			return err
+		}
+		os.archive = d
+		os.archiveDirectory = archiveDirectory
+		return nil
+	}
+}
+
+// WithPolicies : one policy per table. Odds, winning outcome and live score files of a round
+// belong together, every table is kept for the same number of days
+func WithPolicies(policies []retentions.Policy) RetentionConfiguration {
+	return func(os *RetentionService) error {
+		seen := make(map[string]bool)
+		for _, p := range policies {
+			np, err := retentions.NewPolicy(p.Table, p.FileDays, p.RowDays, p.RowAction)
+			if err != nil {
+				return err
+			}
+			if seen[np.Table] {
+				return fmt.Errorf("policy for %s set twice", np.Table)
+			}
+			if len(os.policies) > 0 && (np.FileDays != os.policies[0].FileDays || np.RowDays != os.policies[0].RowDays) {
+				return fmt.Errorf("policy for %s does not keep files and rows as long as %s", np.Table, os.policies[0].Table)
+			}
+			seen[np.Table] = true
+			os.policies = append(os.policies, *np)
+		}
+		return nil
+	}
+}
+
+// WithDryRun : report what would be archived or purged without touching anything
+func WithDryRun(dryRun bool) RetentionConfiguration {
+	return func(os *RetentionService) error {
+		os.dryRun = dryRun
+		return nil
+	}
+}
+
+// Run : applies every policy, files first so that a row is never removed while its file is live.
+func (s *RetentionService) Run(ctx context.Context) ([]TableReport, error) {
+
+	reports := []TableReport{}
+	for _, p := range s.policies {
+
+		r := TableReport{Table: p.Table}
+
+		if p.FileDays > 0 {
+			err := s.archiveFiles(ctx, p, &r)
+			if err != nil {
+				return reports, err
+			}
+		}
+
+		if p.RowAction != retentions.RowKeep {
+			err := s.ageRows(ctx, p, &r)
+			if err != nil {
+				return reports, err
+			}
+		}
+
+		log.Printf("Retention %s | dry run %t | files archived %d missing %d failed %d | rows archived %d purged %d",
+			r.Table, s.dryRun, r.FilesArchived, r.FilesMissing, r.FilesFailed, r.RowsArchived, r.RowsPurged)
+
+		reports = append(reports, r)
+	}
+
+	return reports, nil
+}
+
+// archiveFiles : moves files of rows older than the policy into dated archive directories and
+// points the rows at them. The copy is recorded before the live file is removed.
+func (s *RetentionService) archiveFiles(ctx context.Context, p retentions.Policy, r *TableReport) error {
+
+	fileType := retentions.FileTypes[p.Table]
+
+	if s.dryRun {
+		count, err := s.retentionsMysql.CountAgedFiles(ctx, p.Table, p.FileDays, s.archiveDirectory)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to count aged files of %s", err, p.Table)
+		}
+		r.FilesArchived = count
+
+		files, err := s.retentionsMysql.AgedFiles(ctx, p.Table, p.FileDays, s.archiveDirectory, 10)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to get aged files of %s", err, p.Table)
+		}
+
+		for _, f := range files {
+			dir, name := s.archive.Target(strings.Split(f.Created, " ")[0], f.Country, fileType, f.FileName)
+			r.Samples = append(r.Samples, fmt.Sprintf("%s/%s -> %s/%s", f.Directory, f.FileName, dir, name))
+		}
+		return nil
+	}
+
+	// Files that could not be moved stay aged, they are skipped for the rest of the run.
+	failed := make(map[string]bool)
+
+	for {
+
+		files, err := s.retentionsMysql.AgedFiles(ctx, p.Table, p.FileDays, s.archiveDirectory, batchSize)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to get aged files of %s", err, p.Table)
+		}
+
+		pending := 0
+		for _, f := range files {
+
+			if failed[f.ID] {
+				continue
+			}
+			pending++
+
+			day := strings.Split(f.Created, " ")[0]
+
+			dir, name, err := s.archive.Archive(ctx, day, f.Country, fileType, f.Directory, f.FileName)
+			if err != nil {
+				log.Printf("Err : %v", err)
+				failed[f.ID] = true
+				r.FilesMissing++
+				continue
+			}
+
+			_, err = s.retentionsMysql.MoveFile(ctx, p.Table, f.ID, name, dir)
+			if err != nil {
+				log.Printf("Err : %v", err)
+				s.archive.Remove(ctx, dir, name)
+				failed[f.ID] = true
+				r.FilesFailed++
+				continue
+			}
+
+			err = s.archive.Remove(ctx, f.Directory, f.FileName)
+			if err != nil {
+				log.Printf("Err : %v", err)
+			}
+
+			r.FilesArchived++
+		}
+
+		if pending == 0 || len(files) < batchSize {
+			return nil
+		}
+	}
+}
+
+// ageRows : moves rows older than the policy into <table>_archive or deletes them. When the
+// policy archives files only rows whose file is in the archive are aged, a row whose file
+// could not be archived stays with its file.
+func (s *RetentionService) ageRows(ctx context.Context, p retentions.Policy, r *TableReport) error {
+
+	if s.dryRun {
+		count, err := s.retentionsMysql.CountAgedRows(ctx, p.Table, p.RowDays)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to count aged rows of %s", err, p.Table)
+		}
+		if p.RowAction == retentions.RowArchive {
+			r.RowsArchived = int64(count)
+		} else {
+			r.RowsPurged = int64(count)
+		}
+		return nil
+	}
+
+	archived := ""
+	if p.FileDays > 0 {
+		archived = s.archiveDirectory
+	}
+
+	for {
+
+		ids, err := s.retentionsMysql.AgedRows(ctx, p.Table, p.RowDays, archived, batchSize)
+		if err != nil {
+			return fmt.Errorf("Err : %v failed to get aged rows of %s", err, p.Table)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if p.RowAction == retentions.RowArchive {
+			n, err := s.retentionsMysql.ArchiveRows(ctx, p.Table, ids)
+			if err != nil {
+				return err
+			}
+			r.RowsArchived += n
+		} else {
+			n, err := s.retentionsMysql.PurgeRows(ctx, p.Table, ids)
+			if err != nil {
+				return err
+			}
+			r.RowsPurged += n
+		}
+
+		if len(ids) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package rngReport
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/rngs/cryptoRand"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+)
+
+// RngReportConfiguration is an alias for a function that will take in a pointer to an RngReportService and modify it
+type RngReportConfiguration func(os *RngReportService) error
+
+// RngReportService is a implementation of the RngReportService
+type RngReportService struct {
+	rng        rngs.RngsRepository
+	source     string
+	signingKey ed25519.PrivateKey
+}
+
+// NewRngReportService : instantiate the source under test, crypto/rand unless set
+func NewRngReportService(cfgs ...RngReportConfiguration) (*RngReportService, error) {
+	rng, err := cryptoRand.New()
+	if err != nil {
+		return nil, err
+	}
+
+	os := &RngReportService{rng: rng, source: cryptoRand.Name}
+	for _, cfg := range cfgs {
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithRng : source to test, named source on the report
+func WithRng(r rngs.RngsRepository, source string) RngReportConfiguration {
+	return func(os *RngReportService) error {
+		if r == nil {
+			return fmt.Errorf("rng not set")
+		}
+		if source == "" {
+			return fmt.Errorf("rng source name not set")
+		}
+		os.rng = r
+		os.source = source
+		return nil
+	}
+}
+
+// WithSource : tests the source selections use by name, crypto/rand or the seed
+// commitment draws a daemon with seed_commitments enabled selects with.
+func WithSource(source string) RngReportConfiguration {
+	return func(os *RngReportService) error {
+		switch source {
+		case cryptoRand.Name:
+			return nil
+		case seedCommitments.Name:
+			r, err := seedCommitments.BatteryRand()
+			if err != nil {
+				return err
+			}
+			os.rng = r
+			os.source = source
+			return nil
+		default:
+			return fmt.Errorf("rng source %q not supported, use %s or %s", source, cryptoRand.Name, seedCommitments.Name)
+		}
+	}
+}
+
+// WithSigningKey : reads the ed25519 seed reports are signed with, in hex, from keyFile
+func WithSigningKey(keyFile string) RngReportConfiguration {
+	data, readErr := os.ReadFile(keyFile)
+	return func(os *RngReportService) error {
+		if keyFile == "" {
+			return fmt.Errorf("signing key file not set")
+		}
+
+		if readErr != nil {
+			return fmt.Errorf("err : %v failed to read signing key", readErr)
+		}
+
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return fmt.Errorf("signing key in %s must be a %d byte hex seed", keyFile, ed25519.SeedSize)
+		}
+
+		os.signingKey = ed25519.NewKeyFromSeed(seed)
+		return nil
+	}
+}
+
+// Run : runs the battery against the source and signs the report.
+func (s *RngReportService) Run(ctx context.Context, draws, buckets int, alpha float64) (rngs.SignedReport, error) {
+
+	if s.signingKey == nil {
+		return rngs.SignedReport{}, fmt.Errorf("signing key not set")
+	}
+
+	rp, err := rngs.Battery(s.rng, s.source, draws, buckets, alpha)
+	if err != nil {
+		return rngs.SignedReport{}, fmt.Errorf("err : %v failed to run battery", err)
+	}
+
+	sr, err := rngs.Sign(rp, s.signingKey)
+	if err != nil {
+		return rngs.SignedReport{}, fmt.Errorf("err : %v failed to sign report", err)
+	}
+
+	return sr, nil
+}
+
+// GenerateKey : new signing key, its seed to keep in the key file and the public key to
+// hand to the test lab, both in hex.
+func GenerateKey() (string, string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", fmt.Errorf("err : %v failed to generate signing key", err)
+	}
+	return hex.EncodeToString(privateKey.Seed()), hex.EncodeToString(publicKey), nil
+}
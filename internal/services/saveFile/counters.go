@@ -0,0 +1,72 @@
+package saveFile
+
+import (
+	"sync"
+	"time"
+)
+
+// Counters : files handled by a save file service, safe for concurrent use.
+type Counters struct {
+	mu         sync.Mutex
+	saved      int64
+	duplicates int64
+	revisions  int64
+	failed     int64
+	lastFile   string
+	lastSaved  time.Time
+}
+
+// CountersSnapshot : copy of the counters at a point in time.
+type CountersSnapshot struct {
+	Saved      int64  `json:"saved"`
+	Duplicates int64  `json:"duplicates"`
+	Revisions  int64  `json:"revisions"`
+	Failed     int64  `json:"failed"`
+	LastFile   string `json:"last_file"`
+	LastSaved  string `json:"last_saved"`
+}
+
+// Snapshot : returns the current values.
+func (c *Counters) Snapshot() CountersSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lastSaved := ""
+	if !c.lastSaved.IsZero() {
+		lastSaved = c.lastSaved.Format("2006-01-02 15:04:05")
+	}
+
+	return CountersSnapshot{
+		Saved:      c.saved,
+		Duplicates: c.duplicates,
+		Revisions:  c.revisions,
+		Failed:     c.failed,
+		LastFile:   c.lastFile,
+		LastSaved:  lastSaved,
+	}
+}
+
+// record : outcome is one of saved, duplicate, revision or failed.
+func (c *Counters) record(outcome, fileName string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch outcome {
+	case "saved":
+		c.saved++
+	case "duplicate":
+		c.duplicates++
+	case "revision":
+		c.revisions++
+	default:
+		c.failed++
+		return
+	}
+
+	c.lastFile = fileName
+	c.lastSaved = time.Now()
+}
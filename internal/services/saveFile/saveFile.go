@@ -2,13 +2,26 @@ package saveFile
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
-
+	"time"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileCursors"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileCursors/fileCursorsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileNames/nameParser"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileRevisions"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileRevisions/fileRevisionsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/liveScoreFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/liveScoreFiles/liveScoreFilesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles/oddsFilesMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/quarantines/quarantineDir"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/readFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/readFiles/readDir"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
@@ -26,12 +39,37 @@ type SaveFileService struct {
 
 	// New Implementation
 	liveScoreFilesMysql liveScoreFiles.LiveScoreFilesRepository
+
+	// Watch mode
+	dirWatcher       readFiles.DirectoryWatcher
+	fileCursorsMysql fileCursors.FileCursorsRepository
+
+	fileRevisionsMysql fileRevisions.FileRevisionsRepository
+
+	counters *Counters
+
+	// blobs holds the feed files, the local disk unless set.
+	blobs blobs.BlobsRepository
+
+	// stamps : modification time and size of every file the batch passes saved, a file
+	// listed again with the same stamp is neither hashed nor looked up.
+	stamps map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
 }
 
 // NewSaveFileService : instantiate every connection we need to run current game service
 func NewSaveFileService(cfgs ...SaveFileConfiguration) (*SaveFileService, error) {
+	local, err := blobDir.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &SaveFileService{}
+	os := &SaveFileService{blobs: local, stamps: make(map[string]fileStamp)}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -81,6 +119,18 @@ func WithMysqlWoFilesRepository(connectionString string) SaveFileConfiguration {
 	}
 }
 
+// WithBlobRepository : reads feed files from store instead of the local disk.
+// Must come before the directory options.
+func WithBlobRepository(store blobs.BlobsRepository) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		if store == nil {
+			return fmt.Errorf("store not set")
+		}
+		os.blobs = store
+		return nil
+	}
+}
+
 // WithDirectoryReaderRepository : read directory
 func WithDirectoryReaderRepository(directory, combination string) SaveFileConfiguration {
 	return func(os *SaveFileService) error {
@@ -94,6 +144,75 @@ func WithDirectoryReaderRepository(directory, combination string) SaveFileConfig
 	}
 }
 
+// WithDirectoryWatcherRepository : watch directory for new files
+func WithDirectoryWatcherRepository(directory, combination string) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		d, err := readDir.New(directory, combination)
+		if err != nil {
+			return err
+		}
+		os.dirWatcher = d
+		return nil
+	}
+}
+
+// WithValidatedDirectoryRepository : reads and watches directory, file names are validated
+// and rejected files are moved into quarantineDirectory. projectIDs and competitionIDs are comma
+// separated lists of accepted ids, empty accepts any.
+func WithValidatedDirectoryRepository(directory, combination, country, quarantineDirectory, projectIDs, competitionIDs string) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		parsers, err := nameParser.Parsers(projectIDs, competitionIDs)
+		if err != nil {
+			return err
+		}
+
+		q, err := quarantineDir.NewWithBlobs(quarantineDirectory, os.blobs)
+		if err != nil {
+			return err
+		}
+
+		d, err := readDir.NewWithBlobs(os.blobs, directory, combination, country, parsers, q)
+		if err != nil {
+			return err
+		}
+		os.dirReader = d
+		os.dirWatcher = d
+		return nil
+	}
+}
+
+// WithMysqlFileRevisionsRepository : queues re-delivered files with a changed content
+func WithMysqlFileRevisionsRepository(connectionString string) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		d, err := fileRevisionsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.fileRevisionsMysql = d
+		return nil
+	}
+}
+
+// WithMysqlFileCursorsRepository : keeps the watcher position across restarts
+func WithMysqlFileCursorsRepository(connectionString string) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		d, err := fileCursorsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.fileCursorsMysql = d
+		return nil
+	}
+}
+
+// WithCounters : counts saved, duplicate, revised and failed files into c
+func WithCounters(c *Counters) SaveFileConfiguration {
+	return func(os *SaveFileService) error {
+		os.counters = c
+		return nil
+	}
+}
+
 // SaveFiles : used to save data files into db
 func (s *SaveFileService) SaveFiles(ctx context.Context, fileDir, country string) error {
 
@@ -104,20 +223,19 @@ func (s *SaveFileService) SaveFiles(ctx context.Context, fileDir, country string
 
 	for _, f := range allFiles {
 
-		// Save this files into our databases
-
-		odds, err := oddsFiles.NewOddsFile(f.LsFileName, fileDir, country, f.ExtID, f.CompetitionID, f.ProjectID)
-		if err != nil {
-			return fmt.Errorf("err : %v failed to instantiate files", err)
+		if s.unchanged(f) {
+			continue
 		}
 
-		lastID, err := s.oddsFileMysql.SaveOdd(ctx, *odds)
+		// Save this files into our databases
+
+		err := s.saveOddsFile(ctx, fileDir, country, f)
 		if err != nil {
-			return fmt.Errorf("err : %v failed to save files", err)
+			s.counters.record("failed", f.LsFileName)
+			return err
 		}
 
-		log.Printf("Last inserted ID  %d", lastID)
-
+		s.stamps[f.LsFileName] = fileStamp{modTime: f.ModTime, size: f.Size}
 	}
 
 	return nil
@@ -133,20 +251,19 @@ func (s *SaveFileService) SaveLiveScoreFiles(ctx context.Context, fileDir, count
 
 	for _, f := range allFiles {
 
-		// Save this files into our databases
-
-		ls, err := liveScoreFiles.NewLiveScoreFile(f.LsFileName, fileDir, country, f.ExtID, f.ProjectID, f.CompetitionID)
-		if err != nil {
-			return fmt.Errorf("err : %v failed to instantiate live score files", err)
+		if s.unchanged(f) {
+			continue
 		}
 
-		lastID, err := s.liveScoreFilesMysql.Save(ctx, *ls)
+		// Save this files into our databases
+
+		err := s.saveLsFile(ctx, fileDir, country, f)
 		if err != nil {
-			return fmt.Errorf("err : %v failed to save live score files", err)
+			s.counters.record("failed", f.LsFileName)
+			return err
 		}
 
-		log.Printf("Last inserted ID : %d", lastID)
-
+		s.stamps[f.LsFileName] = fileStamp{modTime: f.ModTime, size: f.Size}
 	}
 
 	return nil
@@ -162,9 +279,126 @@ func (s *SaveFileService) SaveWinningOutcomesFiles(ctx context.Context, fileDir,
 
 	for _, f := range allFiles {
 
+		if s.unchanged(f) {
+			continue
+		}
+
 		// Save this files into our databases
-		status := "pending"
-		wo, err := woFiles.NewWoFile(f.LsFileName, fileDir, country, f.ExtID, f.ProjectID, f.CompetitionID, status)
+
+		err := s.saveWoFile(ctx, fileDir, country, f)
+		if err != nil {
+			s.counters.record("failed", f.LsFileName)
+			return err
+		}
+
+		s.stamps[f.LsFileName] = fileStamp{modTime: f.ModTime, size: f.Size}
+	}
+
+	return nil
+}
+
+// unchanged : whether f was saved by an earlier pass and has the same modification time
+// and size, a file is hashed again only when one of them changed.
+func (s *SaveFileService) unchanged(f lsFiles.FileInfo) bool {
+	st, ok := s.stamps[f.LsFileName]
+	return ok && st.size == f.Size && st.modTime.Equal(f.ModTime)
+}
+
+// saveOddsFile : saves a new odds file, skips an exact duplicate and records a new
+// revision when the provider re-delivers the same parent id with another content.
+func (s *SaveFileService) saveOddsFile(ctx context.Context, fileDir, country string, f lsFiles.FileInfo) error {
+
+	sum, size, err := s.fileHash(ctx, fileDir, f.LsFileName)
+	if err != nil {
+		return err
+	}
+
+	saved, err := s.oddsFileMysql.GetAllOddsParentID(ctx, f.ExtID, country)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to read odds file %s", err, f.ExtID)
+	}
+
+	if len(saved) == 0 {
+
+		odds, err := oddsFiles.NewOddsFile(f.LsFileName, fileDir, country, f.ExtID, f.CompetitionID, f.ProjectID, sum, size)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to instantiate files", err)
+		}
+
+		lastID, err := s.oddsFileMysql.SaveOdd(ctx, *odds)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to save files", err)
+		}
+
+		if lastID > 0 {
+			log.Printf("Last inserted ID  %d", lastID)
+			s.counters.record("saved", f.LsFileName)
+			return nil
+		}
+
+		// Saved by another host since it was read.
+		saved, err = s.oddsFileMysql.GetAllOddsParentID(ctx, f.ExtID, country)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to read odds file %s", err, f.ExtID)
+		}
+
+		if len(saved) == 0 {
+			return fmt.Errorf("odds file %s neither saved nor found", f.ExtID)
+		}
+	}
+
+	o := saved[0]
+	if o.Sha256 == sum {
+		log.Printf("Skip duplicate odds file %s [%s]", f.LsFileName, sum)
+		s.counters.record("duplicate", f.LsFileName)
+		return nil
+	}
+
+	// Rows saved before hashing only get their hash filled in.
+	revision := o.Revision
+	if o.Sha256 != "" {
+		revision++
+	}
+
+	n, err := s.oddsFileMysql.UpdateOddRevision(ctx, f.LsFileName, fileDir, sum, size, revision, o.OddsFileID, o.Sha256)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to update odds file revision", err)
+	}
+
+	if n == 0 {
+		// Revised by another host since it was read.
+		log.Printf("Skip odds file %s already revised", f.LsFileName)
+		return nil
+	}
+
+	err = s.saveRevision(ctx, "odds", country, f.ExtID, sum, size, revision)
+	if err != nil {
+		return err
+	}
+
+	s.counters.record("revision", f.LsFileName)
+	return nil
+}
+
+// saveWoFile : same as saveOddsFile, a changed winning outcome file is set back to
+// pending so that its round is processed again.
+func (s *SaveFileService) saveWoFile(ctx context.Context, fileDir, country string, f lsFiles.FileInfo) error {
+
+	sum, size, err := s.fileHash(ctx, fileDir, f.LsFileName)
+	if err != nil {
+		return err
+	}
+
+	saved, err := s.woFilesMysql.GetWoFileByExtID(ctx, f.ExtID, country)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to read wo file %s", err, f.ExtID)
+	}
+
+	status := "pending"
+
+	if len(saved) == 0 {
+
+		wo, err := woFiles.NewWoFile(f.LsFileName, fileDir, country, f.ExtID, f.ProjectID, f.CompetitionID, status, sum, size)
 		if err != nil {
 			return fmt.Errorf("err : %v failed to instantiate wo files", err)
 		}
@@ -174,8 +408,345 @@ func (s *SaveFileService) SaveWinningOutcomesFiles(ctx context.Context, fileDir,
 			return fmt.Errorf("err : %v failed to save wo files", err)
 		}
 
-		log.Printf("Last inserted ID : %d", lastID)
+		if lastID > 0 {
+			log.Printf("Last inserted ID : %d", lastID)
+			s.counters.record("saved", f.LsFileName)
+			return nil
+		}
+
+		// Saved by another host since it was read.
+		saved, err = s.woFilesMysql.GetWoFileByExtID(ctx, f.ExtID, country)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to read wo file %s", err, f.ExtID)
+		}
+
+		if len(saved) == 0 {
+			return fmt.Errorf("wo file %s neither saved nor found", f.ExtID)
+		}
+	}
+
+	w := saved[0]
+	if w.Sha256 == sum {
+		log.Printf("Skip duplicate wo file %s [%s]", f.LsFileName, sum)
+		s.counters.record("duplicate", f.LsFileName)
+		return nil
+	}
+
+	revision := w.Revision
+	if w.Sha256 != "" {
+		revision++
+	} else {
+		status = w.Status
+	}
+
+	n, err := s.woFilesMysql.UpdateWoRevision(ctx, f.LsFileName, fileDir, sum, size, revision, status, w.WoFileID, w.Sha256)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to update wo file revision", err)
+	}
+
+	if n == 0 {
+		// Revised by another host since it was read.
+		log.Printf("Skip wo file %s already revised", f.LsFileName)
+		return nil
+	}
+
+	err = s.saveRevision(ctx, "wo", country, f.ExtID, sum, size, revision)
+	if err != nil {
+		return err
+	}
+
+	s.counters.record("revision", f.LsFileName)
+	return nil
+}
+
+// saveLsFile : same as saveOddsFile for live score files.
+func (s *SaveFileService) saveLsFile(ctx context.Context, fileDir, country string, f lsFiles.FileInfo) error {
+
+	sum, size, err := s.fileHash(ctx, fileDir, f.LsFileName)
+	if err != nil {
+		return err
+	}
+
+	saved, err := s.liveScoreFilesMysql.GetLsFileByExtID(ctx, f.ExtID, country)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to read live score file %s", err, f.ExtID)
+	}
+
+	if len(saved) == 0 {
+
+		ls, err := liveScoreFiles.NewLiveScoreFile(f.LsFileName, fileDir, country, f.ExtID, f.ProjectID, f.CompetitionID, sum, size)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to instantiate live score files", err)
+		}
+
+		lastID, err := s.liveScoreFilesMysql.Save(ctx, *ls)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to save live score files", err)
+		}
+
+		if lastID > 0 {
+			log.Printf("Last inserted ID : %d", lastID)
+			s.counters.record("saved", f.LsFileName)
+			return nil
+		}
+
+		// Saved by another host since it was read.
+		saved, err = s.liveScoreFilesMysql.GetLsFileByExtID(ctx, f.ExtID, country)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to read live score file %s", err, f.ExtID)
+		}
+
+		if len(saved) == 0 {
+			return fmt.Errorf("live score file %s neither saved nor found", f.ExtID)
+		}
+	}
+
+	l := saved[0]
+	if l.Sha256 == sum {
+		log.Printf("Skip duplicate live score file %s [%s]", f.LsFileName, sum)
+		s.counters.record("duplicate", f.LsFileName)
+		return nil
+	}
+
+	revision := l.Revision
+	if l.Sha256 != "" {
+		revision++
+	}
+
+	n, err := s.liveScoreFilesMysql.UpdateLsRevision(ctx, f.LsFileName, fileDir, sum, size, revision, l.LiveScoreFileID, l.Sha256)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to update live score file revision", err)
+	}
+
+	if n == 0 {
+		// Revised by another host since it was read.
+		log.Printf("Skip live score file %s already revised", f.LsFileName)
+		return nil
+	}
+
+	err = s.saveRevision(ctx, "ls", country, f.ExtID, sum, size, revision)
+	if err != nil {
+		return err
+	}
+
+	s.counters.record("revision", f.LsFileName)
+	return nil
+}
+
+// saveRevision : queues a changed file so that save_keys rebuilds its redis keys.
+func (s *SaveFileService) saveRevision(ctx context.Context, fileType, country, extID, sum string, size int64, revision int) error {
+
+	if revision < 2 {
+		return nil
+	}
+
+	log.Printf("New %s revision %d for %s [%s]", fileType, revision, extID, country)
+
+	if s.fileRevisionsMysql == nil {
+		return nil
+	}
+
+	fr, err := fileRevisions.NewFileRevision(fileType, country, extID, sum, size, revision)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate file revision", err)
+	}
+
+	_, err = s.fileRevisionsMysql.Save(ctx, *fr)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to save file revision", err)
+	}
+
+	return nil
+}
+
+// fileHash : returns the sha256 (hex) and size of a file.
+func (s *SaveFileService) fileHash(ctx context.Context, fileDir, fileName string) (string, int64, error) {
+
+	fullPath := fmt.Sprintf("%s/%s", fileDir, fileName)
+	f, err := s.blobs.Open(ctx, fileDir, fileName)
+	if err != nil {
+		return "", 0, fmt.Errorf("err : %v failed to open %s", err, fullPath)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("err : %v failed to hash %s", err, fullPath)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// WatchFiles : saves files of fileType (odds, wo or ls) as they land in fileDir.
+// On start every file that arrived at or after the saved cursor is picked with a scan,
+// then fsnotify events take over. A file moved in keeps its mtime but not its ctime,
+// so the arrival time is used. The watcher is started before the scan so no file is
+// missed in between; saving the same file twice is harmless.
+func (s *SaveFileService) WatchFiles(ctx context.Context, fileType, fileDir, country string) error {
+
+	cursorName := fmt.Sprintf("%s_%s", country, fileType)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+
+		since := time.Time{}
+		cr, err := s.fileCursorsMysql.GetCursor(ctx, cursorName)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to read cursor %s", err, cursorName)
+		}
+
+		if len(cr) > 0 {
+			since = time.Unix(0, cr[0].LastModTime)
+			log.Printf("Cursor %s at %s [%s]", cursorName, since.Format("2006-01-02 15:04:05"), cr[0].LastFileName)
+		}
+
+		files := make(chan lsFiles.FileInfo, 1000)
+		watchErr := make(chan error, 1)
+
+		go func() {
+			watchErr <- s.dirWatcher.WatchDirectory(ctx, fileType, files)
+		}()
+
+		scanned, err := s.dirWatcher.ScanDirectory(ctx, fileType, since)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to scan %s", err, fileDir)
+		}
+
+		hashes, err := s.trackedHashes(ctx, fileType, country, scanned)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Startup scan %s : %d files, %d tracked", cursorName, len(scanned), len(hashes))
+
+		for _, f := range scanned {
+
+			if sum, ok := hashes[f.ExtID]; ok && sum != "" {
+				current, _, err := s.fileHash(ctx, fileDir, f.LsFileName)
+				if err != nil {
+					return err
+				}
+
+				if current == sum {
+					s.counters.record("duplicate", f.LsFileName)
+					continue
+				}
+			}
 
+			err := s.saveWatchedFile(ctx, cursorName, fileType, fileDir, country, f)
+			if err != nil {
+				return err
+			}
+		}
+
+	watch:
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+
+			case f := <-files:
+				err := s.saveWatchedFile(ctx, cursorName, fileType, fileDir, country, f)
+				if err != nil {
+					return err
+				}
+
+			case err := <-watchErr:
+				// Events may have been dropped, rescan from the cursor.
+				log.Printf("Err : %v watcher stopped for %s, rescanning", err, cursorName)
+				break watch
+			}
+		}
+	}
+}
+
+// trackedHashes : sha256 of the saved file per ext id for the ext ids of files, read
+// with one query. Files that are not saved yet are left out.
+func (s *SaveFileService) trackedHashes(ctx context.Context, fileType, country string, files []lsFiles.FileInfo) (map[string]string, error) {
+
+	hashes := make(map[string]string)
+
+	extIDs := []string{}
+	for _, f := range files {
+		extIDs = append(extIDs, f.ExtID)
+	}
+
+	if len(extIDs) == 0 {
+		return hashes, nil
+	}
+
+	switch fileType {
+	case "odds":
+		saved, err := s.oddsFileMysql.GetOddsFilesByParentIDs(ctx, extIDs, country)
+		if err != nil {
+			return hashes, fmt.Errorf("err : %v failed to read odds files", err)
+		}
+		for _, x := range saved {
+			hashes[x.ParentID] = x.Sha256
+		}
+	case "wo":
+		saved, err := s.woFilesMysql.GetWoFilesByExtIDs(ctx, extIDs, country)
+		if err != nil {
+			return hashes, fmt.Errorf("err : %v failed to read wo files", err)
+		}
+		for _, x := range saved {
+			hashes[x.WoExtID] = x.Sha256
+		}
+	case "ls":
+		saved, err := s.liveScoreFilesMysql.GetLsFilesByExtIDs(ctx, extIDs, country)
+		if err != nil {
+			return hashes, fmt.Errorf("err : %v failed to read live score files", err)
+		}
+		for _, x := range saved {
+			hashes[x.ExtID] = x.Sha256
+		}
+	default:
+		return hashes, fmt.Errorf("unknown file type %s", fileType)
+	}
+
+	return hashes, nil
+}
+
+// saveWatchedFile : saves a single file then moves the cursor to it. A file that could
+// not be saved moves the cursor back before it, a newer file saved first must not leave
+// it behind the cursor.
+func (s *SaveFileService) saveWatchedFile(ctx context.Context, cursorName, fileType, fileDir, country string, f lsFiles.FileInfo) error {
+
+	var err error
+
+	switch fileType {
+	case "odds":
+		err = s.saveOddsFile(ctx, fileDir, country, f)
+	case "wo":
+		err = s.saveWoFile(ctx, fileDir, country, f)
+	case "ls":
+		err = s.saveLsFile(ctx, fileDir, country, f)
+	default:
+		err = fmt.Errorf("unknown file type %s", fileType)
+	}
+
+	if err != nil {
+		s.counters.record("failed", f.LsFileName)
+
+		_, rErr := s.fileCursorsMysql.Rewind(ctx, cursorName, f.ModTime.UnixNano()-1)
+		if rErr != nil {
+			log.Printf("Err : %v", rErr)
+		}
+
+		return err
+	}
+
+	c, err := fileCursors.NewFileCursor(cursorName, f.LsFileName, f.ModTime.UnixNano())
+	if err != nil {
+		return fmt.Errorf("err : %v failed to instantiate file cursor", err)
+	}
+
+	_, err = s.fileCursorsMysql.Save(ctx, *c)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to save file cursor", err)
 	}
 
 	return nil
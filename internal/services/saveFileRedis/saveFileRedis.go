@@ -1,21 +1,34 @@
 package saveFileRedis
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/gomodule/redigo/redis"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobDir"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/blobs/blobS3"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileRevisions"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/fileRevisions/fileRevisionsMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/goalCategories"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/lsFiles/lsFilesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/oddsFiles/oddsFilesMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/processRedis/redisExec"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seasonWeeks/seasonWeeksMysql"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/seedCommitments/seedCommitmentsMysql"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles"
 	"github.com/lukemakhanu/magic_carpet/internal/domains/woFiles/woFilesMysql"
 )
@@ -44,12 +57,42 @@ type FileProcessorService struct {
 	woFilesMysql  woFiles.WoFilesRepository
 	oddsFileMysql oddsFiles.OddsFilesRepository
 	redisConn     processRedis.RunRedis
+
+	fileRevisionsMysql fileRevisions.FileRevisionsRepository
+
+	// keyPrefix is prepended to every key and staging set written, empty when live.
+	keyPrefix string
+
+	// oddsSet and sanitizedSet : a match a revision changes a key of is dropped from the
+	// sanitized sets and queued back in the odds set, not done when empty.
+	oddsSet      string
+	sanitizedSet string
+
+	// blobs holds the feed files, the local disk unless set.
+	blobs blobs.BlobsRepository
+
+	// seasonWeekMysql : season weeks published from a revised match are set back to
+	// inactive so that production_keys builds them again, not done when nil.
+	seasonWeekMysql seasonWeeks.SeasonWeeksRepository
+
+	// seedCommitments : the seed a taken back season week was drawn from is revoked, it is
+	// built again from a new one. Not done when nil.
+	seedCommitments seedCommitments.SeedCommitmentsRepository
 }
 
+// rebuildLead : a season week starting sooner than this is not taken back, production_keys
+// only builds weeks starting more than 2 minutes ahead.
+const rebuildLead = 3 * time.Minute
+
 // NewFileProcessorService : instantiate every connection we need to run current game service
 func NewFileProcessorService(cfgs ...FileProcessorConfiguration) (*FileProcessorService, error) {
+	local, err := blobDir.New()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the seasonService
-	os := &FileProcessorService{}
+	os := &FileProcessorService{blobs: local}
 	// Apply all Configurations passed in
 	for _, cfg := range cfgs {
 		// Pass the service into the configuration function
@@ -97,6 +140,88 @@ func WithMysqlRawOddsRepository(connectionString string) FileProcessorConfigurat
 	}
 }
 
+// WithMysqlFileRevisionsRepository : re-delivered files whose keys must be rebuilt
+func WithMysqlFileRevisionsRepository(connectionString string) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		d, err := fileRevisionsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.fileRevisionsMysql = d
+		return nil
+	}
+}
+
+// WithKeyPrefix : writes every key and staging set under prefix, used by replays
+func WithKeyPrefix(prefix string) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		os.keyPrefix = prefix
+		return nil
+	}
+}
+
+// WithSanitizedSets : sets the goals daemon reads matches from and files them under, so
+// that a match is categorised again once a revision changes its odds, wo or live score
+func WithSanitizedSets(oddsSet, sanitizedSet string) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		if oddsSet == "" || sanitizedSet == "" {
+			return fmt.Errorf("odds and sanitized sets must both be set")
+		}
+		os.oddsSet = oddsSet
+		os.sanitizedSet = sanitizedSet
+		return nil
+	}
+}
+
+// WithMysqlSeasonWeeksRepository : season weeks published from a match a revision changes
+// are taken back and built again from the revision
+func WithMysqlSeasonWeeksRepository(connectionString string) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		d, err := seasonWeekMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seasonWeekMysql = d
+		return nil
+	}
+}
+
+// WithMysqlSeedCommitmentsRepository : revokes the seed commitment of a season week taken
+// back so that it is drawn again from a new seed
+func WithMysqlSeedCommitmentsRepository(connectionString string) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		d, err := seedCommitmentsMysql.New(connectionString)
+		if err != nil {
+			return err
+		}
+		os.seedCommitments = d
+		return nil
+	}
+}
+
+// WithBlobRepository : reads feed files from store instead of the local disk
+func WithBlobRepository(store blobs.BlobsRepository) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		if store == nil {
+			return fmt.Errorf("store not set")
+		}
+		os.blobs = store
+		return nil
+	}
+}
+
+// WithS3BlobRepository : reads feed files from an S3 compatible bucket
+func WithS3BlobRepository(endpoint, region, bucket, accessKey, secretKey string, useSSL bool) FileProcessorConfiguration {
+	return func(os *FileProcessorService) error {
+		d, err := blobS3.New(endpoint, region, bucket, accessKey, secretKey, useSSL)
+		if err != nil {
+			return err
+		}
+		os.blobs = d
+		return nil
+	}
+}
+
 // WithRedisRepository : instantiates redis connections
 func WithRedisRepository(redisServer string, dbNum int, maxIdle int, maxActive int, idleTimeout time.Duration) FileProcessorConfiguration {
 	return func(os *FileProcessorService) error {
@@ -192,19 +317,13 @@ func (s *FileProcessorService) ReturnZRangeData(ctx context.Context, zSetKey str
 // ReturnRawWO : returns all winning outcomes
 func (s *FileProcessorService) ReturnRawWO(ctx context.Context, countryWoStagingSet, fullDirPath string, matchChan chan Job) error {
 
-	jsonFile, err := os.Open(fullDirPath)
-	defer jsonFile.Close()
+	byteValue, err := s.readFile(ctx, path.Dir(fullDirPath), path.Base(fullDirPath))
 	if err != nil {
-		return fmt.Errorf("failed to open file %s | err : %v", fullDirPath, err)
+		return err
 	}
 
 	log.Printf("Successfully opened : %s", fullDirPath)
 
-	byteValue, err := io.ReadAll(jsonFile)
-	if err != nil {
-		return fmt.Errorf("failed to readAll %s | err : %v", fullDirPath, err)
-	}
-
 	// fullDirPath ==> /tz/generator/winning_outcomes/wo_31236351_3_18.txt
 
 	dd := strings.Split(fullDirPath, "/")
@@ -228,6 +347,8 @@ func (s *FileProcessorService) ReturnRawWO(ctx context.Context, countryWoStaging
 
 				hh := woFiles.MatchWO{}
 				hh.RoundNumberID = wo.RoundNumberID
+				hh.TournamentID = wo.TournamentID
+				hh.RoundName = wo.RoundName
 
 				for _, x := range wo.WinningOutcomes {
 
@@ -252,6 +373,9 @@ func (s *FileProcessorService) ReturnRawWO(ctx context.Context, countryWoStaging
 					if i == x.ParentMatchID {
 						hh.AwayScore = x.AwayScore
 						hh.HomeScore = x.HomeScore
+						hh.EventNumber = x.EventNumber
+						hh.HomeTeamID = x.HomeTeamID
+						hh.AwayTeamID = x.AwayTeamID
 					}
 
 				}
@@ -262,7 +386,12 @@ func (s *FileProcessorService) ReturnRawWO(ctx context.Context, countryWoStaging
 					log.Println("Error:", err)
 				}
 
-				key := fmt.Sprintf("%s%s:%s", dd[1], "Wo", i)
+				key := fmt.Sprintf("%s%s%s:%s", s.keyPrefix, dd[1], "Wo", i)
+				err = s.revise(ctx, key, string(jsonData), dd[1], i)
+				if err != nil {
+					return err
+				}
+
 				log.Printf("wo Key saved --> : %s", key)
 				err = s.redisConn.Set(ctx, key, string(jsonData))
 				if err != nil {
@@ -270,7 +399,7 @@ func (s *FileProcessorService) ReturnRawWO(ctx context.Context, countryWoStaging
 				}
 
 				// Used for production
-				err = s.redisConn.ZAdd(ctx, countryWoStagingSet, "1", key)
+				err = s.redisConn.ZAdd(ctx, s.keyPrefix+countryWoStagingSet, "1", key)
 				if err != nil {
 					return fmt.Errorf("err : %v unable to save wo into redis sorted set", err)
 				}
@@ -324,20 +453,18 @@ func (s *FileProcessorService) ReturnOdds(ctx context.Context, countryOddsStagin
 
 	for _, x := range odds {
 
-		fullPath := fmt.Sprintf("%s/%s", x.FileDirectory, x.OddsFileName)
-		jsonFile, err := os.Open(fullPath)
-		defer jsonFile.Close()
+		byteValue, err := s.readFile(ctx, x.FileDirectory, x.OddsFileName)
 		if err != nil {
-			log.Printf("Failed to open file %s | err : %v", fullPath, err)
+			return err
 		}
 
-		byteValue, err := io.ReadAll(jsonFile)
+		// Push this Raw odds as a key in redis.
+		key := fmt.Sprintf("%s%s%s%s", s.keyPrefix, countryCode, "O:", parentMatchID)
+		err = s.revise(ctx, key, string(byteValue), countryCode, parentMatchID)
 		if err != nil {
-			return fmt.Errorf("failed to readAll %s | err : %v", fullPath, err)
+			return err
 		}
 
-		// Push this Raw odds as a key in redis.
-		key := fmt.Sprintf("%s%s%s", countryCode, "O:", parentMatchID)
 		log.Printf("odds Key saved --> : %s", key)
 		err = s.redisConn.Set(ctx, key, string(byteValue))
 		if err != nil {
@@ -345,11 +472,20 @@ func (s *FileProcessorService) ReturnOdds(ctx context.Context, countryOddsStagin
 		}
 
 		// Used for production
-		err = s.redisConn.ZAdd(ctx, countryOddsStagingSet, "1", key)
+		err = s.redisConn.ZAdd(ctx, s.keyPrefix+countryOddsStagingSet, "1", key)
 		if err != nil {
 			return fmt.Errorf("err : %v unable to save wo into redis sorted set", err)
 		}
 
+		// Links the odds file to its round, the retention only archives it once the
+		// winning outcomes of the round are processed.
+		if roundNumberID != "" && s.keyPrefix == "" {
+			_, err = s.oddsFileMysql.SaveMatchOdds(ctx, roundNumberID, parentMatchID, countryCode)
+			if err != nil {
+				return fmt.Errorf("err : %v unable to link odds of %s to round %s", err, parentMatchID, roundNumberID)
+			}
+		}
+
 		return nil
 
 	}
@@ -367,16 +503,9 @@ func (s *FileProcessorService) ReturnRawLs(ctx context.Context, countryLsStaging
 
 	for _, x := range liveScores {
 
-		fullPath := fmt.Sprintf("%s/%s", x.LsDir, x.LsFileName)
-		jsonFile, err := os.Open(fullPath)
-		defer jsonFile.Close()
-		if err != nil {
-			return fmt.Errorf("failed to open file %s | err : %v", fullPath, err)
-		}
-
-		byteValue, err := io.ReadAll(jsonFile)
+		byteValue, err := s.readFile(ctx, x.LsDir, x.LsFileName)
 		if err != nil {
-			return fmt.Errorf("failed to readAll %s | err :: %v", fullPath, err)
+			return err
 		}
 
 		var ls lsFiles.LsData
@@ -415,7 +544,12 @@ func (s *FileProcessorService) ReturnRawLs(ctx context.Context, countryLsStaging
 				log.Println("Error:", err)
 			}
 
-			key := fmt.Sprintf("%s%s:%s", countryCode, "Ls", x)
+			key := fmt.Sprintf("%s%s%s:%s", s.keyPrefix, countryCode, "Ls", x)
+			err = s.revise(ctx, key, string(jsonData), countryCode, x)
+			if err != nil {
+				return err
+			}
+
 			log.Printf("ls Key saved --> : %s", key)
 			err = s.redisConn.Set(ctx, key, string(jsonData))
 			if err != nil {
@@ -423,7 +557,7 @@ func (s *FileProcessorService) ReturnRawLs(ctx context.Context, countryLsStaging
 			}
 
 			// Used for production
-			err = s.redisConn.ZAdd(ctx, countryLsStagingSet, "1", key)
+			err = s.redisConn.ZAdd(ctx, s.keyPrefix+countryLsStagingSet, "1", key)
 			if err != nil {
 				return fmt.Errorf("err : %v unable to save wo into redis sorted set", err)
 			}
@@ -436,6 +570,119 @@ func (s *FileProcessorService) ReturnRawLs(ctx context.Context, countryLsStaging
 
 }
 
+// revise : when key already holds another payload the match of parentMatchID was
+// categorised and published from what is about to be replaced. It is dropped from every
+// sanitized set and queued back in the odds set, so that the goals daemon checks and files
+// it again, and the season weeks published from it are taken back.
+func (s *FileProcessorService) revise(ctx context.Context, key, payload, countryCode, parentMatchID string) error {
+
+	if s.sanitizedSet == "" && s.seasonWeekMysql == nil {
+		return nil
+	}
+
+	saved, err := s.redisConn.Get(ctx, key)
+	if errors.Is(err, redis.ErrNil) {
+		// Not saved before.
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("err : %v failed to read %s", err, key)
+	}
+
+	if saved == payload {
+		return nil
+	}
+
+	match := fmt.Sprintf("%s%s%s%s", s.keyPrefix, countryCode, "O:", parentMatchID)
+
+	if s.sanitizedSet != "" {
+
+		for _, set := range goalCategories.Sets(s.keyPrefix + s.sanitizedSet) {
+			_, err := s.redisConn.ZRem(ctx, set, match)
+			if err != nil {
+				return fmt.Errorf("err : %v failed to remove revised %s from %s", err, match, set)
+			}
+		}
+
+		err = s.redisConn.ZAdd(ctx, s.keyPrefix+s.oddsSet, "1", match)
+		if err != nil {
+			return fmt.Errorf("err : %v failed to queue revised %s in %s", err, match, s.oddsSet)
+		}
+
+		log.Printf("%s changed, %s queued to be categorised again", key, match)
+	}
+
+	return s.takeBackWeeks(ctx, match)
+}
+
+// takeBackWeeks : deletes the keys of every season week published from match that starts
+// later than rebuildLead, revokes the seed it was drawn from and sets it back to inactive,
+// production_keys then builds it again from a new seed. Weeks starting sooner keep the keys
+// they were published with.
+func (s *FileProcessorService) takeBackWeeks(ctx context.Context, match string) error {
+
+	if s.seasonWeekMysql == nil {
+		return nil
+	}
+
+	builtKey := oddsFiles.BuiltWeeksKey(match)
+	saved, err := s.redisConn.Get(ctx, builtKey)
+	if errors.Is(err, redis.ErrNil) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("err : %v failed to read %s", err, builtKey)
+	}
+
+	ww := []oddsFiles.BuiltWeek{}
+	err = json.Unmarshal([]byte(saved), &ww)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to decode %s", err, builtKey)
+	}
+
+	kept := []oddsFiles.BuiltWeek{}
+	for _, w := range ww {
+
+		startTime, err := time.ParseInLocation("2006-01-02 15:04:05", w.StartTime, time.Local)
+		if err != nil || startTime.Before(time.Now().Add(rebuildLead)) {
+			log.Printf("Season week %s starts at %s, it keeps the odds %s was published with", w.SeasonWeekID, w.StartTime, match)
+			kept = append(kept, w)
+			continue
+		}
+
+		for _, k := range w.Keys {
+			_, err := s.redisConn.Delete(ctx, k)
+			if err != nil {
+				return fmt.Errorf("err : %v failed to delete %s of season week %s", err, k, w.SeasonWeekID)
+			}
+		}
+
+		if s.seedCommitments != nil {
+			err = s.seedCommitments.Revoke(ctx, seedCommitments.SeasonWeek, w.SeasonWeekID)
+			if err != nil {
+				return fmt.Errorf("err : %v failed to revoke the seed of season week %s", err, w.SeasonWeekID)
+			}
+		}
+
+		_, err = s.seasonWeekMysql.UpdateSsnWeekStatus(ctx, w.SeasonWeekID, w.SeasonID, "inactive")
+		if err != nil {
+			return fmt.Errorf("err : %v failed to set season week %s back to inactive", err, w.SeasonWeekID)
+		}
+
+		log.Printf("Season week %s taken back, %s was revised", w.SeasonWeekID, match)
+	}
+
+	if len(kept) == len(ww) {
+		return nil
+	}
+
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return fmt.Errorf("err : %v failed to encode %s", err, builtKey)
+	}
+
+	expiry := "108000"
+	return s.redisConn.SetWithExpiry(ctx, builtKey, string(data), expiry)
+}
+
 func (s *FileProcessorService) RemoveFromList(ctx context.Context, list, item string) error {
 	_, err := s.redisConn.ZRem(ctx, list, item)
 	if err != nil {
@@ -469,3 +716,50 @@ func (s *FileProcessorService) UpdateWoStatus(ctx context.Context, status, woFil
 	}
 	return wID, nil
 }
+
+// PendingRevisions : returns re-delivered files not yet pushed to redis
+func (s *FileProcessorService) PendingRevisions(ctx context.Context, status string) ([]fileRevisions.FileRevisions, error) {
+	fr, err := s.fileRevisionsMysql.GetPendingRevisions(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("err %v | failed to return file revisions", err)
+	}
+	return fr, nil
+}
+
+// UpdateRevisionStatus :
+func (s *FileProcessorService) UpdateRevisionStatus(ctx context.Context, status, fileRevisionID string) (int64, error) {
+	fID, err := s.fileRevisionsMysql.UpdateRevisionStatus(ctx, status, fileRevisionID)
+	if err != nil {
+		return 0, fmt.Errorf("err %v | failed to update file revision", err)
+	}
+	return fID, nil
+}
+
+// readFile : reads a whole feed file from the blob store.
+func (s *FileProcessorService) readFile(ctx context.Context, fileDir, fileName string) ([]byte, error) {
+
+	f, err := s.blobs.Open(ctx, fileDir, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s/%s | err : %v", fileDir, fileName, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+
+	// Archived files are gzipped by the retention job.
+	if strings.HasSuffix(fileName, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to gunzip %s/%s | err : %v", fileDir, fileName, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	byteValue, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to readAll %s/%s | err : %v", fileDir, fileName, err)
+	}
+
+	return byteValue, nil
+}
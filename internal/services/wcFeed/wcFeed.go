@@ -0,0 +1,167 @@
+package wcFeed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lukemakhanu/magic_carpet/internal/domains/wcFeeds"
+	"github.com/lukemakhanu/magic_carpet/internal/domains/wcFeeds/wcXml"
+)
+
+// WcFeedConfiguration is an alias for a function that will take in a pointer to an WcFeedService and modify it
+type WcFeedConfiguration func(os *WcFeedService) error
+
+// WcFeedService is a implementation of the WcFeedService
+type WcFeedService struct {
+	wcFeed        wcFeeds.WcFeedRepository
+	projectID     string
+	competitionID string
+}
+
+// NewWcFeedService : instantiate every connection we need to run current game service
+func NewWcFeedService(cfgs ...WcFeedConfiguration) (*WcFeedService, error) {
+	// Create the WcFeedService
+	os := &WcFeedService{}
+	// Apply all Configurations passed in
+	for _, cfg := range cfgs {
+		// Pass the service into the configuration function
+		err := cfg(os)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os, nil
+}
+
+// WithXmlFeedRepository : instantiates the World Cup xml feed reader
+func WithXmlFeedRepository(feedFile, projectID, competitionID string) WcFeedConfiguration {
+	return func(os *WcFeedService) error {
+		d, err := wcXml.New(feedFile, projectID, competitionID)
+		if err != nil {
+			return err
+		}
+		os.wcFeed = d
+		os.projectID = projectID
+		os.competitionID = competitionID
+		return nil
+	}
+}
+
+// SaveOddsFiles : writes one odds file per match (<parentID>_<projectID>_<competitionID>.txt)
+// into the odds directory so that saveFile picks it up like league odds.
+func (s *WcFeedService) SaveOddsFiles(ctx context.Context, oddsDir string) (int, error) {
+
+	odds, err := s.wcFeed.RawOdds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Err : %v failed to read world cup odds", err)
+	}
+
+	saved := 0
+	for _, o := range odds {
+
+		fileName := fmt.Sprintf("%s_%s_%s.txt", o.ParentMatchID, s.projectID, s.competitionID)
+
+		ok, err := writeFile(oddsDir, fileName, o)
+		if err != nil {
+			return saved, err
+		}
+
+		if ok {
+			log.Printf("Saved odds %s | round %s | event number %s", fileName, o.RoundName, o.EventNumber)
+			saved++
+		}
+	}
+
+	return saved, nil
+}
+
+// SaveWinningOutcomeFiles : writes one winning outcome file per resulted round
+// (wo_<roundID>_<projectID>_<competitionID>.txt) into the winning outcome directory.
+func (s *WcFeedService) SaveWinningOutcomeFiles(ctx context.Context, woDir string) (int, error) {
+
+	rounds, err := s.wcFeed.RoundWinningOutcomes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Err : %v failed to read world cup winning outcomes", err)
+	}
+
+	saved := 0
+	for _, r := range rounds {
+
+		fileName := fmt.Sprintf("wo_%s_%s_%s.txt", r.RoundNumberID, s.projectID, s.competitionID)
+
+		ok, err := writeFile(woDir, fileName, r)
+		if err != nil {
+			return saved, err
+		}
+
+		if ok {
+			log.Printf("Saved winning outcomes %s | round %s", fileName, r.RoundName)
+			saved++
+		}
+	}
+
+	return saved, nil
+}
+
+// SaveLiveScoreFiles : writes one live score file per resulted round
+// (<roundID>_<projectID>_<competitionID>.txt) into the live score directory.
+func (s *WcFeedService) SaveLiveScoreFiles(ctx context.Context, lsDir string) (int, error) {
+
+	rounds, err := s.wcFeed.RoundLiveScores(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Err : %v failed to read world cup live scores", err)
+	}
+
+	saved := 0
+	for _, r := range rounds {
+
+		fileName := fmt.Sprintf("%s_%s_%s.txt", r.RoundNumberID, s.projectID, s.competitionID)
+
+		ok, err := writeFile(lsDir, fileName, r)
+		if err != nil {
+			return saved, err
+		}
+
+		if ok {
+			log.Printf("Saved live scores %s", fileName)
+			saved++
+		}
+	}
+
+	return saved, nil
+}
+
+// writeFile : writes payload as json. A file already delivered is only written again when
+// the feed changed it, saveFile records that as a new revision. The file is renamed into
+// place so readers never see a partial file.
+func writeFile(dir, fileName string, payload interface{}) (bool, error) {
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("Err : %v failed to marshal %s", err, fileName)
+	}
+
+	fullPath := filepath.Join(dir, fileName)
+	if current, err := os.ReadFile(fullPath); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	// Directory readers skip hidden files.
+	tmpPath := filepath.Join(dir, "."+fileName)
+	err = os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return false, fmt.Errorf("Err : %v failed to write %s", err, tmpPath)
+	}
+
+	err = os.Rename(tmpPath, fullPath)
+	if err != nil {
+		return false, fmt.Errorf("Err : %v failed to rename %s", err, tmpPath)
+	}
+
+	return true, nil
+}